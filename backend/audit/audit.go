@@ -0,0 +1,350 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kataras/iris"
+)
+
+// the request bodies are recorded up to this size
+const maxBodySize = 64 << 10
+
+// Record of one mutating operation.
+type Record struct {
+	Time       time.Time         `json:"time"`
+	Op         string            `json:"op"`
+	Method     string            `json:"method"`
+	Path       string            `json:"path"`
+	RemoteAddr string            `json:"remoteAddr"`
+	User       string            `json:"user,omitempty"`
+	Params     map[string]string `json:"params,omitempty"`
+	Body       json.RawMessage   `json:"body,omitempty"`
+	Status     int               `json:"status"`
+	Err        string            `json:"error,omitempty"`
+	// e.g. the previous key values returned by the op
+	Result interface{} `json:"result,omitempty"`
+}
+
+// Query of the records, the empty fields match all.
+type Query struct {
+	Op         string
+	User       string
+	RemoteAddr string
+	// a part of the key in the params or the body
+	Key   string
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
+// Log is an append-only json lines file, rotated by size, "audit.log.1" is the newest backup.
+type Log struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	userHeader string
+	// the headers of the client address set by the proxies, in the metadata of the gRPC calls,
+	// the enabled ones are looked up in order as iris does for the requests
+	remoteAddrHeaders []string
+
+	file *os.File
+	size int64
+}
+
+func Open(path string, maxSize int64, maxBackups int, userHeader string, remoteAddrHeaders map[string]bool) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	l := &Log{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		userHeader: userHeader,
+	}
+	for name, enabled := range remoteAddrHeaders {
+		if enabled {
+			l.remoteAddrHeaders = append(l.remoteAddrHeaders, name)
+		}
+	}
+	sort.Strings(l.remoteAddrHeaders)
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *Log) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	l.file = file
+	l.size = stat.Size()
+	return nil
+}
+
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+func (l *Log) Write(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return errors.New("audit log is closed")
+	}
+
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(data)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.file.Write(data)
+	l.size += int64(n)
+	return err
+}
+
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	l.file = nil
+
+	// drop the oldest, then shift the rest
+	os.Remove(l.backup(l.maxBackups))
+	for idx := l.maxBackups - 1; idx >= 1; idx-- {
+		os.Rename(l.backup(idx), l.backup(idx+1))
+	}
+	if l.maxBackups > 0 {
+		if err := os.Rename(l.path, l.backup(1)); err != nil {
+			return err
+		}
+	} else {
+		os.Remove(l.path)
+	}
+
+	return l.open()
+}
+
+func (l *Log) backup(idx int) string {
+	return fmt.Sprintf("%s.%d", l.path, idx)
+}
+
+// Query returns the matched records from the newest, and whether there are more.
+// The files are scanned line by line out of the lock, only the newest matched records of a file are kept.
+func (l *Log) Query(query Query) ([]Record, bool, error) {
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+
+	files, err := l.snapshot()
+	if err != nil {
+		return nil, false, err
+	}
+	defer closeFiles(files)
+
+	var retRecords []Record
+	// from the current file to the oldest backup
+	for _, file := range files {
+		// one more than needed tells there are more
+		records, err := file.scan(query, query.Limit-len(retRecords)+1)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for idx := len(records) - 1; idx >= 0; idx-- {
+			if len(retRecords) >= query.Limit {
+				return retRecords, true, nil
+			}
+			retRecords = append(retRecords, records[idx])
+		}
+	}
+
+	return retRecords, false, nil
+}
+
+// snapshotFile is a file of the log as of the snapshot, the records appended since are not read.
+type snapshotFile struct {
+	*os.File
+	size int64
+}
+
+// snapshot opens the current file and the backups under the lock, the opened files are still read
+// as they were if they're rotated during the scan.
+func (l *Log) snapshot() ([]snapshotFile, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	paths := []string{l.path}
+	for idx := 1; idx <= l.maxBackups; idx++ {
+		paths = append(paths, l.backup(idx))
+	}
+
+	var files []snapshotFile
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			closeFiles(files)
+			return nil, err
+		}
+		stat, err := file.Stat()
+		if err != nil {
+			file.Close()
+			closeFiles(files)
+			return nil, err
+		}
+
+		files = append(files, snapshotFile{file, stat.Size()})
+	}
+
+	return files, nil
+}
+
+func closeFiles(files []snapshotFile) {
+	for _, file := range files {
+		file.Close()
+	}
+}
+
+// scan returns the last n matched records of the file, in the order of the file.
+func (f snapshotFile) scan(query Query, n int) ([]Record, error) {
+	var records []Record
+
+	scanner := bufio.NewScanner(io.LimitReader(f.File, f.size))
+	scanner.Buffer(make([]byte, 64<<10), 16<<20)
+	for scanner.Scan() {
+		var record Record
+		// a torn line is skipped
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil || !query.match(record) {
+			continue
+		}
+
+		records = append(records, record)
+		if len(records) > n {
+			records = records[1:]
+		}
+	}
+
+	return records, scanner.Err()
+}
+
+func (q Query) match(record Record) bool {
+	if len(q.Op) != 0 && record.Op != q.Op {
+		return false
+	}
+	if len(q.User) != 0 && record.User != q.User {
+		return false
+	}
+	if len(q.RemoteAddr) != 0 && record.RemoteAddr != q.RemoteAddr {
+		return false
+	}
+	if !q.Since.IsZero() && record.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && record.Time.After(q.Until) {
+		return false
+	}
+	if len(q.Key) != 0 && !strings.Contains(record.Params["key"], q.Key) && !bytes.Contains(record.Body, []byte(q.Key)) {
+		return false
+	}
+
+	return true
+}
+
+// CaptureBody keeps a copy of the json body for the record, the body can still be read as usual.
+func CaptureBody(irisCtx iris.Context) {
+	req := irisCtx.Request()
+	if req.Method == iris.MethodGet || req.Body == nil || !strings.Contains(req.Header.Get("Content-Type"), "json") {
+		return
+	}
+
+	captured, err := ioutil.ReadAll(io.LimitReader(req.Body, maxBodySize))
+	if err != nil {
+		return
+	}
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(captured), req.Body), req.Body}
+
+	irisCtx.Values().Set("etcd-console.audit.body", captured)
+}
+
+// NewRecord fills the record from the request, the user is taken from the basic auth, or the user header
+// set by an authenticating proxy.
+func (l *Log) NewRecord(irisCtx iris.Context, op string) Record {
+	req := irisCtx.Request()
+
+	record := Record{
+		Time:       time.Now(),
+		Op:         op,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		RemoteAddr: irisCtx.RemoteAddr(),
+	}
+
+	if user, _, ok := req.BasicAuth(); ok {
+		record.User = user
+	} else if len(l.userHeader) != 0 {
+		record.User = req.Header.Get(l.userHeader)
+	}
+
+	if query := req.URL.Query(); len(query) != 0 {
+		record.Params = make(map[string]string, len(query))
+		for name, values := range query {
+			record.Params[name] = strings.Join(values, ",")
+		}
+	}
+
+	if captured, ok := irisCtx.Values().Get("etcd-console.audit.body").([]byte); ok && len(captured) != 0 {
+		if json.Valid(captured) {
+			record.Body = json.RawMessage(captured)
+		} else {
+			// truncated
+			data, _ := json.Marshal(string(captured))
+			record.Body = json.RawMessage(data)
+		}
+	}
+
+	return record
+}
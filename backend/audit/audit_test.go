@@ -0,0 +1,161 @@
+package audit
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func openLog(t *testing.T, maxSize int64, maxBackups int) (*Log, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "etcd-console-audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := Open(filepath.Join(dir, "audit.log"), maxSize, maxBackups, "", nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	return l, func() {
+		l.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func paths(records []Record) []string {
+	ret := make([]string, 0, len(records))
+	for _, record := range records {
+		ret = append(ret, record.Path)
+	}
+	return ret
+}
+
+func TestQuery(t *testing.T) {
+	l, closeFn := openLog(t, 0, 0)
+	defer closeFn()
+
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []Record{
+		{Op: "put", Path: "1", User: "alice", RemoteAddr: "10.0.0.1", Params: map[string]string{"key": "/a/1"}},
+		{Op: "delete", Path: "2", User: "bob", RemoteAddr: "10.0.0.2", Params: map[string]string{"key": "/a/2"}},
+		{Op: "put", Path: "3", User: "bob", RemoteAddr: "10.0.0.1", Body: []byte(`{"key":"/b/1"}`)},
+		{Op: "import", Path: "4", User: "alice", RemoteAddr: "10.0.0.2"},
+	}
+	for idx, record := range records {
+		record.Time = start.Add(time.Duration(idx) * time.Minute)
+		if err := l.Write(record); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		query    Query
+		want     []string
+		wantMore bool
+	}{
+		{"all from the newest", Query{}, []string{"4", "3", "2", "1"}, false},
+		{"op", Query{Op: "put"}, []string{"3", "1"}, false},
+		{"user", Query{User: "alice"}, []string{"4", "1"}, false},
+		{"remote address", Query{RemoteAddr: "10.0.0.2"}, []string{"4", "2"}, false},
+		{"key in the params", Query{Key: "/a/"}, []string{"2", "1"}, false},
+		{"key in the body", Query{Key: "/b/"}, []string{"3"}, false},
+		{"since", Query{Since: start.Add(2 * time.Minute)}, []string{"4", "3"}, false},
+		{"until", Query{Until: start.Add(time.Minute)}, []string{"2", "1"}, false},
+		{"limit", Query{Limit: 2}, []string{"4", "3"}, true},
+		{"limit of all", Query{Op: "put", Limit: 2}, []string{"3", "1"}, false},
+		{"nothing", Query{User: "carol"}, []string{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, more, err := l.Query(tt.query)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if !reflect.DeepEqual(paths(got), tt.want) {
+				t.Errorf("Query() = %v, want %v", paths(got), tt.want)
+			}
+			if more != tt.wantMore {
+				t.Errorf("Query() more = %v, want %v", more, tt.wantMore)
+			}
+		})
+	}
+}
+
+func TestRotate(t *testing.T) {
+	// every record takes a file of its own
+	l, closeFn := openLog(t, 10, 2)
+	defer closeFn()
+
+	for idx := 1; idx <= 5; idx++ {
+		if err := l.Write(Record{Op: "put", Path: fmt.Sprint(idx)}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	for _, name := range []string{l.path, l.backup(1), l.backup(2)} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("%s is missing, %v", name, err)
+		}
+	}
+	if _, err := os.Stat(l.backup(3)); !os.IsNotExist(err) {
+		t.Errorf("%s is kept beyond the backups", l.backup(3))
+	}
+
+	// the oldest records are dropped with their files
+	got, more, err := l.Query(Query{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if want := []string{"5", "4", "3"}; !reflect.DeepEqual(paths(got), want) || more {
+		t.Errorf("Query() = %v, %v, want %v", paths(got), more, want)
+	}
+
+	// the limit is reached across the files
+	got, more, err = l.Query(Query{Limit: 2})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if want := []string{"5", "4"}; !reflect.DeepEqual(paths(got), want) || !more {
+		t.Errorf("Query() = %v, %v, want %v with more", paths(got), more, want)
+	}
+}
+
+func TestQueryNewest(t *testing.T) {
+	l, closeFn := openLog(t, 64<<10, 2)
+	defer closeFn()
+
+	for idx := 1; idx <= 2000; idx++ {
+		if err := l.Write(Record{Op: "put", Path: fmt.Sprint(idx)}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	// the newest of the current file, then of the backups
+	for _, limit := range []int{3, 1500} {
+		got, more, err := l.Query(Query{Limit: limit})
+		if err != nil {
+			t.Fatalf("Query() error = %v", err)
+		}
+		if len(got) != limit || got[0].Path != "2000" || got[limit-1].Path != fmt.Sprint(2001-limit) || !more {
+			t.Errorf("Query(%d) = %d records from %s, more %v", limit, len(got), got[0].Path, more)
+		}
+	}
+}
+
+func TestWriteClosed(t *testing.T) {
+	l, closeFn := openLog(t, 0, 0)
+	defer closeFn()
+
+	l.Close()
+	if err := l.Write(Record{Op: "put"}); err == nil {
+		t.Errorf("Write() of a closed log wants an error")
+	}
+}
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// MethodGRPC is the method of the records of the gRPC calls.
+const MethodGRPC = "GRPC"
+
+// NewCallRecord fills the record from a gRPC call, the path is the full method, the body is the request message,
+// and the user and the client address are taken from the metadata, the same as NewRecord from the headers.
+func (l *Log) NewCallRecord(ctx context.Context, op string, fullMethod string, request interface{}) Record {
+	record := Record{
+		Time:   time.Now(),
+		Op:     op,
+		Method: MethodGRPC,
+		Path:   fullMethod,
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		record.RemoteAddr = p.Addr.String()
+		if host, _, err := net.SplitHostPort(record.RemoteAddr); err == nil {
+			record.RemoteAddr = host
+		}
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		// the keys of the metadata are in lower case
+		req := &http.Request{Header: http.Header{"Authorization": md["authorization"]}}
+		if user, _, ok := req.BasicAuth(); ok {
+			record.User = user
+		} else if values := md[strings.ToLower(l.userHeader)]; len(l.userHeader) != 0 && len(values) != 0 {
+			record.User = values[0]
+		}
+
+		for _, name := range l.remoteAddrHeaders {
+			values := md[strings.ToLower(name)]
+			if len(values) == 0 {
+				continue
+			}
+			value := values[0]
+			// the first one of the proxies is the client
+			if strings.EqualFold(name, "X-Forwarded-For") {
+				if idx := strings.IndexByte(value, ','); idx >= 0 {
+					value = value[:idx]
+				}
+			}
+			if value = strings.TrimSpace(value); len(value) != 0 {
+				record.RemoteAddr = value
+				break
+			}
+		}
+	}
+
+	if request != nil {
+		if data, err := json.Marshal(request); err == nil && len(data) <= maxBodySize {
+			record.Body = json.RawMessage(data)
+		}
+	}
+
+	return record
+}
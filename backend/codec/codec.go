@@ -0,0 +1,153 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/thxcode/etcd-console/backend/prefix"
+)
+
+// Decoder renders the stored values it understands as structures.
+type Decoder interface {
+	Name() string
+
+	// Match reports whether the value is understood, it's used to detect the decoder.
+	Match(key, value []byte) bool
+
+	Decode(key, value []byte) (Decoded, error)
+}
+
+// Codec also encodes the input before it's written, decoders without encoding are read-only.
+type Codec interface {
+	Decoder
+
+	// Encode validates the input and returns the value to store.
+	Encode(key, input []byte) ([]byte, error)
+}
+
+// Options of the codecs which need more than the name.
+type Options struct {
+	// protobuf: the FileDescriptorSet file with imports,
+	// e.g. generated by "protoc --include_imports --descriptor_set_out".
+	// kubernetes: the optional one of the kubernetes types, whose messages are found by the kinds of the objects.
+	DescriptorSet string
+
+	// protobuf: the full name of the message, e.g. "foo.v1.Bar".
+	Message string
+}
+
+// Decoded value, the api version and the kind are only known by some decoders.
+type Decoded struct {
+	APIVersion string
+	Kind       string
+	Object     interface{}
+	// Note tells what's not decoded, e.g. the fields without the descriptors
+	Note string
+}
+
+var (
+	decodersMu sync.RWMutex
+	decoders   []Decoder
+)
+
+func init() {
+	// the order matters to the detection
+	RegisterDecoder(&kubernetesDecoder{})
+	RegisterDecoder(&gzipCodec{})
+	RegisterDecoder(&jsonCodec{})
+	RegisterDecoder(&yamlCodec{})
+}
+
+// New returns the named decoder, the protobuf and the kubernetes ones are created from the descriptors in options.
+func New(name string, options Options) (Decoder, error) {
+	switch name {
+	case protobufCodecName:
+		return newProtobufCodec(options)
+	case kubernetesCodecName:
+		return newKubernetesDecoder(options)
+	}
+
+	if decoder, ok := GetDecoder(name); ok {
+		return decoder, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("unknown codec %s", name))
+}
+
+// RegisterDecoder adds the decoder, or replaces the one with the same name.
+func RegisterDecoder(decoder Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+
+	for idx := range decoders {
+		if decoders[idx].Name() == decoder.Name() {
+			decoders[idx] = decoder
+			return
+		}
+	}
+	decoders = append(decoders, decoder)
+}
+
+func GetDecoder(name string) (Decoder, bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+
+	for _, decoder := range decoders {
+		if decoder.Name() == name {
+			return decoder, true
+		}
+	}
+
+	return nil, false
+}
+
+// DetectDecoder returns the first registered decoder which matches the value.
+func DetectDecoder(key, value []byte) (Decoder, bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+
+	for _, decoder := range decoders {
+		if decoder.Match(key, value) {
+			return decoder, true
+		}
+	}
+
+	return nil, false
+}
+
+// Mapping picks the decoder of a key by the longest matched prefix.
+type Mapping struct {
+	table prefix.Table
+}
+
+func NewMapping() *Mapping {
+	return &Mapping{}
+}
+
+func (m *Mapping) Add(prefix string, decoder Decoder) {
+	m.table.Add(prefix, decoder)
+}
+
+func (m *Mapping) Lookup(key []byte) (Decoder, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	decoder, ok := m.table.Lookup(key)
+	if !ok {
+		return nil, false
+	}
+	return decoder.(Decoder), true
+}
+
+// LookupCodec is like Lookup, but only returns the decoders which can encode.
+func (m *Mapping) LookupCodec(key []byte) (Codec, bool) {
+	decoder, ok := m.Lookup(key)
+	if !ok {
+		return nil, false
+	}
+
+	codec, ok := decoder.(Codec)
+	return codec, ok
+}
@@ -0,0 +1,396 @@
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// kubernetesProtobuf wraps the object in the storage envelope of the kube-apiserver.
+func kubernetesProtobuf(apiVersion, kind string, object []byte) []byte {
+	var typeMeta []byte
+	typeMeta = appendBytes(typeMeta, 1, []byte(apiVersion))
+	typeMeta = appendBytes(typeMeta, 2, []byte(kind))
+
+	var unknown []byte
+	unknown = appendBytes(unknown, 1, typeMeta)
+	unknown = appendBytes(unknown, 2, object)
+
+	return append(append([]byte(nil), kubernetesProtobufMagic...), unknown...)
+}
+
+func gzipped(t *testing.T, data string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectDecoder(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value []byte
+		// empty means undetected
+		want string
+	}{
+		{"kubernetes protobuf", "/any", kubernetesProtobuf("v1", "Pod", nil), "kubernetes"},
+		{"kubernetes json", "/registry/pods/default/a", []byte(`{"kind": "Pod"}`), "kubernetes"},
+		{"json out of the registry", "/a", []byte(` {"kind": "Pod"}`), "json"},
+		{"json list", "/a", []byte(`[1, 2]`), "json"},
+		{"gzip", "/a", gzipped(t, `{"a": 1}`), "gzip"},
+		{"broken json", "/a", []byte(`{"a": `), ""},
+		{"yaml is never detected", "/a", []byte("a: 1"), ""},
+		{"text", "/a", []byte("plain"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder, ok := DetectDecoder([]byte(tt.key), tt.value)
+			var got string
+			if ok {
+				got = decoder.Name()
+			}
+			if got != tt.want {
+				t.Errorf("DetectDecoder() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec string
+		input string
+		// the decoded object, nil means the input cannot be encoded
+		want interface{}
+	}{
+		{"json", "json", `{"a": [1, "b"]}`, map[string]interface{}{"a": []interface{}{float64(1), "b"}}},
+		{"bad json", "json", `{"a": `, nil},
+		{"yaml", "yaml", "a:\n  b: 1\n", map[string]interface{}{"a": map[string]interface{}{"b": 1}}},
+		{"bad yaml", "yaml", "a: [", nil},
+		{"gzip", "gzip", `{"a": "b"}`, map[string]interface{}{"a": "b"}},
+		{"gzip of no json", "gzip", "a: b", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder, ok := GetDecoder(tt.codec)
+			if !ok {
+				t.Fatalf("GetDecoder(%q) is not found", tt.codec)
+			}
+			c := decoder.(Codec)
+
+			value, err := c.Encode([]byte("/a"), []byte(tt.input))
+			if tt.want == nil {
+				if err == nil {
+					t.Errorf("Encode() = %q, want an error", value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			decoded, err := c.Decode([]byte("/a"), value)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(decoded.Object, tt.want) {
+				t.Errorf("Decode() = %#v, want %#v", decoded.Object, tt.want)
+			}
+		})
+	}
+}
+
+func TestGzipDecodeText(t *testing.T) {
+	decoder, _ := GetDecoder("gzip")
+
+	decoded, err := decoder.Decode([]byte("/a"), gzipped(t, "a: b"))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if decoded.Object != "a: b" {
+		t.Errorf("Decode() = %#v, want the text", decoded.Object)
+	}
+
+	if _, err := decoder.Decode([]byte("/a"), gzipped(t, "\xff\xfe")); err == nil {
+		t.Errorf("Decode() of binary wants an error")
+	}
+}
+
+func TestKubernetesDecode(t *testing.T) {
+	var timestamp []byte
+	timestamp = appendTag(timestamp, 1, wireVarint)
+	timestamp = appendVarint(timestamp, 1500000000)
+
+	var label []byte
+	label = appendBytes(label, 1, []byte("app"))
+	label = appendBytes(label, 2, []byte("web"))
+
+	var metadata []byte
+	metadata = appendBytes(metadata, 1, []byte("a"))
+	metadata = appendBytes(metadata, 3, []byte("default"))
+	metadata = appendBytes(metadata, 8, timestamp)
+	metadata = appendBytes(metadata, 11, label)
+
+	var spec []byte
+	spec = appendBytes(spec, 1, []byte("node-1"))
+
+	var object []byte
+	object = appendBytes(object, 1, metadata)
+	object = appendBytes(object, 2, spec)
+
+	decoder, _ := GetDecoder("kubernetes")
+	decoded, err := decoder.Decode([]byte("/registry/pods/default/a"), kubernetesProtobuf("v1", "Pod", object))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if decoded.APIVersion != "v1" || decoded.Kind != "Pod" {
+		t.Errorf("Decode() = %s %s, want v1 Pod", decoded.APIVersion, decoded.Kind)
+	}
+	if decoded.Note != fmt.Sprintf(kubernetesProtobufNote, "v1", "Pod") {
+		t.Errorf("Decode() note = %q, want the fields besides the metadata are not named", decoded.Note)
+	}
+
+	want := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":              "a",
+			"namespace":         "default",
+			"creationTimestamp": "2017-07-14T02:40:00Z",
+			"labels":            map[string]string{"app": "web"},
+		},
+		// the fields out of the metadata are keyed by their numbers
+		"2": map[string]interface{}{"1": "node-1"},
+	}
+	if !reflect.DeepEqual(decoded.Object, want) {
+		t.Errorf("Decode() = %#v, want %#v", decoded.Object, want)
+	}
+
+	if _, err := decoder.Decode(nil, append(append([]byte(nil), kubernetesProtobufMagic...), 0xff)); err == nil {
+		t.Errorf("Decode() of a bad envelope wants an error")
+	}
+}
+
+// kubernetesFiles are the descriptors of a Pod with the apimachinery types it refers to,
+// as protoc writes them from the generated.proto files of k8s.io/api and k8s.io/apimachinery.
+func kubernetesFiles() []*descriptor.FileDescriptorProto {
+	field := func(name string, number int32, typ descriptor.FieldDescriptorProto_Type, typeName string) *descriptor.FieldDescriptorProto {
+		ret := &descriptor.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Label:  descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:   typ.Enum(),
+		}
+		if len(typeName) != 0 {
+			ret.TypeName = proto.String(typeName)
+		}
+		return ret
+	}
+	message := func(name string, fields ...*descriptor.FieldDescriptorProto) *descriptor.DescriptorProto {
+		return &descriptor.DescriptorProto{Name: proto.String(name), Field: fields}
+	}
+	const (
+		typeString  = descriptor.FieldDescriptorProto_TYPE_STRING
+		typeInt32   = descriptor.FieldDescriptorProto_TYPE_INT32
+		typeInt64   = descriptor.FieldDescriptorProto_TYPE_INT64
+		typeMessage = descriptor.FieldDescriptorProto_TYPE_MESSAGE
+	)
+
+	return []*descriptor.FileDescriptorProto{
+		{
+			Name:    proto.String("k8s.io/apimachinery/pkg/apis/meta/v1/generated.proto"),
+			Package: proto.String("k8s.io.apimachinery.pkg.apis.meta.v1"),
+			MessageType: []*descriptor.DescriptorProto{
+				message("ObjectMeta",
+					field("name", 1, typeString, ""),
+					field("namespace", 3, typeString, ""),
+					field("creationTimestamp", 8, typeMessage, ".k8s.io.apimachinery.pkg.apis.meta.v1.Time"),
+					field("deletionTimestamp", 9, typeMessage, ".k8s.io.apimachinery.pkg.apis.meta.v1.Time"),
+				),
+				message("Time",
+					field("seconds", 1, typeInt64, ""),
+					field("nanos", 2, typeInt32, ""),
+				),
+			},
+		},
+		{
+			Name:    proto.String("k8s.io/apimachinery/pkg/util/intstr/generated.proto"),
+			Package: proto.String("k8s.io.apimachinery.pkg.util.intstr"),
+			MessageType: []*descriptor.DescriptorProto{
+				message("IntOrString",
+					field("type", 1, typeInt64, ""),
+					field("intVal", 2, typeInt32, ""),
+					field("strVal", 3, typeString, ""),
+				),
+			},
+		},
+		{
+			Name:    proto.String("k8s.io/api/core/v1/generated.proto"),
+			Package: proto.String("k8s.io.api.core.v1"),
+			MessageType: []*descriptor.DescriptorProto{
+				message("Pod",
+					field("metadata", 1, typeMessage, ".k8s.io.apimachinery.pkg.apis.meta.v1.ObjectMeta"),
+					field("spec", 2, typeMessage, ".k8s.io.api.core.v1.PodSpec"),
+				),
+				message("PodSpec",
+					field("nodeName", 10, typeString, ""),
+					field("port", 11, typeMessage, ".k8s.io.apimachinery.pkg.util.intstr.IntOrString"),
+				),
+			},
+		},
+	}
+}
+
+func TestKubernetesDecodeByDescriptors(t *testing.T) {
+	path := writeDescriptorFiles(t, kubernetesFiles()...)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	decoder, err := New("kubernetes", Options{DescriptorSet: path})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var timestamp []byte
+	timestamp = appendTag(timestamp, 1, wireVarint)
+	timestamp = appendVarint(timestamp, 1500000000)
+
+	var metadata []byte
+	metadata = appendBytes(metadata, 1, []byte("a"))
+	metadata = appendBytes(metadata, 3, []byte("default"))
+	metadata = appendBytes(metadata, 8, timestamp)
+	// the zero time is null, as the kube-apiserver renders it
+	metadata = appendBytes(metadata, 9, nil)
+
+	var port []byte
+	port = appendTag(port, 1, wireVarint)
+	port = appendVarint(port, 1)
+	port = appendBytes(port, 3, []byte("http"))
+
+	var spec []byte
+	spec = appendBytes(spec, 10, []byte("node-1"))
+	spec = appendBytes(spec, 11, port)
+
+	var object []byte
+	object = appendBytes(object, 1, metadata)
+	object = appendBytes(object, 2, spec)
+
+	decoded, err := decoder.Decode([]byte("/registry/pods/default/a"), kubernetesProtobuf("v1", "Pod", object))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(decoded.Note) != 0 {
+		t.Errorf("Decode() note = %q, want none", decoded.Note)
+	}
+
+	want := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":              "a",
+			"namespace":         "default",
+			"creationTimestamp": "2017-07-14T02:40:00Z",
+			"deletionTimestamp": nil,
+		},
+		"spec": map[string]interface{}{
+			"nodeName": "node-1",
+			"port":     "http",
+		},
+	}
+	if !reflect.DeepEqual(decoded.Object, want) {
+		t.Errorf("Decode() = %#v, want %#v", decoded.Object, want)
+	}
+
+	// the kinds without descriptors fall back to the metadata
+	decoded, err = decoder.Decode([]byte("/registry/deployments/default/a"), kubernetesProtobuf("apps/v1", "Deployment", object))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if decoded.Note != fmt.Sprintf(kubernetesProtobufNote, "apps/v1", "Deployment") {
+		t.Errorf("Decode() note = %q, want the descriptors are missing", decoded.Note)
+	}
+}
+
+func TestKubernetesLookup(t *testing.T) {
+	path := writeDescriptorFiles(t, kubernetesFiles()...)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	decoder, err := newKubernetesDecoder(Options{DescriptorSet: path})
+	if err != nil {
+		t.Fatalf("newKubernetesDecoder() error = %v", err)
+	}
+
+	tests := []struct {
+		apiVersion string
+		kind       string
+		want       string
+	}{
+		{"v1", "Pod", ".k8s.io.api.core.v1.Pod"},
+		{"v1", "Node", ""},
+		{"apps/v1", "Pod", ""},
+	}
+	for _, tt := range tests {
+		message, ok := decoder.(*kubernetesDecoder).lookup(tt.apiVersion, tt.kind)
+		if ok != (len(tt.want) != 0) || (ok && message.fullName != tt.want) {
+			t.Errorf("lookup(%s, %s) = %v, want %q", tt.apiVersion, tt.kind, message, tt.want)
+		}
+	}
+
+	if _, err := newKubernetesDecoder(Options{DescriptorSet: path, Message: "k8s.io.api.core.v1.Pod"}); err == nil {
+		t.Errorf("newKubernetesDecoder() with a message wants an error")
+	}
+}
+
+func TestMapping(t *testing.T) {
+	jsonDecoder, _ := GetDecoder("json")
+	kubernetesDecoder, _ := GetDecoder("kubernetes")
+
+	m := NewMapping()
+	m.Add("/a/", jsonDecoder)
+	m.Add("/a/b/", kubernetesDecoder)
+
+	tests := []struct {
+		key       string
+		want      string
+		wantCodec bool
+	}{
+		{"/a/1", "json", true},
+		{"/a/b/1", "kubernetes", false},
+		{"/c", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			decoder, ok := m.Lookup([]byte(tt.key))
+			var got string
+			if ok {
+				got = decoder.Name()
+			}
+			if got != tt.want {
+				t.Errorf("Lookup() = %q, want %q", got, tt.want)
+			}
+			if _, ok := m.LookupCodec([]byte(tt.key)); ok != tt.wantCodec {
+				t.Errorf("LookupCodec() = %v, want %v", ok, tt.wantCodec)
+			}
+		})
+	}
+
+	var nilMapping *Mapping
+	if _, ok := nilMapping.Lookup([]byte("/a/1")); ok {
+		t.Errorf("Lookup() of a nil mapping is found")
+	}
+}
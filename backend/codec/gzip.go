@@ -0,0 +1,74 @@
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"unicode/utf8"
+)
+
+// values are never larger than the request limit of etcd, so it's plenty
+const gzipMaxDecompressed = 64 * 1024 * 1024
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// gzipCodec handles gzip-compressed json.
+type gzipCodec struct {
+}
+
+func (c *gzipCodec) Name() string {
+	return "gzip"
+}
+
+func (c *gzipCodec) Match(key, value []byte) bool {
+	return bytes.HasPrefix(value, gzipMagic)
+}
+
+func (c *gzipCodec) Decode(key, value []byte) (Decoded, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(value))
+	if err != nil {
+		return Decoded{}, err
+	}
+	defer reader.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(reader, gzipMaxDecompressed))
+	if err != nil {
+		return Decoded{}, err
+	}
+
+	var object interface{}
+	if err := json.Unmarshal(data, &object); err == nil {
+		return Decoded{
+			Object: object,
+		}, nil
+	}
+
+	// not json, but still readable
+	if utf8.Valid(data) {
+		return Decoded{
+			Object: string(data),
+		}, nil
+	}
+
+	return Decoded{}, errors.New("decompressed value is neither json nor text")
+}
+
+func (c *gzipCodec) Encode(key, input []byte) ([]byte, error) {
+	if !json.Valid(input) {
+		return nil, errors.New("not a valid json")
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(input); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
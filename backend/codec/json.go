@@ -0,0 +1,39 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+type jsonCodec struct {
+}
+
+func (c *jsonCodec) Name() string {
+	return "json"
+}
+
+func (c *jsonCodec) Match(key, value []byte) bool {
+	trimmed := bytes.TrimSpace(value)
+
+	return (bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("["))) && json.Valid(trimmed)
+}
+
+func (c *jsonCodec) Decode(key, value []byte) (Decoded, error) {
+	var object interface{}
+	if err := json.Unmarshal(value, &object); err != nil {
+		return Decoded{}, err
+	}
+
+	return Decoded{
+		Object: object,
+	}, nil
+}
+
+func (c *jsonCodec) Encode(key, input []byte) ([]byte, error) {
+	if !json.Valid(input) {
+		return nil, errors.New("not a valid json")
+	}
+
+	return input, nil
+}
@@ -0,0 +1,351 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	kubernetesCodecName = "kubernetes"
+	kubernetesKeyPrefix = "/registry/"
+)
+
+// kubernetesProtobufMagic leads every protobuf object stored by the kube-apiserver.
+var kubernetesProtobufMagic = []byte("k8s\x00")
+
+// kubernetesProtobufNote is returned with the protobuf objects whose kinds have no descriptors.
+const kubernetesProtobufNote = "no descriptor of %s %s is configured to the kubernetes codec, " +
+	"only the metadata is decoded, the other fields are keyed by their protobuf field numbers"
+
+// kubernetesDecoder renders the objects stored by the kube-apiserver. The protobuf ones are decoded
+// by the descriptors of their kinds, i.e. the descriptor set of the generated.proto files of k8s.io/api,
+// k8s.io/apimachinery and the like, which are found by the group, the version and the kind of the objects.
+// Without the descriptors, only the metadata is named and the other fields are keyed by their numbers.
+type kubernetesDecoder struct {
+	descriptors *protoDescriptors
+	// the messages by the first label of the group (or "core"), the version and the kind, e.g. "apps.v1.Deployment"
+	kinds map[string]*protoMessage
+}
+
+func newKubernetesDecoder(options Options) (Decoder, error) {
+	if len(options.Message) != 0 {
+		return nil, errors.New("kubernetes codec finds the messages by the kinds of the objects, the message cannot be set")
+	}
+	if len(options.DescriptorSet) == 0 {
+		return &kubernetesDecoder{}, nil
+	}
+
+	descriptors, err := loadDescriptorSet(options.DescriptorSet)
+	if err != nil {
+		return nil, err
+	}
+	descriptors.renderers = kubernetesRenderers
+
+	kinds := make(map[string]*protoMessage)
+	for fullName, message := range descriptors.messages {
+		segments := strings.Split(strings.TrimPrefix(fullName, "."), ".")
+		if len(segments) < 3 || message.mapEntry {
+			continue
+		}
+		kinds[strings.Join(segments[len(segments)-3:], ".")] = message
+	}
+
+	return &kubernetesDecoder{
+		descriptors: descriptors,
+		kinds:       kinds,
+	}, nil
+}
+
+func (d *kubernetesDecoder) Name() string {
+	return kubernetesCodecName
+}
+
+func (d *kubernetesDecoder) Match(key, value []byte) bool {
+	if bytes.HasPrefix(value, kubernetesProtobufMagic) {
+		return true
+	}
+
+	// custom resources and older clusters are stored as json
+	return bytes.HasPrefix(key, []byte(kubernetesKeyPrefix)) && bytes.HasPrefix(bytes.TrimSpace(value), []byte("{"))
+}
+
+func (d *kubernetesDecoder) Decode(key, value []byte) (Decoded, error) {
+	if bytes.HasPrefix(value, kubernetesProtobufMagic) {
+		return d.decodeProtobuf(value[len(kubernetesProtobufMagic):])
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(value, &object); err != nil {
+		return Decoded{}, errors.New("neither a kubernetes protobuf nor a json object")
+	}
+
+	apiVersion, _ := object["apiVersion"].(string)
+	kind, _ := object["kind"].(string)
+
+	return Decoded{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Object:     object,
+	}, nil
+}
+
+// lookup finds the message of the kind, e.g. "apps/v1" "Deployment" is k8s.io.api.apps.v1.Deployment
+// and "v1" "Pod" is k8s.io.api.core.v1.Pod.
+func (d *kubernetesDecoder) lookup(apiVersion, kind string) (*protoMessage, bool) {
+	if d.kinds == nil {
+		return nil, false
+	}
+
+	group, version := "core", apiVersion
+	if idx := strings.Index(apiVersion, "/"); idx >= 0 {
+		group, version = apiVersion[:idx], apiVersion[idx+1:]
+		if idx := strings.Index(group, "."); idx >= 0 {
+			group = group[:idx]
+		}
+	}
+
+	message, ok := d.kinds[group+"."+version+"."+kind]
+	return message, ok
+}
+
+// decodeProtobuf unwraps the runtime.Unknown envelope:
+// typeMeta(1){apiVersion(1), kind(2)}, raw(2), contentEncoding(3), contentType(4).
+func (d *kubernetesDecoder) decodeProtobuf(data []byte) (Decoded, error) {
+	decoded := Decoded{}
+
+	unknown, err := parseMessage(data)
+	if err != nil {
+		return decoded, errors.New(fmt.Sprintf("bad kubernetes storage envelope, %v", err))
+	}
+
+	if typeMeta, err := parseMessage(unknown.bytes(1)); err == nil {
+		decoded.APIVersion = string(typeMeta.bytes(1))
+		decoded.Kind = string(typeMeta.bytes(2))
+	}
+
+	if contentEncoding := string(unknown.bytes(3)); len(contentEncoding) != 0 {
+		return decoded, errors.New(fmt.Sprintf("unsupported kubernetes content encoding %s", contentEncoding))
+	}
+
+	if message, ok := d.lookup(decoded.APIVersion, decoded.Kind); ok {
+		object, err := d.descriptors.decodeMessage(message, unknown.bytes(2))
+		if err != nil {
+			return decoded, errors.New(fmt.Sprintf("bad kubernetes object, %v", err))
+		}
+		object["apiVersion"] = decoded.APIVersion
+		object["kind"] = decoded.Kind
+		decoded.Object = object
+
+		return decoded, nil
+	}
+	decoded.Note = fmt.Sprintf(kubernetesProtobufNote, decoded.APIVersion, decoded.Kind)
+
+	raw, err := parseMessage(unknown.bytes(2))
+	if err != nil {
+		return decoded, errors.New(fmt.Sprintf("bad kubernetes object, %v", err))
+	}
+
+	// every stored object keeps its ObjectMeta in the first field
+	object := raw.generic(1)
+	if metadata, err := parseMessage(raw.bytes(1)); err == nil {
+		object["metadata"] = renderObjectMeta(metadata)
+	}
+	object["apiVersion"] = decoded.APIVersion
+	object["kind"] = decoded.Kind
+	decoded.Object = object
+
+	return decoded, nil
+}
+
+var objectMetaStrings = map[int]string{
+	1:  "name",
+	2:  "generateName",
+	3:  "namespace",
+	4:  "selfLink",
+	5:  "uid",
+	6:  "resourceVersion",
+	15: "clusterName",
+}
+
+func renderObjectMeta(metadata wireMessage) map[string]interface{} {
+	ret := metadata.generic(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15)
+
+	for number, name := range objectMetaStrings {
+		if value := metadata.bytes(number); len(value) != 0 {
+			ret[name] = string(value)
+		}
+	}
+
+	if generation, ok := metadata.varint(7); ok {
+		ret["generation"] = int64(generation)
+	}
+	if seconds, ok := metadata.varint(10); ok {
+		ret["deletionGracePeriodSeconds"] = int64(seconds)
+	}
+	if timestamp, ok := renderTime(metadata.bytes(8)); ok {
+		ret["creationTimestamp"] = timestamp
+	}
+	if timestamp, ok := renderTime(metadata.bytes(9)); ok {
+		ret["deletionTimestamp"] = timestamp
+	}
+
+	labels, annotations, finalizers := map[string]string{}, map[string]string{}, []string{}
+	var ownerReferences []interface{}
+	for _, field := range metadata {
+		switch field.Number {
+		case 11, 12:
+			// map entries are messages of key(1) and value(2)
+			entry, err := parseMessage(field.Bytes)
+			if err != nil {
+				continue
+			}
+			if field.Number == 11 {
+				labels[string(entry.bytes(1))] = string(entry.bytes(2))
+			} else {
+				annotations[string(entry.bytes(1))] = string(entry.bytes(2))
+			}
+		case 13:
+			ownerReferences = append(ownerReferences, renderOwnerReference(field.Bytes))
+		case 14:
+			finalizers = append(finalizers, string(field.Bytes))
+		}
+	}
+	if len(labels) != 0 {
+		ret["labels"] = labels
+	}
+	if len(annotations) != 0 {
+		ret["annotations"] = annotations
+	}
+	if len(ownerReferences) != 0 {
+		ret["ownerReferences"] = ownerReferences
+	}
+	if len(finalizers) != 0 {
+		ret["finalizers"] = finalizers
+	}
+
+	return ret
+}
+
+// renderOwnerReference names kind(1), name(3), uid(4), apiVersion(5), controller(6) and blockOwnerDeletion(7).
+func renderOwnerReference(data []byte) interface{} {
+	reference, err := parseMessage(data)
+	if err != nil {
+		return wireField{WireType: wireBytes, Bytes: data}.generic()
+	}
+
+	ret := reference.generic(1, 3, 4, 5, 6, 7)
+	for number, name := range map[int]string{1: "kind", 3: "name", 4: "uid", 5: "apiVersion"} {
+		if value := reference.bytes(number); len(value) != 0 {
+			ret[name] = string(value)
+		}
+	}
+	if controller, ok := reference.varint(6); ok {
+		ret["controller"] = controller != 0
+	}
+	if block, ok := reference.varint(7); ok {
+		ret["blockOwnerDeletion"] = block != 0
+	}
+
+	return ret
+}
+
+// renderTime formats a metav1.Time of seconds(1) and nanos(2).
+func renderTime(data []byte) (string, bool) {
+	if data == nil {
+		return "", false
+	}
+
+	timestamp, err := parseMessage(data)
+	if err != nil {
+		return "", false
+	}
+	seconds, _ := timestamp.varint(1)
+	nanos, _ := timestamp.varint(2)
+
+	return time.Unix(int64(seconds), int64(nanos)).UTC().Format(time.RFC3339), true
+}
+
+// kubernetesRenderers render the apimachinery types by their json forms rather than their messages.
+var kubernetesRenderers = map[string]func(data []byte) (interface{}, error){
+	".k8s.io.apimachinery.pkg.apis.meta.v1.Time":       renderKubernetesTime(time.RFC3339),
+	".k8s.io.apimachinery.pkg.apis.meta.v1.MicroTime":  renderKubernetesTime("2006-01-02T15:04:05.000000Z07:00"),
+	".k8s.io.apimachinery.pkg.apis.meta.v1.Duration":   renderKubernetesDuration,
+	".k8s.io.apimachinery.pkg.api.resource.Quantity":   renderKubernetesQuantity,
+	".k8s.io.apimachinery.pkg.util.intstr.IntOrString": renderKubernetesIntOrString,
+	".k8s.io.apimachinery.pkg.runtime.RawExtension":    renderKubernetesRaw,
+	".k8s.io.apimachinery.pkg.apis.meta.v1.FieldsV1":   renderKubernetesRaw,
+}
+
+// renderKubernetesTime formats seconds(1) and nanos(2), the zero time is null.
+func renderKubernetesTime(layout string) func(data []byte) (interface{}, error) {
+	return func(data []byte) (interface{}, error) {
+		timestamp, err := parseMessage(data)
+		if err != nil {
+			return nil, err
+		}
+		seconds, _ := timestamp.varint(1)
+		nanos, _ := timestamp.varint(2)
+		if seconds == 0 && nanos == 0 {
+			return nil, nil
+		}
+
+		return time.Unix(int64(seconds), int64(int32(nanos))).UTC().Format(layout), nil
+	}
+}
+
+// renderKubernetesDuration formats duration(1) in nanoseconds, e.g. "1m30s".
+func renderKubernetesDuration(data []byte) (interface{}, error) {
+	duration, err := parseMessage(data)
+	if err != nil {
+		return nil, err
+	}
+	nanoseconds, _ := duration.varint(1)
+
+	return time.Duration(int64(nanoseconds)).String(), nil
+}
+
+// renderKubernetesQuantity takes string(1), e.g. "500m".
+func renderKubernetesQuantity(data []byte) (interface{}, error) {
+	quantity, err := parseMessage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(quantity.bytes(1)), nil
+}
+
+// renderKubernetesIntOrString takes intVal(2) or strVal(3) by type(1).
+func renderKubernetesIntOrString(data []byte) (interface{}, error) {
+	value, err := parseMessage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if typ, _ := value.varint(1); typ == 1 {
+		return string(value.bytes(3)), nil
+	}
+	intVal, _ := value.varint(2)
+	return int64(int32(intVal)), nil
+}
+
+// renderKubernetesRaw takes the json in raw(1), the others are kept as the text.
+func renderKubernetesRaw(data []byte) (interface{}, error) {
+	value, err := parseMessage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := value.bytes(1)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var object interface{}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return string(raw), nil
+	}
+	return object, nil
+}
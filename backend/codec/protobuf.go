@@ -0,0 +1,609 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+const protobufCodecName = "protobuf"
+
+// types of FieldDescriptorProto
+const (
+	protoTypeDouble   = descriptor.FieldDescriptorProto_TYPE_DOUBLE
+	protoTypeFloat    = descriptor.FieldDescriptorProto_TYPE_FLOAT
+	protoTypeInt64    = descriptor.FieldDescriptorProto_TYPE_INT64
+	protoTypeUint64   = descriptor.FieldDescriptorProto_TYPE_UINT64
+	protoTypeInt32    = descriptor.FieldDescriptorProto_TYPE_INT32
+	protoTypeFixed64  = descriptor.FieldDescriptorProto_TYPE_FIXED64
+	protoTypeFixed32  = descriptor.FieldDescriptorProto_TYPE_FIXED32
+	protoTypeBool     = descriptor.FieldDescriptorProto_TYPE_BOOL
+	protoTypeString   = descriptor.FieldDescriptorProto_TYPE_STRING
+	protoTypeGroup    = descriptor.FieldDescriptorProto_TYPE_GROUP
+	protoTypeMessage  = descriptor.FieldDescriptorProto_TYPE_MESSAGE
+	protoTypeBytes    = descriptor.FieldDescriptorProto_TYPE_BYTES
+	protoTypeUint32   = descriptor.FieldDescriptorProto_TYPE_UINT32
+	protoTypeEnum     = descriptor.FieldDescriptorProto_TYPE_ENUM
+	protoTypeSfixed32 = descriptor.FieldDescriptorProto_TYPE_SFIXED32
+	protoTypeSfixed64 = descriptor.FieldDescriptorProto_TYPE_SFIXED64
+	protoTypeSint32   = descriptor.FieldDescriptorProto_TYPE_SINT32
+	protoTypeSint64   = descriptor.FieldDescriptorProto_TYPE_SINT64
+)
+
+type protoField struct {
+	name     string
+	jsonName string
+	number   int
+	repeated bool
+	typ      descriptor.FieldDescriptorProto_Type
+	typeName string
+}
+
+type protoMessage struct {
+	fullName string
+	mapEntry bool
+	fields   map[int]*protoField
+	// by the json names and the original names
+	names map[string]*protoField
+}
+
+type protoEnum struct {
+	names   map[int32]string
+	numbers map[string]int32
+}
+
+// protoDescriptors are indexed by the full names with a leading dot, as "type_name" refers to them.
+type protoDescriptors struct {
+	messages map[string]*protoMessage
+	enums    map[string]*protoEnum
+	// renderers of the messages which have their own json forms, e.g. the timestamps
+	renderers map[string]func(data []byte) (interface{}, error)
+}
+
+// protobufCodec handles one message type by its descriptors, the fields are rendered by their json names.
+// The descriptors are read by the generated descriptor types, but the values are (de)coded here:
+// jsonpb only works with the generated go types, which the stored messages have none of.
+type protobufCodec struct {
+	descriptors *protoDescriptors
+	message     *protoMessage
+}
+
+func newProtobufCodec(options Options) (Decoder, error) {
+	if len(options.DescriptorSet) == 0 || len(options.Message) == 0 {
+		return nil, errors.New("protobuf codec needs both the descriptor set and the message")
+	}
+
+	descriptors, err := loadDescriptorSet(options.DescriptorSet)
+	if err != nil {
+		return nil, err
+	}
+
+	message, ok := descriptors.messages["."+strings.TrimPrefix(options.Message, ".")]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("cannot find message %s in %s", options.Message, options.DescriptorSet))
+	}
+
+	return &protobufCodec{
+		descriptors: descriptors,
+		message:     message,
+	}, nil
+}
+
+func (c *protobufCodec) Name() string {
+	return protobufCodecName
+}
+
+// Match never detects protobuf, binary values are ambiguous.
+func (c *protobufCodec) Match(key, value []byte) bool {
+	return false
+}
+
+func (c *protobufCodec) Decode(key, value []byte) (Decoded, error) {
+	object, err := c.descriptors.decodeMessage(c.message, value)
+	if err != nil {
+		return Decoded{}, err
+	}
+
+	return Decoded{
+		Kind:   strings.TrimPrefix(c.message.fullName, "."),
+		Object: object,
+	}, nil
+}
+
+// Encode takes a json object, like the one rendered by Decode.
+func (c *protobufCodec) Encode(key, input []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(input))
+	decoder.UseNumber()
+
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, errors.New(fmt.Sprintf("not a valid json object, %v", err))
+	}
+
+	return c.descriptors.encodeMessage(c.message, object)
+}
+
+// parseDescriptorSet indexes the messages and the enums of all the files, the nested ones included.
+func loadDescriptorSet(path string) (*protoDescriptors, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptors, err := parseDescriptorSet(data)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("bad descriptor set %s, %v", path, err))
+	}
+
+	return descriptors, nil
+}
+
+func parseDescriptorSet(data []byte) (*protoDescriptors, error) {
+	var set descriptor.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	descriptors := &protoDescriptors{
+		messages: make(map[string]*protoMessage),
+		enums:    make(map[string]*protoEnum),
+	}
+	for _, file := range set.GetFile() {
+		scope := ""
+		if pkg := file.GetPackage(); len(pkg) != 0 {
+			scope = "." + pkg
+		}
+		for _, message := range file.GetMessageType() {
+			descriptors.addMessage(scope, message)
+		}
+		for _, enum := range file.GetEnumType() {
+			descriptors.addEnum(scope, enum)
+		}
+	}
+
+	return descriptors, nil
+}
+
+func (d *protoDescriptors) addMessage(scope string, desc *descriptor.DescriptorProto) {
+	message := &protoMessage{
+		fullName: scope + "." + desc.GetName(),
+		mapEntry: desc.GetOptions().GetMapEntry(),
+		fields:   make(map[int]*protoField),
+		names:    make(map[string]*protoField),
+	}
+
+	for _, fieldDesc := range desc.GetField() {
+		protoField := &protoField{
+			name:     fieldDesc.GetName(),
+			jsonName: fieldDesc.GetJsonName(),
+			number:   int(fieldDesc.GetNumber()),
+			repeated: fieldDesc.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED,
+			typ:      fieldDesc.GetType(),
+			typeName: fieldDesc.GetTypeName(),
+		}
+		if len(protoField.jsonName) == 0 {
+			protoField.jsonName = lowerCamelCase(protoField.name)
+		}
+
+		message.fields[protoField.number] = protoField
+		message.names[protoField.name] = protoField
+		message.names[protoField.jsonName] = protoField
+	}
+	for _, nested := range desc.GetNestedType() {
+		d.addMessage(message.fullName, nested)
+	}
+	for _, enum := range desc.GetEnumType() {
+		d.addEnum(message.fullName, enum)
+	}
+
+	d.messages[message.fullName] = message
+}
+
+func (d *protoDescriptors) addEnum(scope string, desc *descriptor.EnumDescriptorProto) {
+	enum := &protoEnum{
+		names:   make(map[int32]string),
+		numbers: make(map[string]int32),
+	}
+	for _, value := range desc.GetValue() {
+		enum.names[value.GetNumber()] = value.GetName()
+		enum.numbers[value.GetName()] = value.GetNumber()
+	}
+
+	d.enums[scope+"."+desc.GetName()] = enum
+}
+
+func (d *protoDescriptors) isMap(field *protoField) bool {
+	if field.typ != protoTypeMessage || !field.repeated {
+		return false
+	}
+
+	message, ok := d.messages[field.typeName]
+	return ok && message.mapEntry
+}
+
+func (d *protoDescriptors) decodeMessage(message *protoMessage, data []byte) (map[string]interface{}, error) {
+	wire, err := parseMessage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]interface{}, len(wire))
+	for _, wireField := range wire {
+		field, ok := message.fields[wireField.Number]
+		if !ok {
+			// unknown fields are kept by their numbers
+			ret[strconv.Itoa(wireField.Number)] = wireField.generic()
+			continue
+		}
+
+		values, err := d.decodeField(field, wireField)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("field %s of %s, %v", field.name, message.fullName, err))
+		}
+
+		switch {
+		case d.isMap(field):
+			entries, _ := ret[field.jsonName].(map[string]interface{})
+			if entries == nil {
+				entries = make(map[string]interface{})
+				ret[field.jsonName] = entries
+			}
+			for _, value := range values {
+				entry, _ := value.(map[string]interface{})
+				entryKey := ""
+				if key, ok := entry["key"]; ok {
+					entryKey = fmt.Sprint(key)
+				}
+				entries[entryKey] = entry["value"]
+			}
+		case field.repeated:
+			list, _ := ret[field.jsonName].([]interface{})
+			ret[field.jsonName] = append(list, values...)
+		default:
+			ret[field.jsonName] = values[len(values)-1]
+		}
+	}
+
+	return ret, nil
+}
+
+// decodeField returns several values for the packed repeated scalars.
+func (d *protoDescriptors) decodeField(field *protoField, wireField wireField) ([]interface{}, error) {
+	if wireField.WireType != wireBytes {
+		value, err := d.decodeScalar(field, wireField.Varint)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{value}, nil
+	}
+
+	switch field.typ {
+	case protoTypeString:
+		return []interface{}{string(wireField.Bytes)}, nil
+	case protoTypeBytes:
+		return []interface{}{base64.StdEncoding.EncodeToString(wireField.Bytes)}, nil
+	case protoTypeMessage:
+		if render, ok := d.renderers[field.typeName]; ok {
+			value, err := render(wireField.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{value}, nil
+		}
+		message, ok := d.messages[field.typeName]
+		if !ok {
+			return []interface{}{wireField.generic()}, nil
+		}
+		object, err := d.decodeMessage(message, wireField.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{object}, nil
+	}
+
+	// packed
+	var (
+		data   = wireField.Bytes
+		values []interface{}
+	)
+	for len(data) > 0 {
+		var raw uint64
+
+		switch field.typ {
+		case protoTypeDouble, protoTypeFixed64, protoTypeSfixed64:
+			if len(data) < 8 {
+				return nil, errBadWire
+			}
+			raw = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case protoTypeFloat, protoTypeFixed32, protoTypeSfixed32:
+			if len(data) < 4 {
+				return nil, errBadWire
+			}
+			raw = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		case protoTypeGroup:
+			return nil, errors.New("groups are not supported")
+		default:
+			value, n := binary.Uvarint(data)
+			if n <= 0 {
+				return nil, errBadWire
+			}
+			raw = value
+			data = data[n:]
+		}
+
+		value, err := d.decodeScalar(field, raw)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+func (d *protoDescriptors) decodeScalar(field *protoField, raw uint64) (interface{}, error) {
+	switch field.typ {
+	case protoTypeDouble:
+		return math.Float64frombits(raw), nil
+	case protoTypeFloat:
+		return float64(math.Float32frombits(uint32(raw))), nil
+	case protoTypeInt64, protoTypeSfixed64:
+		return int64(raw), nil
+	case protoTypeUint64, protoTypeFixed64:
+		return raw, nil
+	case protoTypeInt32:
+		return int32(raw), nil
+	case protoTypeSfixed32:
+		return int32(uint32(raw)), nil
+	case protoTypeUint32, protoTypeFixed32:
+		return uint32(raw), nil
+	case protoTypeBool:
+		return raw != 0, nil
+	case protoTypeSint32:
+		return int32(int64(raw>>1) ^ -int64(raw&1)), nil
+	case protoTypeSint64:
+		return int64(raw>>1) ^ -int64(raw&1), nil
+	case protoTypeEnum:
+		if enum, ok := d.enums[field.typeName]; ok {
+			if name, ok := enum.names[int32(raw)]; ok {
+				return name, nil
+			}
+		}
+		return int32(raw), nil
+	}
+
+	return nil, errors.New("unexpected wire type")
+}
+
+func (d *protoDescriptors) encodeMessage(message *protoMessage, object map[string]interface{}) ([]byte, error) {
+	type fieldValue struct {
+		field *protoField
+		value interface{}
+	}
+
+	fieldValues := make([]fieldValue, 0, len(object))
+	for name, value := range object {
+		field, ok := message.names[name]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("unknown field %s of %s", name, message.fullName))
+		}
+		if value == nil {
+			continue
+		}
+		fieldValues = append(fieldValues, fieldValue{field, value})
+	}
+	// in the order of the field numbers, like the generated code
+	sort.Slice(fieldValues, func(i, j int) bool {
+		return fieldValues[i].field.number < fieldValues[j].field.number
+	})
+
+	var (
+		buf []byte
+		err error
+	)
+	for _, fv := range fieldValues {
+		field := fv.field
+
+		switch {
+		case d.isMap(field):
+			entries, ok := fv.value.(map[string]interface{})
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("field %s of %s expects an object", field.name, message.fullName))
+			}
+			entryKeys := make([]string, 0, len(entries))
+			for entryKey := range entries {
+				entryKeys = append(entryKeys, entryKey)
+			}
+			sort.Strings(entryKeys)
+
+			entryMessage := d.messages[field.typeName]
+			for _, entryKey := range entryKeys {
+				entry, err := d.encodeMessage(entryMessage, map[string]interface{}{
+					"key":   entryKey,
+					"value": entries[entryKey],
+				})
+				if err != nil {
+					return nil, err
+				}
+				buf = appendBytes(buf, field.number, entry)
+			}
+		case field.repeated:
+			list, ok := fv.value.([]interface{})
+			if !ok {
+				return nil, errors.New(fmt.Sprintf("field %s of %s expects a list", field.name, message.fullName))
+			}
+			// unpacked, parsers accept both
+			for _, value := range list {
+				if buf, err = d.appendField(buf, field, value); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			if buf, err = d.appendField(buf, field, fv.value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return buf, nil
+}
+
+func (d *protoDescriptors) appendField(buf []byte, field *protoField, value interface{}) ([]byte, error) {
+	switch field.typ {
+	case protoTypeMessage:
+		message, ok := d.messages[field.typeName]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("cannot find message %s", field.typeName))
+		}
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("field %s expects an object", field.name))
+		}
+		encoded, err := d.encodeMessage(message, object)
+		if err != nil {
+			return nil, err
+		}
+		return appendBytes(buf, field.number, encoded), nil
+	case protoTypeString:
+		str, ok := value.(string)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("field %s expects a string", field.name))
+		}
+		return appendBytes(buf, field.number, []byte(str)), nil
+	case protoTypeBytes:
+		str, ok := value.(string)
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("field %s expects a base64 string", field.name))
+		}
+		data, err := base64.StdEncoding.DecodeString(str)
+		if err != nil {
+			if data, err = base64.URLEncoding.DecodeString(str); err != nil {
+				return nil, errors.New(fmt.Sprintf("field %s expects a base64 string", field.name))
+			}
+		}
+		return appendBytes(buf, field.number, data), nil
+	case protoTypeGroup:
+		return nil, errors.New("groups are not supported")
+	}
+
+	raw, err := d.encodeScalar(field, value)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("field %s, %v", field.name, err))
+	}
+
+	switch field.typ {
+	case protoTypeDouble, protoTypeFixed64, protoTypeSfixed64:
+		var tmp [8]byte
+		binary.LittleEndian.PutUint64(tmp[:], raw)
+		buf = appendTag(buf, field.number, wireFixed64)
+		return append(buf, tmp[:]...), nil
+	case protoTypeFloat, protoTypeFixed32, protoTypeSfixed32:
+		var tmp [4]byte
+		binary.LittleEndian.PutUint32(tmp[:], uint32(raw))
+		buf = appendTag(buf, field.number, wireFixed32)
+		return append(buf, tmp[:]...), nil
+	}
+
+	buf = appendTag(buf, field.number, wireVarint)
+	return appendVarint(buf, raw), nil
+}
+
+// encodeScalar accepts the numbers of json, and also their strings like the 64-bit integers in protobuf json.
+func (d *protoDescriptors) encodeScalar(field *protoField, value interface{}) (uint64, error) {
+	var text string
+	switch typed := value.(type) {
+	case json.Number:
+		text = typed.String()
+	case string:
+		text = typed
+	case bool:
+		text = strconv.FormatBool(typed)
+	default:
+		return 0, errors.New(fmt.Sprintf("unexpected value %v", value))
+	}
+
+	switch field.typ {
+	case protoTypeBool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return 0, err
+		}
+		if b {
+			return 1, nil
+		}
+		return 0, nil
+	case protoTypeEnum:
+		if enum, ok := d.enums[field.typeName]; ok {
+			if number, ok := enum.numbers[text]; ok {
+				return uint64(int64(number)), nil
+			}
+		}
+		number, err := strconv.ParseInt(text, 10, 32)
+		if err != nil {
+			return 0, errors.New(fmt.Sprintf("unknown enum value %s of %s", text, field.typeName))
+		}
+		return uint64(number), nil
+	case protoTypeDouble:
+		f, err := strconv.ParseFloat(text, 64)
+		return math.Float64bits(f), err
+	case protoTypeFloat:
+		f, err := strconv.ParseFloat(text, 32)
+		return uint64(math.Float32bits(float32(f))), err
+	case protoTypeInt32, protoTypeSfixed32:
+		n, err := strconv.ParseInt(text, 10, 32)
+		return uint64(n), err
+	case protoTypeInt64, protoTypeSfixed64:
+		n, err := strconv.ParseInt(text, 10, 64)
+		return uint64(n), err
+	case protoTypeSint32, protoTypeSint64:
+		bitSize := 64
+		if field.typ == protoTypeSint32 {
+			bitSize = 32
+		}
+		n, err := strconv.ParseInt(text, 10, bitSize)
+		return uint64((n << 1) ^ (n >> 63)), err
+	case protoTypeUint32, protoTypeFixed32:
+		n, err := strconv.ParseUint(text, 10, 32)
+		return n, err
+	case protoTypeUint64, protoTypeFixed64:
+		n, err := strconv.ParseUint(text, 10, 64)
+		return n, err
+	}
+
+	return 0, errors.New(fmt.Sprintf("unsupported type %s", field.typ))
+}
+
+// lowerCamelCase is how protoc derives the json names, "foo_bar" becomes "fooBar".
+func lowerCamelCase(name string) string {
+	var (
+		buf       bytes.Buffer
+		upperNext bool
+	)
+	for _, r := range name {
+		if r == '_' {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		buf.WriteRune(r)
+	}
+
+	return buf.String()
+}
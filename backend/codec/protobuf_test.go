@@ -0,0 +1,254 @@
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// writeDescriptorSet writes descriptor.proto, as registered by the generated descriptor package,
+// together with a file of a map field, as protoc would write them with --include_imports.
+func writeDescriptorSet(t *testing.T) string {
+	t.Helper()
+
+	reader, err := gzip.NewReader(bytes.NewReader(proto.FileDescriptor("google/protobuf/descriptor.proto")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var descriptorFile descriptor.FileDescriptorProto
+	if err := proto.Unmarshal(data, &descriptorFile); err != nil {
+		t.Fatal(err)
+	}
+
+	// message Labels { map<string, int64> values = 1; }
+	labelsFile := &descriptor.FileDescriptorProto{
+		Name:    proto.String("labels.proto"),
+		Package: proto.String("test"),
+		MessageType: []*descriptor.DescriptorProto{{
+			Name: proto.String("Labels"),
+			Field: []*descriptor.FieldDescriptorProto{{
+				Name:     proto.String("values"),
+				Number:   proto.Int32(1),
+				Label:    descriptor.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				Type:     descriptor.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: proto.String(".test.Labels.ValuesEntry"),
+			}},
+			NestedType: []*descriptor.DescriptorProto{{
+				Name: proto.String("ValuesEntry"),
+				Field: []*descriptor.FieldDescriptorProto{
+					{
+						Name:   proto.String("key"),
+						Number: proto.Int32(1),
+						Label:  descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:   descriptor.FieldDescriptorProto_TYPE_STRING.Enum(),
+					},
+					{
+						Name:   proto.String("value"),
+						Number: proto.Int32(2),
+						Label:  descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:   descriptor.FieldDescriptorProto_TYPE_INT64.Enum(),
+					},
+				},
+				Options: &descriptor.MessageOptions{MapEntry: proto.Bool(true)},
+			}},
+		}},
+	}
+
+	return writeDescriptorFiles(t, &descriptorFile, labelsFile)
+}
+
+// writeDescriptorFiles writes the files as a descriptor set into a temp dir.
+func writeDescriptorFiles(t *testing.T, files ...*descriptor.FileDescriptorProto) string {
+	t.Helper()
+
+	data, err := proto.Marshal(&descriptor.FileDescriptorSet{File: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "etcd-console-codec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "set.pb")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// sampleFile covers the scalar types, the enums, the nested and the repeated messages, and the packed fields.
+func sampleFile() *descriptor.FileDescriptorProto {
+	return &descriptor.FileDescriptorProto{
+		Name:       proto.String("sample.proto"),
+		Package:    proto.String("sample.v1"),
+		Dependency: []string{"a.proto", "b.proto"},
+		MessageType: []*descriptor.DescriptorProto{{
+			Name: proto.String("Sample"),
+			Field: []*descriptor.FieldDescriptorProto{{
+				Name:     proto.String("sample_field"),
+				JsonName: proto.String("sampleField"),
+				Number:   proto.Int32(1),
+				Label:    descriptor.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+				Type:     descriptor.FieldDescriptorProto_TYPE_SINT64.Enum(),
+				Options: &descriptor.FieldOptions{
+					Packed: proto.Bool(true),
+					UninterpretedOption: []*descriptor.UninterpretedOption{{
+						PositiveIntValue: proto.Uint64(1 << 40),
+						NegativeIntValue: proto.Int64(-1 << 40),
+						DoubleValue:      proto.Float64(1.5),
+						StringValue:      []byte{0, 1, 0xfe, 0xff},
+					}},
+				},
+			}},
+		}},
+		EnumType: []*descriptor.EnumDescriptorProto{{
+			Name: proto.String("State"),
+			Value: []*descriptor.EnumValueDescriptorProto{
+				{Name: proto.String("UNKNOWN"), Number: proto.Int32(-1)},
+				{Name: proto.String("READY"), Number: proto.Int32(1)},
+			},
+		}},
+		Options: &descriptor.FileOptions{
+			OptimizeFor: descriptor.FileOptions_CODE_SIZE.Enum(),
+		},
+		SourceCodeInfo: &descriptor.SourceCodeInfo{
+			Location: []*descriptor.SourceCodeInfo_Location{{
+				// packed
+				Path: []int32{4, 0, 2, 0},
+				Span: []int32{3, 2, 40},
+			}},
+		},
+		Syntax: proto.String("proto3"),
+	}
+}
+
+func TestProtobufCodec(t *testing.T) {
+	path := writeDescriptorSet(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	decoder, err := New(protobufCodecName, Options{DescriptorSet: path, Message: "google.protobuf.FileDescriptorProto"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	c := decoder.(Codec)
+
+	sample := sampleFile()
+	value, err := proto.Marshal(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("decode", func(t *testing.T) {
+		decoded, err := c.Decode(nil, value)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if decoded.Kind != "google.protobuf.FileDescriptorProto" {
+			t.Errorf("Decode() kind = %s", decoded.Kind)
+		}
+		object := decoded.Object.(map[string]interface{})
+		if object["package"] != "sample.v1" || object["syntax"] != "proto3" {
+			t.Errorf("Decode() = %v, want the package and the syntax", object)
+		}
+		if options := object["options"].(map[string]interface{}); options["optimizeFor"] != "CODE_SIZE" {
+			t.Errorf("Decode() options = %v, want the enum by its name", options)
+		}
+
+		// what is rendered is read back by jsonpb into the same message
+		rendered, err := json.Marshal(decoded.Object)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got descriptor.FileDescriptorProto
+		if err := jsonpb.UnmarshalString(string(rendered), &got); err != nil {
+			t.Fatalf("jsonpb.Unmarshal(%s) error = %v", rendered, err)
+		}
+		if !proto.Equal(&got, sample) {
+			t.Errorf("Decode() = %s,\nwant %s", proto.CompactTextString(&got), proto.CompactTextString(sample))
+		}
+	})
+
+	t.Run("encode", func(t *testing.T) {
+		// jsonpb renders the 64-bit integers as strings
+		input, err := (&jsonpb.Marshaler{}).MarshalToString(sample)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded, err := c.Encode(nil, []byte(input))
+		if err != nil {
+			t.Fatalf("Encode(%s) error = %v", input, err)
+		}
+		var got descriptor.FileDescriptorProto
+		if err := proto.Unmarshal(encoded, &got); err != nil {
+			t.Fatalf("Encode() is not readable, %v", err)
+		}
+		if !proto.Equal(&got, sample) {
+			t.Errorf("Encode() = %s,\nwant %s", proto.CompactTextString(&got), proto.CompactTextString(sample))
+		}
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		if _, err := c.Encode(nil, []byte(`{"name": "a.proto", "nope": 1}`)); err == nil {
+			t.Errorf("Encode() of an unknown field error = nil")
+		}
+	})
+}
+
+func TestProtobufCodecMap(t *testing.T) {
+	path := writeDescriptorSet(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	decoder, err := New(protobufCodecName, Options{DescriptorSet: path, Message: ".test.Labels"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	c := decoder.(Codec)
+
+	encoded, err := c.Encode(nil, []byte(`{"values": {"b": "2", "a": 1}}`))
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	decoded, err := c.Decode(nil, encoded)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	rendered, _ := json.Marshal(decoded.Object)
+	if string(rendered) != `{"values":{"a":1,"b":2}}` {
+		t.Errorf("Decode() = %s, want the entries by their keys", rendered)
+	}
+}
+
+func TestProtobufCodecErrors(t *testing.T) {
+	path := writeDescriptorSet(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	if _, err := New(protobufCodecName, Options{DescriptorSet: path, Message: "test.Missing"}); err == nil {
+		t.Errorf("New() of a missing message error = nil")
+	}
+	if _, err := New(protobufCodecName, Options{DescriptorSet: path}); err == nil {
+		t.Errorf("New() without the message error = nil")
+	}
+
+	bad := filepath.Join(filepath.Dir(path), "bad.pb")
+	if err := ioutil.WriteFile(bad, []byte{0xff, 0xff}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(protobufCodecName, Options{DescriptorSet: bad, Message: "test.Labels"}); err == nil {
+		t.Errorf("New() of a bad descriptor set error = nil")
+	}
+}
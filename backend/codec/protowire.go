@@ -0,0 +1,190 @@
+package codec
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+var errBadWire = errors.New("bad protobuf wire format")
+
+// protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// wireField is one field of a protobuf message decoded without its descriptor.
+type wireField struct {
+	Number   int
+	WireType int
+	Varint   uint64
+	Bytes    []byte
+}
+
+type wireMessage []wireField
+
+func parseMessage(data []byte) (wireMessage, error) {
+	var msg wireMessage
+
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 || tag>>3 == 0 {
+			return nil, errBadWire
+		}
+		data = data[n:]
+
+		field := wireField{
+			Number:   int(tag >> 3),
+			WireType: int(tag & 7),
+		}
+		switch field.WireType {
+		case wireVarint:
+			value, n := binary.Uvarint(data)
+			if n <= 0 {
+				return nil, errBadWire
+			}
+			field.Varint = value
+			data = data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return nil, errBadWire
+			}
+			field.Varint = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return nil, errBadWire
+			}
+			field.Bytes = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case wireFixed32:
+			if len(data) < 4 {
+				return nil, errBadWire
+			}
+			field.Varint = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		default:
+			// groups are deprecated, nobody stores them
+			return nil, errBadWire
+		}
+
+		msg = append(msg, field)
+	}
+
+	return msg, nil
+}
+
+// bytes returns the last length-delimited field with the number.
+func (m wireMessage) bytes(number int) []byte {
+	var ret []byte
+	for _, field := range m {
+		if field.Number == number && field.WireType == wireBytes {
+			ret = field.Bytes
+		}
+	}
+
+	return ret
+}
+
+func (m wireMessage) varint(number int) (uint64, bool) {
+	var (
+		ret   uint64
+		found bool
+	)
+	for _, field := range m {
+		if field.Number == number && field.WireType != wireBytes {
+			ret, found = field.Varint, true
+		}
+	}
+
+	return ret, found
+}
+
+// generic renders the fields keyed by their numbers, repeated fields become lists.
+func (m wireMessage) generic(skip ...int) map[string]interface{} {
+	ret := make(map[string]interface{}, len(m))
+
+fields:
+	for _, field := range m {
+		for _, number := range skip {
+			if field.Number == number {
+				continue fields
+			}
+		}
+
+		key := strconv.Itoa(field.Number)
+		value := field.generic()
+		if existing, ok := ret[key]; ok {
+			if list, ok := existing.([]interface{}); ok {
+				ret[key] = append(list, value)
+			} else {
+				ret[key] = []interface{}{existing, value}
+			}
+		} else {
+			ret[key] = value
+		}
+	}
+
+	return ret
+}
+
+// generic guesses what a length-delimited field is: a readable string, a nested message or raw bytes.
+func (f wireField) generic() interface{} {
+	if f.WireType != wireBytes {
+		return f.Varint
+	}
+
+	// messages mostly start with a low field number, which is a control character
+	if len(f.Bytes) != 0 && f.Bytes[0] < 0x20 {
+		if msg, err := parseMessage(f.Bytes); err == nil {
+			return msg.generic()
+		}
+	}
+	if isPrintable(f.Bytes) {
+		return string(f.Bytes)
+	}
+	if msg, err := parseMessage(f.Bytes); err == nil {
+		return msg.generic()
+	}
+
+	return base64.StdEncoding.EncodeToString(f.Bytes)
+}
+
+func isPrintable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func appendVarint(buf []byte, value uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], value)
+
+	return append(buf, tmp[:n]...)
+}
+
+func appendTag(buf []byte, number int, wireType int) []byte {
+	return appendVarint(buf, uint64(number)<<3|uint64(wireType))
+}
+
+func appendBytes(buf []byte, number int, data []byte) []byte {
+	buf = appendTag(buf, number, wireBytes)
+	buf = appendVarint(buf, uint64(len(data)))
+
+	return append(buf, data...)
+}
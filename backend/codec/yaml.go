@@ -0,0 +1,58 @@
+package codec
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+type yamlCodec struct {
+}
+
+func (c *yamlCodec) Name() string {
+	return "yaml"
+}
+
+// Match never detects yaml, almost any text is valid yaml.
+func (c *yamlCodec) Match(key, value []byte) bool {
+	return false
+}
+
+func (c *yamlCodec) Decode(key, value []byte) (Decoded, error) {
+	var object interface{}
+	if err := yaml.Unmarshal(value, &object); err != nil {
+		return Decoded{}, err
+	}
+
+	return Decoded{
+		Object: NormalizeYAML(object),
+	}, nil
+}
+
+func (c *yamlCodec) Encode(key, input []byte) ([]byte, error) {
+	var object interface{}
+	if err := yaml.Unmarshal(input, &object); err != nil {
+		return nil, err
+	}
+
+	return input, nil
+}
+
+// NormalizeYAML turns the maps of yaml into the ones json can encode.
+func NormalizeYAML(object interface{}) interface{} {
+	switch typed := object.(type) {
+	case map[interface{}]interface{}:
+		ret := make(map[string]interface{}, len(typed))
+		for key, value := range typed {
+			ret[fmt.Sprint(key)] = NormalizeYAML(value)
+		}
+		return ret
+	case []interface{}:
+		for idx := range typed {
+			typed[idx] = NormalizeYAML(typed[idx])
+		}
+		return typed
+	}
+
+	return object
+}
@@ -106,6 +106,14 @@ type Configuration struct {
 	// Defaults to "true"
 	Test bool `json:"test,omitempty" yaml:"Test"`
 
+	// How many members the embedding etcd starts, on the loopback ports 2379/2380, 12379/12380 and so on.
+	// Defaults to 1
+	TestMembers int `json:"testMembers,omitempty" yaml:"TestMembers"`
+
+	// Where the embedding etcd stores the data, the members of more than one store in the dirs of their names.
+	// Defaults to "/tmp/etcd_console.etcd"
+	TestDataDir string `json:"testDataDir,omitempty" yaml:"TestDataDir"`
+
 	// Start with an embedding etcd or not.
 	// Defaults to "true"
 	LogLevel string `json:"logLevel,omitempty" yaml:"LogLevel"`
@@ -114,6 +122,72 @@ type Configuration struct {
 	// Defaults to "/tmp/etcd_console.backup"
 	BackupDir string `json:"backupDir,omitempty"`
 
+	// How long to keep retrying the etcd endpoints in the background at startup, in seconds,
+	// the console serves in degraded mode meanwhile. Zero means retrying forever.
+	// Defaults to 60
+	StartupTimeout int64 `json:"startupTimeout,omitempty" yaml:"StartupTimeout"`
+
+	// How often to re-detect the version of etcd, in seconds,
+	// the client is switched when the major version changes. Zero means never.
+	// Defaults to 30
+	VersionProbeInterval int64 `json:"versionProbeInterval,omitempty" yaml:"VersionProbeInterval"`
+
+	// Which codec renders and writes the values under the key prefixes,
+	// the longest matched prefix wins.
+	// Defaults to an empty slice.
+	Codecs []CodecConfiguration `json:"codecs,omitempty" yaml:"Codecs"`
+
+	// Which JSON Schema the values under the key prefixes are validated against before writing,
+	// the longest matched prefix wins.
+	// Defaults to an empty slice.
+	Schemas []SchemaConfiguration `json:"schemas,omitempty" yaml:"Schemas"`
+
+	// The other clusters the prefixes can be mirrored to, by name.
+	// Defaults to an empty slice.
+	Mirrors []MirrorConfiguration `json:"mirrors,omitempty" yaml:"Mirrors"`
+
+	// Let the mirror jobs dial the endpoints in their requests, not only the clusters named in Mirrors.
+	// Defaults to false
+	MirrorAnyEndpoints bool `json:"mirrorAnyEndpoints,omitempty" yaml:"MirrorAnyEndpoints"`
+
+	// Where is appending the audit log of the mutating operations, empty means disabled.
+	// Defaults to "/tmp/etcd_console.audit/audit.log"
+	AuditFile string `json:"auditFile,omitempty" yaml:"AuditFile"`
+
+	// How large the audit log grows before rotating, in megabytes.
+	// Defaults to 100
+	AuditMaxSize int64 `json:"auditMaxSize,omitempty" yaml:"AuditMaxSize"`
+
+	// How many rotated audit logs are kept.
+	// Defaults to 10
+	AuditMaxBackups int `json:"auditMaxBackups,omitempty" yaml:"AuditMaxBackups"`
+
+	// Refuse all changes of the keys, the mirror jobs and the backups, the backups can still be taken.
+	// Defaults to false
+	ReadOnly bool `json:"readOnly,omitempty" yaml:"ReadOnly"`
+
+	// The keys under the prefixes cannot be written or removed through the console.
+	// Defaults to an empty slice.
+	ProtectedPrefixes []string `json:"protectedPrefixes,omitempty" yaml:"ProtectedPrefixes"`
+
+	// How many keys a range remove may delete at once, more keys need the token of a preceding count,
+	// zero means no limit.
+	// Defaults to 1000
+	DeleteLimit int64 `json:"deleteLimit,omitempty" yaml:"DeleteLimit"`
+
+	// How many previous key values the undo journal keeps in memory for the writes and removes of the console,
+	// zero means disabled.
+	// Defaults to 10000
+	JournalSize int `json:"journalSize,omitempty" yaml:"JournalSize"`
+
+	// Which request header carries the user authenticated by a proxy, the basic auth user is preferred.
+	// Defaults to "X-Remote-User"
+	AuditUserHeader string `json:"auditUserHeader,omitempty" yaml:"AuditUserHeader"`
+
+	// The address is used for serving the gRPC API, empty means disabled.
+	// Defaults to ""
+	GRPCAdvertise string `json:"grpcAdvertise,omitempty" yaml:"GRPCAdvertise"`
+
 	////////////////////////
 	// iris.Configuration //
 	///////////////////////
@@ -257,12 +331,51 @@ type Configuration struct {
 	Other map[string]interface{} `json:"other,omitempty" yaml:"Other" toml:"Other"`
 }
 
+type CodecConfiguration struct {
+	// The key prefix, e.g. "/registry/".
+	Prefix string `json:"prefix" yaml:"Prefix"`
+
+	// The name of codec, one of kubernetes, json, yaml, gzip and protobuf.
+	Codec string `json:"codec" yaml:"Codec"`
+
+	// The compiled FileDescriptorSet file and the full name of message for protobuf,
+	// or the optional FileDescriptorSet file of the kubernetes types for kubernetes.
+	DescriptorSet string `json:"descriptorSet,omitempty" yaml:"DescriptorSet"`
+	Message       string `json:"message,omitempty" yaml:"Message"`
+}
+
+type SchemaConfiguration struct {
+	// The key prefix, e.g. "/config/".
+	Prefix string `json:"prefix" yaml:"Prefix"`
+
+	// The JSON Schema file, in json or yaml.
+	Schema string `json:"schema" yaml:"Schema"`
+}
+
+type MirrorConfiguration struct {
+	// The name used by the mirror jobs, e.g. "staging".
+	Name string `json:"name" yaml:"Name"`
+
+	// The endpoints of the cluster.
+	Endpoints []string `json:"endpoints" yaml:"Endpoints"`
+}
+
 func DefaultConfiguration() Configuration {
 	return Configuration{
-		Advertise: ":8080",
-		Endpoints: []string{"http://127.0.0.1:2379"},
-		Test:      true,
-		LogLevel:  "debug",
+		Advertise:            ":8080",
+		Endpoints:            []string{"http://127.0.0.1:2379"},
+		Test:                 true,
+		TestMembers:          1,
+		TestDataDir:          filepath.Join(os.TempDir(), "etcd_console.etcd"),
+		LogLevel:             "debug",
+		StartupTimeout:       60,
+		VersionProbeInterval: 30,
+		AuditFile:            filepath.Join(os.TempDir(), "etcd_console.audit", "audit.log"),
+		AuditMaxSize:         100,
+		AuditMaxBackups:      10,
+		AuditUserHeader:      "X-Remote-User",
+		JournalSize:          10000,
+		DeleteLimit:          1000,
 
 		///////////////////////////////
 		// iris.DefaultConfiguration //
@@ -0,0 +1,144 @@
+package backend
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeYAML(t *testing.T, content string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "etcd-console-configuration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "etcd-console.yml")
+	if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestParseYAML(t *testing.T) {
+	filename := writeYAML(t, `
+Advertise: ":9090"
+Endpoints:
+- http://etcd-0:2379
+- http://etcd-1:2379
+ReadOnly: true
+ProtectedPrefixes:
+- /registry/
+DeleteLimit: 10
+Codecs:
+- Prefix: /registry/
+  Codec: protobuf
+  DescriptorSet: /etc/registry.pb
+  Message: registry.Value
+Mirrors:
+- Name: staging
+  Endpoints:
+  - http://staging:2379
+`)
+	defer os.RemoveAll(filepath.Dir(filename))
+
+	c, err := parseYAML(filename)
+	if err != nil {
+		t.Fatalf("parseYAML() error = %v", err)
+	}
+
+	if c.Advertise != ":9090" {
+		t.Errorf("Advertise = %q, want :9090", c.Advertise)
+	}
+	if want := []string{"http://etcd-0:2379", "http://etcd-1:2379"}; !reflect.DeepEqual(c.Endpoints, want) {
+		t.Errorf("Endpoints = %q, want %q", c.Endpoints, want)
+	}
+	if !c.ReadOnly || c.DeleteLimit != 10 {
+		t.Errorf("ReadOnly, DeleteLimit = %v, %d, want true, 10", c.ReadOnly, c.DeleteLimit)
+	}
+	if want := []string{"/registry/"}; !reflect.DeepEqual(c.ProtectedPrefixes, want) {
+		t.Errorf("ProtectedPrefixes = %q, want %q", c.ProtectedPrefixes, want)
+	}
+	wantCodecs := []CodecConfiguration{{
+		Prefix:        "/registry/",
+		Codec:         "protobuf",
+		DescriptorSet: "/etc/registry.pb",
+		Message:       "registry.Value",
+	}}
+	if !reflect.DeepEqual(c.Codecs, wantCodecs) {
+		t.Errorf("Codecs = %+v, want %+v", c.Codecs, wantCodecs)
+	}
+	wantMirrors := []MirrorConfiguration{{Name: "staging", Endpoints: []string{"http://staging:2379"}}}
+	if !reflect.DeepEqual(c.Mirrors, wantMirrors) {
+		t.Errorf("Mirrors = %+v, want %+v", c.Mirrors, wantMirrors)
+	}
+
+	// the absent ones keep the defaults
+	defaults := DefaultConfiguration()
+	if c.JournalSize != defaults.JournalSize || c.StartupTimeout != defaults.StartupTimeout || c.AuditUserHeader != defaults.AuditUserHeader {
+		t.Errorf("JournalSize, StartupTimeout, AuditUserHeader = %d, %d, %q, want the defaults", c.JournalSize, c.StartupTimeout, c.AuditUserHeader)
+	}
+	// as the -audit-file flag
+	if want := filepath.Join(os.TempDir(), "etcd_console.audit", "audit.log"); c.AuditFile != want {
+		t.Errorf("AuditFile = %q, want %q", c.AuditFile, want)
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	if _, err := parseYAML(filepath.Join(os.TempDir(), "etcd-console-missing.yml")); err == nil {
+		t.Errorf("parseYAML() of a missing file error = nil, want an error")
+	}
+
+	filename := writeYAML(t, "Endpoints: [unclosed\n")
+	defer os.RemoveAll(filepath.Dir(filename))
+
+	if _, err := parseYAML(filename); err == nil {
+		t.Errorf("parseYAML() of a bad file error = nil, want an error")
+	}
+}
+
+func TestYAMLPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("YAML() of a missing file doesn't panic")
+		}
+	}()
+
+	YAML(filepath.Join(os.TempDir(), "etcd-console-missing.yml"))
+}
+
+func TestCheckRange(t *testing.T) {
+	c := DefaultConfiguration()
+	c.ProtectedPrefixes = []string{"/registry/"}
+
+	tests := []struct {
+		name       string
+		start, end string
+		forbidden  bool
+	}{
+		{"key under the prefix", "/registry/pods", "", true},
+		{"key beside the prefix", "/registryx", "", false},
+		{"range ending at the prefix", "/a", "/registry/", false},
+		{"range into the prefix", "/a", "/registry/z", true},
+		{"range after the prefix", "/registry0", "/z", false},
+		{"all keys from the start", "/a", "\x00", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := c.CheckRange(tt.start, tt.end); IsForbidden(err) != tt.forbidden {
+				t.Errorf("CheckRange(%q, %q) error = %v, want forbidden %v", tt.start, tt.end, err, tt.forbidden)
+			}
+		})
+	}
+
+	c.ReadOnly = true
+	if err := c.CheckKey("/a"); !IsForbidden(err) {
+		t.Errorf("CheckKey() in read-only mode error = %v, want forbidden", err)
+	}
+	if err := c.CheckProtected("/a", ""); err != nil {
+		t.Errorf("CheckProtected() in read-only mode error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,353 @@
+// Package embedded runs the in-process etcd cluster of the test mode, the members listen on the loopback ports
+// and can be stopped and started again, e.g. for demoing the leader elections and the failures.
+package embedded
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/embed"
+)
+
+const (
+	// The ports of the first member, the ones of the following members are stepped by PortStep,
+	// e.g. 2379, 12379 and 22379 for the clients of 3 members.
+	ClientPort = 2379
+	PeerPort   = 2380
+	PortStep   = 10000
+
+	// The members fit in the ports below 65536.
+	MaxMembers = 6
+
+	// SizeFile records the members in the data dir, the members of another size cannot reuse it.
+	SizeFile = "etcd-console-members"
+)
+
+var (
+	// StartTimeout bounds how long the cluster takes to elect the leader at the start.
+	StartTimeout = 60 * time.Second
+
+	// LogPkgLevels of the members, as "--log-package-levels" of etcd, empty means the global level of capnslog.
+	LogPkgLevels = "etcdserver=WARNING,security=WARNING,raft=WARNING"
+)
+
+// Member is a member of the cluster and its state.
+type Member struct {
+	Name      string
+	ClientURL string
+	PeerURL   string
+	DataDir   string
+	Running   bool
+}
+
+type member struct {
+	name      string
+	clientURL url.URL
+	peerURL   url.URL
+	dataDir   string
+	etcd      *embed.Etcd
+}
+
+// Cluster is the started members, Close it at the end.
+type Cluster struct {
+	mu             sync.Mutex
+	members        []*member
+	initialCluster string
+	// a single member is a new cluster at every start, as its data dir may be left by another one
+	forceNew bool
+}
+
+// Start starts the members on the loopback ports, and waits for them to be ready.
+// A single member is named "default" and stores the data in the data dir itself,
+// more members are named "member-1" to "member-N" and store the data in the dirs of their names under it.
+// The fixed ports are checked before any member starts, the first one taken fails the start.
+func Start(size int, dataDir string) (*Cluster, error) {
+	if err := checkSize(size); err != nil {
+		return nil, err
+	}
+
+	var urls [][2]url.URL
+	for i := 0; i < size; i++ {
+		urls = append(urls, [2]url.URL{
+			loopbackURL(ClientPort + i*PortStep),
+			loopbackURL(PeerPort + i*PortStep),
+		})
+	}
+	if err := checkPorts(urls); err != nil {
+		return nil, err
+	}
+	return start(dataDir, urls)
+}
+
+// StartOnFreePorts is Start on the free loopback ports picked by the system instead of the fixed ones,
+// e.g. for the tests starting their clusters side by side.
+func StartOnFreePorts(size int, dataDir string) (*Cluster, error) {
+	if err := checkSize(size); err != nil {
+		return nil, err
+	}
+
+	var urls [][2]url.URL
+	for i := 0; i < size; i++ {
+		clientURL, err := freeLoopbackURL()
+		if err != nil {
+			return nil, err
+		}
+		peerURL, err := freeLoopbackURL()
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, [2]url.URL{clientURL, peerURL})
+	}
+	return start(dataDir, urls)
+}
+
+// checkPorts tells which port of the members is taken, rather than the members failing one by one
+// after the others started.
+func checkPorts(urls [][2]url.URL) error {
+	for i, memberURLs := range urls {
+		for _, u := range memberURLs {
+			l, err := net.Listen("tcp", u.Host)
+			if err != nil {
+				return fmt.Errorf("the port %s of embedding etcd member %d is taken, %v", u.Port(), i+1, err)
+			}
+			l.Close()
+		}
+	}
+	return nil
+}
+
+func checkSize(size int) error {
+	if size < 1 || size > MaxMembers {
+		return fmt.Errorf("the members of the embedding etcd must be from 1 to %d", MaxMembers)
+	}
+	return nil
+}
+
+// start starts a member of every pair of the client and the peer URLs.
+func start(dataDir string, urls [][2]url.URL) (*Cluster, error) {
+	if err := checkDataDir(dataDir, len(urls)); err != nil {
+		return nil, err
+	}
+
+	c := &Cluster{
+		forceNew: len(urls) == 1,
+	}
+
+	var initialCluster []string
+	for i, memberURLs := range urls {
+		m := &member{
+			name:      fmt.Sprintf("member-%d", i+1),
+			clientURL: memberURLs[0],
+			peerURL:   memberURLs[1],
+			dataDir:   filepath.Join(dataDir, fmt.Sprintf("member-%d", i+1)),
+		}
+		if c.forceNew {
+			m.name, m.dataDir = embed.DefaultName, dataDir
+		}
+
+		c.members = append(c.members, m)
+		initialCluster = append(initialCluster, fmt.Sprintf("%s=%s", m.name, m.peerURL.String()))
+	}
+	c.initialCluster = strings.Join(initialCluster, ",")
+
+	// the members are ready only when the quorum is started
+	for _, m := range c.members {
+		if err := c.startMember(m); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	for _, m := range c.members {
+		select {
+		case <-m.etcd.Server.ReadyNotify():
+		case <-time.After(StartTimeout):
+			c.Close()
+			return nil, errors.New("embedding etcd took too long to start")
+		}
+	}
+
+	return c, nil
+}
+
+// checkDataDir refuses the data dir left by a cluster of another size, its members would start
+// from the data of the other cluster, or the single member would leave the dirs of the others behind.
+func checkDataDir(dataDir string, size int) error {
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return fmt.Errorf("cannot create the data dir of embedding etcd, %v", err)
+	}
+
+	sizeFile := filepath.Join(dataDir, SizeFile)
+	left := 0
+	data, err := ioutil.ReadFile(sizeFile)
+	switch {
+	case err == nil:
+		if left, err = strconv.Atoi(strings.TrimSpace(string(data))); err != nil {
+			return fmt.Errorf("bad size of embedding etcd in %s, %v", sizeFile, err)
+		}
+	case os.IsNotExist(err):
+		// the dirs left before the size is recorded
+		left = leftMembers(dataDir)
+	default:
+		return err
+	}
+
+	if left != 0 && left != size {
+		return fmt.Errorf("the data dir %s is left by an embedding etcd of %d members, remove it or choose another one for %d members", dataDir, left, size)
+	}
+	return ioutil.WriteFile(sizeFile, []byte(strconv.Itoa(size)), 0600)
+}
+
+func leftMembers(dataDir string) int {
+	// the single member stores its "member" dir in the data dir itself
+	if stat, err := os.Stat(filepath.Join(dataDir, "member")); err == nil && stat.IsDir() {
+		return 1
+	}
+
+	dirs, _ := filepath.Glob(filepath.Join(dataDir, "member-*"))
+	return len(dirs)
+}
+
+func (c *Cluster) startMember(m *member) error {
+	cfg := embed.NewConfig()
+	cfg.Name = m.name
+	cfg.Dir = m.dataDir
+	cfg.LCUrls, cfg.ACUrls = []url.URL{m.clientURL}, []url.URL{m.clientURL}
+	cfg.LPUrls, cfg.APUrls = []url.URL{m.peerURL}, []url.URL{m.peerURL}
+	cfg.InitialCluster = c.initialCluster
+	cfg.ForceNewCluster = c.forceNew
+	cfg.LogPkgLevels = LogPkgLevels
+
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		return fmt.Errorf("cannot start embedding etcd member %s, %v", m.name, err)
+	}
+	m.etcd = e
+	return nil
+}
+
+// Endpoints returns the client URLs of all members, running or not.
+func (c *Cluster) Endpoints() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	endpoints := make([]string, 0, len(c.members))
+	for _, m := range c.members {
+		endpoints = append(endpoints, m.clientURL.String())
+	}
+	return endpoints
+}
+
+// Members returns the members in the order of their names.
+func (c *Cluster) Members() []Member {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	members := make([]Member, 0, len(c.members))
+	for _, m := range c.members {
+		members = append(members, m.get())
+	}
+	return members
+}
+
+// StopMember stops the member, the others go on electing the leader if they're still the quorum.
+func (c *Cluster) StopMember(name string) (Member, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, err := c.lookup(name)
+	if err != nil {
+		return Member{}, err
+	}
+	if m.etcd == nil {
+		return Member{}, ErrMemberStopped
+	}
+
+	m.etcd.Close()
+	m.etcd = nil
+	return m.get(), nil
+}
+
+// StartMember starts the stopped member again from its data dir, it's ready once it catches up with the quorum.
+func (c *Cluster) StartMember(name string) (Member, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, err := c.lookup(name)
+	if err != nil {
+		return Member{}, err
+	}
+	if m.etcd != nil {
+		return Member{}, ErrMemberRunning
+	}
+
+	if err := c.startMember(m); err != nil {
+		return Member{}, err
+	}
+	return m.get(), nil
+}
+
+// Close stops all running members.
+func (c *Cluster) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, m := range c.members {
+		if m.etcd != nil {
+			m.etcd.Close()
+			m.etcd = nil
+		}
+	}
+}
+
+var (
+	// ErrMemberNotFound is returned for the names out of the cluster.
+	ErrMemberNotFound = errors.New("cannot find embedding etcd member")
+	// ErrMemberRunning is returned for starting a running member.
+	ErrMemberRunning = errors.New("embedding etcd member is running already")
+	// ErrMemberStopped is returned for stopping a stopped member.
+	ErrMemberStopped = errors.New("embedding etcd member is stopped already")
+)
+
+func (c *Cluster) lookup(name string) (*member, error) {
+	for _, m := range c.members {
+		if m.name == name {
+			return m, nil
+		}
+	}
+	return nil, ErrMemberNotFound
+}
+
+func (m *member) get() Member {
+	return Member{
+		Name:      m.name,
+		ClientURL: m.clientURL.String(),
+		PeerURL:   m.peerURL.String(),
+		DataDir:   m.dataDir,
+		Running:   m.etcd != nil,
+	}
+}
+
+func loopbackURL(port int) url.URL {
+	return url.URL{Scheme: "http", Host: fmt.Sprintf("localhost:%d", port)}
+}
+
+// freeLoopbackURL takes a free loopback port, it may be taken by others before the member listens on it,
+// which is unlikely enough.
+func freeLoopbackURL() (url.URL, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return url.URL{}, fmt.Errorf("cannot find a free port, %v", err)
+	}
+	defer l.Close()
+
+	return url.URL{Scheme: "http", Host: fmt.Sprintf("127.0.0.1:%d", l.Addr().(*net.TCPAddr).Port)}, nil
+}
@@ -0,0 +1,137 @@
+package embedded
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v3 "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/pkg/capnslog"
+)
+
+func TestStartErrors(t *testing.T) {
+	for _, size := range []int{0, MaxMembers + 1} {
+		if _, err := Start(size, os.TempDir()); err == nil {
+			t.Errorf("Start(%d) error = nil, want an error", size)
+		}
+		if _, err := StartOnFreePorts(size, os.TempDir()); err == nil {
+			t.Errorf("StartOnFreePorts(%d) error = nil, want an error", size)
+		}
+	}
+}
+
+func TestCheckPorts(t *testing.T) {
+	free, err := freeLoopbackURL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkPorts([][2]url.URL{{free, free}}); err != nil {
+		t.Errorf("checkPorts() of the free port error = %v", err)
+	}
+
+	l, err := net.Listen("tcp", free.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	err = checkPorts([][2]url.URL{{free, free}})
+	if err == nil || !strings.Contains(err.Error(), free.Port()) {
+		t.Errorf("checkPorts() of the taken port error = %v, want the port %s", err, free.Port())
+	}
+}
+
+func TestClusterStopStartMember(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-console-embedded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	capnslog.SetGlobalLogLevel(capnslog.CRITICAL)
+
+	c, err := StartOnFreePorts(3, dir)
+	if err != nil {
+		t.Fatalf("StartOnFreePorts() error = %v", err)
+	}
+	defer c.Close()
+
+	members := c.Members()
+	if len(members) != 3 || members[0].Name != "member-1" || !members[2].Running {
+		t.Fatalf("Members() = %+v, want 3 running members", members)
+	}
+
+	client, err := v3.New(v3.Config{Endpoints: c.Endpoints(), DialTimeout: 10 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if _, err := c.StopMember("member-1"); err != nil {
+		t.Fatalf("StopMember() error = %v", err)
+	}
+	if _, err := c.StopMember("member-1"); err != ErrMemberStopped {
+		t.Errorf("StopMember() of a stopped member error = %v, want %v", err, ErrMemberStopped)
+	}
+	if _, err := c.StopMember("member-9"); err != ErrMemberNotFound {
+		t.Errorf("StopMember() of an unknown member error = %v, want %v", err, ErrMemberNotFound)
+	}
+
+	// the quorum is still there
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	_, err = client.Put(ctx, "/a", "1")
+	cancel()
+	if err != nil {
+		t.Fatalf("put with a stopped member error = %v", err)
+	}
+
+	member, err := c.StartMember("member-1")
+	if err != nil {
+		t.Fatalf("StartMember() error = %v", err)
+	}
+	if !member.Running {
+		t.Errorf("StartMember() = %+v, want running", member)
+	}
+	if _, err := c.StartMember("member-1"); err != ErrMemberRunning {
+		t.Errorf("StartMember() of a running member error = %v, want %v", err, ErrMemberRunning)
+	}
+}
+
+func TestStartDataDirOfAnotherSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-console-embedded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	capnslog.SetGlobalLogLevel(capnslog.CRITICAL)
+
+	c, err := StartOnFreePorts(1, dir)
+	if err != nil {
+		t.Fatalf("StartOnFreePorts() error = %v", err)
+	}
+	c.Close()
+
+	if _, err := StartOnFreePorts(2, dir); err == nil {
+		t.Fatalf("StartOnFreePorts() of another size error = nil, want an error")
+	}
+
+	// the same size reuses the data dir
+	c, err = StartOnFreePorts(1, dir)
+	if err != nil {
+		t.Fatalf("StartOnFreePorts() of the same size error = %v", err)
+	}
+	c.Close()
+
+	// the dirs left before the size is recorded
+	if err := os.Remove(filepath.Join(dir, SizeFile)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := StartOnFreePorts(3, dir); err == nil {
+		t.Errorf("StartOnFreePorts() of a data dir without the size error = nil, want an error")
+	}
+}
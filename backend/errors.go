@@ -0,0 +1,51 @@
+package backend
+
+// codes of the API errors, the clients can tell the failures apart by them
+const (
+	ErrCodeBadRequest           = "BadRequest"
+	ErrCodeForbidden            = "Forbidden"
+	ErrCodeNotFound             = "NotFound"
+	ErrCodeMethodNotAllowed     = "MethodNotAllowed"
+	ErrCodeTimeout              = "Timeout"
+	ErrCodeConflict             = "Conflict"
+	ErrCodeInvalidValue         = "InvalidValue"
+	ErrCodeConfirmationRequired = "ConfirmationRequired"
+	ErrCodeInternal             = "Internal"
+	ErrCodeUnsupported          = "Unsupported"
+	ErrCodeUnavailable          = "Unavailable"
+)
+
+// APIError is a failure with a machine-readable code, which is mapped to the status of the response.
+type APIError struct {
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+func NewAPIError(code string, message string) *APIError {
+	return &APIError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// NewBadRequestError is returned for the bad parameters or bodies of the requests.
+func NewBadRequestError(message string) *APIError {
+	return NewAPIError(ErrCodeBadRequest, message)
+}
+
+func NewNotFoundError(message string) *APIError {
+	return NewAPIError(ErrCodeNotFound, message)
+}
+
+func NewConflictError(message string) *APIError {
+	return NewAPIError(ErrCodeConflict, message)
+}
+
+// NewUnsupportedError is returned for the features the console or the etcd doesn't support, e.g. etcd v2.
+func NewUnsupportedError(message string) *APIError {
+	return NewAPIError(ErrCodeUnsupported, message)
+}
@@ -1,25 +1,50 @@
 package backend
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	v3 "github.com/coreos/etcd/clientv3"
-	v2 "github.com/coreos/etcd/client"
 	sv2 "github.com/Masterminds/semver"
-	"github.com/kataras/iris"
+	v2 "github.com/coreos/etcd/client"
+	v3 "github.com/coreos/etcd/clientv3"
 	"github.com/kataras/golog"
-	"github.com/gorilla/http"
+)
 
-	"bytes"
-	"net/url"
-	"fmt"
-	"errors"
-	"encoding/json"
+const (
+	connectBackoffMin   = 1 * time.Second
+	connectBackoffMax   = 30 * time.Second
+	lazyConnectInterval = 5 * time.Second
+	versionProbeTimeout = 3 * time.Second
+	// in-flight requests may still hold a switched client
+	clientCloseDelay = 30 * time.Second
+)
+
+var (
+	errConnecting = errors.New("etcd client is connecting")
+	errClosed     = errors.New("etcd client is closed")
 )
 
 type EtcdClient struct {
-	version *sv2.Version
-	client  interface{}
+	endpoints []string
+
+	mu             sync.RWMutex
+	version        *sv2.Version
+	clusterVersion *sv2.Version
+	client         interface{}
+	lastErr        error
+	lastTry        time.Time
+
+	connecting int32
+	stopCh     chan struct{}
+	stopOnce   sync.Once
 }
 
 type EtcdVersion struct {
@@ -27,77 +52,349 @@ type EtcdVersion struct {
 	Etcdcluster string `json:"etcdcluster"`
 }
 
+// NotReadyError is returned while the etcd client hasn't finished its setup yet,
+// e.g. none of the endpoints has answered since the console started.
+type NotReadyError struct {
+	Cause error
+}
+
+func (e *NotReadyError) Error() string {
+	if e.Cause == nil {
+		return "etcd client is not ready"
+	}
+	return fmt.Sprintf("etcd client is not ready, %v", e.Cause)
+}
+
+func IsNotReady(err error) bool {
+	_, ok := err.(*NotReadyError)
+	return ok
+}
+
 var (
 	logger *golog.Logger
 )
 
-func NewEtcdClient(app *iris.Application, config Configuration) *EtcdClient {
-	logger = app.Logger()
+// NewEtcdClient returns immediately, the client keeps probing the endpoints in the background
+// with backoff until StartupTimeout, afterwards every call tries to finish the setup lazily.
+// Once connected, the version is re-detected every VersionProbeInterval,
+// and the client is switched when the major version changes, nil logger means golog.Default.
+func NewEtcdClient(l *golog.Logger, config Configuration) *EtcdClient {
+	logger = l
+	if logger == nil {
+		logger = golog.Default
+	}
+
+	c := &EtcdClient{
+		endpoints: config.Endpoints,
+		stopCh:    make(chan struct{}),
+	}
+
+	go c.keepConnecting(time.Duration(config.StartupTimeout) * time.Second)
+	go c.keepDetecting(time.Duration(config.VersionProbeInterval) * time.Second)
+
+	return c
+}
+
+// Close stops the background probing and closes the underlying client.
+func (c *EtcdClient) Close() {
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
 
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		closeClient(c.client)
+		c.client = nil
+	})
+}
+
+func (c *EtcdClient) keepConnecting(timeout time.Duration) {
 	var (
-		version     *sv2.Version
-		client      interface{}
-		err         error
-		etcdVersion bytes.Buffer
+		backoff  = connectBackoffMin
+		deadline = time.Now().Add(timeout)
 	)
 
-	if url, err := url.Parse(config.Endpoints[0]); err != nil {
-		logger.Fatal(err)
-	} else {
-		url.Path = "/version"
-		if _, err := http.Get(&etcdVersion, url.String(), ); err != nil {
-			logger.Fatal(err)
-		} else {
-			logger.Info(etcdVersion.String())
+	for {
+		err := c.connect()
+		if err == nil {
+			return
 		}
+		if err == errConnecting {
+			// a lazy connection is in flight
+			err = c.Err()
+		}
+
+		if timeout > 0 && time.Now().After(deadline) {
+			logger.Errorf("etcd endpoints(%v) are still unreachable after %v, serving in degraded mode, %v", c.endpoints, timeout, err)
+			return
+		}
+
+		logger.Warnf("etcd client is waiting for endpoints(%v), retry in %v, %v", c.endpoints, backoff, err)
+		select {
+		case <-c.stopCh:
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > connectBackoffMax {
+			backoff = connectBackoffMax
+		}
+	}
+}
+
+// connect tries every endpoint for the version, then creates the matching client.
+func (c *EtcdClient) connect() error {
+	if !atomic.CompareAndSwapInt32(&c.connecting, 0, 1) {
+		return errConnecting
+	}
+	defer atomic.StoreInt32(&c.connecting, 0)
+
+	if c.Ready() {
+		return nil
+	}
+
+	c.mu.Lock()
+	c.lastTry = time.Now()
+	c.mu.Unlock()
+
+	var client interface{}
+	version, clusterVersion, endpoint, err := probeVersion(c.endpoints)
+	if err == nil {
+		client, err = newClient(version, c.endpoints)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		c.lastErr = err
+		return err
 	}
 
-	for true {
-		var etcdVersionObj EtcdVersion
-		if err := json.Unmarshal(etcdVersion.Bytes(), &etcdVersionObj); err != nil {
-			logger.Fatal(err)
-			break
-		} else {
-			version, err = sv2.NewVersion(etcdVersionObj.Etcdserver)
-			if err == nil {
-				break
-			}
+	c.version = version
+	c.clusterVersion = clusterVersion
+	c.client = client
+	c.lastErr = nil
+	logger.Infof("etcd client is ready, etcd %v answered on %s", version, endpoint)
+
+	return nil
+}
+
+func (c *EtcdClient) keepDetecting(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			c.redetect()
 		}
 	}
+}
+
+// redetect probes the version again, e.g. after an in-place upgrade of the cluster,
+// and switches the client when the major version changes.
+func (c *EtcdClient) redetect() {
+	if !atomic.CompareAndSwapInt32(&c.connecting, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&c.connecting, 0)
 
-	for true {
-		if version.Major() == 2 {
-			v2Client, v2Err := v2.New(v2.Config{
-				Endpoints:               config.Endpoints,
-				Transport:               v2.DefaultTransport,
-				HeaderTimeoutPerRequest: 5 * time.Second,
-			})
-			if v2Err != nil {
-				err = v2Err
-			}
-
-			client = &v2Client
-		} else {
-			client, err = v3.New(v3.Config{
-				Endpoints:   config.Endpoints,
-				DialTimeout: 5 * time.Second,
-			})
+	c.mu.RLock()
+	current := c.version
+	c.mu.RUnlock()
+
+	if current == nil {
+		// still connecting
+		return
+	}
+
+	version, clusterVersion, endpoint, err := probeVersion(c.endpoints)
+	if err != nil {
+		logger.Warnf("cannot re-detect the version of etcd, %v", err)
+		return
+	}
+
+	if version.Major() != current.Major() {
+		client, err := newClient(version, c.endpoints)
+		if err != nil {
+			logger.Errorf("etcd on %s is changed from %v to %v, but cannot switch the client, %v", endpoint, current, version, err)
+			return
 		}
+
+		c.mu.Lock()
+		oldClient := c.client
+		c.version = version
+		c.clusterVersion = clusterVersion
+		c.client = client
+		c.mu.Unlock()
+
+		logger.Infof("etcd on %s is changed from %v to %v, switched the client", endpoint, current, version)
+		time.AfterFunc(clientCloseDelay, func() {
+			closeClient(oldClient)
+		})
+
+		return
+	}
+
+	if !version.Equal(current) {
+		logger.Infof("etcd on %s is changed from %v to %v", endpoint, current, version)
+	}
+
+	c.mu.Lock()
+	c.version = version
+	c.clusterVersion = clusterVersion
+	c.mu.Unlock()
+}
+
+// ensure finishes the client setup lazily, at most once per lazyConnectInterval.
+func (c *EtcdClient) ensure() error {
+	c.mu.RLock()
+	ready, lastTry, lastErr := c.client != nil, c.lastTry, c.lastErr
+	c.mu.RUnlock()
+
+	if ready {
+		return nil
+	}
+
+	select {
+	case <-c.stopCh:
+		return &NotReadyError{errClosed}
+	default:
+	}
+
+	if time.Since(lastTry) < lazyConnectInterval {
+		return &NotReadyError{lastErr}
+	}
+
+	if err := c.connect(); err != nil {
+		if err == errConnecting {
+			return &NotReadyError{lastErr}
+		}
+		return &NotReadyError{err}
+	}
+
+	return nil
+}
+
+// probeVersion returns the server version and the cluster version of the first answered endpoint,
+// the cluster version is nil while it's not decided yet.
+func probeVersion(endpoints []string) (*sv2.Version, *sv2.Version, string, error) {
+	var errMsgs []string
+
+	for _, endpoint := range endpoints {
+		version, clusterVersion, err := fetchVersion(endpoint)
 		if err == nil {
-			logger.Info("etcd client is ready")
-			break
+			return version, clusterVersion, endpoint, nil
+		}
+		errMsgs = append(errMsgs, fmt.Sprintf("%s: %v", endpoint, err))
+	}
+
+	return nil, nil, "", errors.New(strings.Join(errMsgs, "; "))
+}
+
+func fetchVersion(endpoint string) (*sv2.Version, *sv2.Version, error) {
+	versionURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	versionURL.Path = "/version"
+
+	timeoutCtx, timeoutCancelFn := context.WithTimeout(context.Background(), versionProbeTimeout)
+	defer timeoutCancelFn()
+
+	req, err := http.NewRequest(http.MethodGet, versionURL.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(timeoutCtx))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, errors.New(fmt.Sprintf("unexpected status %s", resp.Status))
+	}
+
+	var etcdVersion EtcdVersion
+	if err := json.NewDecoder(resp.Body).Decode(&etcdVersion); err != nil {
+		return nil, nil, errors.New(fmt.Sprintf("bad version response, %v", err))
+	}
+
+	version, err := sv2.NewVersion(etcdVersion.Etcdserver)
+	if err != nil {
+		return nil, nil, errors.New(fmt.Sprintf("bad etcd server version %q, %v", etcdVersion.Etcdserver, err))
+	}
+
+	// "not_decided" before the cluster version is agreed
+	clusterVersion, err := sv2.NewVersion(etcdVersion.Etcdcluster)
+	if err != nil {
+		clusterVersion = nil
+	}
+
+	return version, clusterVersion, nil
+}
+
+func newClient(version *sv2.Version, endpoints []string) (interface{}, error) {
+	if version.Major() == 2 {
+		v2Client, err := v2.New(v2.Config{
+			Endpoints:               endpoints,
+			Transport:               v2.DefaultTransport,
+			HeaderTimeoutPerRequest: 5 * time.Second,
+		})
+		if err != nil {
+			return nil, err
 		}
-		logger.Warnf("etcd client is waiting for endpoints(%v)...", config.Endpoints)
-		time.Sleep(2 * time.Second)
+
+		return &v2Client, nil
 	}
 
-	return &EtcdClient{
-		version,
-		client,
+	return v3.New(v3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: 5 * time.Second,
+	})
+}
+
+func closeClient(client interface{}) {
+	if v3Client, ok := client.(*v3.Client); ok {
+		if err := v3Client.Close(); err != nil {
+			logger.Warnf("cannot close etcd client, %v", err)
+		}
 	}
 }
 
+// Ready reports whether the client setup is finished.
+func (c *EtcdClient) Ready() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.client != nil
+}
+
+// Err returns the last error while connecting.
+func (c *EtcdClient) Err() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.lastErr
+}
+
 func (c *EtcdClient) V2() (*v2.Client, error) {
+	if err := c.ensure(); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	if c.version.Major() == 2 {
 		return c.client.(*v2.Client), nil
 	}
@@ -106,6 +403,13 @@ func (c *EtcdClient) V2() (*v2.Client, error) {
 }
 
 func (c *EtcdClient) V3() (*v3.Client, error) {
+	if err := c.ensure(); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	if c.version.Major() == 3 {
 		return c.client.(*v3.Client), nil
 	}
@@ -113,6 +417,40 @@ func (c *EtcdClient) V3() (*v3.Client, error) {
 	return nil, errors.New(fmt.Sprintf("the version of etcd is %v", c.version))
 }
 
-func (c *EtcdClient) Version() *sv2.Version {
-	return c.version
+func (c *EtcdClient) Version() (*sv2.Version, error) {
+	if err := c.ensure(); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.version, nil
+}
+
+// ClusterVersion returns the version agreed by the whole cluster,
+// it falls back to the server version while it's not decided yet.
+func (c *EtcdClient) ClusterVersion() (*sv2.Version, error) {
+	if err := c.ensure(); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.clusterVersion == nil {
+		return c.version, nil
+	}
+
+	return c.clusterVersion, nil
+}
+
+// Features returns the capabilities of the cluster, derived from the cluster version.
+func (c *EtcdClient) Features() (EtcdFeatures, error) {
+	version, err := c.ClusterVersion()
+	if err != nil {
+		return EtcdFeatures{}, err
+	}
+
+	return NewEtcdFeatures(version), nil
 }
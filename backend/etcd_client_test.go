@@ -0,0 +1,267 @@
+package backend
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	sv2 "github.com/Masterminds/semver"
+	"github.com/kataras/golog"
+)
+
+// fakeEtcd answers the version probes only, the versions of 2 are enough for the setup of the client,
+// which doesn't dial the endpoints.
+type fakeEtcd struct {
+	*httptest.Server
+
+	mu             sync.Mutex
+	version        string
+	clusterVersion string
+	// unavailable answers 503 to the probes
+	unavailable bool
+}
+
+func startFakeEtcd(version, clusterVersion string) *fakeEtcd {
+	f := &fakeEtcd{
+		version:        version,
+		clusterVersion: clusterVersion,
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if f.unavailable || r.URL.Path != "/version" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{"etcdserver":%q,"etcdcluster":%q}`, f.version, f.clusterVersion)
+	}))
+	return f
+}
+
+func (f *fakeEtcd) set(version, clusterVersion string, unavailable bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.version, f.clusterVersion, f.unavailable = version, clusterVersion, unavailable
+}
+
+func quietLogger() *golog.Logger {
+	l := golog.New()
+	l.SetLevel("disable")
+	return l
+}
+
+// waitReady waits for the background setup of the client.
+func waitReady(t *testing.T, c *EtcdClient, timeout time.Duration) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for !c.Ready() {
+		if time.Now().After(deadline) {
+			t.Fatalf("etcd client is not ready after %v, %v", timeout, c.Err())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestEtcdClientConnectsInBackground(t *testing.T) {
+	f := startFakeEtcd("2.3.8", "2.3.0")
+	defer f.Close()
+	f.set("2.3.8", "2.3.0", true)
+
+	c := NewEtcdClient(quietLogger(), Configuration{Endpoints: []string{f.URL}})
+	defer c.Close()
+
+	// the console serves before etcd answers
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Err() == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("etcd client has not probed the endpoints")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if _, err := c.Version(); !IsNotReady(err) {
+		t.Fatalf("Version() error = %v, want not ready", err)
+	}
+
+	f.set("2.3.8", "2.3.0", false)
+	waitReady(t, c, 5*time.Second)
+
+	version, err := c.Version()
+	if err != nil || version.String() != "2.3.8" {
+		t.Errorf("Version() = %v, %v, want 2.3.8", version, err)
+	}
+	if _, err := c.V2(); err != nil {
+		t.Errorf("V2() error = %v", err)
+	}
+	if _, err := c.V3(); err == nil || IsNotReady(err) {
+		t.Errorf("V3() of etcd 2 error = %v, want the version mismatch", err)
+	}
+	if c.Err() != nil {
+		t.Errorf("Err() = %v, want nil once connected", c.Err())
+	}
+}
+
+func TestEtcdClientDegradedMode(t *testing.T) {
+	f := startFakeEtcd("2.3.8", "2.3.0")
+	defer f.Close()
+	f.set("2.3.8", "2.3.0", true)
+
+	// the background setup gives up after the retry of a second
+	c := NewEtcdClient(quietLogger(), Configuration{Endpoints: []string{f.URL}, StartupTimeout: 1})
+	defer c.Close()
+	time.Sleep(connectBackoffMin + time.Second)
+	if c.Err() == nil {
+		t.Fatalf("Err() = nil, want the error of the probe")
+	}
+
+	f.set("2.3.8", "2.3.0", false)
+
+	// the calls within the lazy interval answer the last error without probing
+	if _, err := c.Version(); !IsNotReady(err) {
+		t.Fatalf("Version() in the lazy interval error = %v, want not ready", err)
+	}
+
+	// the calls afterwards finish the setup
+	c.mu.Lock()
+	c.lastTry = time.Now().Add(-lazyConnectInterval)
+	c.mu.Unlock()
+	if version, err := c.Version(); err != nil || version.String() != "2.3.8" {
+		t.Errorf("Version() after the lazy interval = %v, %v, want 2.3.8", version, err)
+	}
+}
+
+func TestEtcdClientClosed(t *testing.T) {
+	f := startFakeEtcd("2.3.8", "2.3.0")
+	defer f.Close()
+	f.set("2.3.8", "2.3.0", true)
+
+	c := NewEtcdClient(quietLogger(), Configuration{Endpoints: []string{f.URL}})
+	c.Close()
+	c.Close()
+
+	err := c.ensure()
+	if notReady, ok := err.(*NotReadyError); !ok || notReady.Cause != errClosed {
+		t.Errorf("ensure() of the closed client error = %v, want %v", err, errClosed)
+	}
+}
+
+func TestProbeVersion(t *testing.T) {
+	f := startFakeEtcd("3.2.13", "not_decided")
+	defer f.Close()
+
+	// the unreachable endpoints are skipped
+	version, clusterVersion, endpoint, err := probeVersion([]string{"http://127.0.0.1:1", f.URL})
+	if err != nil {
+		t.Fatalf("probeVersion() error = %v", err)
+	}
+	if version.String() != "3.2.13" || clusterVersion != nil || endpoint != f.URL {
+		t.Errorf("probeVersion() = %v, %v, %s, want 3.2.13 of %s without the cluster version", version, clusterVersion, endpoint, f.URL)
+	}
+
+	f.set("bad", "3.2.0", false)
+	if _, _, _, err := probeVersion([]string{f.URL}); err == nil {
+		t.Errorf("probeVersion() of a bad version error = nil, want an error")
+	}
+
+	f.set("3.2.13", "3.2.0", true)
+	if _, _, _, err := probeVersion([]string{f.URL}); err == nil {
+		t.Errorf("probeVersion() of the unavailable endpoint error = nil, want an error")
+	}
+}
+
+func TestEtcdClientRedetect(t *testing.T) {
+	f := startFakeEtcd("2.3.8", "not_decided")
+	defer f.Close()
+
+	c := NewEtcdClient(quietLogger(), Configuration{Endpoints: []string{f.URL}})
+	defer c.Close()
+	waitReady(t, c, 5*time.Second)
+
+	// the cluster version falls back to the server one while it's not decided
+	if clusterVersion, err := c.ClusterVersion(); err != nil || clusterVersion.String() != "2.3.8" {
+		t.Errorf("ClusterVersion() = %v, %v, want 2.3.8", clusterVersion, err)
+	}
+
+	// a minor upgrade keeps the client
+	v2Client, _ := c.V2()
+	f.set("2.3.9", "2.3.0", false)
+	c.redetect()
+	if version, _ := c.Version(); version.String() != "2.3.9" {
+		t.Errorf("Version() after the upgrade = %v, want 2.3.9", version)
+	}
+	if clusterVersion, _ := c.ClusterVersion(); clusterVersion.String() != "2.3.0" {
+		t.Errorf("ClusterVersion() after the upgrade = %v, want 2.3.0", clusterVersion)
+	}
+	if switched, _ := c.V2(); switched != v2Client {
+		t.Errorf("V2() after a minor upgrade is switched")
+	}
+
+	// an unavailable etcd keeps the last version
+	f.set("2.3.9", "2.3.0", true)
+	c.redetect()
+	if version, _ := c.Version(); version.String() != "2.3.9" {
+		t.Errorf("Version() of the unavailable etcd = %v, want the last 2.3.9", version)
+	}
+}
+
+func TestEtcdClientRedetectMajor(t *testing.T) {
+	f := startFakeEtcd("2.3.8", "2.3.0")
+	defer f.Close()
+
+	// the client of etcd 3, which is rolled back to 2 in place
+	c := &EtcdClient{
+		endpoints: []string{f.URL},
+		version:   mustVersion(t, "3.2.13"),
+		client:    struct{}{},
+		stopCh:    make(chan struct{}),
+	}
+	logger = quietLogger()
+
+	c.redetect()
+	if version, _ := c.Version(); version.String() != "2.3.8" {
+		t.Errorf("Version() after the rollback = %v, want 2.3.8", version)
+	}
+	if _, err := c.V2(); err != nil {
+		t.Errorf("V2() after the rollback error = %v, want the switched client", err)
+	}
+	if features, _ := c.Features(); features.V3API {
+		t.Errorf("Features() after the rollback = %+v, want no v3 API", features)
+	}
+}
+
+func TestEtcdFeatures(t *testing.T) {
+	tests := []struct {
+		version string
+		want    EtcdFeatures
+	}{
+		{version: "2.3.8", want: EtcdFeatures{}},
+		{version: "3.2.13", want: EtcdFeatures{V3API: true}},
+		{version: "3.3.0", want: EtcdFeatures{V3API: true, MoveLeader: true}},
+		{version: "3.4.1", want: EtcdFeatures{V3API: true, MoveLeader: true, Learner: true}},
+		{version: "3.5.0", want: EtcdFeatures{V3API: true, MoveLeader: true, Learner: true, Downgrade: true}},
+		{version: "4.0.0", want: EtcdFeatures{V3API: true, MoveLeader: true, Learner: true, Downgrade: true}},
+	}
+	for _, tt := range tests {
+		if got := NewEtcdFeatures(mustVersion(t, tt.version)); got != tt.want {
+			t.Errorf("NewEtcdFeatures(%s) = %+v, want %+v", tt.version, got, tt.want)
+		}
+	}
+	if got := NewEtcdFeatures(nil); got != (EtcdFeatures{}) {
+		t.Errorf("NewEtcdFeatures(nil) = %+v, want none", got)
+	}
+}
+
+func mustVersion(t *testing.T, version string) *sv2.Version {
+	t.Helper()
+
+	v, err := sv2.NewVersion(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
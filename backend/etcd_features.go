@@ -0,0 +1,38 @@
+package backend
+
+import (
+	sv2 "github.com/Masterminds/semver"
+)
+
+// EtcdFeatures are the capabilities of etcd derived from its version.
+type EtcdFeatures struct {
+	// Since 3.0
+	V3API bool `json:"v3api"`
+	// Since 3.3
+	MoveLeader bool `json:"moveLeader"`
+	// Since 3.4
+	Learner bool `json:"learner"`
+	// Since 3.5
+	Downgrade bool `json:"downgrade"`
+}
+
+func NewEtcdFeatures(version *sv2.Version) EtcdFeatures {
+	if version == nil {
+		return EtcdFeatures{}
+	}
+
+	return EtcdFeatures{
+		V3API:      versionAtLeast(version, 3, 0),
+		MoveLeader: versionAtLeast(version, 3, 3),
+		Learner:    versionAtLeast(version, 3, 4),
+		Downgrade:  versionAtLeast(version, 3, 5),
+	}
+}
+
+func versionAtLeast(version *sv2.Version, major, minor int64) bool {
+	if version.Major() != major {
+		return version.Major() > major
+	}
+
+	return version.Minor() >= minor
+}
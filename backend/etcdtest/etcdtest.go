@@ -0,0 +1,158 @@
+// Package etcdtest starts an in-process etcd for the tests, the embedding etcd of "--test",
+// listening on free local ports in a temporary dir, so the tests of the packages don't share anything.
+package etcdtest
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v3 "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/pkg/capnslog"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/embedded"
+)
+
+// StartTimeout bounds the start of the etcd and the connection of the console client.
+const StartTimeout = 30 * time.Second
+
+// Etcd is a started etcd, Close it at the end of the test.
+type Etcd struct {
+	// The client URLs of the etcd
+	Endpoints []string
+	// A raw client for seeding and checking the keys
+	Client *v3.Client
+
+	dir     string
+	cluster *embedded.Cluster
+}
+
+// Start starts an etcd of one member or fails the test.
+func Start(t testing.TB) *Etcd {
+	t.Helper()
+
+	return StartMembers(t, 1)
+}
+
+// StartMembers starts an etcd of the members or fails the test.
+func StartMembers(t testing.TB, size int) *Etcd {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "etcd-console-test")
+	if err != nil {
+		t.Fatalf("cannot create the dir of the etcd, %v", err)
+	}
+
+	// the logs of the etcd bury the ones of the tests
+	capnslog.SetGlobalLogLevel(capnslog.CRITICAL)
+	embedded.LogPkgLevels = ""
+	embedded.StartTimeout = StartTimeout
+
+	cluster, err := embedded.StartOnFreePorts(size, filepath.Join(dir, "etcd"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("cannot start the etcd, %v", err)
+	}
+
+	endpoints := cluster.Endpoints()
+	client, err := v3.New(v3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: StartTimeout,
+	})
+	if err != nil {
+		cluster.Close()
+		os.RemoveAll(dir)
+		t.Fatalf("cannot connect to the etcd, %v", err)
+	}
+
+	return &Etcd{
+		Endpoints: endpoints,
+		Client:    client,
+		dir:       dir,
+		cluster:   cluster,
+	}
+}
+
+// Cluster returns the members, e.g. for stopping and starting them.
+func (e *Etcd) Cluster() *embedded.Cluster {
+	return e.cluster
+}
+
+// Close stops the etcd and removes its dir.
+func (e *Etcd) Close() {
+	e.Client.Close()
+	e.cluster.Close()
+	os.RemoveAll(e.dir)
+}
+
+// Configuration is the default one reaching the etcd, with a backup dir of its own.
+func (e *Etcd) Configuration(t testing.TB) backend.Configuration {
+	t.Helper()
+
+	backupDir := filepath.Join(e.dir, "backup")
+	if err := os.MkdirAll(backupDir, os.ModePerm); err != nil {
+		t.Fatalf("cannot create the backup dir, %v", err)
+	}
+
+	configuration := backend.DefaultConfiguration()
+	configuration.Endpoints = e.Endpoints
+	configuration.Test = false
+	configuration.BackupDir = backupDir
+	return configuration
+}
+
+// NewEtcdClient returns a console client of the configuration once it's ready, or fails the test.
+func (e *Etcd) NewEtcdClient(t testing.TB, configuration backend.Configuration) *backend.EtcdClient {
+	t.Helper()
+
+	etcdClient := backend.NewEtcdClient(nil, configuration)
+	for deadline := time.Now().Add(StartTimeout); !etcdClient.Ready(); {
+		if time.Now().After(deadline) {
+			etcdClient.Close()
+			t.Fatalf("the console client is not ready after %v, %v", StartTimeout, etcdClient.Err())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return etcdClient
+}
+
+// Put seeds the keys and the values in pairs, returns the revision of the last one.
+func (e *Etcd) Put(t testing.TB, kvs ...string) int64 {
+	t.Helper()
+
+	if len(kvs)%2 != 0 {
+		t.Fatalf("the keys and the values are not in pairs, %q", kvs)
+	}
+
+	var rev int64
+	for i := 0; i < len(kvs); i += 2 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		putResp, err := e.Client.Put(ctx, kvs[i], kvs[i+1])
+		cancel()
+		if err != nil {
+			t.Fatalf("cannot put %q, %v", kvs[i], err)
+		}
+		rev = putResp.Header.Revision
+	}
+	return rev
+}
+
+// Get returns the value of the key, and whether it exists.
+func (e *Etcd) Get(t testing.TB, key string) (string, bool) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	getResp, err := e.Client.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("cannot get %q, %v", key, err)
+	}
+	if len(getResp.Kvs) == 0 {
+		return "", false
+	}
+	return string(getResp.Kvs[0].Value), true
+}
@@ -0,0 +1,38 @@
+// Package prefix maps the keys to the values of their longest matched prefixes.
+package prefix
+
+import (
+	"sort"
+)
+
+// Table picks the value of a key by the longest matched prefix, the zero value is an empty table.
+type Table struct {
+	entries []entry
+}
+
+type entry struct {
+	prefix string
+	value  interface{}
+}
+
+func (t *Table) Add(prefix string, value interface{}) {
+	t.entries = append(t.entries, entry{prefix, value})
+
+	sort.SliceStable(t.entries, func(i, j int) bool {
+		return len(t.entries[i].prefix) > len(t.entries[j].prefix)
+	})
+}
+
+func (t *Table) Lookup(key []byte) (interface{}, bool) {
+	if t == nil {
+		return nil, false
+	}
+
+	for _, entry := range t.entries {
+		if len(key) >= len(entry.prefix) && string(key[:len(entry.prefix)]) == entry.prefix {
+			return entry.value, true
+		}
+	}
+
+	return nil, false
+}
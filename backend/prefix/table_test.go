@@ -0,0 +1,39 @@
+package prefix
+
+import (
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	var table Table
+	table.Add("/a/", "a")
+	table.Add("/a/b/", "b")
+	table.Add("", "root")
+	// the first added of the same prefix is kept
+	table.Add("/a/", "a2")
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"/a/1", "a"},
+		{"/a/b/1", "b"},
+		{"/a/b", "a"},
+		{"/c", "root"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got, ok := table.Lookup([]byte(tt.key)); !ok || got != tt.want {
+				t.Errorf("Lookup() = %v, %v, want %v", got, ok, tt.want)
+			}
+		})
+	}
+
+	var nilTable *Table
+	if _, ok := nilTable.Lookup([]byte("/a/1")); ok {
+		t.Errorf("Lookup() of a nil table is found")
+	}
+	if _, ok := new(Table).Lookup([]byte("/a/1")); ok {
+		t.Errorf("Lookup() of an empty table is found")
+	}
+}
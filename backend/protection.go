@@ -0,0 +1,73 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	v3 "github.com/coreos/etcd/clientv3"
+)
+
+// ForbiddenError is returned when the console refuses to change anything, e.g. it's read-only,
+// or the keys are under a protected prefix.
+type ForbiddenError struct {
+	Reason string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Reason
+}
+
+func IsForbidden(err error) bool {
+	_, ok := err.(*ForbiddenError)
+	return ok
+}
+
+// CheckWritable refuses all changes in read-only mode.
+func (c Configuration) CheckWritable() error {
+	if c.ReadOnly {
+		return &ForbiddenError{"etcd-console is read-only"}
+	}
+	return nil
+}
+
+// CheckKey refuses the changes of a key under the protected prefixes.
+func (c Configuration) CheckKey(key string) error {
+	return c.CheckRange(key, "")
+}
+
+// CheckRange refuses the changes of a range having any key under the protected prefixes,
+// an empty end means the single key, and "\x00" means all keys from the start.
+func (c Configuration) CheckRange(start, end string) error {
+	if err := c.CheckWritable(); err != nil {
+		return err
+	}
+	return c.CheckProtected(start, end)
+}
+
+// CheckProtected is CheckRange regardless of read-only mode, e.g. for planning.
+func (c Configuration) CheckProtected(start, end string) error {
+	for _, prefix := range c.ProtectedPrefixes {
+		if overlaps(start, end, prefix) {
+			if len(end) == 0 {
+				return &ForbiddenError{fmt.Sprintf("key %q is under the protected prefix %q", start, prefix)}
+			}
+			return &ForbiddenError{fmt.Sprintf("range [%q, %q) has keys under the protected prefix %q", start, end, prefix)}
+		}
+	}
+	return nil
+}
+
+func overlaps(start, end, prefix string) bool {
+	if len(end) == 0 {
+		return strings.HasPrefix(start, prefix)
+	}
+
+	// the range ends before the prefix, or starts after all keys of the prefix
+	if end != "\x00" && end <= prefix {
+		return false
+	}
+	if prefixEnd := v3.GetPrefixRangeEnd(prefix); prefixEnd != "\x00" && start >= prefixEnd {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,255 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// the forms of the keyword values
+const (
+	kindString = iota
+	kindBool
+	kindAny
+	kindArray
+	kindNumber
+	kindNumberOrBool
+	kindCount
+	kindStrings
+	kindType
+	kindSchemaURI
+	kindRef
+	kindSchema
+	kindSchemas
+	kindSchemaOrSchemas
+	kindSchemaMap
+	kindDependencies
+)
+
+// keywords are the supported keywords of draft-07, along with the forms of draft-04 and draft-06 which differ,
+// i.e. "id" and the boolean "exclusiveMinimum" and "exclusiveMaximum". The others fail the compiling,
+// e.g. "format", "contentMediaType" and "contentEncoding", rather than be silently ignored.
+var keywords = map[string]int{
+	// annotations, which are not validated
+	"$id":         kindString,
+	"id":          kindString,
+	"$comment":    kindString,
+	"title":       kindString,
+	"description": kindString,
+	"default":     kindAny,
+	"examples":    kindArray,
+	"readOnly":    kindBool,
+	"writeOnly":   kindBool,
+	"definitions": kindSchemaMap,
+
+	"$schema": kindSchemaURI,
+	"$ref":    kindRef,
+
+	"type":  kindType,
+	"enum":  kindArray,
+	"const": kindAny,
+
+	"allOf": kindSchemas,
+	"anyOf": kindSchemas,
+	"oneOf": kindSchemas,
+	"not":   kindSchema,
+	"if":    kindSchema,
+	"then":  kindSchema,
+	"else":  kindSchema,
+
+	"multipleOf":       kindNumber,
+	"minimum":          kindNumber,
+	"maximum":          kindNumber,
+	"exclusiveMinimum": kindNumberOrBool,
+	"exclusiveMaximum": kindNumberOrBool,
+
+	"minLength": kindCount,
+	"maxLength": kindCount,
+	"pattern":   kindString,
+
+	"items":           kindSchemaOrSchemas,
+	"additionalItems": kindSchema,
+	"minItems":        kindCount,
+	"maxItems":        kindCount,
+	"uniqueItems":     kindBool,
+	"contains":        kindSchema,
+
+	"properties":           kindSchemaMap,
+	"patternProperties":    kindSchemaMap,
+	"additionalProperties": kindSchema,
+	"required":             kindStrings,
+	"minProperties":        kindCount,
+	"maxProperties":        kindCount,
+	"propertyNames":        kindSchema,
+	"dependencies":         kindDependencies,
+}
+
+// schemaURIs are the "$schema"s of the supported drafts.
+var schemaURIs = []string{
+	"http://json-schema.org/draft-04/schema",
+	"http://json-schema.org/draft-06/schema",
+	"http://json-schema.org/draft-07/schema",
+}
+
+var typeNames = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"object":  true,
+	"array":   true,
+	"number":  true,
+	"integer": true,
+	"string":  true,
+}
+
+// checkKeywords rejects the unsupported keywords and the keyword values of bad forms in the schema and its sub schemas,
+// and collects the "$ref"s.
+func checkKeywords(node interface{}, pointer string, refs *[]string) error {
+	if _, ok := node.(bool); ok {
+		return nil
+	}
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		return errors.New(fmt.Sprintf("#%s is not a schema", pointer))
+	}
+
+	// in order, the errors are stable
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		kind, ok := keywords[name]
+		if !ok {
+			return errors.New(fmt.Sprintf("unsupported keyword %s at #%s", name, pointer))
+		}
+
+		if err := checkKeyword(kind, schema[name], pointer+"/"+escapeToken(name), refs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkKeyword(kind int, value interface{}, pointer string, refs *[]string) error {
+	bad := func(form string) error {
+		return errors.New(fmt.Sprintf("#%s is not %s", pointer, form))
+	}
+
+	switch kind {
+	case kindString:
+		if _, ok := value.(string); !ok {
+			return bad("a string")
+		}
+	case kindBool:
+		if _, ok := value.(bool); !ok {
+			return bad("a boolean")
+		}
+	case kindArray:
+		if _, ok := value.([]interface{}); !ok {
+			return bad("an array")
+		}
+	case kindNumber:
+		if _, ok := numberOf(value); !ok {
+			return bad("a number")
+		}
+	case kindNumberOrBool:
+		if _, ok := value.(bool); ok {
+			return nil
+		}
+		if _, ok := numberOf(value); !ok {
+			return bad("a number or a boolean")
+		}
+	case kindCount:
+		number, ok := value.(json.Number)
+		if !ok {
+			return bad("a non-negative integer")
+		}
+		if count, err := number.Int64(); err != nil || count < 0 {
+			return bad("a non-negative integer")
+		}
+	case kindStrings:
+		items, ok := value.([]interface{})
+		if !ok {
+			return bad("an array of strings")
+		}
+		for _, item := range items {
+			if _, ok := item.(string); !ok {
+				return bad("an array of strings")
+			}
+		}
+	case kindType:
+		names, ok := value.([]interface{})
+		if !ok {
+			names = []interface{}{value}
+		}
+		for _, name := range names {
+			if typed, ok := name.(string); !ok || !typeNames[typed] {
+				return bad("a type or an array of types")
+			}
+		}
+	case kindSchemaURI:
+		uri, _ := value.(string)
+		uri = strings.TrimSuffix(strings.Replace(uri, "https://", "http://", 1), "#")
+		for _, supported := range schemaURIs {
+			if uri == supported {
+				return nil
+			}
+		}
+		return bad("draft-04, draft-06 or draft-07")
+	case kindRef:
+		ref, ok := value.(string)
+		if !ok || !strings.HasPrefix(ref, "#") {
+			return bad("a json pointer of the same document")
+		}
+		*refs = append(*refs, ref)
+	case kindSchema:
+		return checkKeywords(value, pointer, refs)
+	case kindSchemas:
+		subs, ok := value.([]interface{})
+		if !ok || len(subs) == 0 {
+			return bad("a non-empty array of schemas")
+		}
+		for idx, sub := range subs {
+			if err := checkKeywords(sub, fmt.Sprintf("%s/%d", pointer, idx), refs); err != nil {
+				return err
+			}
+		}
+	case kindSchemaOrSchemas:
+		if subs, ok := value.([]interface{}); ok {
+			for idx, sub := range subs {
+				if err := checkKeywords(sub, fmt.Sprintf("%s/%d", pointer, idx), refs); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return checkKeywords(value, pointer, refs)
+	case kindSchemaMap, kindDependencies:
+		subs, ok := value.(map[string]interface{})
+		if !ok {
+			return bad("an object of schemas")
+		}
+		for name, sub := range subs {
+			subPointer := pointer + "/" + escapeToken(name)
+			// the dependencies are schemas or the names of the required properties
+			if kind == kindDependencies {
+				if _, ok := sub.([]interface{}); ok {
+					if err := checkKeyword(kindStrings, sub, subPointer, refs); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			if err := checkKeywords(sub, subPointer, refs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
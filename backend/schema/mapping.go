@@ -0,0 +1,48 @@
+package schema
+
+import (
+	"github.com/thxcode/etcd-console/backend/prefix"
+)
+
+// Mapping picks the schema of a key by the longest matched prefix.
+type Mapping struct {
+	table prefix.Table
+}
+
+func NewMapping() *Mapping {
+	return &Mapping{}
+}
+
+func (m *Mapping) Add(prefix string, schema *Schema) {
+	m.table.Add(prefix, schema)
+}
+
+func (m *Mapping) Lookup(key []byte) (*Schema, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	schema, ok := m.table.Lookup(key)
+	if !ok {
+		return nil, false
+	}
+	return schema.(*Schema), true
+}
+
+// Validate checks the value against the schema of the key, nil if the key has no schema or the value is valid.
+func (m *Mapping) Validate(key, value []byte) error {
+	schema, ok := m.Lookup(key)
+	if !ok {
+		return nil
+	}
+
+	if violations := schema.Validate(value); len(violations) != 0 {
+		return &ValidationError{
+			Key:        string(key),
+			Schema:     schema.Name(),
+			Violations: violations,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,741 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/thxcode/etcd-console/backend/codec"
+	"gopkg.in/yaml.v2"
+)
+
+// Schema validates the json documents by JSON Schema draft-07, the schemas of draft-04 and draft-06 are accepted too.
+// The supported keywords are listed in keywords, a schema having any other, e.g. "format", fails the compiling.
+// "$ref" only refers to the same document, e.g. "#/definitions/foo", and its siblings are ignored.
+type Schema struct {
+	name     string
+	root     interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+// Violation is one failed keyword, the path is a json pointer of the value.
+type Violation struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when a value doesn't match the schema of its key.
+type ValidationError struct {
+	Key        string
+	Schema     string
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", violation.Path, violation.Message))
+	}
+
+	return fmt.Sprintf("value of %s violates schema %s, %s", e.Key, e.Schema, strings.Join(msgs, "; "))
+}
+
+func IsValidationError(err error) bool {
+	_, ok := err.(*ValidationError)
+	return ok
+}
+
+// Load compiles the schema file, which is json or yaml.
+func Load(filename string) (*Schema, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return Compile(filename, data)
+}
+
+func Compile(name string, data []byte) (*Schema, error) {
+	root, err := parseDocument(data)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("bad schema %s, %v", name, err))
+	}
+
+	var refs []string
+	if err := checkKeywords(root, "", &refs); err != nil {
+		return nil, errors.New(fmt.Sprintf("bad schema %s, %v", name, err))
+	}
+
+	s := &Schema{
+		name:     name,
+		root:     root,
+		patterns: make(map[string]*regexp.Regexp),
+	}
+	if err := s.compilePatterns(root); err != nil {
+		return nil, errors.New(fmt.Sprintf("bad schema %s, %v", name, err))
+	}
+	if err := s.checkRefs(refs); err != nil {
+		return nil, errors.New(fmt.Sprintf("bad schema %s, %v", name, err))
+	}
+
+	return s, nil
+}
+
+func (s *Schema) Name() string {
+	return s.name
+}
+
+// Validate checks the value, which is json or yaml, and returns the violations.
+func (s *Schema) Validate(value []byte) []Violation {
+	document, err := parseDocument(value)
+	if err != nil {
+		return []Violation{{
+			Path:    "/",
+			Keyword: "document",
+			Message: fmt.Sprintf("not a valid json or yaml document, %v", err),
+		}}
+	}
+
+	var violations []Violation
+	s.validate(s.root, document, "", &violations)
+	return violations
+}
+
+// parseDocument decodes json, or yaml as a fallback, the numbers are kept as json.Number.
+func parseDocument(data []byte) (interface{}, error) {
+	document, jsonErr := decodeJSON(data)
+	if jsonErr == nil {
+		return document, nil
+	}
+
+	var object interface{}
+	if err := yaml.Unmarshal(data, &object); err != nil {
+		return nil, jsonErr
+	}
+
+	// through json again, so the numbers of yaml are json.Number as well
+	data, err := json.Marshal(codec.NormalizeYAML(object))
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(data)
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+func (s *Schema) compilePatterns(node interface{}) error {
+	switch typed := node.(type) {
+	case map[string]interface{}:
+		if pattern, ok := typed["pattern"].(string); ok {
+			if err := s.compilePattern(pattern); err != nil {
+				return err
+			}
+		}
+		if patternProperties, ok := typed["patternProperties"].(map[string]interface{}); ok {
+			for pattern := range patternProperties {
+				if err := s.compilePattern(pattern); err != nil {
+					return err
+				}
+			}
+		}
+		for _, child := range typed {
+			if err := s.compilePatterns(child); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, child := range typed {
+			if err := s.compilePatterns(child); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) compilePattern(pattern string) error {
+	if _, ok := s.patterns[pattern]; ok {
+		return nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return errors.New(fmt.Sprintf("bad pattern %q, %v", pattern, err))
+	}
+	s.patterns[pattern] = re
+	return nil
+}
+
+// resolve follows a "$ref" inside the document.
+func (s *Schema) resolve(ref string) (interface{}, bool) {
+	tokens, ok := refTokens(ref)
+	if !ok {
+		return nil, false
+	}
+
+	node := s.root
+	for _, token := range tokens {
+		switch typed := node.(type) {
+		case map[string]interface{}:
+			child, ok := typed[token]
+			if !ok {
+				return nil, false
+			}
+			node = child
+		case []interface{}:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(typed) {
+				return nil, false
+			}
+			node = typed[idx]
+		default:
+			return nil, false
+		}
+	}
+
+	return node, true
+}
+
+// refTokens splits a "$ref" of the same document into the unescaped tokens of its json pointer.
+func refTokens(ref string) ([]string, bool) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, false
+	}
+
+	var tokens []string
+	for _, token := range strings.Split(strings.TrimPrefix(ref[1:], "/"), "/") {
+		if len(token) == 0 {
+			continue
+		}
+		tokens = append(tokens, strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1))
+	}
+	return tokens, true
+}
+
+// refPointer is the json pointer of a "$ref", the same target is the same pointer however it's written.
+func refPointer(ref string) string {
+	tokens, _ := refTokens(ref)
+
+	var pointer string
+	for _, token := range tokens {
+		pointer += "/" + escapeToken(token)
+	}
+	return pointer
+}
+
+func escapeToken(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+// checkRefs rejects the "$ref"s which cannot be resolved, and the ones leading back to themselves without going into the value,
+// e.g. {"$ref": "#"}, or the definitions referring to each other, validating them would never end.
+// The recursive schemas going through "properties" or "items" are fine, the value ends somewhere.
+func (s *Schema) checkRefs(refs []string) error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	states := make(map[string]int)
+
+	var visit func(pointer string, node interface{}) error
+	visit = func(pointer string, node interface{}) error {
+		switch states[pointer] {
+		case visiting:
+			return errors.New(fmt.Sprintf("$ref cycle at #%s", pointer))
+		case visited:
+			return nil
+		}
+		states[pointer] = visiting
+
+		schema, _ := node.(map[string]interface{})
+		if ref, ok := schema["$ref"].(string); ok {
+			// the unresolved ones are rejected along with the collected refs
+			if refNode, ok := s.resolve(ref); ok {
+				if err := visit(refPointer(ref), refNode); err != nil {
+					return err
+				}
+			}
+		} else {
+			// the keywords applying to the same value
+			for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+				subs, _ := schema[keyword].([]interface{})
+				for idx, sub := range subs {
+					if err := visit(fmt.Sprintf("%s/%s/%d", pointer, keyword, idx), sub); err != nil {
+						return err
+					}
+				}
+			}
+			for _, keyword := range []string{"not", "if", "then", "else"} {
+				if sub, ok := schema[keyword]; ok {
+					if err := visit(pointer+"/"+keyword, sub); err != nil {
+						return err
+					}
+				}
+			}
+			dependencies, _ := schema["dependencies"].(map[string]interface{})
+			for name, dependency := range dependencies {
+				if _, ok := dependency.([]interface{}); ok {
+					continue
+				}
+				if err := visit(pointer+"/dependencies/"+escapeToken(name), dependency); err != nil {
+					return err
+				}
+			}
+		}
+
+		states[pointer] = visited
+		return nil
+	}
+
+	// a cycle passes a "$ref", so it's found from the root or the target of a "$ref"
+	if err := visit("", s.root); err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		refNode, ok := s.resolve(ref)
+		if !ok {
+			return errors.New(fmt.Sprintf("cannot resolve $ref %s", ref))
+		}
+		if err := visit(refPointer(ref), refNode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) validate(node interface{}, value interface{}, path string, violations *[]Violation) {
+	violate := func(keyword string, format string, args ...interface{}) {
+		violationPath := path
+		if len(violationPath) == 0 {
+			violationPath = "/"
+		}
+		*violations = append(*violations, Violation{
+			Path:    violationPath,
+			Keyword: keyword,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	// boolean schemas of draft-06
+	if b, ok := node.(bool); ok {
+		if !b {
+			violate("false", "no value is allowed")
+		}
+		return
+	}
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		refNode, ok := s.resolve(ref)
+		if !ok {
+			violate("$ref", "cannot resolve %s", ref)
+			return
+		}
+		// the siblings of "$ref" are ignored
+		s.validate(refNode, value, path, violations)
+		return
+	}
+
+	// generic
+	if types, ok := schema["type"]; ok {
+		var names []string
+		switch typed := types.(type) {
+		case string:
+			names = []string{typed}
+		case []interface{}:
+			for _, name := range typed {
+				names = append(names, fmt.Sprint(name))
+			}
+		}
+		matched := false
+		for _, name := range names {
+			if isType(value, name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			violate("type", "expecting %s, got %s", strings.Join(names, " or "), typeOf(value))
+			// the rest keywords would be noises
+			return
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		matched := false
+		for _, candidate := range enum {
+			if equal(candidate, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			violate("enum", "expecting one of %s", render(enum))
+		}
+	}
+	if constant, ok := schema["const"]; ok && !equal(constant, value) {
+		violate("const", "expecting %s", render(constant))
+	}
+
+	// combinations
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			s.validate(sub, value, path, violations)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if s.valid(sub, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			violate("anyOf", "matches none of the schemas")
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		matches := 0
+		for _, sub := range oneOf {
+			if s.valid(sub, value) {
+				matches++
+			}
+		}
+		if matches != 1 {
+			violate("oneOf", "matches %d of the schemas, expecting exactly one", matches)
+		}
+	}
+	if not, ok := schema["not"]; ok && s.valid(not, value) {
+		violate("not", "matches the schema which is not allowed")
+	}
+	if condition, ok := schema["if"]; ok {
+		if s.valid(condition, value) {
+			if then, ok := schema["then"]; ok {
+				s.validate(then, value, path, violations)
+			}
+		} else if otherwise, ok := schema["else"]; ok {
+			s.validate(otherwise, value, path, violations)
+		}
+	}
+
+	switch typed := value.(type) {
+	case json.Number:
+		s.validateNumber(schema, typed, violate)
+	case string:
+		s.validateString(schema, typed, violate)
+	case []interface{}:
+		s.validateArray(schema, typed, path, violate, violations)
+	case map[string]interface{}:
+		s.validateObject(schema, typed, path, violate, violations)
+	}
+}
+
+// valid is for the combinations, the violations of the sub schemas are dropped.
+func (s *Schema) valid(node interface{}, value interface{}) bool {
+	var violations []Violation
+	s.validate(node, value, "", &violations)
+	return len(violations) == 0
+}
+
+type violateFunc func(keyword string, format string, args ...interface{})
+
+func (s *Schema) validateNumber(schema map[string]interface{}, value json.Number, violate violateFunc) {
+	number, err := value.Float64()
+	if err != nil {
+		return
+	}
+
+	if minimum, ok := numberOf(schema["minimum"]); ok {
+		// draft-04 "exclusiveMinimum": true
+		if exclusive, _ := schema["exclusiveMinimum"].(bool); exclusive {
+			if number <= minimum {
+				violate("minimum", "%v is not greater than %v", value, minimum)
+			}
+		} else if number < minimum {
+			violate("minimum", "%v is less than %v", value, minimum)
+		}
+	}
+	if exclusiveMinimum, ok := numberOf(schema["exclusiveMinimum"]); ok && number <= exclusiveMinimum {
+		violate("exclusiveMinimum", "%v is not greater than %v", value, exclusiveMinimum)
+	}
+	if maximum, ok := numberOf(schema["maximum"]); ok {
+		if exclusive, _ := schema["exclusiveMaximum"].(bool); exclusive {
+			if number >= maximum {
+				violate("maximum", "%v is not less than %v", value, maximum)
+			}
+		} else if number > maximum {
+			violate("maximum", "%v is greater than %v", value, maximum)
+		}
+	}
+	if exclusiveMaximum, ok := numberOf(schema["exclusiveMaximum"]); ok && number >= exclusiveMaximum {
+		violate("exclusiveMaximum", "%v is not less than %v", value, exclusiveMaximum)
+	}
+	if multipleOf, ok := numberOf(schema["multipleOf"]); ok && multipleOf > 0 {
+		quotient := number / multipleOf
+		if math.Abs(quotient-math.Floor(quotient+0.5)) > 1e-9 {
+			violate("multipleOf", "%v is not a multiple of %v", value, multipleOf)
+		}
+	}
+}
+
+func (s *Schema) validateString(schema map[string]interface{}, value string, violate violateFunc) {
+	length := utf8.RuneCountInString(value)
+
+	if minLength, ok := numberOf(schema["minLength"]); ok && float64(length) < minLength {
+		violate("minLength", "length %d is less than %v", length, minLength)
+	}
+	if maxLength, ok := numberOf(schema["maxLength"]); ok && float64(length) > maxLength {
+		violate("maxLength", "length %d is greater than %v", length, maxLength)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if re, ok := s.patterns[pattern]; ok && !re.MatchString(value) {
+			violate("pattern", "%q does not match %q", value, pattern)
+		}
+	}
+}
+
+func (s *Schema) validateArray(schema map[string]interface{}, value []interface{}, path string, violate violateFunc, violations *[]Violation) {
+	if minItems, ok := numberOf(schema["minItems"]); ok && float64(len(value)) < minItems {
+		violate("minItems", "%d items are less than %v", len(value), minItems)
+	}
+	if maxItems, ok := numberOf(schema["maxItems"]); ok && float64(len(value)) > maxItems {
+		violate("maxItems", "%d items are more than %v", len(value), maxItems)
+	}
+	if uniqueItems, _ := schema["uniqueItems"].(bool); uniqueItems {
+	unique:
+		for i := range value {
+			for j := i + 1; j < len(value); j++ {
+				if equal(value[i], value[j]) {
+					violate("uniqueItems", "items %d and %d are equal", i, j)
+					break unique
+				}
+			}
+		}
+	}
+
+	switch items := schema["items"].(type) {
+	case []interface{}:
+		// tuple
+		for idx, item := range value {
+			itemPath := path + "/" + strconv.Itoa(idx)
+			if idx < len(items) {
+				s.validate(items[idx], item, itemPath, violations)
+			} else if additionalItems, ok := schema["additionalItems"]; ok {
+				s.validate(additionalItems, item, itemPath, violations)
+			}
+		}
+	case nil:
+	default:
+		for idx, item := range value {
+			s.validate(items, item, path+"/"+strconv.Itoa(idx), violations)
+		}
+	}
+
+	if contains, ok := schema["contains"]; ok {
+		matched := false
+		for _, item := range value {
+			if s.valid(contains, item) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			violate("contains", "none of the items matches")
+		}
+	}
+}
+
+func (s *Schema) validateObject(schema map[string]interface{}, value map[string]interface{}, path string, violate violateFunc, violations *[]Violation) {
+	if minProperties, ok := numberOf(schema["minProperties"]); ok && float64(len(value)) < minProperties {
+		violate("minProperties", "%d properties are less than %v", len(value), minProperties)
+	}
+	if maxProperties, ok := numberOf(schema["maxProperties"]); ok && float64(len(value)) > maxProperties {
+		violate("maxProperties", "%d properties are more than %v", len(value), maxProperties)
+	}
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, ok := value[fmt.Sprint(name)]; !ok {
+				violate("required", "missing property %s", name)
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	patternProperties, _ := schema["patternProperties"].(map[string]interface{})
+	additionalProperties, hasAdditional := schema["additionalProperties"]
+
+	// in order, the violations are stable
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertyPath := path + "/" + escapeToken(name)
+		matched := false
+
+		if property, ok := properties[name]; ok {
+			matched = true
+			s.validate(property, value[name], propertyPath, violations)
+		}
+		for pattern, property := range patternProperties {
+			if re, ok := s.patterns[pattern]; ok && re.MatchString(name) {
+				matched = true
+				s.validate(property, value[name], propertyPath, violations)
+			}
+		}
+
+		if !matched && hasAdditional {
+			if allowed, ok := additionalProperties.(bool); ok && !allowed {
+				violate("additionalProperties", "property %s is not allowed", name)
+			} else {
+				s.validate(additionalProperties, value[name], propertyPath, violations)
+			}
+		}
+	}
+
+	if propertyNames, ok := schema["propertyNames"]; ok {
+		for _, name := range names {
+			if !s.valid(propertyNames, name) {
+				violate("propertyNames", "property name %s is not allowed", name)
+			}
+		}
+	}
+	if dependencies, ok := schema["dependencies"].(map[string]interface{}); ok {
+		for name, dependency := range dependencies {
+			if _, ok := value[name]; !ok {
+				continue
+			}
+			if requiredNames, ok := dependency.([]interface{}); ok {
+				for _, requiredName := range requiredNames {
+					if _, ok := value[fmt.Sprint(requiredName)]; !ok {
+						violate("dependencies", "property %s is required by %s", requiredName, name)
+					}
+				}
+			} else {
+				s.validate(dependency, value, path, violations)
+			}
+		}
+	}
+}
+
+func isType(value interface{}, name string) bool {
+	switch name {
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		if _, err := number.Int64(); err == nil {
+			return true
+		}
+		f, err := number.Float64()
+		return err == nil && f == math.Trunc(f)
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	}
+
+	return typeOf(value) == name
+}
+
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	return reflect.TypeOf(value).String()
+}
+
+func numberOf(value interface{}) (float64, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, false
+	}
+
+	f, err := number.Float64()
+	return f, err == nil
+}
+
+// equal compares the json values, 1 and 1.0 are equal.
+func equal(a, b interface{}) bool {
+	aNumber, aOk := a.(json.Number)
+	bNumber, bOk := b.(json.Number)
+	if aOk && bOk {
+		aFloat, aErr := aNumber.Float64()
+		bFloat, bErr := bNumber.Float64()
+		return aErr == nil && bErr == nil && aFloat == bFloat
+	}
+
+	switch aTyped := a.(type) {
+	case []interface{}:
+		bTyped, ok := b.([]interface{})
+		if !ok || len(aTyped) != len(bTyped) {
+			return false
+		}
+		for idx := range aTyped {
+			if !equal(aTyped[idx], bTyped[idx]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		bTyped, ok := b.(map[string]interface{})
+		if !ok || len(aTyped) != len(bTyped) {
+			return false
+		}
+		for key, aValue := range aTyped {
+			bValue, ok := bTyped[key]
+			if !ok || !equal(aValue, bValue) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return a == b
+}
+
+func render(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,148 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		// the keywords of the violations in order, empty means valid
+		violated []string
+	}{
+		{"type", `{"type": "string"}`, `"a"`, nil},
+		{"type mismatch", `{"type": "string"}`, `1`, []string{"type"}},
+		{"type list", `{"type": ["string", "null"]}`, `null`, nil},
+		{"integer", `{"type": "integer"}`, `1.0`, nil},
+		{"not an integer", `{"type": "integer"}`, `1.5`, []string{"type"}},
+
+		{"enum", `{"enum": ["a", 1]}`, `1.0`, nil},
+		{"not in enum", `{"enum": ["a", 1]}`, `"b"`, []string{"enum"}},
+
+		{"pattern", `{"pattern": "^[a-z]+$"}`, `"abc"`, nil},
+		{"pattern mismatch", `{"pattern": "^[a-z]+$"}`, `"ABC"`, []string{"pattern"}},
+
+		{
+			"properties",
+			`{"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}, "port": {"type": "integer", "minimum": 1}}}`,
+			`{"name": "a", "port": 80}`,
+			nil,
+		},
+		{
+			"properties violated",
+			`{"type": "object", "required": ["name"], "properties": {"port": {"type": "integer", "minimum": 1}}}`,
+			`{"port": 0}`,
+			[]string{"required", "minimum"},
+		},
+		{
+			"additional properties",
+			`{"properties": {"a": true}, "additionalProperties": false}`,
+			`{"a": 1, "b": 2}`,
+			[]string{"additionalProperties"},
+		},
+
+		{"items", `{"type": "array", "items": {"type": "string"}}`, `["a", "b"]`, nil},
+		{"items violated", `{"type": "array", "items": {"type": "string"}, "maxItems": 1}`, `["a", 1]`, []string{"maxItems", "type"}},
+		{"tuple items", `{"items": [{"type": "string"}], "additionalItems": false}`, `["a", "b"]`, []string{"false"}},
+
+		{
+			"$ref",
+			`{"definitions": {"port": {"type": "integer"}}, "properties": {"port": {"$ref": "#/definitions/port"}}}`,
+			`{"port": "80"}`,
+			[]string{"type"},
+		},
+		{
+			"recursive $ref",
+			`{"type": "object", "properties": {"name": {"type": "string"}, "children": {"type": "array", "items": {"$ref": "#"}}}}`,
+			`{"name": "a", "children": [{"name": "b", "children": [{"name": 1}]}]}`,
+			[]string{"type"},
+		},
+
+		{"if then", `{"if": {"type": "string"}, "then": {"minLength": 2}, "else": {"minimum": 1}}`, `"a"`, []string{"minLength"}},
+		{"if else", `{"if": {"type": "string"}, "then": {"minLength": 2}, "else": {"minimum": 1}}`, `0`, []string{"minimum"}},
+		{"draft-04 exclusive minimum", `{"$schema": "http://json-schema.org/draft-04/schema#", "minimum": 1, "exclusiveMinimum": true}`, `1`, []string{"minimum"}},
+		{"annotations", `{"title": "port", "description": "the port", "default": 80, "examples": [80, 443]}`, `"80"`, nil},
+
+		{"yaml value", `{"type": "object", "properties": {"port": {"type": "integer"}}}`, "port: 80\n", nil},
+		{"bad document", `{"type": "object"}`, `{"unclosed": [`, []string{"document"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Compile(tt.name, []byte(tt.schema))
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			violations := s.Validate([]byte(tt.value))
+			var violated []string
+			for _, violation := range violations {
+				violated = append(violated, violation.Keyword)
+			}
+			if strings.Join(violated, ",") != strings.Join(tt.violated, ",") {
+				t.Errorf("Validate(%s) = %+v, want %q", tt.value, violations, tt.violated)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		err    string
+	}{
+		{"bad document", `{"type": `, "bad schema"},
+		{"bad pattern", `{"pattern": "["}`, "bad pattern"},
+		{"unsupported keyword", `{"type": "string", "format": "email"}`, "unsupported keyword format at #"},
+		{"unsupported nested keyword", `{"properties": {"a": {"contentEncoding": "base64"}}}`, "unsupported keyword contentEncoding at #/properties/a"},
+		{"typo", `{"properties": {"a": {"maxlength": 1}}}`, "unsupported keyword maxlength"},
+		{"bad keyword value", `{"minimum": "1"}`, "#/minimum is not a number"},
+		{"negative count", `{"maxItems": -1}`, "#/maxItems is not a non-negative integer"},
+		{"bad type", `{"type": "float"}`, "#/type is not a type"},
+		{"empty allOf", `{"allOf": []}`, "#/allOf is not a non-empty array"},
+		{"unsupported draft", `{"$schema": "https://json-schema.org/draft/2019-09/schema"}`, "#/$schema is not draft-04"},
+		{"remote $ref", `{"$ref": "http://example.com/schema.json"}`, "#/$ref is not a json pointer of the same document"},
+		{"unresolved $ref", `{"$ref": "#/definitions/missing"}`, "cannot resolve $ref #/definitions/missing"},
+		{"not a schema", `{"properties": {"a": 1}}`, "#/properties/a is not a schema"},
+		{"$ref to itself", `{"$ref": "#"}`, "$ref cycle"},
+		{
+			"definitions referring to each other",
+			`{"definitions": {"a": {"$ref": "#/definitions/b"}, "b": {"$ref": "#/definitions/a"}}, "properties": {"x": {"$ref": "#/definitions/a"}}}`,
+			"$ref cycle",
+		},
+		{
+			"cycle through allOf",
+			`{"definitions": {"a": {"allOf": [{"type": "object"}, {"$ref": "#/definitions/a"}]}}}`,
+			"$ref cycle",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile(tt.name, []byte(tt.schema)); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Compile() error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestMappingValidate(t *testing.T) {
+	s, err := Compile("port", []byte(`{"type": "integer"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMapping()
+	m.Add("/config/", s)
+
+	if err := m.Validate([]byte("/config/port"), []byte("80")); err != nil {
+		t.Errorf("Validate() of a valid value error = %v", err)
+	}
+	if err := m.Validate([]byte("/config/port"), []byte(`"80"`)); !IsValidationError(err) {
+		t.Errorf("Validate() of an invalid value error = %v, want a validation error", err)
+	}
+	if err := m.Validate([]byte("/other"), []byte(`"80"`)); err != nil {
+		t.Errorf("Validate() of a key without schema error = %v", err)
+	}
+}
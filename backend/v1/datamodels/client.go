@@ -1,5 +1,9 @@
 package datamodels
 
+import (
+	"github.com/thxcode/etcd-console/backend"
+)
+
 // KeyValue Pair
 type KeyValue struct {
 	Key            string `json:"key"`
@@ -8,4 +12,153 @@ type KeyValue struct {
 	ModRevision    int64  `json:"modRevision"`
 	Version        int64  `json:"version"`
 	Lease          string `json:"lease"`
+
+	// How the key and the value are encoded, empty means utf8
+	Encoding string `json:"encoding,omitempty"`
+	// The key or the value isn't valid utf8
+	Binary bool `json:"binary,omitempty"`
+
+	// The value rendered by a decoder, only on demand
+	Decoded *DecodedValue `json:"decoded,omitempty"`
+}
+
+// Decoded Value, the object is rendered in json or yaml, the note tells what's not decoded
+type DecodedValue struct {
+	Decoder    string      `json:"decoder"`
+	APIVersion string      `json:"apiVersion,omitempty"`
+	Kind       string      `json:"kind,omitempty"`
+	Object     interface{} `json:"object,omitempty"`
+	YAML       string      `json:"yaml,omitempty"`
+	Note       string      `json:"note,omitempty"`
+	Err        string      `json:"error,omitempty"`
+}
+
+// Directory of the key space, keys under it are counted
+type KeyDir struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// One level of the key space, split by the delimiter
+type KeyTree struct {
+	Prefix    string     `json:"prefix"`
+	Delimiter string     `json:"delimiter"`
+	Revision  int64      `json:"revision"`
+	Dirs      []KeyDir   `json:"dirs"`
+	Keys      []KeyValue `json:"keys"`
+	More      bool       `json:"more"`
+}
+
+// Page of a range read, the cursor continues to the next page
+type KeyValuePage struct {
+	KVS    []KeyValue `json:"kvs"`
+	More   bool       `json:"more"`
+	Count  int64      `json:"count"`
+	Cursor string     `json:"cursor,omitempty"`
+}
+
+// Exported key value, the metadata is optional
+type ExportKeyValue struct {
+	Key            string `json:"key"`
+	Value          string `json:"value"`
+	Encoding       string `json:"encoding,omitempty"`
+	CreateRevision int64  `json:"createRevision,omitempty"`
+	ModRevision    int64  `json:"modRevision,omitempty"`
+	Version        int64  `json:"version,omitempty"`
+	Lease          string `json:"lease,omitempty"`
+}
+
+// Keys a remove would delete, counted at the revision
+type DeletePlan struct {
+	Revision int64 `json:"revision"`
+	Count    int64 `json:"count"`
+	// a sample of the keys from the first one, only on demand
+	Keys []KeyValue `json:"keys,omitempty"`
+	More bool       `json:"more,omitempty"`
+	// the delete limit of the range removes, the token confirms the remove of the keys counted if the count exceeds it
+	Limit int64  `json:"limit,omitempty"`
+	Token string `json:"token,omitempty"`
+}
+
+// Planned action of one imported key, the result is set once applied
+type ImportItem struct {
+	Key      string `json:"key"`
+	Encoding string `json:"encoding,omitempty"`
+	Action   string `json:"action"`
+	Result   string `json:"result,omitempty"`
+	Err      string `json:"error,omitempty"`
+}
+
+// Plan of an import, against the revision the current values are read at
+type ImportPlan struct {
+	Revision  int64        `json:"revision"`
+	DryRun    bool         `json:"dryRun"`
+	Creates   int64        `json:"creates"`
+	Updates   int64        `json:"updates"`
+	Unchanged int64        `json:"unchanged"`
+	Deletes   int64        `json:"deletes"`
+	Invalid   int64        `json:"invalid"`
+	Applied   int64        `json:"applied"`
+	Failed    int64        `json:"failed"`
+	Items     []ImportItem `json:"items"`
+	// the entry of the undo journal, zero if not journaled
+	Journal int64 `json:"journal,omitempty"`
+}
+
+// Key which cannot be copied or moved
+type CopyConflict struct {
+	Key      string `json:"key"`
+	Encoding string `json:"encoding,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// Result of a copy or a move, the source is read at the revision
+type CopyResult struct {
+	Revision  int64          `json:"revision"`
+	Copied    int64          `json:"copied"`
+	Conflicts []CopyConflict `json:"conflicts"`
+	// the entry of the undo journal, zero if not journaled
+	Journal int64 `json:"journal,omitempty"`
+}
+
+// One version of a key, a deleted one only has the revision of the deletion, zero if unknown.
+// The time is only known for the versions written by the console and still in the undo journal
+type KeyVersion struct {
+	KeyValue
+	Deleted bool              `json:"deleted,omitempty"`
+	Time    *backend.JSONTime `json:"time,omitempty"`
+}
+
+// Versions of a key from the newest, walking back until the compacted revision
+type KeyHistory struct {
+	Key       string       `json:"key"`
+	Encoding  string       `json:"encoding,omitempty"`
+	Revision  int64        `json:"revision"`
+	Versions  []KeyVersion `json:"versions"`
+	Compacted bool         `json:"compacted"`
+	More      bool         `json:"more"`
+}
+
+// Change of a key between two revisions, the patch is a line diff of the values
+type KeyChange struct {
+	Key      string    `json:"key"`
+	Encoding string    `json:"encoding,omitempty"`
+	Change   string    `json:"change"`
+	Before   *KeyValue `json:"before,omitempty"`
+	After    *KeyValue `json:"after,omitempty"`
+	Patch    string    `json:"patch,omitempty"`
+}
+
+// Changes of a key or a prefix between two revisions
+type KeyDiff struct {
+	From      int64       `json:"from"`
+	To        int64       `json:"to"`
+	Added     int64       `json:"added"`
+	Removed   int64       `json:"removed"`
+	Modified  int64       `json:"modified"`
+	Rewritten int64       `json:"rewritten"`
+	Changes   []KeyChange `json:"changes"`
+	// the prefix is compared page by page, the cursor continues to the next page
+	More   bool   `json:"more"`
+	Cursor string `json:"cursor,omitempty"`
 }
@@ -0,0 +1,33 @@
+package datamodels
+
+import (
+	"github.com/thxcode/etcd-console/backend"
+)
+
+// A console-initiated mutation, kept with the previous values to be undone
+type JournalEntry struct {
+	ID   int64            `json:"id"`
+	Time backend.JSONTime `json:"time"`
+	// one of write, remove, import, copy, move and undo
+	Op       string       `json:"op"`
+	Revision int64        `json:"revision"`
+	Keys     []JournalKey `json:"keys"`
+	// the revision of the last undo, zero if never undone
+	Undone int64 `json:"undone,omitempty"`
+}
+
+// A key changed by a mutation, the previous one is absent if the key was new
+type JournalKey struct {
+	Key      string    `json:"key"`
+	Encoding string    `json:"encoding,omitempty"`
+	Deleted  bool      `json:"deleted,omitempty"`
+	Prev     *KeyValue `json:"prev,omitempty"`
+}
+
+// Result of an undo, which is journaled as well to be redone
+type UndoResult struct {
+	ID        int64          `json:"id"`
+	Journal   int64          `json:"journal,omitempty"`
+	Restored  int64          `json:"restored"`
+	Conflicts []CopyConflict `json:"conflicts"`
+}
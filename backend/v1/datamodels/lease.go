@@ -0,0 +1,13 @@
+package datamodels
+
+// Lease, the attached keys are listed on demand
+type Lease struct {
+	// ID in hex, the same as the lease of the key values
+	ID string `json:"id"`
+	// the remaining seconds
+	TTL        int64 `json:"ttl"`
+	GrantedTTL int64 `json:"grantedTTL"`
+	// How the keys are encoded, empty means utf8
+	Encoding string   `json:"encoding,omitempty"`
+	Keys     []string `json:"keys,omitempty"`
+}
@@ -0,0 +1,27 @@
+package datamodels
+
+import (
+	"github.com/thxcode/etcd-console/backend"
+)
+
+// Mirror Job, copies a prefix to another cluster, then follows the updates if watching
+type MirrorJob struct {
+	ID          string   `json:"id"`
+	Prefix      string   `json:"prefix"`
+	DestPrefix  string   `json:"destPrefix"`
+	Source      []string `json:"source"`
+	Destination []string `json:"destination"`
+	Watch       bool     `json:"watch"`
+
+	// one of syncing, watching, done, stopped and failed
+	State string `json:"state"`
+	// the initial sync is consistent at the revision of the source
+	Revision int64 `json:"revision"`
+	Synced   int64 `json:"synced"`
+	// the updates applied by watching, until the last revision of the source
+	Updates      int64            `json:"updates"`
+	LastRevision int64            `json:"lastRevision"`
+	StartTime    backend.JSONTime `json:"startTime"`
+	EndTime      backend.JSONTime `json:"endTime"`
+	Err          string           `json:"error,omitempty"`
+}
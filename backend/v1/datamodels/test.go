@@ -0,0 +1,10 @@
+package datamodels
+
+// Test Member, a member of the embedding etcd of the test mode
+type TestMember struct {
+	Name      string `json:"name"`
+	ClientURL string `json:"clientURL"`
+	PeerURL   string `json:"peerURL"`
+	DataDir   string `json:"dataDir"`
+	Running   bool   `json:"running"`
+}
@@ -0,0 +1,1094 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: console.proto
+
+/*
+Package rpc is a generated protocol buffer package.
+
+The gRPC API of the etcd console, every call is served by the same services as its /api/v1 route,
+so both APIs share the same behaviors, safeguards and audit log. No REST gateway is generated from it.
+
+It is generated from these files:
+
+	console.proto
+
+It has these top-level messages:
+
+	KeyValue
+	RangeRequest
+	RangeResponse
+	PutRequest
+	PutResponse
+	DeleteRequest
+	DeleteResponse
+	Member
+	MemberStatusRequest
+	MemberStatusResponse
+	Backup
+	ListBackupsRequest
+	ListBackupsResponse
+	CreateBackupRequest
+	DeleteBackupRequest
+	DeleteBackupResponse
+	DownloadBackupRequest
+	BackupChunk
+*/
+package rpc
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type KeyValue struct {
+	Key            string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Value          string `protobuf:"bytes,2,opt,name=value" json:"value,omitempty"`
+	CreateRevision int64  `protobuf:"varint,3,opt,name=create_revision,json=createRevision" json:"create_revision,omitempty"`
+	ModRevision    int64  `protobuf:"varint,4,opt,name=mod_revision,json=modRevision" json:"mod_revision,omitempty"`
+	Version        int64  `protobuf:"varint,5,opt,name=version" json:"version,omitempty"`
+	// ID of the lease in hex, "0" if none
+	Lease string `protobuf:"bytes,6,opt,name=lease" json:"lease,omitempty"`
+	// How the key and the value are encoded, empty means utf8
+	Encoding string `protobuf:"bytes,7,opt,name=encoding" json:"encoding,omitempty"`
+	// The key or the value isn't valid utf8
+	Binary bool `protobuf:"varint,8,opt,name=binary" json:"binary,omitempty"`
+}
+
+func (m *KeyValue) Reset()                    { *m = KeyValue{} }
+func (m *KeyValue) String() string            { return proto.CompactTextString(m) }
+func (*KeyValue) ProtoMessage()               {}
+func (*KeyValue) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *KeyValue) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *KeyValue) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *KeyValue) GetCreateRevision() int64 {
+	if m != nil {
+		return m.CreateRevision
+	}
+	return 0
+}
+
+func (m *KeyValue) GetModRevision() int64 {
+	if m != nil {
+		return m.ModRevision
+	}
+	return 0
+}
+
+func (m *KeyValue) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *KeyValue) GetLease() string {
+	if m != nil {
+		return m.Lease
+	}
+	return ""
+}
+
+func (m *KeyValue) GetEncoding() string {
+	if m != nil {
+		return m.Encoding
+	}
+	return ""
+}
+
+func (m *KeyValue) GetBinary() bool {
+	if m != nil {
+		return m.Binary
+	}
+	return false
+}
+
+type RangeRequest struct {
+	Key     string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Prefix  bool   `protobuf:"varint,2,opt,name=prefix" json:"prefix,omitempty"`
+	FromKey bool   `protobuf:"varint,3,opt,name=from_key,json=fromKey" json:"from_key,omitempty"`
+	// The end of the range, exclusive
+	RangeEnd string `protobuf:"bytes,4,opt,name=range_end,json=rangeEnd" json:"range_end,omitempty"`
+	Limit    int64  `protobuf:"varint,5,opt,name=limit" json:"limit,omitempty"`
+	// Read at the revision, zero means the latest
+	Revision int64 `protobuf:"varint,6,opt,name=revision" json:"revision,omitempty"`
+	KeysOnly bool  `protobuf:"varint,7,opt,name=keys_only,json=keysOnly" json:"keys_only,omitempty"`
+	// One of utf8(default), base64 and hex
+	Encoding string `protobuf:"bytes,8,opt,name=encoding" json:"encoding,omitempty"`
+	// The cursor of the previous page
+	Cursor       string `protobuf:"bytes,9,opt,name=cursor" json:"cursor,omitempty"`
+	Serializable bool   `protobuf:"varint,10,opt,name=serializable" json:"serializable,omitempty"`
+}
+
+func (m *RangeRequest) Reset()                    { *m = RangeRequest{} }
+func (m *RangeRequest) String() string            { return proto.CompactTextString(m) }
+func (*RangeRequest) ProtoMessage()               {}
+func (*RangeRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *RangeRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *RangeRequest) GetPrefix() bool {
+	if m != nil {
+		return m.Prefix
+	}
+	return false
+}
+
+func (m *RangeRequest) GetFromKey() bool {
+	if m != nil {
+		return m.FromKey
+	}
+	return false
+}
+
+func (m *RangeRequest) GetRangeEnd() string {
+	if m != nil {
+		return m.RangeEnd
+	}
+	return ""
+}
+
+func (m *RangeRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *RangeRequest) GetRevision() int64 {
+	if m != nil {
+		return m.Revision
+	}
+	return 0
+}
+
+func (m *RangeRequest) GetKeysOnly() bool {
+	if m != nil {
+		return m.KeysOnly
+	}
+	return false
+}
+
+func (m *RangeRequest) GetEncoding() string {
+	if m != nil {
+		return m.Encoding
+	}
+	return ""
+}
+
+func (m *RangeRequest) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+func (m *RangeRequest) GetSerializable() bool {
+	if m != nil {
+		return m.Serializable
+	}
+	return false
+}
+
+type RangeResponse struct {
+	Kvs    []*KeyValue `protobuf:"bytes,1,rep,name=kvs" json:"kvs,omitempty"`
+	More   bool        `protobuf:"varint,2,opt,name=more" json:"more,omitempty"`
+	Count  int64       `protobuf:"varint,3,opt,name=count" json:"count,omitempty"`
+	Cursor string      `protobuf:"bytes,4,opt,name=cursor" json:"cursor,omitempty"`
+}
+
+func (m *RangeResponse) Reset()                    { *m = RangeResponse{} }
+func (m *RangeResponse) String() string            { return proto.CompactTextString(m) }
+func (*RangeResponse) ProtoMessage()               {}
+func (*RangeResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *RangeResponse) GetKvs() []*KeyValue {
+	if m != nil {
+		return m.Kvs
+	}
+	return nil
+}
+
+func (m *RangeResponse) GetMore() bool {
+	if m != nil {
+		return m.More
+	}
+	return false
+}
+
+func (m *RangeResponse) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *RangeResponse) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
+type PutRequest struct {
+	Key      string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Value    string `protobuf:"bytes,2,opt,name=value" json:"value,omitempty"`
+	Encoding string `protobuf:"bytes,3,opt,name=encoding" json:"encoding,omitempty"`
+	// ID of the lease in hex
+	Lease       string `protobuf:"bytes,4,opt,name=lease" json:"lease,omitempty"`
+	PrevKv      bool   `protobuf:"varint,5,opt,name=prev_kv,json=prevKv" json:"prev_kv,omitempty"`
+	IgnoreValue bool   `protobuf:"varint,6,opt,name=ignore_value,json=ignoreValue" json:"ignore_value,omitempty"`
+	IgnoreLease bool   `protobuf:"varint,7,opt,name=ignore_lease,json=ignoreLease" json:"ignore_lease,omitempty"`
+	// Write the value as it is, skipping the codec mapped by the key prefix
+	Raw bool `protobuf:"varint,8,opt,name=raw" json:"raw,omitempty"`
+}
+
+func (m *PutRequest) Reset()                    { *m = PutRequest{} }
+func (m *PutRequest) String() string            { return proto.CompactTextString(m) }
+func (*PutRequest) ProtoMessage()               {}
+func (*PutRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *PutRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *PutRequest) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *PutRequest) GetEncoding() string {
+	if m != nil {
+		return m.Encoding
+	}
+	return ""
+}
+
+func (m *PutRequest) GetLease() string {
+	if m != nil {
+		return m.Lease
+	}
+	return ""
+}
+
+func (m *PutRequest) GetPrevKv() bool {
+	if m != nil {
+		return m.PrevKv
+	}
+	return false
+}
+
+func (m *PutRequest) GetIgnoreValue() bool {
+	if m != nil {
+		return m.IgnoreValue
+	}
+	return false
+}
+
+func (m *PutRequest) GetIgnoreLease() bool {
+	if m != nil {
+		return m.IgnoreLease
+	}
+	return false
+}
+
+func (m *PutRequest) GetRaw() bool {
+	if m != nil {
+		return m.Raw
+	}
+	return false
+}
+
+type PutResponse struct {
+	PrevKvs []*KeyValue `protobuf:"bytes,1,rep,name=prev_kvs,json=prevKvs" json:"prev_kvs,omitempty"`
+	// The entry of the undo journal, zero if not journaled
+	Journal int64 `protobuf:"varint,2,opt,name=journal" json:"journal,omitempty"`
+}
+
+func (m *PutResponse) Reset()                    { *m = PutResponse{} }
+func (m *PutResponse) String() string            { return proto.CompactTextString(m) }
+func (*PutResponse) ProtoMessage()               {}
+func (*PutResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *PutResponse) GetPrevKvs() []*KeyValue {
+	if m != nil {
+		return m.PrevKvs
+	}
+	return nil
+}
+
+func (m *PutResponse) GetJournal() int64 {
+	if m != nil {
+		return m.Journal
+	}
+	return 0
+}
+
+type DeleteRequest struct {
+	Key      string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Prefix   bool   `protobuf:"varint,2,opt,name=prefix" json:"prefix,omitempty"`
+	FromKey  bool   `protobuf:"varint,3,opt,name=from_key,json=fromKey" json:"from_key,omitempty"`
+	RangeEnd string `protobuf:"bytes,4,opt,name=range_end,json=rangeEnd" json:"range_end,omitempty"`
+	Encoding string `protobuf:"bytes,5,opt,name=encoding" json:"encoding,omitempty"`
+	PrevKv   bool   `protobuf:"varint,6,opt,name=prev_kv,json=prevKv" json:"prev_kv,omitempty"`
+	// The token confirming a remove over the delete limit
+	Confirm string `protobuf:"bytes,7,opt,name=confirm" json:"confirm,omitempty"`
+}
+
+func (m *DeleteRequest) Reset()                    { *m = DeleteRequest{} }
+func (m *DeleteRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteRequest) ProtoMessage()               {}
+func (*DeleteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *DeleteRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *DeleteRequest) GetPrefix() bool {
+	if m != nil {
+		return m.Prefix
+	}
+	return false
+}
+
+func (m *DeleteRequest) GetFromKey() bool {
+	if m != nil {
+		return m.FromKey
+	}
+	return false
+}
+
+func (m *DeleteRequest) GetRangeEnd() string {
+	if m != nil {
+		return m.RangeEnd
+	}
+	return ""
+}
+
+func (m *DeleteRequest) GetEncoding() string {
+	if m != nil {
+		return m.Encoding
+	}
+	return ""
+}
+
+func (m *DeleteRequest) GetPrevKv() bool {
+	if m != nil {
+		return m.PrevKv
+	}
+	return false
+}
+
+func (m *DeleteRequest) GetConfirm() string {
+	if m != nil {
+		return m.Confirm
+	}
+	return ""
+}
+
+type DeleteResponse struct {
+	PrevKvs []*KeyValue `protobuf:"bytes,1,rep,name=prev_kvs,json=prevKvs" json:"prev_kvs,omitempty"`
+	Journal int64       `protobuf:"varint,2,opt,name=journal" json:"journal,omitempty"`
+}
+
+func (m *DeleteResponse) Reset()                    { *m = DeleteResponse{} }
+func (m *DeleteResponse) String() string            { return proto.CompactTextString(m) }
+func (*DeleteResponse) ProtoMessage()               {}
+func (*DeleteResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *DeleteResponse) GetPrevKvs() []*KeyValue {
+	if m != nil {
+		return m.PrevKvs
+	}
+	return nil
+}
+
+func (m *DeleteResponse) GetJournal() int64 {
+	if m != nil {
+		return m.Journal
+	}
+	return 0
+}
+
+type Member struct {
+	Id        string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Name      string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Endpoint  string `protobuf:"bytes,3,opt,name=endpoint" json:"endpoint,omitempty"`
+	Leader    bool   `protobuf:"varint,4,opt,name=leader" json:"leader,omitempty"`
+	Health    bool   `protobuf:"varint,5,opt,name=health" json:"health,omitempty"`
+	Connected bool   `protobuf:"varint,6,opt,name=connected" json:"connected,omitempty"`
+	DbSize    int64  `protobuf:"varint,7,opt,name=db_size,json=dbSize" json:"db_size,omitempty"`
+	Version   string `protobuf:"bytes,8,opt,name=version" json:"version,omitempty"`
+}
+
+func (m *Member) Reset()                    { *m = Member{} }
+func (m *Member) String() string            { return proto.CompactTextString(m) }
+func (*Member) ProtoMessage()               {}
+func (*Member) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *Member) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Member) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Member) GetEndpoint() string {
+	if m != nil {
+		return m.Endpoint
+	}
+	return ""
+}
+
+func (m *Member) GetLeader() bool {
+	if m != nil {
+		return m.Leader
+	}
+	return false
+}
+
+func (m *Member) GetHealth() bool {
+	if m != nil {
+		return m.Health
+	}
+	return false
+}
+
+func (m *Member) GetConnected() bool {
+	if m != nil {
+		return m.Connected
+	}
+	return false
+}
+
+func (m *Member) GetDbSize() int64 {
+	if m != nil {
+		return m.DbSize
+	}
+	return 0
+}
+
+func (m *Member) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+type MemberStatusRequest struct {
+}
+
+func (m *MemberStatusRequest) Reset()                    { *m = MemberStatusRequest{} }
+func (m *MemberStatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*MemberStatusRequest) ProtoMessage()               {}
+func (*MemberStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+type MemberStatusResponse struct {
+	Members []*Member `protobuf:"bytes,1,rep,name=members" json:"members,omitempty"`
+}
+
+func (m *MemberStatusResponse) Reset()                    { *m = MemberStatusResponse{} }
+func (m *MemberStatusResponse) String() string            { return proto.CompactTextString(m) }
+func (*MemberStatusResponse) ProtoMessage()               {}
+func (*MemberStatusResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *MemberStatusResponse) GetMembers() []*Member {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
+type Backup struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Size int64  `protobuf:"varint,2,opt,name=size" json:"size,omitempty"`
+	// Formatted as "2006-01-02 15:04:05"
+	CreateTime string `protobuf:"bytes,3,opt,name=create_time,json=createTime" json:"create_time,omitempty"`
+}
+
+func (m *Backup) Reset()                    { *m = Backup{} }
+func (m *Backup) String() string            { return proto.CompactTextString(m) }
+func (*Backup) ProtoMessage()               {}
+func (*Backup) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *Backup) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Backup) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *Backup) GetCreateTime() string {
+	if m != nil {
+		return m.CreateTime
+	}
+	return ""
+}
+
+type ListBackupsRequest struct {
+}
+
+func (m *ListBackupsRequest) Reset()                    { *m = ListBackupsRequest{} }
+func (m *ListBackupsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListBackupsRequest) ProtoMessage()               {}
+func (*ListBackupsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+type ListBackupsResponse struct {
+	Backups []*Backup `protobuf:"bytes,1,rep,name=backups" json:"backups,omitempty"`
+}
+
+func (m *ListBackupsResponse) Reset()                    { *m = ListBackupsResponse{} }
+func (m *ListBackupsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListBackupsResponse) ProtoMessage()               {}
+func (*ListBackupsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *ListBackupsResponse) GetBackups() []*Backup {
+	if m != nil {
+		return m.Backups
+	}
+	return nil
+}
+
+type CreateBackupRequest struct {
+}
+
+func (m *CreateBackupRequest) Reset()                    { *m = CreateBackupRequest{} }
+func (m *CreateBackupRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateBackupRequest) ProtoMessage()               {}
+func (*CreateBackupRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+type DeleteBackupRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *DeleteBackupRequest) Reset()                    { *m = DeleteBackupRequest{} }
+func (m *DeleteBackupRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteBackupRequest) ProtoMessage()               {}
+func (*DeleteBackupRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *DeleteBackupRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type DeleteBackupResponse struct {
+}
+
+func (m *DeleteBackupResponse) Reset()                    { *m = DeleteBackupResponse{} }
+func (m *DeleteBackupResponse) String() string            { return proto.CompactTextString(m) }
+func (*DeleteBackupResponse) ProtoMessage()               {}
+func (*DeleteBackupResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+type DownloadBackupRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *DownloadBackupRequest) Reset()                    { *m = DownloadBackupRequest{} }
+func (m *DownloadBackupRequest) String() string            { return proto.CompactTextString(m) }
+func (*DownloadBackupRequest) ProtoMessage()               {}
+func (*DownloadBackupRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *DownloadBackupRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type BackupChunk struct {
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *BackupChunk) Reset()                    { *m = BackupChunk{} }
+func (m *BackupChunk) String() string            { return proto.CompactTextString(m) }
+func (*BackupChunk) ProtoMessage()               {}
+func (*BackupChunk) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *BackupChunk) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*KeyValue)(nil), "etcdconsole.v1.KeyValue")
+	proto.RegisterType((*RangeRequest)(nil), "etcdconsole.v1.RangeRequest")
+	proto.RegisterType((*RangeResponse)(nil), "etcdconsole.v1.RangeResponse")
+	proto.RegisterType((*PutRequest)(nil), "etcdconsole.v1.PutRequest")
+	proto.RegisterType((*PutResponse)(nil), "etcdconsole.v1.PutResponse")
+	proto.RegisterType((*DeleteRequest)(nil), "etcdconsole.v1.DeleteRequest")
+	proto.RegisterType((*DeleteResponse)(nil), "etcdconsole.v1.DeleteResponse")
+	proto.RegisterType((*Member)(nil), "etcdconsole.v1.Member")
+	proto.RegisterType((*MemberStatusRequest)(nil), "etcdconsole.v1.MemberStatusRequest")
+	proto.RegisterType((*MemberStatusResponse)(nil), "etcdconsole.v1.MemberStatusResponse")
+	proto.RegisterType((*Backup)(nil), "etcdconsole.v1.Backup")
+	proto.RegisterType((*ListBackupsRequest)(nil), "etcdconsole.v1.ListBackupsRequest")
+	proto.RegisterType((*ListBackupsResponse)(nil), "etcdconsole.v1.ListBackupsResponse")
+	proto.RegisterType((*CreateBackupRequest)(nil), "etcdconsole.v1.CreateBackupRequest")
+	proto.RegisterType((*DeleteBackupRequest)(nil), "etcdconsole.v1.DeleteBackupRequest")
+	proto.RegisterType((*DeleteBackupResponse)(nil), "etcdconsole.v1.DeleteBackupResponse")
+	proto.RegisterType((*DownloadBackupRequest)(nil), "etcdconsole.v1.DownloadBackupRequest")
+	proto.RegisterType((*BackupChunk)(nil), "etcdconsole.v1.BackupChunk")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Console service
+
+type ConsoleClient interface {
+	// Range reads a key, a prefix or a range, one page at a time.
+	Range(ctx context.Context, in *RangeRequest, opts ...grpc.CallOption) (*RangeResponse, error)
+	// Put writes a key, the value goes through the codec and the schema mapped by the key prefix.
+	Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error)
+	// Delete removes a key, a prefix or a range, the removes over the delete limit need the
+	// token in the "confirm-token" trailer of a preceding call.
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	MemberStatus(ctx context.Context, in *MemberStatusRequest, opts ...grpc.CallOption) (*MemberStatusResponse, error)
+	ListBackups(ctx context.Context, in *ListBackupsRequest, opts ...grpc.CallOption) (*ListBackupsResponse, error)
+	CreateBackup(ctx context.Context, in *CreateBackupRequest, opts ...grpc.CallOption) (*Backup, error)
+	DeleteBackup(ctx context.Context, in *DeleteBackupRequest, opts ...grpc.CallOption) (*DeleteBackupResponse, error)
+	// DownloadBackup streams the zip file of a backup.
+	DownloadBackup(ctx context.Context, in *DownloadBackupRequest, opts ...grpc.CallOption) (Console_DownloadBackupClient, error)
+}
+
+type consoleClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewConsoleClient(cc *grpc.ClientConn) ConsoleClient {
+	return &consoleClient{cc}
+}
+
+func (c *consoleClient) Range(ctx context.Context, in *RangeRequest, opts ...grpc.CallOption) (*RangeResponse, error) {
+	out := new(RangeResponse)
+	err := grpc.Invoke(ctx, "/etcdconsole.v1.Console/Range", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *consoleClient) Put(ctx context.Context, in *PutRequest, opts ...grpc.CallOption) (*PutResponse, error) {
+	out := new(PutResponse)
+	err := grpc.Invoke(ctx, "/etcdconsole.v1.Console/Put", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *consoleClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	out := new(DeleteResponse)
+	err := grpc.Invoke(ctx, "/etcdconsole.v1.Console/Delete", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *consoleClient) MemberStatus(ctx context.Context, in *MemberStatusRequest, opts ...grpc.CallOption) (*MemberStatusResponse, error) {
+	out := new(MemberStatusResponse)
+	err := grpc.Invoke(ctx, "/etcdconsole.v1.Console/MemberStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *consoleClient) ListBackups(ctx context.Context, in *ListBackupsRequest, opts ...grpc.CallOption) (*ListBackupsResponse, error) {
+	out := new(ListBackupsResponse)
+	err := grpc.Invoke(ctx, "/etcdconsole.v1.Console/ListBackups", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *consoleClient) CreateBackup(ctx context.Context, in *CreateBackupRequest, opts ...grpc.CallOption) (*Backup, error) {
+	out := new(Backup)
+	err := grpc.Invoke(ctx, "/etcdconsole.v1.Console/CreateBackup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *consoleClient) DeleteBackup(ctx context.Context, in *DeleteBackupRequest, opts ...grpc.CallOption) (*DeleteBackupResponse, error) {
+	out := new(DeleteBackupResponse)
+	err := grpc.Invoke(ctx, "/etcdconsole.v1.Console/DeleteBackup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *consoleClient) DownloadBackup(ctx context.Context, in *DownloadBackupRequest, opts ...grpc.CallOption) (Console_DownloadBackupClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Console_serviceDesc.Streams[0], c.cc, "/etcdconsole.v1.Console/DownloadBackup", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &consoleDownloadBackupClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Console_DownloadBackupClient interface {
+	Recv() (*BackupChunk, error)
+	grpc.ClientStream
+}
+
+type consoleDownloadBackupClient struct {
+	grpc.ClientStream
+}
+
+func (x *consoleDownloadBackupClient) Recv() (*BackupChunk, error) {
+	m := new(BackupChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for Console service
+
+type ConsoleServer interface {
+	// Range reads a key, a prefix or a range, one page at a time.
+	Range(context.Context, *RangeRequest) (*RangeResponse, error)
+	// Put writes a key, the value goes through the codec and the schema mapped by the key prefix.
+	Put(context.Context, *PutRequest) (*PutResponse, error)
+	// Delete removes a key, a prefix or a range, the removes over the delete limit need the
+	// token in the "confirm-token" trailer of a preceding call.
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	MemberStatus(context.Context, *MemberStatusRequest) (*MemberStatusResponse, error)
+	ListBackups(context.Context, *ListBackupsRequest) (*ListBackupsResponse, error)
+	CreateBackup(context.Context, *CreateBackupRequest) (*Backup, error)
+	DeleteBackup(context.Context, *DeleteBackupRequest) (*DeleteBackupResponse, error)
+	// DownloadBackup streams the zip file of a backup.
+	DownloadBackup(*DownloadBackupRequest, Console_DownloadBackupServer) error
+}
+
+func RegisterConsoleServer(s *grpc.Server, srv ConsoleServer) {
+	s.RegisterService(&_Console_serviceDesc, srv)
+}
+
+func _Console_Range_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsoleServer).Range(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/etcdconsole.v1.Console/Range",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsoleServer).Range(ctx, req.(*RangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Console_Put_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsoleServer).Put(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/etcdconsole.v1.Console/Put",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsoleServer).Put(ctx, req.(*PutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Console_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsoleServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/etcdconsole.v1.Console/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsoleServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Console_MemberStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MemberStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsoleServer).MemberStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/etcdconsole.v1.Console/MemberStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsoleServer).MemberStatus(ctx, req.(*MemberStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Console_ListBackups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBackupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsoleServer).ListBackups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/etcdconsole.v1.Console/ListBackups",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsoleServer).ListBackups(ctx, req.(*ListBackupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Console_CreateBackup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBackupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsoleServer).CreateBackup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/etcdconsole.v1.Console/CreateBackup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsoleServer).CreateBackup(ctx, req.(*CreateBackupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Console_DeleteBackup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBackupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConsoleServer).DeleteBackup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/etcdconsole.v1.Console/DeleteBackup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConsoleServer).DeleteBackup(ctx, req.(*DeleteBackupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Console_DownloadBackup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadBackupRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConsoleServer).DownloadBackup(m, &consoleDownloadBackupServer{stream})
+}
+
+type Console_DownloadBackupServer interface {
+	Send(*BackupChunk) error
+	grpc.ServerStream
+}
+
+type consoleDownloadBackupServer struct {
+	grpc.ServerStream
+}
+
+func (x *consoleDownloadBackupServer) Send(m *BackupChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _Console_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "etcdconsole.v1.Console",
+	HandlerType: (*ConsoleServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Range",
+			Handler:    _Console_Range_Handler,
+		},
+		{
+			MethodName: "Put",
+			Handler:    _Console_Put_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Console_Delete_Handler,
+		},
+		{
+			MethodName: "MemberStatus",
+			Handler:    _Console_MemberStatus_Handler,
+		},
+		{
+			MethodName: "ListBackups",
+			Handler:    _Console_ListBackups_Handler,
+		},
+		{
+			MethodName: "CreateBackup",
+			Handler:    _Console_CreateBackup_Handler,
+		},
+		{
+			MethodName: "DeleteBackup",
+			Handler:    _Console_DeleteBackup_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DownloadBackup",
+			Handler:       _Console_DownloadBackup_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "console.proto",
+}
+
+func init() { proto.RegisterFile("console.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 933 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x56, 0xdd, 0x6e, 0xdc, 0x44,
+	0x14, 0x96, 0xe3, 0x5d, 0xaf, 0xf7, 0xec, 0x66, 0xa9, 0x26, 0x69, 0x30, 0x6e, 0x81, 0xd4, 0x2d,
+	0x22, 0x80, 0x14, 0x95, 0xf6, 0x9e, 0x8b, 0x26, 0xa8, 0x48, 0x29, 0xa2, 0xb8, 0xa8, 0x12, 0x08,
+	0x69, 0xe5, 0xb5, 0x4f, 0x92, 0x61, 0xed, 0x99, 0x65, 0xfc, 0x53, 0x36, 0x12, 0x4f, 0xc0, 0x03,
+	0xf0, 0x3a, 0xdc, 0xf2, 0x04, 0xdc, 0xf3, 0x24, 0x68, 0x7e, 0xec, 0xb5, 0xf7, 0x27, 0xbd, 0xa2,
+	0x77, 0x73, 0xce, 0xf9, 0xe6, 0xcc, 0xf9, 0x3e, 0x7f, 0x33, 0xbb, 0xb0, 0x1f, 0x73, 0x96, 0xf3,
+	0x14, 0x4f, 0x17, 0x82, 0x17, 0x9c, 0x4c, 0xb0, 0x88, 0x93, 0x3a, 0x55, 0x7d, 0x19, 0xfc, 0x6b,
+	0x81, 0x7b, 0x81, 0xcb, 0xd7, 0x51, 0x5a, 0x22, 0xb9, 0x03, 0xf6, 0x1c, 0x97, 0x9e, 0x75, 0x6c,
+	0x9d, 0x0c, 0x43, 0xb9, 0x24, 0x87, 0xd0, 0xaf, 0x64, 0xc9, 0xdb, 0x53, 0x39, 0x1d, 0x90, 0x4f,
+	0xe1, 0xbd, 0x58, 0x60, 0x54, 0xe0, 0x54, 0x60, 0x45, 0x73, 0xca, 0x99, 0x67, 0x1f, 0x5b, 0x27,
+	0x76, 0x38, 0xd1, 0xe9, 0xd0, 0x64, 0xc9, 0x03, 0x18, 0x67, 0x3c, 0x59, 0xa1, 0x7a, 0x0a, 0x35,
+	0xca, 0x78, 0xd2, 0x40, 0x3c, 0x18, 0x54, 0x28, 0x54, 0xb5, 0xaf, 0xaa, 0x75, 0x28, 0xcf, 0x4e,
+	0x31, 0xca, 0xd1, 0x73, 0xf4, 0xd9, 0x2a, 0x20, 0x3e, 0xb8, 0xc8, 0x62, 0x9e, 0x50, 0x76, 0xe5,
+	0x0d, 0x54, 0xa1, 0x89, 0xc9, 0x11, 0x38, 0x33, 0xca, 0x22, 0xb1, 0xf4, 0xdc, 0x63, 0xeb, 0xc4,
+	0x0d, 0x4d, 0x14, 0xfc, 0xb9, 0x07, 0xe3, 0x30, 0x62, 0x57, 0x18, 0xe2, 0xaf, 0x25, 0xe6, 0xc5,
+	0x16, 0xa2, 0x47, 0xe0, 0x2c, 0x04, 0x5e, 0xd2, 0xdf, 0x14, 0x53, 0x37, 0x34, 0x11, 0xf9, 0x00,
+	0xdc, 0x4b, 0xc1, 0xb3, 0xa9, 0x84, 0xdb, 0xaa, 0x32, 0x90, 0xf1, 0x05, 0x2e, 0xc9, 0x3d, 0x18,
+	0x0a, 0xd9, 0x74, 0x8a, 0x2c, 0x51, 0xcc, 0x86, 0xa1, 0xab, 0x12, 0x5f, 0xb3, 0x44, 0x0d, 0x4f,
+	0x33, 0x5a, 0x18, 0x52, 0x3a, 0x90, 0xc3, 0x37, 0x5a, 0x38, 0xaa, 0xd0, 0xc4, 0xb2, 0xdd, 0x1c,
+	0x97, 0xf9, 0x94, 0xb3, 0x74, 0xa9, 0x98, 0xb9, 0xa1, 0x2b, 0x13, 0xdf, 0xb1, 0x74, 0xd9, 0x61,
+	0xed, 0x6e, 0xb2, 0x8e, 0x4b, 0x91, 0x73, 0xe1, 0x0d, 0x55, 0xc5, 0x44, 0x24, 0x80, 0x71, 0x8e,
+	0x82, 0x46, 0x29, 0xbd, 0x89, 0x66, 0x29, 0x7a, 0xa0, 0x7a, 0x76, 0x72, 0xc1, 0xef, 0xb0, 0x6f,
+	0x84, 0xc9, 0x17, 0x9c, 0xe5, 0x48, 0x3e, 0x07, 0x7b, 0x5e, 0xe5, 0x9e, 0x75, 0x6c, 0x9f, 0x8c,
+	0x9e, 0x78, 0xa7, 0x5d, 0xb7, 0x9c, 0xd6, 0x4e, 0x09, 0x25, 0x88, 0x10, 0xe8, 0x65, 0x5c, 0xa0,
+	0x51, 0x4c, 0xad, 0x25, 0xef, 0x98, 0x97, 0xac, 0x30, 0x86, 0xd0, 0x41, 0x6b, 0xc4, 0x5e, 0x7b,
+	0xc4, 0xe0, 0x1f, 0x0b, 0xe0, 0x65, 0x59, 0xec, 0xfe, 0x2c, 0xdb, 0xfd, 0xd7, 0x56, 0xc3, 0x5e,
+	0x53, 0xa3, 0x71, 0x4d, 0xaf, 0xed, 0x9a, 0xf7, 0x61, 0xb0, 0x10, 0x58, 0x4d, 0xe7, 0x95, 0xd7,
+	0x6f, 0xbe, 0x6f, 0x75, 0x51, 0x49, 0x87, 0xd2, 0x2b, 0xc6, 0x05, 0x4e, 0xf5, 0x39, 0x8e, 0xaa,
+	0x8e, 0x74, 0x4e, 0xdf, 0x8a, 0x15, 0x44, 0x37, 0x1e, 0xb4, 0x21, 0x2f, 0x54, 0xfb, 0x3b, 0x60,
+	0x8b, 0xe8, 0x8d, 0x71, 0x9d, 0x5c, 0x06, 0x3f, 0xc3, 0x48, 0x11, 0x33, 0xb2, 0x3e, 0x05, 0xd7,
+	0x9c, 0xff, 0x76, 0x6d, 0x07, 0x7a, 0xb4, 0x5c, 0x5e, 0x8d, 0x5f, 0x78, 0x29, 0x58, 0x94, 0x2a,
+	0xfa, 0x76, 0x58, 0x87, 0xc1, 0x5f, 0x16, 0xec, 0x9f, 0x63, 0x8a, 0xc5, 0x3b, 0x74, 0x74, 0x5b,
+	0xf4, 0xfe, 0x9a, 0xe8, 0x2d, 0x79, 0x9d, 0x8e, 0xbc, 0x1e, 0x0c, 0x62, 0xce, 0x2e, 0xa9, 0xc8,
+	0xcc, 0x65, 0xad, 0xc3, 0x60, 0x0a, 0x93, 0x9a, 0xc1, 0xff, 0xa3, 0xd1, 0xdf, 0x16, 0x38, 0xdf,
+	0x62, 0x36, 0x43, 0x41, 0x26, 0xb0, 0x47, 0x13, 0xa3, 0xcd, 0x1e, 0x4d, 0xa4, 0x71, 0x59, 0x94,
+	0xd5, 0xa6, 0x52, 0x6b, 0x4d, 0x2f, 0x59, 0x70, 0x6a, 0xbc, 0x3b, 0x0c, 0x9b, 0x58, 0x4a, 0x99,
+	0x62, 0x94, 0xa0, 0xb6, 0xaf, 0x1b, 0x9a, 0x48, 0xe6, 0xaf, 0x31, 0x4a, 0x8b, 0xeb, 0xda, 0x54,
+	0x3a, 0x22, 0xf7, 0x61, 0x18, 0x73, 0xc6, 0x30, 0x2e, 0x30, 0x31, 0x82, 0xac, 0x12, 0x52, 0xac,
+	0x64, 0x36, 0xcd, 0xe9, 0x8d, 0xb6, 0x92, 0x1d, 0x3a, 0xc9, 0xec, 0x15, 0xbd, 0xc1, 0xf6, 0x53,
+	0xa8, 0xef, 0x78, 0x1d, 0x06, 0x77, 0xe1, 0x40, 0x53, 0x79, 0x55, 0x44, 0x45, 0x99, 0x9b, 0x8f,
+	0x1e, 0x7c, 0x03, 0x87, 0xdd, 0xb4, 0x51, 0xf2, 0x31, 0x0c, 0x32, 0x95, 0xaf, 0x85, 0x3c, 0x5a,
+	0x17, 0x52, 0x6f, 0x0b, 0x6b, 0x58, 0xf0, 0x3d, 0x38, 0xcf, 0xa2, 0x78, 0x5e, 0x2e, 0x1a, 0x6d,
+	0xac, 0x96, 0x36, 0x04, 0x7a, 0x6a, 0x5c, 0xad, 0xb0, 0x5a, 0x93, 0x8f, 0x61, 0x64, 0x7e, 0x03,
+	0x0a, 0x9a, 0xa1, 0x91, 0x0c, 0x74, 0xea, 0x07, 0x9a, 0x61, 0x70, 0x08, 0xe4, 0x05, 0xcd, 0x0b,
+	0xdd, 0xb6, 0x19, 0xf9, 0x39, 0x1c, 0x74, 0xb2, 0xab, 0x89, 0x67, 0x3a, 0xb5, 0x6b, 0x62, 0xbd,
+	0x23, 0xac, 0x61, 0x52, 0x92, 0x33, 0x75, 0x98, 0x29, 0x98, 0xfe, 0x9f, 0xc1, 0x81, 0xb6, 0x55,
+	0x27, 0xbd, 0x8d, 0x55, 0x70, 0x04, 0x87, 0x5d, 0xa8, 0x9e, 0x25, 0xf8, 0x02, 0xee, 0x9e, 0xf3,
+	0x37, 0x2c, 0xe5, 0x51, 0xf2, 0xf6, 0x26, 0x0f, 0x60, 0xa4, 0x41, 0x67, 0xd7, 0x25, 0x9b, 0x4b,
+	0x48, 0x12, 0x15, 0x91, 0x82, 0x8c, 0x43, 0xb5, 0x7e, 0xf2, 0x47, 0x1f, 0x06, 0x67, 0x9a, 0x08,
+	0x39, 0x87, 0xbe, 0x7a, 0x6f, 0xc9, 0xfd, 0x75, 0x7e, 0xed, 0xdf, 0x27, 0xff, 0xc3, 0x1d, 0x55,
+	0xa3, 0xd6, 0x57, 0x60, 0xbf, 0x2c, 0x0b, 0xe2, 0xaf, 0xa3, 0x56, 0x4f, 0xa9, 0x7f, 0x6f, 0x6b,
+	0xcd, 0xec, 0x7f, 0x0e, 0x8e, 0x66, 0x4e, 0x36, 0x0e, 0xea, 0xbc, 0x2a, 0xfe, 0x47, 0xbb, 0xca,
+	0xa6, 0xd1, 0x8f, 0x30, 0x6e, 0x1b, 0x90, 0x3c, 0xdc, 0xee, 0xb3, 0x8e, 0x6b, 0xfd, 0x47, 0xb7,
+	0x83, 0x4c, 0xeb, 0xd7, 0x30, 0x6a, 0x19, 0x85, 0x04, 0xeb, 0x9b, 0x36, 0xbd, 0xe5, 0x3f, 0xbc,
+	0x15, 0x63, 0xfa, 0x5e, 0xc0, 0xb8, 0xed, 0x9b, 0xcd, 0x91, 0xb7, 0xb8, 0xca, 0xdf, 0xe1, 0x46,
+	0xc9, 0xbf, 0x6d, 0xa1, 0xcd, 0x66, 0x5b, 0xbc, 0xe8, 0x3f, 0xba, 0x1d, 0xd4, 0xf0, 0x9f, 0x74,
+	0x5d, 0x48, 0x3e, 0xd9, 0xd8, 0xb7, 0xcd, 0xa5, 0x9b, 0x5f, 0xbe, 0xe5, 0xcf, 0xc7, 0xd6, 0xb3,
+	0xfe, 0x4f, 0xb6, 0x58, 0xc4, 0x33, 0x47, 0xfd, 0x1d, 0x7c, 0xfa, 0xdf, 0x00, 0x28, 0x36, 0xd7,
+	0x57, 0x1f, 0x0a, 0x00, 0x00,
+}
@@ -0,0 +1,301 @@
+// Package rpc serves the gRPC API of the console, every call is served by the same services as its /api/v1
+// route, so both APIs share the same safeguards, journal and audit log.
+package rpc
+
+//go:generate protoc --go_out=plugins=grpc:. console.proto
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/kataras/golog"
+	"github.com/kataras/iris"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/audit"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+	"github.com/thxcode/etcd-console/backend/v1/services"
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+	"github.com/thxcode/etcd-console/backend/web"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// the backups are streamed in chunks of
+const backupChunkSize = 64 << 10
+
+// grpcCodes maps the codes of the API errors to the gRPC codes.
+var grpcCodes = map[string]codes.Code{
+	backend.ErrCodeBadRequest:           codes.InvalidArgument,
+	backend.ErrCodeForbidden:            codes.PermissionDenied,
+	backend.ErrCodeNotFound:             codes.NotFound,
+	backend.ErrCodeMethodNotAllowed:     codes.Unimplemented,
+	backend.ErrCodeTimeout:              codes.DeadlineExceeded,
+	backend.ErrCodeConflict:             codes.Aborted,
+	backend.ErrCodeInvalidValue:         codes.InvalidArgument,
+	backend.ErrCodeConfirmationRequired: codes.FailedPrecondition,
+	backend.ErrCodeInternal:             codes.Internal,
+	backend.ErrCodeUnsupported:          codes.Unimplemented,
+	backend.ErrCodeUnavailable:          codes.Unavailable,
+}
+
+// server calls the services shared with the REST routes.
+type server struct {
+	clientService  services.ClientService
+	clusterService services.ClusterService
+	// nil means disabled
+	auditLog *audit.Log
+	logger   *golog.Logger
+}
+
+// NewServer serves the gRPC calls by the services, which must be the ones of the REST routes,
+// e.g. the undo journal is kept by the client service. A nil logger is golog.Default.
+func NewServer(clientService services.ClientService, clusterService services.ClusterService, auditLog *audit.Log, logger *golog.Logger) ConsoleServer {
+	if logger == nil {
+		logger = golog.Default
+	}
+
+	return &server{
+		clientService:  clientService,
+		clusterService: clusterService,
+		auditLog:       auditLog,
+		logger:         logger,
+	}
+}
+
+func (s *server) Range(ctx context.Context, in *RangeRequest) (*RangeResponse, error) {
+	getRequest := services.GetRequest{
+		KeyRange: services.KeyRange{
+			Key:      in.Key,
+			Prefix:   in.Prefix,
+			FromKey:  in.FromKey,
+			Range:    in.RangeEnd,
+			Encoding: in.Encoding,
+		},
+		Limit:    in.Limit,
+		Rev:      in.Revision,
+		KeysOnly: in.KeysOnly,
+		Cursor:   in.Cursor,
+	}
+	if in.Serializable {
+		getRequest.Consistency = "s"
+	}
+
+	page, err := s.clientService.Get(ctx, getRequest)
+	if err != nil {
+		return nil, s.statusOf(ctx, err)
+	}
+
+	return &RangeResponse{
+		Kvs:    newKeyValues(page.KVS),
+		More:   page.More,
+		Count:  page.Count,
+		Cursor: page.Cursor,
+	}, nil
+}
+
+func (s *server) Put(ctx context.Context, in *PutRequest) (*PutResponse, error) {
+	setRequest := services.SetRequest{
+		ClientSetRequest: viewmodels.ClientSetRequest{
+			Key:         in.Key,
+			Value:       in.Value,
+			Encoding:    in.Encoding,
+			Raw:         in.Raw,
+			Lease:       in.Lease,
+			PrevKV:      in.PrevKv,
+			IgnoreValue: in.IgnoreValue,
+			IgnoreLease: in.IgnoreLease,
+		},
+	}
+
+	kvs, journalID, err := s.clientService.Set(ctx, setRequest)
+	s.auditOp(ctx, "client/write", "/etcdconsole.v1.Console/Put", in, kvs, err)
+	if err != nil {
+		return nil, s.statusOf(ctx, err)
+	}
+
+	return &PutResponse{
+		PrevKvs: newKeyValues(kvs),
+		Journal: journalID,
+	}, nil
+}
+
+func (s *server) Delete(ctx context.Context, in *DeleteRequest) (*DeleteResponse, error) {
+	delRequest := services.DelRequest{
+		KeyRange: services.KeyRange{
+			Key:      in.Key,
+			Prefix:   in.Prefix,
+			FromKey:  in.FromKey,
+			Range:    in.RangeEnd,
+			Encoding: in.Encoding,
+		},
+		PrevKV:  in.PrevKv,
+		Confirm: in.Confirm,
+	}
+
+	kvs, journalID, err := s.clientService.Del(ctx, delRequest)
+	s.auditOp(ctx, "client/remove", "/etcdconsole.v1.Console/Delete", in, kvs, err)
+	if err != nil {
+		return nil, s.statusOf(ctx, err)
+	}
+
+	return &DeleteResponse{
+		PrevKvs: newKeyValues(kvs),
+		Journal: journalID,
+	}, nil
+}
+
+func (s *server) MemberStatus(ctx context.Context, in *MemberStatusRequest) (*MemberStatusResponse, error) {
+	members, err := s.clusterService.GetStatuses(ctx, 0)
+	if err != nil {
+		return nil, s.statusOf(ctx, err)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Name < members[j].Name
+	})
+
+	retResponse := &MemberStatusResponse{
+		Members: make([]*Member, 0, len(members)),
+	}
+	for _, member := range members {
+		retResponse.Members = append(retResponse.Members, &Member{
+			Id:        member.ID,
+			Name:      member.Name,
+			Endpoint:  member.Endpoint,
+			Leader:    member.IsLeader,
+			Health:    member.IsHealth,
+			Connected: member.IsConnected,
+			DbSize:    member.DBSize,
+			Version:   member.Version,
+		})
+	}
+	return retResponse, nil
+}
+
+func (s *server) ListBackups(ctx context.Context, in *ListBackupsRequest) (*ListBackupsResponse, error) {
+	backups, err := s.clusterService.GetBackups(ctx, 0)
+	if err != nil {
+		return nil, s.statusOf(ctx, err)
+	}
+	// the newest first
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreateTime.Unix() > backups[j].CreateTime.Unix()
+	})
+
+	retResponse := &ListBackupsResponse{
+		Backups: make([]*Backup, 0, len(backups)),
+	}
+	for _, backup := range backups {
+		retResponse.Backups = append(retResponse.Backups, newBackup(backup))
+	}
+	return retResponse, nil
+}
+
+func (s *server) CreateBackup(ctx context.Context, in *CreateBackupRequest) (*Backup, error) {
+	backup, err := s.clusterService.NewBackup(ctx, 0)
+	s.auditOp(ctx, "cluster/backup/create", "/etcdconsole.v1.Console/CreateBackup", in, backup, err)
+	if err != nil {
+		return nil, s.statusOf(ctx, err)
+	}
+
+	return newBackup(backup), nil
+}
+
+func (s *server) DeleteBackup(ctx context.Context, in *DeleteBackupRequest) (*DeleteBackupResponse, error) {
+	err := s.clusterService.DelBackup(ctx, in.Name)
+	s.auditOp(ctx, "cluster/backup/remove", "/etcdconsole.v1.Console/DeleteBackup", in, nil, err)
+	if err != nil {
+		return nil, s.statusOf(ctx, err)
+	}
+
+	return &DeleteBackupResponse{}, nil
+}
+
+func (s *server) DownloadBackup(in *DownloadBackupRequest, stream Console_DownloadBackupServer) error {
+	if err := s.clusterService.DownloadBackup(stream.Context(), in.Name, &chunkWriter{stream: stream}); err != nil {
+		return s.statusOf(stream.Context(), err)
+	}
+	return nil
+}
+
+// chunkWriter sends the written bytes in chunks of backupChunkSize at most.
+type chunkWriter struct {
+	stream Console_DownloadBackupServer
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		end := written + backupChunkSize
+		if end > len(p) {
+			end = len(p)
+		}
+		if err := w.stream.Send(&BackupChunk{Data: p[written:end]}); err != nil {
+			return written, err
+		}
+		written = end
+	}
+	return written, nil
+}
+
+// statusOf turns the service error into the gRPC status, the token to confirm a remove is sent in the trailer.
+func (s *server) statusOf(ctx context.Context, err error) error {
+	s.logger.Error(err)
+
+	if confirmationErr, ok := err.(*services.DeleteConfirmationError); ok {
+		grpc.SetTrailer(ctx, metadata.Pairs("confirm-token", confirmationErr.Token))
+	}
+
+	code, ok := grpcCodes[web.ErrorCode(err)]
+	if !ok {
+		code = codes.Unknown
+	}
+	return status.Error(code, err.Error())
+}
+
+// auditOp records a mutating call with its result, the same as web.AuditOp of the routes.
+func (s *server) auditOp(ctx context.Context, op string, fullMethod string, request interface{}, result interface{}, err error) {
+	if s.auditLog == nil {
+		return
+	}
+
+	record := s.auditLog.NewCallRecord(ctx, op, fullMethod, request)
+	record.Status = iris.StatusOK
+	if err != nil {
+		record.Status = web.ErrorStatusCode(err)
+		record.Err = err.Error()
+	} else {
+		record.Result = result
+	}
+
+	if err := s.auditLog.Write(record); err != nil {
+		s.logger.Errorf("cannot write audit log, %v", err)
+	}
+}
+
+func newKeyValues(kvs []datamodels.KeyValue) []*KeyValue {
+	retKeyValues := make([]*KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		retKeyValues = append(retKeyValues, &KeyValue{
+			Key:            kv.Key,
+			Value:          kv.Value,
+			CreateRevision: kv.CreateRevision,
+			ModRevision:    kv.ModRevision,
+			Version:        kv.Version,
+			Lease:          kv.Lease,
+			Encoding:       kv.Encoding,
+			Binary:         kv.Binary,
+		})
+	}
+	return retKeyValues
+}
+
+func newBackup(backup datamodels.Backup) *Backup {
+	return &Backup{
+		Name:       backup.Name,
+		Size:       backup.Size,
+		CreateTime: time.Time(backup.CreateTime).Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,129 @@
+package rpc
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thxcode/etcd-console/backend/audit"
+	"github.com/thxcode/etcd-console/backend/etcdtest"
+	"github.com/thxcode/etcd-console/backend/v1/services"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func codeOf(err error) codes.Code {
+	s, _ := status.FromError(err)
+	return s.Code()
+}
+
+func TestServer(t *testing.T) {
+	e := etcdtest.Start(t)
+	defer e.Close()
+
+	configuration := e.Configuration(t)
+	configuration.DeleteLimit = 1
+	etcdClient := e.NewEtcdClient(t, configuration)
+	defer etcdClient.Close()
+
+	auditDir, err := ioutil.TempDir("", "etcd-console-audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(auditDir)
+	auditLog, err := audit.Open(filepath.Join(auditDir, "audit.log"), 0, 0, "X-Remote-User", map[string]bool{"X-Forwarded-For": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auditLog.Close()
+
+	deps := services.Dependencies{
+		Client:        etcdClient,
+		Configuration: configuration,
+	}
+	grpcServer := grpc.NewServer()
+	RegisterConsoleServer(grpcServer, NewServer(services.NewClientService(deps), services.NewClusterService(deps), auditLog, nil))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := NewConsoleClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("x-remote-user", "alice"))
+
+	putResp, err := client.Put(ctx, &PutRequest{Key: "/a/1", Value: "v1"})
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if putResp.Journal == 0 {
+		t.Errorf("Put() journal = 0, want an entry")
+	}
+	e.Put(t, "/a/2", "v2")
+
+	rangeResp, err := client.Range(ctx, &RangeRequest{Key: "/a/", Prefix: true})
+	if err != nil {
+		t.Fatalf("Range() error = %v", err)
+	}
+	if len(rangeResp.Kvs) != 2 || rangeResp.Kvs[0].Value != "v1" {
+		t.Errorf("Range() = %+v, want /a/1 and /a/2", rangeResp.Kvs)
+	}
+
+	// over the delete limit, the token comes in the trailer
+	var trailer metadata.MD
+	_, err = client.Delete(ctx, &DeleteRequest{Key: "/a/", Prefix: true}, grpc.Trailer(&trailer))
+	if codeOf(err) != codes.FailedPrecondition || len(trailer["confirm-token"]) == 0 {
+		t.Fatalf("Delete() over the limit error = %v with trailer %v, want FailedPrecondition with the token", err, trailer)
+	}
+	if _, err := client.Delete(ctx, &DeleteRequest{Key: "/a/", Prefix: true, Confirm: trailer["confirm-token"][0]}); err != nil {
+		t.Fatalf("Delete() confirmed error = %v", err)
+	}
+	if _, ok := e.Get(t, "/a/1"); ok {
+		t.Errorf("/a/1 is not removed")
+	}
+
+	if _, err := client.DeleteBackup(ctx, &DeleteBackupRequest{Name: "missing.zip"}); codeOf(err) != codes.NotFound {
+		t.Errorf("DeleteBackup() of a missing backup error = %v, want NotFound", err)
+	}
+
+	// the calls are audited from the peer, not from the loopback of a gateway
+	records, _, err := auditLog.Query(audit.Query{Op: "client/write"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("audit records = %+v, want the write", records)
+	}
+	record := records[0]
+	if record.Method != audit.MethodGRPC || record.Path != "/etcdconsole.v1.Console/Put" || record.User != "alice" || record.RemoteAddr != "127.0.0.1" {
+		t.Errorf("audit record = %+v, want the gRPC call of alice from 127.0.0.1", record)
+	}
+
+	// behind a proxy, the client is the first one of the forwarded addresses
+	forwardedCtx := metadata.NewOutgoingContext(ctx, metadata.Pairs("x-forwarded-for", "10.0.0.1, 127.0.0.1"))
+	if _, err := client.Put(forwardedCtx, &PutRequest{Key: "/a/3", Value: "v3"}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	records, _, err = auditLog.Query(audit.Query{Op: "client/write", RemoteAddr: "10.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Errorf("audit records from 10.0.0.1 = %+v, want the forwarded write", records)
+	}
+}
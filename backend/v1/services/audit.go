@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/audit"
+)
+
+type AuditService interface {
+	Query(ctx context.Context, req AuditRequest) ([]audit.Record, bool, error)
+}
+
+type auditService struct {
+	deps Dependencies
+}
+
+func NewAuditService(deps Dependencies) AuditService {
+	return &auditService{
+		deps: deps.withDefaults(),
+	}
+}
+
+func (a *auditService) Query(ctx context.Context, req AuditRequest) ([]audit.Record, bool, error) {
+	auditLog := a.deps.Audit
+	if auditLog == nil {
+		return nil, false, backend.NewNotFoundError("audit log is disabled")
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := audit.Query{
+		Op:         req.Op,
+		User:       req.User,
+		RemoteAddr: req.RemoteAddr,
+		Key:        req.Key,
+		Limit:      limit,
+	}
+	var err error
+	if query.Since, err = parseAuditTime(req.Since); err != nil {
+		return nil, false, backend.NewBadRequestError(fmt.Sprintf(`bad "since", %v`, err))
+	}
+	if query.Until, err = parseAuditTime(req.Until); err != nil {
+		return nil, false, backend.NewBadRequestError(fmt.Sprintf(`bad "until", %v`, err))
+	}
+
+	return auditLog.Query(query)
+}
+
+// parseAuditTime accepts RFC 3339 or unix seconds.
+func parseAuditTime(value string) (time.Time, error) {
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
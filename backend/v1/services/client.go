@@ -1,110 +1,127 @@
 package services
 
 import (
-	"github.com/kataras/iris"
 	"context"
 	"github.com/thxcode/etcd-console/backend/v1/datamodels"
 	"github.com/thxcode/etcd-console/backend"
 	"time"
 
-	"errors"
 	"fmt"
 	v3 "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
 	"strings"
-	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
 	"strconv"
 )
 
 type ClientService interface {
-	Get(ctx context.Context, irisCtx iris.Context) ([]datamodels.KeyValue, error)
-	Set(ctx context.Context, irisCtx iris.Context) ([]datamodels.KeyValue, error)
-	Del(ctx context.Context, irisCtx iris.Context) ([]datamodels.KeyValue, error)
+	Get(ctx context.Context, req GetRequest) (datamodels.KeyValuePage, error)
+	// Set returns the previous key value on demand, and the ID of the journal entry, zero if not journaled.
+	Set(ctx context.Context, req SetRequest) ([]datamodels.KeyValue, int64, error)
+	// Del returns the removed key values on demand, and the ID of the journal entry, zero if not journaled.
+	Del(ctx context.Context, req DelRequest) ([]datamodels.KeyValue, int64, error)
+	DelDryRun(ctx context.Context, req DelRequest) (datamodels.DeletePlan, error)
+	Tree(ctx context.Context, req TreeRequest) (datamodels.KeyTree, error)
+	Export(ctx context.Context, req ExportRequest, w ExportWriter) error
+	Import(ctx context.Context, req ImportRequest) (datamodels.ImportPlan, error)
+	Copy(ctx context.Context, req CopyRequest, move bool) (datamodels.CopyResult, error)
+	History(ctx context.Context, req HistoryRequest) (datamodels.KeyHistory, error)
+	Diff(ctx context.Context, req DiffRequest) (datamodels.KeyDiff, error)
+	Journal(ctx context.Context, req JournalRequest) ([]datamodels.JournalEntry, error)
+	Undo(ctx context.Context, req UndoRequest) (datamodels.UndoResult, error)
 }
 
 type clientService struct {
+	deps                Dependencies
+	undoJournal         *undoJournal
+	deleteConfirmations *deleteConfirmations
 }
 
-func NewClientService() ClientService {
+func NewClientService(deps Dependencies) ClientService {
 	return &clientService{
+		deps:        deps.withDefaults(),
+		undoJournal: &undoJournal{},
+		deleteConfirmations: &deleteConfirmations{
+			tokens: make(map[string]deleteConfirmation),
+		},
 	}
 }
 
-func (c *clientService) Get(ctx context.Context, irisCtx iris.Context) ([]datamodels.KeyValue, error) {
-	etcdClient := irisCtx.Values().Get("etcd-console.client").(*backend.EtcdClient)
+func (c *clientService) Get(ctx context.Context, req GetRequest) (datamodels.KeyValuePage, error) {
+	etcdClient := c.deps.Client
 
-	timeout, err := irisCtx.URLParamInt64Default("timeout", 5)
-	if err != nil {
-		timeout = 5
-	}
-	timeoutCtx, timeoutCancelFn := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, req.Timeout, 5*time.Second)
 	defer timeoutCancelFn()
 
-	var retKeyValues []datamodels.KeyValue
+	var retPage datamodels.KeyValuePage
 
-	version := etcdClient.Version()
+	version, err := etcdClient.Version()
+	if err != nil {
+		return retPage, err
+	}
 	if version.Major() == 2 {
 
-		return nil, errors.New("cannot support v2 now")
+		return retPage, backend.NewUnsupportedError("cannot support v2 now")
 	} else {
-		// prefix: bool
-		// fromKey: bool
-		// key: string
-		// consistency: string
-		// range: string
-		// limit: int64
-		// rev: int64
-		// keysOnly: bool
-		// sortOrder: string
-		// sortTarget: string
-
 		// create opts
-		var opts []v3.OpOption
+		var (
+			opts     []v3.OpOption
+			rangeEnd string
+		)
 
-		prefix, err := irisCtx.URLParamBool("prefix")
-		if err != nil {
-			prefix = false
+		prefix := req.Prefix
+		fromKey := req.FromKey
+
+		if prefix && fromKey {
+			return retPage, backend.NewBadRequestError(`"prefix" and "fromKey" cannot be set at the same time, choose one`)
 		}
 
-		fromKey, err := irisCtx.URLParamBool("fromKey")
+		encoding, err := parseEncoding(req.Encoding)
 		if err != nil {
-			fromKey = false
+			return retPage, err
 		}
 
-		if prefix && fromKey {
-			return nil, errors.New(`"prefix" and "fromKey" cannot be set at the same time, choose one`)
+		key, err := decodeString(req.Key, encoding)
+		if err != nil {
+			return retPage, backend.NewBadRequestError(fmt.Sprintf(`bad "key", expecting %s`, encoding))
 		}
 
-		key := irisCtx.URLParamEscape("key")
+		// decoders render the values, the codecs mapped by the key prefix are used by default
+		codecs := c.deps.Codecs
+		decode, err := parseDecode(req.Decode)
+		if err != nil {
+			return retPage, err
+		}
+		format, err := parseFormat(req.Format)
+		if err != nil {
+			return retPage, err
+		}
 
-		consistency := irisCtx.URLParamDefault("consistency", "l")
+		consistency := req.Consistency
 		switch consistency {
 		case "s":
 			opts = append(opts, v3.WithSerializable())
-		case "l":
+		case "l", "":
 		default:
-			return nil, errors.New(fmt.Sprintf(`unknown "consistency" flag %s`, consistency))
+			return retPage, backend.NewBadRequestError(fmt.Sprintf(`unknown "consistency" flag %s`, consistency))
 		}
 
-		if irisCtx.URLParamExists("range") {
-			opts = append(opts, v3.WithRange(irisCtx.URLParam("range")))
+		if len(req.Range) != 0 {
+			rangeEnd, err = decodeString(req.Range, encoding)
+			if err != nil {
+				return retPage, backend.NewBadRequestError(fmt.Sprintf(`bad "range", expecting %s`, encoding))
+			}
+			opts = append(opts, v3.WithRange(rangeEnd))
 		}
 
-		limit, err := irisCtx.URLParamInt64Default("limit", 0)
-		if err != nil {
-			limit = 0
-		}
-		opts = append(opts, v3.WithLimit(limit))
+		opts = append(opts, v3.WithLimit(req.Limit))
 
-		rev, err := irisCtx.URLParamInt64Default("rev", 0)
-		if err != nil {
-			rev = 0
-		}
+		rev := req.Rev
 		if rev > 0 {
 			opts = append(opts, v3.WithRev(rev))
 		}
 
 		sortOrder := v3.SortNone
-		switch strings.ToUpper(irisCtx.URLParamDefault("sortOrder", "")) {
+		switch strings.ToUpper(req.SortOrder) {
 		case "ASCEND":
 			sortOrder = v3.SortAscend
 		case "DESCEND":
@@ -112,10 +129,10 @@ func (c *clientService) Get(ctx context.Context, irisCtx iris.Context) ([]datamo
 		case "":
 			// nothing
 		default:
-			return nil, errors.New(fmt.Sprintf("bad sort order %v", irisCtx.URLParam("sortOrder")))
+			return retPage, backend.NewBadRequestError(fmt.Sprintf("bad sort order %v", req.SortOrder))
 		}
 		sortTarget := v3.SortByKey
-		switch strings.ToUpper(irisCtx.URLParamDefault("sortTarget", "")) {
+		switch strings.ToUpper(req.SortTarget) {
 		case "CREATE":
 			sortTarget = v3.SortByCreateRevision
 		case "KEY":
@@ -129,15 +146,17 @@ func (c *clientService) Get(ctx context.Context, irisCtx iris.Context) ([]datamo
 		case "":
 			// nothing
 		default:
-			return nil, errors.New(fmt.Sprintf("bad sort target %v", irisCtx.URLParam("sortTarget")))
+			return retPage, backend.NewBadRequestError(fmt.Sprintf("bad sort target %v", req.SortTarget))
 		}
 		opts = append(opts, v3.WithSort(sortTarget, sortOrder))
 
 		if prefix {
 			if len(key) == 0 {
 				key = "\x00"
+				rangeEnd = "\x00"
 				opts = append(opts, v3.WithFromKey())
 			} else {
+				rangeEnd = v3.GetPrefixRangeEnd(key)
 				opts = append(opts, v3.WithPrefix())
 			}
 		}
@@ -146,68 +165,134 @@ func (c *clientService) Get(ctx context.Context, irisCtx iris.Context) ([]datamo
 			if len(key) == 0 {
 				key = "\x00"
 			}
+			rangeEnd = "\x00"
 			opts = append(opts, v3.WithFromKey())
 		}
 
-		keysOnly, err := irisCtx.URLParamBool("keysOnly")
-		if err != nil {
-			keysOnly = false
-		}
+		keysOnly := req.KeysOnly
 		if keysOnly {
 			opts = append(opts, v3.WithKeysOnly())
 		}
 
+		// continue after the last key of the previous page, at its revision
+		if len(req.Cursor) != 0 {
+			cursor, err := decodeReadCursor(req.Cursor)
+			if err != nil {
+				return retPage, err
+			}
+			if len(rangeEnd) == 0 {
+				return retPage, backend.NewBadRequestError(`"cursor" can only be used with "prefix", "fromKey" or "range"`)
+			}
+			if sortTarget != v3.SortByKey {
+				return retPage, backend.NewBadRequestError(`"cursor" can only be used when sorting by key`)
+			}
+
+			if sortOrder == v3.SortDescend {
+				rangeEnd = string(cursor.Key)
+			} else {
+				key = string(cursor.Key) + "\x00"
+			}
+			rev = cursor.Rev
+
+			opts = append(opts, v3.WithRange(rangeEnd), v3.WithRev(rev))
+		}
+
 		client, err := etcdClient.V3()
 		if err != nil {
-			return nil, err
+			return retPage, err
 		}
 		getResp, err := client.Get(timeoutCtx, key, opts...)
 		if err != nil {
-			return nil, err
+			return retPage, err
 		}
 
 		if kvsSize := len(getResp.Kvs); kvsSize != 0 {
-			retKeyValues = make([]datamodels.KeyValue, kvsSize)
+			retPage.KVS = make([]datamodels.KeyValue, kvsSize)
 
 			for idx := range getResp.Kvs {
 				kv := getResp.Kvs[idx]
 
-				retKeyValues[idx] = datamodels.KeyValue{
-					Key:            string(kv.Key),
-					Value:          string(kv.Value),
-					CreateRevision: kv.CreateRevision,
-					ModRevision:    kv.ModRevision,
-					Version:        kv.Version,
-					Lease:          fmt.Sprintf("%x", kv.Lease),
+				retPage.KVS[idx] = newKeyValue(kv, encoding, !keysOnly)
+				if decode != decodeNone && !keysOnly {
+					retPage.KVS[idx].Decoded = decodeValue(kv, decode, format, codecs)
 				}
 			}
 		}
 
+		retPage.More = getResp.More
+		retPage.Count = getResp.Count
+		if getResp.More && len(getResp.Kvs) != 0 && len(rangeEnd) != 0 && sortTarget == v3.SortByKey {
+			// pin the following pages to the revision of the first one
+			if rev <= 0 {
+				rev = getResp.Header.Revision
+			}
+
+			retPage.Cursor = encodeReadCursor(readCursor{
+				Key: getResp.Kvs[len(getResp.Kvs)-1].Key,
+				Rev: rev,
+			})
+		}
+
 	}
 
-	return retKeyValues, nil
+	return retPage, nil
 }
 
-func (c *clientService) Set(ctx context.Context, irisCtx iris.Context) ([]datamodels.KeyValue, error) {
-	etcdClient := irisCtx.Values().Get("etcd-console.client").(*backend.EtcdClient)
+func (c *clientService) Set(ctx context.Context, req SetRequest) ([]datamodels.KeyValue, int64, error) {
+	etcdClient := c.deps.Client
 
-	timeout, err := irisCtx.URLParamInt64Default("timeout", 5)
-	if err != nil {
-		timeout = 5
-	}
-	timeoutCtx, timeoutCancelFn := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, req.Timeout, 5*time.Second)
 	defer timeoutCancelFn()
 
-	var retKeyValues []datamodels.KeyValue
+	var (
+		retKeyValues []datamodels.KeyValue
+		retJournal   int64
+	)
 
-	version := etcdClient.Version()
+	version, err := etcdClient.Version()
+	if err != nil {
+		return nil, 0, err
+	}
 	if version.Major() == 2 {
 
-		return nil, errors.New("cannot support v2 now")
+		return nil, 0, backend.NewUnsupportedError("cannot support v2 now")
 	} else {
-		clientSetRequest := &viewmodels.ClientSetRequest{}
-		if err := irisCtx.ReadJSON(clientSetRequest); err != nil {
-			return nil, err
+		clientSetRequest := &req.ClientSetRequest
+
+		encoding, err := parseEncoding(clientSetRequest.Encoding)
+		if err != nil {
+			return nil, 0, err
+		}
+		key, err := decodeString(clientSetRequest.Key, encoding)
+		if err != nil {
+			return nil, 0, backend.NewBadRequestError(fmt.Sprintf(`bad "key", expecting %s`, encoding))
+		}
+
+		configuration := c.deps.Configuration
+		if err := configuration.CheckKey(key); err != nil {
+			return nil, 0, err
+		}
+		value, err := decodeString(clientSetRequest.Value, encoding)
+		if err != nil {
+			return nil, 0, backend.NewBadRequestError(fmt.Sprintf(`bad "value", expecting %s`, encoding))
+		}
+
+		// nothing reaches etcd if the value violates the schema mapped by the key prefix
+		if !clientSetRequest.IgnoreValue {
+			if err := c.deps.Schemas.Validate([]byte(key), []byte(value)); err != nil {
+				return nil, 0, err
+			}
+		}
+
+		// the value is written through the codec mapped by the key prefix, unless it's raw
+		if !clientSetRequest.Raw && !clientSetRequest.IgnoreValue {
+			if valueCodec, ok := c.deps.Codecs.LookupCodec([]byte(key)); ok {
+				encoded, err := valueCodec.Encode([]byte(key), []byte(value))
+				if err != nil {
+					return nil, 0, backend.NewBadRequestError(fmt.Sprintf("bad value for %s codec, %v", valueCodec.Name(), err))
+				}
+				value = string(encoded)
+			}
 		}
 
 		if len(clientSetRequest.Lease) == 0 || clientSetRequest.Lease == "" {
@@ -215,7 +300,7 @@ func (c *clientService) Set(ctx context.Context, irisCtx iris.Context) ([]datamo
 		}
 		leaseId, err := strconv.ParseInt(clientSetRequest.Lease, 16, 64)
 		if err != nil {
-			return nil, errors.New(fmt.Sprintf("bad lease ID (%v), expecting ID in Hex", clientSetRequest.Lease))
+			return nil, 0, backend.NewBadRequestError(fmt.Sprintf("bad lease ID (%v), expecting ID in Hex", clientSetRequest.Lease))
 		}
 
 		var opts []v3.OpOption
@@ -223,7 +308,8 @@ func (c *clientService) Set(ctx context.Context, irisCtx iris.Context) ([]datamo
 			opts = append(opts, v3.WithLease(v3.LeaseID(leaseId)))
 		}
 
-		if clientSetRequest.PrevKV {
+		// the previous one is always taken for the journal
+		if clientSetRequest.PrevKV || c.journaling() {
 			opts = append(opts, v3.WithPrevKV())
 		}
 
@@ -237,139 +323,385 @@ func (c *clientService) Set(ctx context.Context, irisCtx iris.Context) ([]datamo
 
 		client, err := etcdClient.V3()
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
-		setResp, err := client.Put(timeoutCtx, clientSetRequest.Key, clientSetRequest.Value, opts...)
+		setResp, err := client.Put(timeoutCtx, key, value, opts...)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
-		if clientSetRequest.PrevKV {
-			retKeyValues = make([]datamodels.KeyValue, 1)
+		retJournal = c.journal(journalWrite, []journalKey{{
+			key:      []byte(key),
+			revision: setResp.Header.Revision,
+			prev:     setResp.PrevKv,
+		}})
 
-			respKV := setResp.PrevKv
-			if clientSetRequest.IgnoreValue {
-				retKeyValues[0] = datamodels.KeyValue{
-					Key:            string(respKV.Key),
-					CreateRevision: respKV.CreateRevision,
-					ModRevision:    respKV.ModRevision,
-					Version:        respKV.Version,
-					Lease:          fmt.Sprintf("%x", respKV.Lease),
-				}
-			} else {
-				retKeyValues[0] = datamodels.KeyValue{
-					Key:            string(respKV.Key),
-					Value:          string(respKV.Value),
-					CreateRevision: respKV.CreateRevision,
-					ModRevision:    respKV.ModRevision,
-					Version:        respKV.Version,
-					Lease:          fmt.Sprintf("%x", respKV.Lease),
-				}
+		// no previous one if the key is new
+		if clientSetRequest.PrevKV && setResp.PrevKv != nil {
+			retKeyValues = []datamodels.KeyValue{
+				newKeyValue(setResp.PrevKv, encoding, !clientSetRequest.IgnoreValue),
 			}
 		}
 
 	}
 
-	return retKeyValues, nil
+	return retKeyValues, retJournal, nil
 }
 
-func (c *clientService) Del(ctx context.Context, irisCtx iris.Context) ([]datamodels.KeyValue, error) {
-	etcdClient := irisCtx.Values().Get("etcd-console.client").(*backend.EtcdClient)
+func (c *clientService) Del(ctx context.Context, req DelRequest) ([]datamodels.KeyValue, int64, error) {
+	etcdClient := c.deps.Client
 
-	timeout, err := irisCtx.URLParamInt64Default("timeout", 5)
-	if err != nil {
-		timeout = 5
-	}
-	timeoutCtx, timeoutCancelFn := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, req.Timeout, 5*time.Second)
 	defer timeoutCancelFn()
 
-	var retKeyValues []datamodels.KeyValue
+	var (
+		retKeyValues []datamodels.KeyValue
+		retJournal   int64
+	)
 
-	version := etcdClient.Version()
+	version, err := etcdClient.Version()
+	if err != nil {
+		return nil, 0, err
+	}
 	if version.Major() == 2 {
-		return nil, errors.New("cannot support v2 now")
+		return nil, 0, backend.NewUnsupportedError("cannot support v2 now")
 	} else {
-		// prefix: bool
-		// fromKey: bool
-		// prevKV: bool
-		// range: string
+		key, rangeEnd, encoding, err := parseRemoveRange(req.KeyRange)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		configuration := c.deps.Configuration
+		if err := configuration.CheckRange(key, rangeEnd); err != nil {
+			return nil, 0, err
+		}
 
 		var opts []v3.OpOption
+		if len(rangeEnd) != 0 {
+			opts = append(opts, v3.WithRange(rangeEnd))
+		}
+
+		prevKV := req.PrevKV
+		// the previous ones are always taken for the journal
+		if prevKV || c.journaling() {
+			opts = append(opts, v3.WithPrevKV())
+		}
 
-		prefix, err := irisCtx.URLParamBool("prefix")
+		client, err := etcdClient.V3()
 		if err != nil {
-			prefix = false
+			return nil, 0, err
+		}
+
+		var (
+			prevKVs     []*mvccpb.KeyValue
+			journalKeys []journalKey
+		)
+		if len(rangeEnd) != 0 && configuration.DeleteLimit > 0 {
+			// a range having too many keys is only removed with the token of a preceding count,
+			// and only the keys counted are removed, as they are at the revision of the count
+			countResp, err := client.Get(timeoutCtx, key, v3.WithRange(rangeEnd), v3.WithCountOnly())
+			if err != nil {
+				return nil, 0, err
+			}
+
+			rev, count := countResp.Header.Revision, countResp.Count
+			if count > configuration.DeleteLimit {
+				confirmedRev, ok := c.deleteConfirmations.confirm(req.Confirm, key, rangeEnd)
+				if !ok {
+					token, err := c.deleteConfirmations.issue(key, rangeEnd, rev)
+					if err != nil {
+						return nil, 0, err
+					}
+					return nil, 0, &DeleteConfirmationError{
+						Count: count,
+						Limit: configuration.DeleteLimit,
+						Token: token,
+					}
+				}
+				rev = confirmedRev
+			}
+
+			prevKVs, journalKeys, err = deleteCounted(timeoutCtx, client, key, rangeEnd, rev, prevKV || c.journaling())
+			if err != nil {
+				// the keys removed before the failure can be undone still
+				c.journal(journalRemove, journalKeys)
+				return nil, 0, err
+			}
+		} else {
+			delResp, err := client.Delete(timeoutCtx, key, opts...)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			prevKVs = delResp.PrevKvs
+			for _, prevKv := range delResp.PrevKvs {
+				journalKeys = append(journalKeys, journalKey{
+					key:      prevKv.Key,
+					revision: delResp.Header.Revision,
+					deleted:  true,
+					prev:     prevKv,
+				})
+			}
 		}
+		retJournal = c.journal(journalRemove, journalKeys)
+
+		if prevKV {
+			if prevKVSize := len(prevKVs); prevKVSize != 0 {
+				retKeyValues = make([]datamodels.KeyValue, prevKVSize)
 
-		fromKey, err := irisCtx.URLParamBool("fromKey")
+				for idx := range prevKVs {
+					retKeyValues[idx] = newKeyValue(prevKVs[idx], encoding, true)
+				}
+			}
+		}
+
+	}
+
+	return retKeyValues, retJournal, nil
+}
+
+// DelDryRun counts the keys a remove of the same parameters would delete, with a sample of them on demand.
+// The token to confirm the remove is issued if there are more keys than the delete limit.
+func (c *clientService) DelDryRun(ctx context.Context, req DelRequest) (datamodels.DeletePlan, error) {
+	etcdClient := c.deps.Client
+
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, req.Timeout, 5*time.Second)
+	defer timeoutCancelFn()
+
+	var retPlan datamodels.DeletePlan
+
+	version, err := etcdClient.Version()
+	if err != nil {
+		return retPlan, err
+	}
+	if version.Major() == 2 {
+		return retPlan, backend.NewUnsupportedError("cannot support v2 now")
+	} else {
+		key, rangeEnd, encoding, err := parseRemoveRange(req.KeyRange)
 		if err != nil {
-			fromKey = false
+			return retPlan, err
 		}
 
-		if prefix && fromKey {
-			return nil, errors.New(`"prefix" and "fromKey" cannot be set at the same time, choose one`)
+		// the refusals of the remove are told as well
+		configuration := c.deps.Configuration
+		if err := configuration.CheckRange(key, rangeEnd); err != nil {
+			return retPlan, err
 		}
 
-		key := irisCtx.URLParamEscape("key")
+		sample := req.Sample
+		if sample < 0 {
+			sample = 0
+		}
+		if sample > 1000 {
+			sample = 1000
+		}
 
-		if irisCtx.URLParamExists("range") {
-			opts = append(opts, v3.WithRange(irisCtx.URLParam("range")))
+		client, err := etcdClient.V3()
+		if err != nil {
+			return retPlan, err
 		}
 
-		if prefix {
-			if len(key) == 0 {
-				key = "\x00"
-				opts = append(opts, v3.WithFromKey())
-			} else {
-				opts = append(opts, v3.WithPrefix())
+		var opts []v3.OpOption
+		if len(rangeEnd) != 0 {
+			opts = append(opts, v3.WithRange(rangeEnd))
+		}
+
+		countResp, err := client.Get(timeoutCtx, key, append(opts, v3.WithCountOnly())...)
+		if err != nil {
+			return retPlan, err
+		}
+		retPlan.Revision = countResp.Header.Revision
+		retPlan.Count = countResp.Count
+
+		if sample != 0 && retPlan.Count != 0 {
+			getResp, err := client.Get(timeoutCtx, key, append(opts,
+				v3.WithKeysOnly(),
+				v3.WithLimit(sample),
+				v3.WithRev(retPlan.Revision),
+				v3.WithSort(v3.SortByKey, v3.SortAscend),
+			)...)
+			if err != nil {
+				return retPlan, err
+			}
+
+			retPlan.Keys = make([]datamodels.KeyValue, 0, len(getResp.Kvs))
+			for _, kv := range getResp.Kvs {
+				retPlan.Keys = append(retPlan.Keys, newKeyValue(kv, encoding, false))
 			}
+			retPlan.More = retPlan.Count > int64(len(getResp.Kvs))
 		}
 
-		if fromKey {
-			if len(key) == 0 {
-				key = "\x00"
+		if len(rangeEnd) != 0 && configuration.DeleteLimit > 0 {
+			retPlan.Limit = configuration.DeleteLimit
+			if retPlan.Count > retPlan.Limit {
+				if retPlan.Token, err = c.deleteConfirmations.issue(key, rangeEnd, retPlan.Revision); err != nil {
+					return retPlan, err
+				}
 			}
-			opts = append(opts, v3.WithFromKey())
 		}
+	}
+
+	return retPlan, nil
+}
+
+// parseRemoveRange resolves the key and the range end of a remove, an empty end means the single key,
+// and "\x00" means all keys from the key.
+func parseRemoveRange(keyRange KeyRange) (string, string, string, error) {
+	prefix := keyRange.Prefix
+	fromKey := keyRange.FromKey
+
+	if prefix && fromKey {
+		return "", "", "", backend.NewBadRequestError(`"prefix" and "fromKey" cannot be set at the same time, choose one`)
+	}
+
+	encoding, err := parseEncoding(keyRange.Encoding)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	key, err := decodeString(keyRange.Key, encoding)
+	if err != nil {
+		return "", "", "", backend.NewBadRequestError(fmt.Sprintf(`bad "key", expecting %s`, encoding))
+	}
 
-		prevKV, err := irisCtx.URLParamBool("prevKV")
+	var rangeEnd string
+	if len(keyRange.Range) != 0 {
+		rangeEnd, err = decodeString(keyRange.Range, encoding)
 		if err != nil {
-			prevKV = false
+			return "", "", "", backend.NewBadRequestError(fmt.Sprintf(`bad "range", expecting %s`, encoding))
 		}
-		if prevKV {
-			opts = append(opts, v3.WithPrevKV())
+	}
+
+	if prefix {
+		if len(key) == 0 {
+			key = "\x00"
+			rangeEnd = "\x00"
+		} else {
+			rangeEnd = v3.GetPrefixRangeEnd(key)
+		}
+	}
+
+	if fromKey {
+		if len(key) == 0 {
+			key = "\x00"
+		}
+		rangeEnd = "\x00"
+	}
+
+	return key, rangeEnd, encoding, nil
+}
+
+func (c *clientService) Tree(ctx context.Context, req TreeRequest) (datamodels.KeyTree, error) {
+	etcdClient := c.deps.Client
+
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, req.Timeout, 15*time.Second)
+	defer timeoutCancelFn()
+
+	var retTree datamodels.KeyTree
+
+	version, err := etcdClient.Version()
+	if err != nil {
+		return retTree, err
+	}
+	if version.Major() == 2 {
+		return retTree, backend.NewUnsupportedError("cannot support v2 now")
+	} else {
+		prefix := req.Key
+
+		delimiter := req.Delimiter
+		if len(delimiter) == 0 {
+			delimiter = "/"
+		}
+
+		rev := req.Rev
+		limit := req.Limit
+
+		pageSize := req.PageSize
+		if pageSize <= 0 {
+			pageSize = 1000
 		}
 
 		client, err := etcdClient.V3()
 		if err != nil {
-			return nil, err
+			return retTree, err
 		}
-		delResp, err := client.Delete(timeoutCtx, key, opts...)
-		if err != nil {
-			return nil, err
+
+		retTree.Prefix = prefix
+		retTree.Delimiter = delimiter
+
+		// "\x00" as the range end means all keys from the start
+		start, end := prefix, v3.GetPrefixRangeEnd(prefix)
+		if len(start) == 0 {
+			start = "\x00"
 		}
 
-		if prevKV {
-			if prevKVSize := len(delResp.PrevKvs); prevKVSize != 0 {
-				retKeyValues = make([]datamodels.KeyValue, prevKVSize)
+	pages:
+		for end == "\x00" || start < end {
+			// keys only, the values are never needed here
+			opts := []v3.OpOption{
+				v3.WithRange(end),
+				v3.WithKeysOnly(),
+				v3.WithLimit(pageSize),
+				v3.WithSort(v3.SortByKey, v3.SortAscend),
+			}
+			// pin all pages to the revision of the first one
+			if rev > 0 {
+				opts = append(opts, v3.WithRev(rev))
+			}
+
+			getResp, err := client.Get(timeoutCtx, start, opts...)
+			if err != nil {
+				return retTree, err
+			}
+			if rev <= 0 {
+				rev = getResp.Header.Revision
+			}
+
+			if len(getResp.Kvs) == 0 {
+				break
+			}
 
-				for idx := range delResp.PrevKvs {
-					kv := delResp.PrevKvs[idx]
+			for _, kv := range getResp.Kvs {
+				if limit > 0 && int64(len(retTree.Dirs)+len(retTree.Keys)) >= limit {
+					retTree.More = true
+					break pages
+				}
 
-					retKeyValues[idx] = datamodels.KeyValue{
-						Key:            string(kv.Key),
-						Value:          string(kv.Value),
-						CreateRevision: kv.CreateRevision,
-						ModRevision:    kv.ModRevision,
-						Version:        kv.Version,
-						Lease:          fmt.Sprintf("%x", kv.Lease),
-					}
+				key := string(kv.Key)
+				idx := strings.Index(key[len(prefix):], delimiter)
+				if idx < 0 {
+					retTree.Keys = append(retTree.Keys, newKeyValue(kv, encodingUTF8, false))
+					continue
 				}
+
+				// count the whole directory at once, then skip over it
+				dir := key[:len(prefix)+idx+len(delimiter)]
+				countResp, err := client.Get(timeoutCtx, dir, v3.WithPrefix(), v3.WithCountOnly(), v3.WithRev(rev))
+				if err != nil {
+					return retTree, err
+				}
+				retTree.Dirs = append(retTree.Dirs, datamodels.KeyDir{
+					Key:   dir,
+					Count: countResp.Count,
+				})
+
+				start = v3.GetPrefixRangeEnd(dir)
+				if start == "\x00" {
+					// the directory runs to the end of the key space
+					break pages
+				}
+				continue pages
+			}
+
+			if !getResp.More {
+				break
 			}
+			start = string(getResp.Kvs[len(getResp.Kvs)-1].Key) + "\x00"
 		}
 
+		retTree.Revision = rev
 	}
 
-	return retKeyValues, nil
+	return retTree, nil
 }
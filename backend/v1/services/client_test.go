@@ -0,0 +1,750 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/etcdtest"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+)
+
+// startDependencies starts an etcd for the test, the configuration can be changed by the fn.
+func startDependencies(t *testing.T, fn func(configuration *backend.Configuration)) (*etcdtest.Etcd, Dependencies) {
+	e := etcdtest.Start(t)
+
+	configuration := e.Configuration(t)
+	if fn != nil {
+		fn(&configuration)
+	}
+	etcdClient := e.NewEtcdClient(t, configuration)
+
+	return e, Dependencies{
+		Client:        etcdClient,
+		Configuration: configuration,
+	}
+}
+
+func closeDependencies(e *etcdtest.Etcd, deps Dependencies) {
+	deps.Client.Close()
+	e.Close()
+}
+
+func keysOf(kvs []datamodels.KeyValue) []string {
+	keys := make([]string, 0, len(kvs))
+	for _, kv := range kvs {
+		keys = append(keys, kv.Key)
+	}
+	return keys
+}
+
+func errorCode(err error) string {
+	if apiErr, ok := err.(*backend.APIError); ok {
+		return apiErr.Code
+	}
+	if backend.IsForbidden(err) {
+		return backend.ErrCodeForbidden
+	}
+	return ""
+}
+
+func TestClientGet(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/a/1", "v1",
+		"/a/2", "v2",
+		"/a/3", "v3",
+		"/b/1", "v4",
+		"/c", "v5",
+	)
+	service := NewClientService(deps)
+
+	tests := []struct {
+		name      string
+		req       GetRequest
+		wantKeys  []string
+		wantCount int64
+		wantMore  bool
+	}{
+		{
+			name:      "single key",
+			req:       GetRequest{KeyRange: KeyRange{Key: "/a/1"}},
+			wantKeys:  []string{"/a/1"},
+			wantCount: 1,
+		},
+		{
+			name:     "missing key",
+			req:      GetRequest{KeyRange: KeyRange{Key: "/a"}},
+			wantKeys: []string{},
+		},
+		{
+			name:      "prefix",
+			req:       GetRequest{KeyRange: KeyRange{Key: "/a/", Prefix: true}},
+			wantKeys:  []string{"/a/1", "/a/2", "/a/3"},
+			wantCount: 3,
+		},
+		{
+			name:      "empty prefix is all keys",
+			req:       GetRequest{KeyRange: KeyRange{Prefix: true}},
+			wantKeys:  []string{"/a/1", "/a/2", "/a/3", "/b/1", "/c"},
+			wantCount: 5,
+		},
+		{
+			name:      "from key",
+			req:       GetRequest{KeyRange: KeyRange{Key: "/b", FromKey: true}},
+			wantKeys:  []string{"/b/1", "/c"},
+			wantCount: 2,
+		},
+		{
+			name:      "empty from key is all keys",
+			req:       GetRequest{KeyRange: KeyRange{FromKey: true}},
+			wantKeys:  []string{"/a/1", "/a/2", "/a/3", "/b/1", "/c"},
+			wantCount: 5,
+		},
+		{
+			name:      "range end is exclusive",
+			req:       GetRequest{KeyRange: KeyRange{Key: "/a/2", Range: "/b/1"}},
+			wantKeys:  []string{"/a/2", "/a/3"},
+			wantCount: 2,
+		},
+		{
+			name:      "limit",
+			req:       GetRequest{KeyRange: KeyRange{Key: "/a/", Prefix: true}, Limit: 2},
+			wantKeys:  []string{"/a/1", "/a/2"},
+			wantCount: 3,
+			wantMore:  true,
+		},
+		{
+			name:      "sort descend",
+			req:       GetRequest{KeyRange: KeyRange{Key: "/a/", Prefix: true}, SortOrder: "descend"},
+			wantKeys:  []string{"/a/3", "/a/2", "/a/1"},
+			wantCount: 3,
+		},
+		{
+			name:      "sort by value",
+			req:       GetRequest{KeyRange: KeyRange{FromKey: true}, SortTarget: "VALUE", SortOrder: "DESCEND", Limit: 1},
+			wantKeys:  []string{"/c"},
+			wantCount: 5,
+			wantMore:  true,
+		},
+		{
+			name:      "serializable",
+			req:       GetRequest{KeyRange: KeyRange{Key: "/c"}, Consistency: "s"},
+			wantKeys:  []string{"/c"},
+			wantCount: 1,
+		},
+		{
+			name:      "base64 key",
+			req:       GetRequest{KeyRange: KeyRange{Key: base64.StdEncoding.EncodeToString([]byte("/c")), Encoding: "base64"}},
+			wantKeys:  []string{base64.StdEncoding.EncodeToString([]byte("/c"))},
+			wantCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := service.Get(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got := keysOf(page.KVS); !reflect.DeepEqual(got, tt.wantKeys) {
+				t.Errorf("Get() keys = %q, want %q", got, tt.wantKeys)
+			}
+			if page.Count != tt.wantCount {
+				t.Errorf("Get() count = %d, want %d", page.Count, tt.wantCount)
+			}
+			if page.More != tt.wantMore {
+				t.Errorf("Get() more = %v, want %v", page.More, tt.wantMore)
+			}
+		})
+	}
+}
+
+func TestClientGetValues(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	rev := e.Put(t, "/k", "old")
+	e.Put(t, "/k", "new")
+	service := NewClientService(deps)
+
+	page, err := service.Get(context.Background(), GetRequest{KeyRange: KeyRange{Key: "/k"}})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(page.KVS) != 1 || page.KVS[0].Value != "new" || page.KVS[0].Version != 2 {
+		t.Errorf("Get() = %+v, want the value new at version 2", page.KVS)
+	}
+
+	page, err = service.Get(context.Background(), GetRequest{KeyRange: KeyRange{Key: "/k"}, Rev: rev})
+	if err != nil {
+		t.Fatalf("Get() at the revision error = %v", err)
+	}
+	if len(page.KVS) != 1 || page.KVS[0].Value != "old" {
+		t.Errorf("Get() at the revision = %+v, want the value old", page.KVS)
+	}
+
+	page, err = service.Get(context.Background(), GetRequest{KeyRange: KeyRange{Key: "/k"}, KeysOnly: true})
+	if err != nil {
+		t.Fatalf("Get() keys only error = %v", err)
+	}
+	if len(page.KVS) != 1 || page.KVS[0].Value != "" {
+		t.Errorf("Get() keys only = %+v, want no value", page.KVS)
+	}
+}
+
+func TestClientGetEncodings(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	if _, err := e.Client.Put(context.Background(), "/bin", "\xff\x00"); err != nil {
+		t.Fatal(err)
+	}
+	service := NewClientService(deps)
+
+	// utf8 falls back to base64 for the binary values
+	page, err := service.Get(context.Background(), GetRequest{KeyRange: KeyRange{Key: "/bin"}})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	want := datamodels.KeyValue{
+		Key:      base64.StdEncoding.EncodeToString([]byte("/bin")),
+		Value:    base64.StdEncoding.EncodeToString([]byte("\xff\x00")),
+		Encoding: "base64",
+		Binary:   true,
+	}
+	if len(page.KVS) != 1 || page.KVS[0].Key != want.Key || page.KVS[0].Value != want.Value || page.KVS[0].Encoding != want.Encoding || !page.KVS[0].Binary {
+		t.Errorf("Get() of the binary value = %+v, want %+v", page.KVS, want)
+	}
+
+	// the key of the request and the key values of the response are in hex
+	page, err = service.Get(context.Background(), GetRequest{KeyRange: KeyRange{Key: "2f62696e", Encoding: "HEX"}})
+	if err != nil {
+		t.Fatalf("Get() in hex error = %v", err)
+	}
+	if len(page.KVS) != 1 || page.KVS[0].Key != "2f62696e" || page.KVS[0].Value != "ff00" || page.KVS[0].Encoding != "hex" {
+		t.Errorf("Get() in hex = %+v, want /bin of ff00", page.KVS)
+	}
+
+	// the keys only are not binary by the values
+	page, err = service.Get(context.Background(), GetRequest{KeyRange: KeyRange{Key: "/bin"}, KeysOnly: true})
+	if err != nil {
+		t.Fatalf("Get() keys only error = %v", err)
+	}
+	if len(page.KVS) != 1 || page.KVS[0].Key != "/bin" || page.KVS[0].Binary {
+		t.Errorf("Get() keys only = %+v, want /bin in utf8", page.KVS)
+	}
+
+	if _, _, err := service.Set(context.Background(), SetRequest{
+		ClientSetRequest: viewmodels.ClientSetRequest{Key: "L2I2NA==", Value: "AQI=", Encoding: "base64"},
+	}); err != nil {
+		t.Fatalf("Set() in base64 error = %v", err)
+	}
+	if value, ok := e.Get(t, "/b64"); !ok || value != "\x01\x02" {
+		t.Errorf("Set() in base64 wrote %q, %v, want 0102 to /b64", value, ok)
+	}
+
+	if _, _, err := service.Set(context.Background(), SetRequest{
+		ClientSetRequest: viewmodels.ClientSetRequest{Key: "2f68", Value: "zz", Encoding: "hex"},
+	}); errorCode(err) != backend.ErrCodeBadRequest {
+		t.Errorf("Set() of a bad hex value error = %v, want %s", err, backend.ErrCodeBadRequest)
+	}
+}
+
+func TestClientGetPages(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/p/1", "1",
+		"/p/2", "2",
+		"/p/3", "3",
+		"/p/4", "4",
+		"/p/5", "5",
+	)
+	service := NewClientService(deps)
+
+	req := GetRequest{KeyRange: KeyRange{Key: "/p/", Prefix: true}, Limit: 2}
+	first, err := service.Get(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !first.More || len(first.Cursor) == 0 || first.Count != 5 {
+		t.Fatalf("Get() = %+v, want more of 5 keys with a cursor", first)
+	}
+
+	// the following pages are pinned to the revision of the first one
+	e.Put(t, "/p/0", "0", "/p/6", "6")
+
+	var keys []string
+	keys = append(keys, keysOf(first.KVS)...)
+	for cursor := first.Cursor; len(cursor) != 0; {
+		req.Cursor = cursor
+		page, err := service.Get(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Get() the cursor %s error = %v", cursor, err)
+		}
+		keys = append(keys, keysOf(page.KVS)...)
+		cursor = page.Cursor
+	}
+
+	want := []string{"/p/1", "/p/2", "/p/3", "/p/4", "/p/5"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("Get() pages = %q, want %q", keys, want)
+	}
+}
+
+func TestClientGetErrors(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	service := NewClientService(deps)
+
+	tests := []struct {
+		name string
+		req  GetRequest
+	}{
+		{"prefix and from key", GetRequest{KeyRange: KeyRange{Key: "/a", Prefix: true, FromKey: true}}},
+		{"unknown encoding", GetRequest{KeyRange: KeyRange{Key: "/a", Encoding: "utf16"}}},
+		{"bad base64 key", GetRequest{KeyRange: KeyRange{Key: "!", Encoding: "base64"}}},
+		{"bad hex range", GetRequest{KeyRange: KeyRange{Key: "00", Range: "zz", Encoding: "hex"}}},
+		{"unknown consistency", GetRequest{KeyRange: KeyRange{Key: "/a"}, Consistency: "x"}},
+		{"bad sort order", GetRequest{KeyRange: KeyRange{Key: "/a"}, SortOrder: "up"}},
+		{"bad sort target", GetRequest{KeyRange: KeyRange{Key: "/a"}, SortTarget: "size"}},
+		{"bad cursor", GetRequest{KeyRange: KeyRange{Key: "/a", Prefix: true}, Cursor: "!"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Get(context.Background(), tt.req)
+			if code := errorCode(err); code != backend.ErrCodeBadRequest {
+				t.Errorf("Get() error = %v (%s), want %s", err, code, backend.ErrCodeBadRequest)
+			}
+		})
+	}
+}
+
+func TestClientTree(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/registry/pods/a", "1",
+		"/registry/pods/b", "2",
+		"/registry/services/s", "3",
+		"/registry/top", "4",
+		"/other", "5",
+		"x:a:1", "6",
+		"x:a:2", "7",
+		"x:b", "8",
+	)
+	service := NewClientService(deps)
+
+	tree, err := service.Tree(context.Background(), TreeRequest{Key: "/registry/"})
+	if err != nil {
+		t.Fatalf("Tree() error = %v", err)
+	}
+	wantDirs := []datamodels.KeyDir{{Key: "/registry/pods/", Count: 2}, {Key: "/registry/services/", Count: 1}}
+	if !reflect.DeepEqual(tree.Dirs, wantDirs) || !reflect.DeepEqual(keysOf(tree.Keys), []string{"/registry/top"}) {
+		t.Errorf("Tree() = %+v, %q, want the dirs of pods and services with the key top", tree.Dirs, keysOf(tree.Keys))
+	}
+	if tree.Delimiter != "/" || tree.More || tree.Revision == 0 {
+		t.Errorf("Tree() delimiter, more, revision = %q, %v, %d, want / without more at a revision", tree.Delimiter, tree.More, tree.Revision)
+	}
+	if len(tree.Keys[0].Value) != 0 {
+		t.Errorf("Tree() key value = %q, want the keys only", tree.Keys[0].Value)
+	}
+
+	// the pages of a single key run into the dirs in the same way
+	paged, err := service.Tree(context.Background(), TreeRequest{Key: "/registry/", PageSize: 1})
+	if err != nil {
+		t.Fatalf("Tree() of pages error = %v", err)
+	}
+	if !reflect.DeepEqual(paged.Dirs, wantDirs) || !reflect.DeepEqual(keysOf(paged.Keys), []string{"/registry/top"}) {
+		t.Errorf("Tree() of pages = %+v, %q, want the same level", paged.Dirs, keysOf(paged.Keys))
+	}
+
+	// the whole key space
+	root, err := service.Tree(context.Background(), TreeRequest{Key: "/"})
+	if err != nil {
+		t.Fatalf("Tree() of / error = %v", err)
+	}
+	if !reflect.DeepEqual(root.Dirs, []datamodels.KeyDir{{Key: "/registry/", Count: 4}}) || !reflect.DeepEqual(keysOf(root.Keys), []string{"/other"}) {
+		t.Errorf("Tree() of / = %+v, %q, want the dir of registry with the key other", root.Dirs, keysOf(root.Keys))
+	}
+
+	limited, err := service.Tree(context.Background(), TreeRequest{Key: "/registry/", Limit: 1})
+	if err != nil {
+		t.Fatalf("Tree() of the limit error = %v", err)
+	}
+	if len(limited.Dirs)+len(limited.Keys) != 1 || !limited.More {
+		t.Errorf("Tree() of the limit 1 = %+v, %q, want an entry and more", limited.Dirs, keysOf(limited.Keys))
+	}
+
+	delimited, err := service.Tree(context.Background(), TreeRequest{Key: "x:", Delimiter: ":"})
+	if err != nil {
+		t.Fatalf("Tree() of the delimiter error = %v", err)
+	}
+	if !reflect.DeepEqual(delimited.Dirs, []datamodels.KeyDir{{Key: "x:a:", Count: 2}}) || !reflect.DeepEqual(keysOf(delimited.Keys), []string{"x:b"}) {
+		t.Errorf("Tree() of the delimiter : = %+v, %q, want the dir x:a: with the key x:b", delimited.Dirs, keysOf(delimited.Keys))
+	}
+
+	// the revision pins the level
+	e.Put(t, "/registry/new", "9")
+	pinned, err := service.Tree(context.Background(), TreeRequest{Key: "/registry/", Rev: tree.Revision})
+	if err != nil {
+		t.Fatalf("Tree() at the revision error = %v", err)
+	}
+	if pinned.Revision != tree.Revision || !reflect.DeepEqual(keysOf(pinned.Keys), []string{"/registry/top"}) {
+		t.Errorf("Tree() at the revision %d = %d, %q, want the key top only", tree.Revision, pinned.Revision, keysOf(pinned.Keys))
+	}
+}
+
+func TestClientSet(t *testing.T) {
+	e, deps := startDependencies(t, func(configuration *backend.Configuration) {
+		configuration.ProtectedPrefixes = []string{"/protected/"}
+	})
+	defer closeDependencies(e, deps)
+
+	service := NewClientService(deps)
+
+	kvs, journalID, err := service.Set(context.Background(), SetRequest{
+		ClientSetRequest: viewmodels.ClientSetRequest{Key: "/k", Value: "v1"},
+	})
+	if err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if len(kvs) != 0 {
+		t.Errorf("Set() = %+v, want no previous key value", kvs)
+	}
+	if journalID == 0 {
+		t.Errorf("Set() journal ID = 0, want the ID of the entry")
+	}
+
+	kvs, _, err = service.Set(context.Background(), SetRequest{
+		ClientSetRequest: viewmodels.ClientSetRequest{Key: "/k", Value: "v2", PrevKV: true},
+	})
+	if err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if len(kvs) != 1 || kvs[0].Value != "v1" {
+		t.Errorf("Set() = %+v, want the previous value v1", kvs)
+	}
+	if value, _ := e.Get(t, "/k"); value != "v2" {
+		t.Errorf("the value = %q, want v2", value)
+	}
+
+	tests := []struct {
+		name string
+		req  viewmodels.ClientSetRequest
+		code string
+	}{
+		{"protected key", viewmodels.ClientSetRequest{Key: "/protected/k", Value: "v"}, backend.ErrCodeForbidden},
+		{"bad lease", viewmodels.ClientSetRequest{Key: "/k", Value: "v", Lease: "xyz"}, backend.ErrCodeBadRequest},
+		{"bad hex value", viewmodels.ClientSetRequest{Key: "00", Value: "zz", Encoding: "hex"}, backend.ErrCodeBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := service.Set(context.Background(), SetRequest{ClientSetRequest: tt.req})
+			if code := errorCode(err); code != tt.code {
+				t.Errorf("Set() error = %v (%s), want %s", err, code, tt.code)
+			}
+		})
+	}
+}
+
+func TestClientSetReadOnly(t *testing.T) {
+	e, deps := startDependencies(t, func(configuration *backend.Configuration) {
+		configuration.ReadOnly = true
+	})
+	defer closeDependencies(e, deps)
+
+	service := NewClientService(deps)
+
+	_, _, err := service.Set(context.Background(), SetRequest{
+		ClientSetRequest: viewmodels.ClientSetRequest{Key: "/k", Value: "v"},
+	})
+	if !backend.IsForbidden(err) {
+		t.Errorf("Set() error = %v, want forbidden", err)
+	}
+	if _, ok := e.Get(t, "/k"); ok {
+		t.Errorf("the key is written in read-only mode")
+	}
+}
+
+func TestClientDel(t *testing.T) {
+	e, deps := startDependencies(t, func(configuration *backend.Configuration) {
+		configuration.DeleteLimit = 2
+	})
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/a/1", "1",
+		"/a/2", "2",
+		"/a/3", "3",
+		"/b/1", "4",
+	)
+	service := NewClientService(deps)
+
+	kvs, _, err := service.Del(context.Background(), DelRequest{KeyRange: KeyRange{Key: "/b/1"}, PrevKV: true})
+	if err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	if got := keysOf(kvs); !reflect.DeepEqual(got, []string{"/b/1"}) {
+		t.Errorf("Del() = %q, want the removed /b/1", got)
+	}
+
+	// the prefix has more keys than the delete limit
+	req := DelRequest{KeyRange: KeyRange{Key: "/a/", Prefix: true}}
+	_, _, err = service.Del(context.Background(), req)
+	confirmationErr, ok := err.(*DeleteConfirmationError)
+	if !ok {
+		t.Fatalf("Del() error = %v, want a confirmation", err)
+	}
+	if confirmationErr.Count != 3 || confirmationErr.Limit != 2 {
+		t.Errorf("Del() confirmation = %+v, want 3 keys over the limit 2", confirmationErr)
+	}
+	if _, ok := e.Get(t, "/a/1"); !ok {
+		t.Fatalf("the keys are removed without the confirmation")
+	}
+
+	// the token removes the keys counted, not the ones created since
+	e.Put(t, "/a/4", "5")
+	req.Confirm = confirmationErr.Token
+	kvs, _, err = service.Del(context.Background(), DelRequest{KeyRange: req.KeyRange, PrevKV: true, Confirm: req.Confirm})
+	if err != nil {
+		t.Fatalf("Del() with the token error = %v", err)
+	}
+	if got := keysOf(kvs); !reflect.DeepEqual(got, []string{"/a/1", "/a/2", "/a/3"}) {
+		t.Errorf("Del() with the token = %q, want the keys counted", got)
+	}
+	for _, key := range []string{"/a/1", "/a/2", "/a/3"} {
+		if _, ok := e.Get(t, key); ok {
+			t.Errorf("the key %s is not removed", key)
+		}
+	}
+	if _, ok := e.Get(t, "/a/4"); !ok {
+		t.Errorf("the key /a/4 created after the count is removed")
+	}
+
+	if _, _, err := service.Del(context.Background(), DelRequest{KeyRange: KeyRange{Key: "/a", Prefix: true, FromKey: true}}); errorCode(err) != backend.ErrCodeBadRequest {
+		t.Errorf("Del() of prefix and from key error = %v, want %s", err, backend.ErrCodeBadRequest)
+	}
+}
+
+func TestClientDelDryRun(t *testing.T) {
+	e, deps := startDependencies(t, func(configuration *backend.Configuration) {
+		configuration.DeleteLimit = 2
+	})
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/a/1", "1",
+		"/a/2", "2",
+		"/a/3", "3",
+	)
+	service := NewClientService(deps)
+
+	plan, err := service.DelDryRun(context.Background(), DelRequest{KeyRange: KeyRange{Key: "/a/", Prefix: true}, Sample: 2})
+	if err != nil {
+		t.Fatalf("DelDryRun() error = %v", err)
+	}
+	if plan.Count != 3 || !plan.More || len(plan.Token) == 0 {
+		t.Errorf("DelDryRun() = %+v, want 3 keys, more of them and a token", plan)
+	}
+	if got := keysOf(plan.Keys); !reflect.DeepEqual(got, []string{"/a/1", "/a/2"}) {
+		t.Errorf("DelDryRun() sample = %q, want the first two keys", got)
+	}
+	if _, ok := e.Get(t, "/a/1"); !ok {
+		t.Errorf("the keys are removed on dry run")
+	}
+
+	// the token of the dry run does not remove the keys changed since the count
+	e.Put(t, "/a/2", "changed")
+	if _, _, err := service.Del(context.Background(), DelRequest{KeyRange: KeyRange{Key: "/a/", Prefix: true}, Confirm: plan.Token}); errorCode(err) != backend.ErrCodeConflict {
+		t.Errorf("Del() of the changed keys error = %v, want %s", err, backend.ErrCodeConflict)
+	}
+	if _, ok := e.Get(t, "/a/1"); !ok {
+		t.Errorf("the keys are removed along with the changed ones")
+	}
+
+	// the token of the dry run confirms the remove
+	plan, err = service.DelDryRun(context.Background(), DelRequest{KeyRange: KeyRange{Key: "/a/", Prefix: true}})
+	if err != nil {
+		t.Fatalf("DelDryRun() error = %v", err)
+	}
+	if _, _, err := service.Del(context.Background(), DelRequest{KeyRange: KeyRange{Key: "/a/", Prefix: true}, Confirm: plan.Token}); err != nil {
+		t.Errorf("Del() with the token of the dry run error = %v", err)
+	}
+}
+
+func TestClientDelDryRunLimits(t *testing.T) {
+	e, deps := startDependencies(t, func(configuration *backend.Configuration) {
+		configuration.DeleteLimit = 2
+		configuration.ProtectedPrefixes = []string{"/protected/"}
+	})
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/a/1", "1",
+		"/a/2", "2",
+		"/protected/1", "3",
+	)
+	service := NewClientService(deps)
+	ctx := context.Background()
+
+	// the range within the limit needs no token
+	plan, err := service.DelDryRun(ctx, DelRequest{KeyRange: KeyRange{Key: "/a/", Prefix: true}, Sample: 10})
+	if err != nil {
+		t.Fatalf("DelDryRun() error = %v", err)
+	}
+	if plan.Count != 2 || plan.More || plan.Limit != 2 || len(plan.Token) != 0 || len(plan.Keys) != 2 {
+		t.Errorf("DelDryRun() within the limit = %+v, want 2 keys without a token", plan)
+	}
+
+	// the single key is not limited
+	plan, err = service.DelDryRun(ctx, DelRequest{KeyRange: KeyRange{Key: "/a/1"}})
+	if err != nil {
+		t.Fatalf("DelDryRun() error = %v", err)
+	}
+	if plan.Count != 1 || plan.Limit != 0 || len(plan.Token) != 0 || len(plan.Keys) != 0 {
+		t.Errorf("DelDryRun() of a single key = %+v, want counted without the sample and the limit", plan)
+	}
+
+	// the sample is in the encoding of the request
+	plan, err = service.DelDryRun(ctx, DelRequest{KeyRange: KeyRange{Key: "2f612f", Prefix: true, Encoding: "hex"}, Sample: 1})
+	if err != nil {
+		t.Fatalf("DelDryRun() error = %v", err)
+	}
+	if got := keysOf(plan.Keys); !reflect.DeepEqual(got, []string{"2f612f31"}) || !plan.More {
+		t.Errorf("DelDryRun() sample in hex = %q, %+v, want 2f612f31 and more", got, plan)
+	}
+
+	// the refusals are told by the dry run
+	if _, err := service.DelDryRun(ctx, DelRequest{KeyRange: KeyRange{Key: "/protected/", Prefix: true}}); !backend.IsForbidden(err) {
+		t.Errorf("DelDryRun() of a protected range error = %v, want forbidden", err)
+	}
+	if _, err := service.DelDryRun(ctx, DelRequest{KeyRange: KeyRange{Key: "/a/", Prefix: true, FromKey: true}}); errorCode(err) != backend.ErrCodeBadRequest {
+		t.Errorf("DelDryRun() of prefix and fromKey error = %v, want bad request", err)
+	}
+}
+
+func TestClientUndo(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	e.Put(t, "/k", "v1")
+	service := NewClientService(deps)
+
+	_, journalID, err := service.Set(context.Background(), SetRequest{
+		ClientSetRequest: viewmodels.ClientSetRequest{Key: "/k", Value: "v2"},
+	})
+	if err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	entries, err := service.Journal(context.Background(), JournalRequest{})
+	if err != nil {
+		t.Fatalf("Journal() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].ID != journalID {
+		t.Fatalf("Journal() = %+v, want the entry %d", entries, journalID)
+	}
+
+	if _, err := service.Undo(context.Background(), UndoRequest{ID: journalID}); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if value, _ := e.Get(t, "/k"); value != "v1" {
+		t.Errorf("the value after the undo = %q, want v1", value)
+	}
+
+	if _, err := service.Undo(context.Background(), UndoRequest{ID: journalID + 100}); errorCode(err) != backend.ErrCodeNotFound {
+		t.Errorf("Undo() of an unknown entry error = %v, want %s", err, backend.ErrCodeNotFound)
+	}
+}
+
+func TestClientUndoImport(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/i/1", "old",
+		"/i/2", "v2",
+	)
+	service := NewClientService(deps)
+
+	// /i/1 is updated, /i/2 is pruned and /i/3 is created
+	plan, err := service.Import(context.Background(), ImportRequest{
+		Key:   "/i/",
+		Prune: true,
+		Data:  []byte(`{"key": "/i/1", "value": "new"}` + "\n" + `{"key": "/i/3", "value": "v3"}`),
+		Name:  "keys.jsonl",
+	})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if plan.Applied != 3 || plan.Journal == 0 {
+		t.Fatalf("Import() = %+v, want 3 keys applied and journaled", plan)
+	}
+
+	result, err := service.Undo(context.Background(), UndoRequest{ID: plan.Journal})
+	if err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if result.Restored != 3 || len(result.Conflicts) != 0 {
+		t.Errorf("Undo() = %+v, want 3 keys restored", result)
+	}
+	for key, want := range map[string]string{"/i/1": "old", "/i/2": "v2"} {
+		if value, _ := e.Get(t, key); value != want {
+			t.Errorf("%s after the undo = %q, want %q", key, value, want)
+		}
+	}
+	if _, ok := e.Get(t, "/i/3"); ok {
+		t.Errorf("/i/3 is kept after the undo")
+	}
+}
+
+func TestClientUndoMove(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/from/1", "v1",
+		"/from/2", "v2",
+		"/to/2", "old",
+	)
+	service := NewClientService(deps)
+
+	result, err := service.Copy(context.Background(), CopyRequest{
+		ClientCopyRequest: viewmodels.ClientCopyRequest{From: "/from/", To: "/to/", Overwrite: true},
+	}, true)
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if result.Copied != 2 || result.Journal == 0 {
+		t.Fatalf("Copy() = %+v, want 2 keys moved and journaled", result)
+	}
+
+	entries, err := service.Journal(context.Background(), JournalRequest{})
+	if err != nil {
+		t.Fatalf("Journal() error = %v", err)
+	}
+	if entries[0].Op != journalMove || len(entries[0].Keys) != 4 {
+		t.Fatalf("Journal() = %+v, want the move of 2 destinations and 2 sources", entries[0])
+	}
+
+	if _, err := service.Undo(context.Background(), UndoRequest{ID: result.Journal}); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	for key, want := range map[string]string{"/from/1": "v1", "/from/2": "v2", "/to/2": "old"} {
+		if value, _ := e.Get(t, key); value != want {
+			t.Errorf("%s after the undo = %q, want %q", key, value, want)
+		}
+	}
+	if _, ok := e.Get(t, "/to/1"); ok {
+		t.Errorf("/to/1 is kept after the undo")
+	}
+}
@@ -3,12 +3,12 @@ package services
 import (
 	"github.com/thxcode/etcd-console/backend/v1/datamodels"
 	"github.com/thxcode/etcd-console/backend"
-	"github.com/kataras/iris"
 	sv2 "github.com/Masterminds/semver"
 	v3 "github.com/coreos/etcd/clientv3"
 	"context"
 	"time"
 	"fmt"
+	"sort"
 	"sync"
 	"github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
 	"errors"
@@ -21,44 +21,50 @@ import (
 	"crypto/sha1"
 )
 
+// ClusterService takes the zero timeouts as the defaults of the ops.
 type ClusterService interface {
-	GetVersion(ctx context.Context, irisCtx iris.Context) *sv2.Version
-	GetStatuses(ctx context.Context, irisCtx iris.Context) ([]datamodels.MemberStatus, error)
-	GetBackups(ctx context.Context, irisCtx iris.Context) ([]datamodels.Backup, error)
-	NewBackup(ctx context.Context, irisCtx iris.Context) (datamodels.Backup, error)
-	DelBackup(ctx context.Context, irisCtx iris.Context) error
-	DownloadBackup(ctx context.Context, irisCtx iris.Context) error
+	GetVersion(ctx context.Context) (*sv2.Version, error)
+	GetFeatures(ctx context.Context) (backend.EtcdFeatures, error)
+	GetStatuses(ctx context.Context, timeout time.Duration) ([]datamodels.MemberStatus, error)
+	GetBackups(ctx context.Context, timeout time.Duration) ([]datamodels.Backup, error)
+	NewBackup(ctx context.Context, timeout time.Duration) (datamodels.Backup, error)
+	DelBackup(ctx context.Context, name string) error
+	// DownloadBackup writes the backup zip of the name to the w.
+	DownloadBackup(ctx context.Context, name string, w io.Writer) error
 }
 
 type clusterService struct {
+	deps Dependencies
 }
 
-func NewClusterService() ClusterService {
+func NewClusterService(deps Dependencies) ClusterService {
 	return &clusterService{
+		deps: deps.withDefaults(),
 	}
 }
 
-func (c *clusterService) GetVersion(ctx context.Context, irisCtx iris.Context) *sv2.Version {
-	etcdClient := irisCtx.Values().Get("etcd-console.client").(*backend.EtcdClient)
+func (c *clusterService) GetVersion(ctx context.Context) (*sv2.Version, error) {
+	return c.deps.Client.Version()
+}
 
-	return etcdClient.Version()
+func (c *clusterService) GetFeatures(ctx context.Context) (backend.EtcdFeatures, error) {
+	return c.deps.Client.Features()
 }
 
-func (c *clusterService) GetStatuses(ctx context.Context, irisCtx iris.Context) ([]datamodels.MemberStatus, error) {
-	etcdClient := irisCtx.Values().Get("etcd-console.client").(*backend.EtcdClient)
+func (c *clusterService) GetStatuses(ctx context.Context, timeout time.Duration) ([]datamodels.MemberStatus, error) {
+	etcdClient := c.deps.Client
 
-	timeout, err := irisCtx.URLParamInt64Default("timeout", 5)
-	if err != nil {
-		timeout = 5
-	}
-	timeoutCtx, timeoutCancelFn := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, timeout, 5*time.Second)
 	defer timeoutCancelFn()
 
 	var retMemberStatuses []datamodels.MemberStatus
 
-	version := etcdClient.Version()
+	version, err := etcdClient.Version()
+	if err != nil {
+		return nil, err
+	}
 	if version.Major() == 2 {
-		return nil, errors.New("cannot support v2 now")
+		return nil, backend.NewUnsupportedError("cannot support v2 now")
 	} else {
 		client, err := etcdClient.V3()
 		if err != nil {
@@ -94,17 +100,7 @@ func (c *clusterService) GetStatuses(ctx context.Context, irisCtx iris.Context)
 						memberStatus.IsConnected = true
 						memberStatus.Version = statusRep.Version
 						memberStatus.DBSize = statusRep.DbSize
-
-						epClient, err := v3.New(v3.Config{
-							Endpoints:   []string{memberEndpoint},
-							DialTimeout: 5 * time.Second,
-						})
-						if err == nil {
-							_, err = epClient.Get(timeoutCtx, "health")
-							if err == nil || err == rpctypes.ErrPermissionDenied {
-								memberStatus.IsHealth = true
-							}
-						}
+						memberStatus.IsHealth = isMemberHealthy(timeoutCtx, memberEndpoint)
 					}
 
 					memberStatusChan <- memberStatus
@@ -124,15 +120,27 @@ func (c *clusterService) GetStatuses(ctx context.Context, irisCtx iris.Context)
 	return retMemberStatuses, nil
 }
 
-func (c *clusterService) GetBackups(ctx context.Context, irisCtx iris.Context) ([]datamodels.Backup, error) {
-	etcdClient := irisCtx.Values().Get("etcd-console.client").(*backend.EtcdClient)
-	configuration := irisCtx.Values().Get("etcd-console.config").(backend.Configuration)
-
-	timeout, err := irisCtx.URLParamInt64Default("timeout", 15)
+// isMemberHealthy reads from the member alone, like "etcdctl endpoint health",
+// the client of the member is closed on return, or every status poll would leak its connection.
+func isMemberHealthy(ctx context.Context, memberEndpoint string) bool {
+	epClient, err := v3.New(v3.Config{
+		Endpoints:   []string{memberEndpoint},
+		DialTimeout: 5 * time.Second,
+	})
 	if err != nil {
-		timeout = 15
+		return false
 	}
-	timeoutCtx, timeoutCancelFn := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer epClient.Close()
+
+	_, err = epClient.Get(ctx, "health")
+	return err == nil || err == rpctypes.ErrPermissionDenied
+}
+
+func (c *clusterService) GetBackups(ctx context.Context, timeout time.Duration) ([]datamodels.Backup, error) {
+	etcdClient := c.deps.Client
+	configuration := c.deps.Configuration
+
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, timeout, 15*time.Second)
 	defer timeoutCancelFn()
 
 	var retBackups []datamodels.Backup
@@ -143,9 +151,12 @@ func (c *clusterService) GetBackups(ctx context.Context, irisCtx iris.Context) (
 		return nil, err
 	}
 
-	version := etcdClient.Version()
+	version, err := etcdClient.Version()
+	if err != nil {
+		return nil, err
+	}
 	if version.Major() == 2 {
-		return nil, errors.New("cannot support v2 now")
+		return nil, backend.NewUnsupportedError("cannot support v2 now")
 	} else {
 		var (
 			wg                = &sync.WaitGroup{}
@@ -162,17 +173,19 @@ func (c *clusterService) GetBackups(ctx context.Context, irisCtx iris.Context) (
 					backupZipName := backupZip.Name()
 					backupPath := filepath.Join(backupDir, backupZipName)
 
+					// the files other than zips are skipped, there's nothing to close then
 					backup, err := zip.OpenReader(backupPath)
-					if err == nil {
-						modTime := backupZip.ModTime()
-
-						backupZipsSyncMap.Store(modTime.Unix(), datamodels.Backup{
-							Name:       backupZipName,
-							Size:       backupZip.Size(),
-							CreateTime: backend.JSONTime(modTime),
-						})
+					if err != nil {
+						return
 					}
 					defer backup.Close()
+
+					// by the names, the backups taken in the same second have the same mod time
+					backupZipsSyncMap.Store(backupZipName, datamodels.Backup{
+						Name:       backupZipName,
+						Size:       backupZip.Size(),
+						CreateTime: backend.JSONTime(backupZip.ModTime()),
+					})
 				}(timeoutCtx, backupZip)
 			}
 		}
@@ -183,21 +196,25 @@ func (c *clusterService) GetBackups(ctx context.Context, irisCtx iris.Context) (
 			retBackups = append(retBackups, value.(datamodels.Backup))
 			return true
 		})
+		// the newest first, as the map is not ordered
+		sort.Slice(retBackups, func(i, j int) bool {
+			iTime, jTime := time.Time(retBackups[i].CreateTime), time.Time(retBackups[j].CreateTime)
+			if !iTime.Equal(jTime) {
+				return iTime.After(jTime)
+			}
+			return retBackups[i].Name < retBackups[j].Name
+		})
 
 	}
 
 	return retBackups, nil
 }
 
-func (c *clusterService) NewBackup(ctx context.Context, irisCtx iris.Context) (datamodels.Backup, error) {
-	etcdClient := irisCtx.Values().Get("etcd-console.client").(*backend.EtcdClient)
-	configuration := irisCtx.Values().Get("etcd-console.config").(backend.Configuration)
+func (c *clusterService) NewBackup(ctx context.Context, timeout time.Duration) (datamodels.Backup, error) {
+	etcdClient := c.deps.Client
+	configuration := c.deps.Configuration
 
-	timeout, err := irisCtx.URLParamInt64Default("timeout", 30)
-	if err != nil {
-		timeout = 30
-	}
-	timeoutCtx, timeoutCancelFn := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, timeout, 30*time.Second)
 	defer timeoutCancelFn()
 
 	var retBackup datamodels.Backup
@@ -209,9 +226,12 @@ func (c *clusterService) NewBackup(ctx context.Context, irisCtx iris.Context) (d
 		return retBackup, errors.New("bakcup dir is lost")
 	}
 
-	version := etcdClient.Version()
+	version, err := etcdClient.Version()
+	if err != nil {
+		return retBackup, err
+	}
 	if version.Major() == 2 {
-		return retBackup, errors.New("cannot support v2 now")
+		return retBackup, backend.NewUnsupportedError("cannot support v2 now")
 	} else {
 		client, err := etcdClient.V3()
 		if err != nil {
@@ -225,59 +245,67 @@ func (c *clusterService) NewBackup(ctx context.Context, irisCtx iris.Context) (d
 		if err != nil {
 			return retBackup, err
 		}
+		defer snapshotReader.Close()
 		snapshotName := fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprintf("%d-snapshot", time.Now().UnixNano()))))
 		snapshotTmpPath := filepath.Join(os.TempDir(), snapshotName)
 		snapshotTmpFile, err := os.Create(snapshotTmpPath)
 		if err != nil {
-			irisCtx.Application().Logger().Error(err)
+			c.deps.Logger.Error(err)
 			return retBackup, errors.New("cannot create backup")
 		}
+		// the temporary snapshot is removed on the failures as well
+		defer func() {
+			snapshotTmpFile.Close()
+			os.Remove(snapshotTmpPath)
+		}()
 		if _, err := io.Copy(snapshotTmpFile, snapshotReader); err != nil {
-			irisCtx.Application().Logger().Error(err)
+			c.deps.Logger.Error(err)
 			return retBackup, errors.New("cannot create backup")
 		}
 		fileutil.Fsync(snapshotTmpFile)
 		snapshotReader.Close()
+		// the snapshot is zipped from the start, the copy above left the offset at the end
+		if _, err := snapshotTmpFile.Seek(0, io.SeekStart); err != nil {
+			c.deps.Logger.Error(err)
+			return retBackup, errors.New("cannot create backup")
+		}
 		//snapshotTmpFile.Close()
 
 		// snapshot packages
 		retBackupPath := filepath.Join(backupDir, fmt.Sprintf("%s.zip", snapshotName))
 		snapshotZipFile, err := os.Create(retBackupPath)
 		if err != nil {
-			irisCtx.Application().Logger().Error(err)
+			c.deps.Logger.Error(err)
 			return retBackup, errors.New("cannot create backup")
 		}
 		snapshotArchiveWriter := zip.NewWriter(snapshotZipFile)
 		//snapshotTmpFileReOpen, err := os.Open(snapshotTmpPath)
 		//if err != nil {
-		//	irisCtx.Application().Logger().Error(err)
+		//	c.deps.Logger.Error(err)
 		//	return retBackupName, errors.New("cannot create backup")
 		//}
 		//snapshotTmpFileInfo, err := snapshotTmpFileReOpen.Stat()
 		snapshotTmpFileInfo, err := snapshotTmpFile.Stat()
 		if err != nil {
-			irisCtx.Application().Logger().Error(err)
+			c.deps.Logger.Error(err)
 			return retBackup, errors.New("cannot create backup")
 		}
 		snapshotZipFileHeader, err := zip.FileInfoHeader(snapshotTmpFileInfo)
 		if err != nil {
-			irisCtx.Application().Logger().Error(err)
+			c.deps.Logger.Error(err)
 			return retBackup, errors.New("cannot create backup")
 		}
 		snapshotZipFileWriter, err := snapshotArchiveWriter.CreateHeader(snapshotZipFileHeader)
 		if err != nil {
-			irisCtx.Application().Logger().Error(err)
+			c.deps.Logger.Error(err)
 			return retBackup, errors.New("cannot create backup")
 		}
 		if _, err := io.Copy(snapshotZipFileWriter, snapshotTmpFile); err != nil {
-			irisCtx.Application().Logger().Error(err)
+			c.deps.Logger.Error(err)
 			return retBackup, errors.New("cannot create backup")
 		}
 		snapshotArchiveWriter.Close()
 		snapshotZipFile.Close()
-		snapshotTmpFile.Close()
-
-		os.Remove(snapshotTmpPath)
 
 		snapshotZipFileStat, err := os.Stat(retBackupPath)
 		if err != nil {
@@ -294,38 +322,45 @@ func (c *clusterService) NewBackup(ctx context.Context, irisCtx iris.Context) (d
 	return retBackup, nil
 }
 
-func (c *clusterService) DelBackup(ctx context.Context, irisCtx iris.Context) error {
-	etcdClient := irisCtx.Values().Get("etcd-console.client").(*backend.EtcdClient)
-	configuration := irisCtx.Values().Get("etcd-console.config").(backend.Configuration)
+func (c *clusterService) DelBackup(ctx context.Context, name string) error {
+	etcdClient := c.deps.Client
+	configuration := c.deps.Configuration
+
+	// the backups are taken in read-only mode as well, but never removed
+	if err := configuration.CheckWritable(); err != nil {
+		return err
+	}
 
 	backupDir := configuration.BackupDir
 	if stat, err := os.Stat(backupDir); err != nil {
-		irisCtx.Application().Logger().Error(err)
+		c.deps.Logger.Error(err)
 		return errors.New("bakcup dir is lost")
 	} else if !stat.IsDir() {
 		return errors.New("bakcup dir is lost")
 	}
 
-	version := etcdClient.Version()
+	version, err := etcdClient.Version()
+	if err != nil {
+		return err
+	}
 	if version.Major() == 2 {
-		return errors.New("cannot support v2 now")
+		return backend.NewUnsupportedError("cannot support v2 now")
 	} else {
-		name := irisCtx.URLParamEscape("name")
 		if name == "" {
-			return errors.New("name is required")
+			return backend.NewBadRequestError("name is required")
 		}
 
 		backupZipPath := filepath.Join(backupDir, name)
 		backupZip, err := os.Stat(backupZipPath)
 		if err != nil {
-			irisCtx.Application().Logger().Error(err)
-			return errors.New("cannot find backup")
+			c.deps.Logger.Error(err)
+			return backend.NewNotFoundError("cannot find backup")
 		} else if backupZip.IsDir() {
-			return errors.New("cannot find backup")
+			return backend.NewNotFoundError("cannot find backup")
 		}
 
 		if err := os.Remove(backupZipPath); err != nil {
-			irisCtx.Application().Logger().Error(err)
+			c.deps.Logger.Error(err)
 			return errors.New("cannot remove backup")
 		}
 	}
@@ -333,42 +368,44 @@ func (c *clusterService) DelBackup(ctx context.Context, irisCtx iris.Context) er
 	return nil
 }
 
-func (c *clusterService) DownloadBackup(ctx context.Context, irisCtx iris.Context) error {
-	etcdClient := irisCtx.Values().Get("etcd-console.client").(*backend.EtcdClient)
-	configuration := irisCtx.Values().Get("etcd-console.config").(backend.Configuration)
+func (c *clusterService) DownloadBackup(ctx context.Context, name string, w io.Writer) error {
+	etcdClient := c.deps.Client
+	configuration := c.deps.Configuration
 
 	backupDir := configuration.BackupDir
 	if stat, err := os.Stat(backupDir); err != nil {
-		irisCtx.Application().Logger().Error(err)
+		c.deps.Logger.Error(err)
 		return errors.New("bakcup dir is lost")
 	} else if !stat.IsDir() {
 		return errors.New("bakcup dir is lost")
 	}
 
-	version := etcdClient.Version()
+	version, err := etcdClient.Version()
+	if err != nil {
+		return err
+	}
 	if version.Major() == 2 {
-		return errors.New("cannot support v2 now")
+		return backend.NewUnsupportedError("cannot support v2 now")
 	} else {
-		name := irisCtx.URLParamEscape("name")
 		if name == "" {
-			return errors.New("name is required")
+			return backend.NewBadRequestError("name is required")
 		}
 
 		backupZipPath := filepath.Join(backupDir, name)
 		backupZip, err := os.Open(backupZipPath)
 		if err != nil {
-			irisCtx.Application().Logger().Error(err)
-			return errors.New("cannot find backup")
+			c.deps.Logger.Error(err)
+			return backend.NewNotFoundError("cannot find backup")
 		} else if stat, err := backupZip.Stat(); err != nil {
-			irisCtx.Application().Logger().Error(err)
-			return errors.New("cannot find backup")
+			c.deps.Logger.Error(err)
+			return backend.NewNotFoundError("cannot find backup")
 		} else if stat.IsDir() {
-			return errors.New("cannot find backup")
+			return backend.NewNotFoundError("cannot find backup")
 		}
 		defer backupZip.Close()
 
-		if _, err := io.Copy(irisCtx.ResponseWriter(), backupZip); err != nil {
-			irisCtx.Application().Logger().Error(err)
+		if _, err := io.Copy(w, backupZip); err != nil {
+			c.deps.Logger.Error(err)
 			return errors.New("cannot find backup")
 		}
 
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/thxcode/etcd-console/backend"
+)
+
+func TestClusterVersion(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	service := NewClusterService(deps)
+
+	version, err := service.GetVersion(context.Background())
+	if err != nil {
+		t.Fatalf("GetVersion() error = %v", err)
+	}
+	if version.Major() != 3 {
+		t.Errorf("GetVersion() = %v, want etcd v3", version)
+	}
+
+	if _, err := service.GetFeatures(context.Background()); err != nil {
+		t.Errorf("GetFeatures() error = %v", err)
+	}
+}
+
+func TestClusterStatuses(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	service := NewClusterService(deps)
+
+	members, err := service.GetStatuses(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("GetStatuses() error = %v", err)
+	}
+	if len(members) != 1 {
+		t.Fatalf("GetStatuses() = %+v, want one member", members)
+	}
+	member := members[0]
+	if member.Endpoint != e.Endpoints[0] {
+		t.Errorf("GetStatuses() endpoint = %s, want %s", member.Endpoint, e.Endpoints[0])
+	}
+	if !member.IsLeader || !member.IsHealth || !member.IsConnected {
+		t.Errorf("GetStatuses() = %+v, want the connected and healthy leader", member)
+	}
+	if member.DBSize == 0 || len(member.Version) == 0 {
+		t.Errorf("GetStatuses() = %+v, want the db size and the version", member)
+	}
+
+	// the clients of the members are closed, their goroutines don't pile up with the polls
+	goroutines := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		if _, err := service.GetStatuses(context.Background(), 0); err != nil {
+			t.Fatalf("GetStatuses() error = %v", err)
+		}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > goroutines+5 {
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutines = %d after polling the statuses, was %d", runtime.NumGoroutine(), goroutines)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestClusterBackups(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	e.Put(t, "/k", "v")
+	service := NewClusterService(deps)
+
+	// the files of the backup dir are listed only if they're zips
+	if err := ioutil.WriteFile(filepath.Join(deps.Configuration.BackupDir, "notes.txt"), []byte("not a backup"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var created []string
+	for i := 0; i < 2; i++ {
+		backup, err := service.NewBackup(context.Background(), 0)
+		if err != nil {
+			t.Fatalf("NewBackup() error = %v", err)
+		}
+		if len(backup.Name) == 0 || backup.Size == 0 {
+			t.Fatalf("NewBackup() = %+v, want the name and the size", backup)
+		}
+		created = append(created, backup.Name)
+	}
+
+	// neither a file named like a zip, nor the backups of the same mod time hide the others
+	if err := ioutil.WriteFile(filepath.Join(deps.Configuration.BackupDir, "broken.zip"), []byte("not a zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sameTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, name := range created {
+		if err := os.Chtimes(filepath.Join(deps.Configuration.BackupDir, name), sameTime, sameTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := service.GetBackups(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("GetBackups() error = %v", err)
+	}
+	if len(backups) != len(created) {
+		t.Fatalf("GetBackups() = %+v, want the backups %q", backups, created)
+	}
+	if backups[0].Name > backups[1].Name {
+		t.Errorf("GetBackups() = %+v, want the backups of the same time by their names", backups)
+	}
+
+	// the backup is a zip of the snapshot
+	var buf bytes.Buffer
+	if err := service.DownloadBackup(context.Background(), created[0], &buf); err != nil {
+		t.Fatalf("DownloadBackup() error = %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("DownloadBackup() is not a zip, %v", err)
+	}
+	if len(archive.File) != 1 || archive.File[0].UncompressedSize64 == 0 {
+		t.Fatalf("DownloadBackup() = %+v, want one snapshot file", archive.File)
+	}
+	// the whole snapshot is zipped, from the meta page of the bolt db
+	snapshot, err := archive.File[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer snapshot.Close()
+	meta := make([]byte, 20)
+	if _, err := io.ReadFull(snapshot, meta); err != nil {
+		t.Fatalf("the snapshot is too short, %v", err)
+	}
+	if magic := binary.LittleEndian.Uint32(meta[16:]); magic != 0xED0CDAED {
+		t.Errorf("the snapshot starts with the magic %x, want the one of bolt", magic)
+	}
+
+	for _, name := range created {
+		if err := service.DelBackup(context.Background(), name); err != nil {
+			t.Fatalf("DelBackup() error = %v", err)
+		}
+	}
+	backups, err = service.GetBackups(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("GetBackups() error = %v", err)
+	}
+	if len(backups) != 0 {
+		t.Errorf("GetBackups() = %+v, want none after the removes", backups)
+	}
+}
+
+func TestClusterBackupErrors(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	service := NewClusterService(deps)
+
+	if err := service.DownloadBackup(context.Background(), "", ioutil.Discard); errorCode(err) != backend.ErrCodeBadRequest {
+		t.Errorf("DownloadBackup() without the name error = %v, want %s", err, backend.ErrCodeBadRequest)
+	}
+	if err := service.DownloadBackup(context.Background(), "missing.zip", ioutil.Discard); errorCode(err) != backend.ErrCodeNotFound {
+		t.Errorf("DownloadBackup() of a missing backup error = %v, want %s", err, backend.ErrCodeNotFound)
+	}
+	if err := service.DelBackup(context.Background(), "missing.zip"); errorCode(err) != backend.ErrCodeNotFound {
+		t.Errorf("DelBackup() of a missing backup error = %v, want %s", err, backend.ErrCodeNotFound)
+	}
+
+	readOnlyDeps := deps
+	readOnlyDeps.Configuration.ReadOnly = true
+	if err := NewClusterService(readOnlyDeps).DelBackup(context.Background(), "missing.zip"); !backend.IsForbidden(err) {
+		t.Errorf("DelBackup() in read-only mode error = %v, want forbidden", err)
+	}
+
+	if err := os.RemoveAll(deps.Configuration.BackupDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.NewBackup(context.Background(), 0); err == nil {
+		t.Errorf("NewBackup() without the backup dir error = nil, want an error")
+	}
+	if _, err := service.GetBackups(context.Background(), 0); err == nil {
+		t.Errorf("GetBackups() without the backup dir error = nil, want an error")
+	}
+}
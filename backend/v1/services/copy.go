@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	v3 "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/schema"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+)
+
+// Copy copies the keys under a prefix to another one, or a key to another one if exact, and deletes the sources on move.
+// Every key is guarded by its mod revision, and the destination by its absence unless overwriting.
+// The values invalid against the schemas of their destinations are not copied, but listed as conflicts.
+func (c *clientService) Copy(ctx context.Context, req CopyRequest, move bool) (datamodels.CopyResult, error) {
+	etcdClient := c.deps.Client
+
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, req.Timeout, 60*time.Second)
+	defer timeoutCancelFn()
+
+	var retResult datamodels.CopyResult
+
+	version, err := etcdClient.Version()
+	if err != nil {
+		return retResult, err
+	}
+	if version.Major() == 2 {
+		return retResult, backend.NewUnsupportedError("cannot support v2 now")
+	} else {
+		clientCopyRequest := &req.ClientCopyRequest
+
+		encoding, err := parseEncoding(clientCopyRequest.Encoding)
+		if err != nil {
+			return retResult, err
+		}
+		from, err := decodeString(clientCopyRequest.From, encoding)
+		if err != nil {
+			return retResult, backend.NewBadRequestError(fmt.Sprintf(`bad "from", expecting %s`, encoding))
+		}
+		to, err := decodeString(clientCopyRequest.To, encoding)
+		if err != nil {
+			return retResult, backend.NewBadRequestError(fmt.Sprintf(`bad "to", expecting %s`, encoding))
+		}
+
+		exact := clientCopyRequest.Exact
+
+		if len(from) == 0 {
+			return retResult, backend.NewBadRequestError(`"from" cannot be empty`)
+		}
+		// the keys of both sides would be read and written in the same transactions
+		switch {
+		case exact:
+			if len(to) == 0 || from == to {
+				return retResult, backend.NewBadRequestError(`"to" must be another key`)
+			}
+		case strings.HasPrefix(to, from) || strings.HasPrefix(from, to):
+			return retResult, backend.NewBadRequestError(`"from" and "to" cannot overlap`)
+		}
+
+		// an empty end means the single key
+		fromEnd, toEnd := v3.GetPrefixRangeEnd(from), v3.GetPrefixRangeEnd(to)
+		if exact {
+			fromEnd, toEnd = "", ""
+		}
+
+		configuration := c.deps.Configuration
+		if err := configuration.CheckRange(to, toEnd); err != nil {
+			return retResult, err
+		}
+		if move {
+			if err := configuration.CheckRange(from, fromEnd); err != nil {
+				return retResult, err
+			}
+		}
+
+		// a source takes two compares and up to two operations
+		maxTxnOps := clientCopyRequest.MaxTxnOps
+		if maxTxnOps <= 0 {
+			maxTxnOps = 128
+		}
+		batchSize := maxTxnOps / 2
+		if batchSize == 0 {
+			batchSize = 1
+		}
+
+		client, err := etcdClient.V3()
+		if err != nil {
+			return retResult, err
+		}
+
+		copier := &prefixCopier{
+			client:        client,
+			from:          from,
+			to:            to,
+			move:          move,
+			preserveLease: clientCopyRequest.PreserveLease,
+			overwrite:     clientCopyRequest.Overwrite,
+			schemas:       c.deps.Schemas,
+			journaling:    c.journaling(),
+			encoding:      encoding,
+			result:        &retResult,
+		}
+
+		var (
+			rev   int64
+			start = from
+		)
+		for {
+			opts := []v3.OpOption{
+				v3.WithLimit(batchSize),
+				v3.WithSort(v3.SortByKey, v3.SortAscend),
+			}
+			if len(fromEnd) != 0 {
+				opts = append(opts, v3.WithRange(fromEnd))
+			}
+			// pin all pages to the revision of the first one
+			if rev > 0 {
+				opts = append(opts, v3.WithRev(rev))
+			}
+
+			getResp, err := client.Get(timeoutCtx, start, opts...)
+			if err != nil {
+				return retResult, err
+			}
+			if rev <= 0 {
+				rev = getResp.Header.Revision
+			}
+
+			if len(getResp.Kvs) == 0 {
+				break
+			}
+			if err := copier.copy(timeoutCtx, getResp.Kvs); err != nil {
+				return retResult, err
+			}
+
+			if !getResp.More {
+				break
+			}
+			start = string(getResp.Kvs[len(getResp.Kvs)-1].Key) + "\x00"
+		}
+
+		retResult.Revision = rev
+
+		journalOp := journalCopy
+		if move {
+			journalOp = journalMove
+		}
+		retResult.Journal = c.journal(journalOp, copier.journalKeys)
+	}
+
+	return retResult, nil
+}
+
+type prefixCopier struct {
+	client        *v3.Client
+	from          string
+	to            string
+	move          bool
+	preserveLease bool
+	overwrite     bool
+	schemas       *schema.Mapping
+	journaling    bool
+	encoding      string
+
+	result      *datamodels.CopyResult
+	journalKeys []journalKey
+}
+
+// copy commits the batch at once, it falls back to one key per transaction
+// to find out the conflicts if the batch fails.
+func (p *prefixCopier) copy(ctx context.Context, kvs []*mvccpb.KeyValue) error {
+	kvs = p.validate(kvs)
+	if len(kvs) == 0 {
+		return nil
+	}
+
+	cmps, ops := p.txn(kvs)
+
+	txnResp, err := p.client.Txn(ctx).If(cmps...).Then(ops...).Commit()
+	if err == nil && txnResp.Succeeded {
+		p.commit(ops, txnResp)
+		p.result.Copied += int64(len(kvs))
+		return nil
+	}
+	if err != nil && ctx.Err() != nil {
+		return err
+	}
+
+	for _, kv := range kvs {
+		cmps, ops := p.txn([]*mvccpb.KeyValue{kv})
+
+		txnResp, err := p.client.Txn(ctx).If(cmps...).Then(ops...).Commit()
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return err
+			}
+			p.conflict(kv.Key, err.Error())
+		case !txnResp.Succeeded:
+			p.conflict(kv.Key, p.reason(ctx, kv))
+		default:
+			p.commit(ops, txnResp)
+			p.result.Copied++
+		}
+	}
+
+	return nil
+}
+
+// validate returns the keys whose values are valid at their destinations, the others are conflicts.
+func (p *prefixCopier) validate(kvs []*mvccpb.KeyValue) []*mvccpb.KeyValue {
+	valid := make([]*mvccpb.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		destination := p.to + string(kv.Key)[len(p.from):]
+
+		if err := p.schemas.Validate([]byte(destination), kv.Value); err != nil {
+			p.conflict(kv.Key, err.Error())
+			continue
+		}
+		valid = append(valid, kv)
+	}
+
+	return valid
+}
+
+func (p *prefixCopier) txn(kvs []*mvccpb.KeyValue) ([]v3.Cmp, []v3.Op) {
+	var (
+		cmps = make([]v3.Cmp, 0, 2*len(kvs))
+		ops  = make([]v3.Op, 0, 2*len(kvs))
+	)
+
+	for _, kv := range kvs {
+		source := string(kv.Key)
+		destination := p.to + source[len(p.from):]
+
+		cmps = append(cmps, v3.Compare(v3.ModRevision(source), "=", kv.ModRevision))
+		if !p.overwrite {
+			cmps = append(cmps, v3.Compare(v3.CreateRevision(destination), "=", 0))
+		}
+
+		var opts []v3.OpOption
+		if p.preserveLease && kv.Lease != 0 {
+			opts = append(opts, v3.WithLease(v3.LeaseID(kv.Lease)))
+		}
+		if p.journaling {
+			opts = append(opts, v3.WithPrevKV())
+		}
+		ops = append(ops, v3.OpPut(destination, string(kv.Value), opts...))
+		if p.move {
+			if p.journaling {
+				ops = append(ops, v3.OpDelete(source, v3.WithPrevKV()))
+			} else {
+				ops = append(ops, v3.OpDelete(source))
+			}
+		}
+	}
+
+	return cmps, ops
+}
+
+// commit journals the destinations with their replaced values, and the deleted sources on move.
+func (p *prefixCopier) commit(ops []v3.Op, txnResp *v3.TxnResponse) {
+	if p.journaling {
+		p.journalKeys = appendJournalKeys(p.journalKeys, ops, txnResp)
+	}
+}
+
+// reason tells which side of a failed key is changed.
+func (p *prefixCopier) reason(ctx context.Context, kv *mvccpb.KeyValue) string {
+	source := string(kv.Key)
+	destination := p.to + source[len(p.from):]
+
+	getResp, err := p.client.Get(ctx, source, v3.WithKeysOnly())
+	if err != nil {
+		return err.Error()
+	}
+	if len(getResp.Kvs) == 0 {
+		return "the source is deleted"
+	}
+	if getResp.Kvs[0].ModRevision != kv.ModRevision {
+		return "the source is modified"
+	}
+
+	if !p.overwrite {
+		getResp, err := p.client.Get(ctx, destination, v3.WithCountOnly())
+		if err != nil {
+			return err.Error()
+		}
+		if getResp.Count != 0 {
+			return "the destination exists"
+		}
+	}
+
+	return "unknown"
+}
+
+func (p *prefixCopier) conflict(key []byte, reason string) {
+	keyValue := newKeyValue(&mvccpb.KeyValue{Key: key}, p.encoding, false)
+
+	p.result.Conflicts = append(p.result.Conflicts, datamodels.CopyConflict{
+		Key:      keyValue.Key,
+		Encoding: keyValue.Encoding,
+		Reason:   reason,
+	})
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	v3 "github.com/coreos/etcd/clientv3"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/schema"
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+)
+
+func TestClientCopy(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/a/1", "v1",
+		"/a/2", "v2",
+		"/a/3", "v3",
+		"/b/2", "existing",
+	)
+	service := NewClientService(deps)
+	ctx := context.Background()
+
+	// a page per key, one of them taken at the destination
+	result, err := service.Copy(ctx, CopyRequest{ClientCopyRequest: viewmodels.ClientCopyRequest{From: "/a/", To: "/b/", MaxTxnOps: 2}}, false)
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if result.Copied != 2 || len(result.Conflicts) != 1 || result.Conflicts[0].Key != "/a/2" || result.Conflicts[0].Reason != "the destination exists" {
+		t.Errorf("Copy() = %+v, want /a/2 in conflict", result)
+	}
+	if value, _ := e.Get(t, "/b/2"); value != "existing" {
+		t.Errorf("/b/2 = %q, want it untouched", value)
+	}
+
+	result, err = service.Copy(ctx, CopyRequest{ClientCopyRequest: viewmodels.ClientCopyRequest{From: "/a/", To: "/b/", Overwrite: true}}, false)
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if result.Copied != 3 || len(result.Conflicts) != 0 {
+		t.Errorf("Copy() = %+v, want all overwritten", result)
+	}
+	if value, _ := e.Get(t, "/b/2"); value != "v2" {
+		t.Errorf("/b/2 = %q, want v2", value)
+	}
+}
+
+func TestClientMove(t *testing.T) {
+	e, deps := startDependencies(t, func(configuration *backend.Configuration) {
+		configuration.ProtectedPrefixes = []string{"/protected/"}
+	})
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/a/1", "v1",
+		"/a/2", "v2",
+		"/protected/1", "v3",
+	)
+	service := NewClientService(deps)
+	ctx := context.Background()
+
+	result, err := service.Copy(ctx, CopyRequest{ClientCopyRequest: viewmodels.ClientCopyRequest{From: "/a/", To: "/c/"}}, true)
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if result.Copied != 2 || len(result.Conflicts) != 0 {
+		t.Errorf("Copy() = %+v, want all moved", result)
+	}
+
+	getResp, err := e.Client.Get(ctx, "/", v3.WithPrefix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, kv := range getResp.Kvs {
+		keys = append(keys, string(kv.Key))
+	}
+	if want := []string{"/c/1", "/c/2", "/protected/1"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+
+	tests := []struct {
+		name string
+		req  viewmodels.ClientCopyRequest
+		code string
+	}{
+		{"no source", viewmodels.ClientCopyRequest{To: "/d/"}, backend.ErrCodeBadRequest},
+		{"destination under the source", viewmodels.ClientCopyRequest{From: "/c/", To: "/c/d/"}, backend.ErrCodeBadRequest},
+		{"source under the destination", viewmodels.ClientCopyRequest{From: "/c/d/", To: "/c/"}, backend.ErrCodeBadRequest},
+		{"protected destination", viewmodels.ClientCopyRequest{From: "/c/", To: "/protected/c/"}, backend.ErrCodeForbidden},
+		{"protected source", viewmodels.ClientCopyRequest{From: "/protected/", To: "/d/"}, backend.ErrCodeForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.Copy(ctx, CopyRequest{ClientCopyRequest: tt.req}, true); errorCode(err) != tt.code {
+				t.Errorf("Copy() error = %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestClientCopyConflictEncoding(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/a/1", "v1",
+		"/b/1", "existing",
+	)
+	service := NewClientService(deps)
+
+	// the conflicts are rendered in the encoding of the request
+	result, err := service.Copy(context.Background(), CopyRequest{ClientCopyRequest: viewmodels.ClientCopyRequest{From: "2f612f", To: "2f622f", Encoding: "hex"}}, false)
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Key != "2f612f31" || result.Conflicts[0].Encoding != "hex" {
+		t.Errorf("Copy() = %+v, want /a/1 in conflict as hex", result)
+	}
+}
+
+func TestClientCopyExact(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/a", "v1",
+		"/ab", "v2",
+	)
+	service := NewClientService(deps)
+	ctx := context.Background()
+
+	// a key is renamed to a longer one, the keys sharing its prefix are left
+	result, err := service.Copy(ctx, CopyRequest{ClientCopyRequest: viewmodels.ClientCopyRequest{From: "/a", To: "/a/renamed", Exact: true}}, true)
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if result.Copied != 1 || len(result.Conflicts) != 0 {
+		t.Errorf("Copy() = %+v, want the key moved", result)
+	}
+	if _, ok := e.Get(t, "/a"); ok {
+		t.Errorf("/a exists after the move")
+	}
+	if value, _ := e.Get(t, "/a/renamed"); value != "v1" {
+		t.Errorf("/a/renamed = %q, want v1", value)
+	}
+	if value, _ := e.Get(t, "/ab"); value != "v2" {
+		t.Errorf("/ab = %q, want it untouched", value)
+	}
+
+	// a missing key copies nothing
+	result, err = service.Copy(ctx, CopyRequest{ClientCopyRequest: viewmodels.ClientCopyRequest{From: "/a", To: "/b", Exact: true}}, false)
+	if err != nil || result.Copied != 0 {
+		t.Errorf("Copy() of a missing key = %+v, %v, want nothing copied", result, err)
+	}
+
+	if _, err := service.Copy(ctx, CopyRequest{ClientCopyRequest: viewmodels.ClientCopyRequest{From: "/ab", To: "/ab", Exact: true}}, false); errorCode(err) != backend.ErrCodeBadRequest {
+		t.Errorf("Copy() to the same key error = %v, want bad request", err)
+	}
+}
+
+func TestClientCopySchema(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	s, err := schema.Compile("port", []byte(`{"type": "integer", "minimum": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deps.Schemas = schema.NewMapping()
+	deps.Schemas.Add("/ports/", s)
+
+	e.Put(t,
+		"/a/1", "80",
+		"/a/2", "http",
+	)
+	service := NewClientService(deps)
+
+	result, err := service.Copy(context.Background(), CopyRequest{ClientCopyRequest: viewmodels.ClientCopyRequest{From: "/a/", To: "/ports/"}}, true)
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if result.Copied != 1 || len(result.Conflicts) != 1 || result.Conflicts[0].Key != "/a/2" || !strings.Contains(result.Conflicts[0].Reason, "violates schema port") {
+		t.Errorf("Copy() = %+v, want /a/2 invalid", result)
+	}
+	if _, ok := e.Get(t, "/ports/2"); ok {
+		t.Errorf("the invalid value is copied")
+	}
+	if value, _ := e.Get(t, "/a/2"); value != "http" {
+		t.Errorf("the invalid value is moved away, /a/2 = %q", value)
+	}
+}
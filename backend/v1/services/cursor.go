@@ -0,0 +1,35 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/thxcode/etcd-console/backend"
+)
+
+// readCursor points after the last key of a page, at the revision which all pages are read on.
+type readCursor struct {
+	// bytes, so binary keys survive the json encoding
+	Key []byte `json:"k"`
+	Rev int64  `json:"r"`
+}
+
+func encodeReadCursor(cursor readCursor) string {
+	data, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeReadCursor(encoded string) (readCursor, error) {
+	var cursor readCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, backend.NewBadRequestError("bad cursor")
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || len(cursor.Key) == 0 || cursor.Rev <= 0 {
+		return cursor, backend.NewBadRequestError("bad cursor")
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"github.com/thxcode/etcd-console/backend"
+)
+
+func TestReadCursor(t *testing.T) {
+	// the binary keys survive the round trip
+	cursor := readCursor{Key: []byte{0xff, 0x00, '/'}, Rev: 7}
+	encoded := encodeReadCursor(cursor)
+	decoded, err := decodeReadCursor(encoded)
+	if err != nil {
+		t.Fatalf("decodeReadCursor(%s) error = %v", encoded, err)
+	}
+	if !reflect.DeepEqual(decoded, cursor) {
+		t.Errorf("decodeReadCursor(%s) = %+v, want %+v", encoded, decoded, cursor)
+	}
+
+	for _, bad := range []string{
+		"!",
+		base64.RawURLEncoding.EncodeToString([]byte("not json")),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"r":7}`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"k":"L2E=","r":0}`)),
+	} {
+		if _, err := decodeReadCursor(bad); errorCode(err) != backend.ErrCodeBadRequest {
+			t.Errorf("decodeReadCursor(%s) error = %v, want %s", bad, err, backend.ErrCodeBadRequest)
+		}
+	}
+}
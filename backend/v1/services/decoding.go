@@ -0,0 +1,97 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/codec"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+	"gopkg.in/yaml.v2"
+)
+
+// "auto" picks the codec mapped by the key prefix, or the first decoder which matches the value,
+// "none" disables the decoding, and the mapped codec is used when "decode" is empty
+const (
+	decodeAuto = "auto"
+	decodeNone = "none"
+)
+
+// formats of the decoded values
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+func parseDecode(decode string) (string, error) {
+	if len(decode) == 0 || decode == decodeAuto || decode == decodeNone {
+		return decode, nil
+	}
+
+	if _, ok := codec.GetDecoder(decode); !ok {
+		return "", backend.NewBadRequestError(fmt.Sprintf(`unknown "decode" %s`, decode))
+	}
+
+	return decode, nil
+}
+
+func parseFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", formatJSON:
+		return formatJSON, nil
+	case formatYAML, "yml":
+		return formatYAML, nil
+	}
+
+	return "", backend.NewBadRequestError(fmt.Sprintf(`unknown "format" %s, choose one of json and yaml`, format))
+}
+
+// decodeValue renders the value by the named decoder, the mapped one, or the detected one on "auto",
+// nil means that no decoder understands the value.
+func decodeValue(kv *mvccpb.KeyValue, decode string, format string, codecs *codec.Mapping) *datamodels.DecodedValue {
+	var (
+		decoder codec.Decoder
+		ok      bool
+	)
+	switch decode {
+	case decodeNone:
+	case "":
+		decoder, ok = codecs.Lookup(kv.Key)
+	case decodeAuto:
+		if decoder, ok = codecs.Lookup(kv.Key); !ok {
+			decoder, ok = codec.DetectDecoder(kv.Key, kv.Value)
+		}
+	default:
+		decoder, ok = codec.GetDecoder(decode)
+	}
+	if !ok {
+		return nil
+	}
+
+	retDecoded := &datamodels.DecodedValue{
+		Decoder: decoder.Name(),
+	}
+
+	decoded, err := decoder.Decode(kv.Key, kv.Value)
+	if err != nil {
+		retDecoded.Err = err.Error()
+		return retDecoded
+	}
+	retDecoded.APIVersion = decoded.APIVersion
+	retDecoded.Kind = decoded.Kind
+	retDecoded.Note = decoded.Note
+
+	if format == formatYAML {
+		data, err := yaml.Marshal(decoded.Object)
+		if err != nil {
+			retDecoded.Err = err.Error()
+			return retDecoded
+		}
+		retDecoded.YAML = string(data)
+	} else {
+		retDecoded.Object = decoded.Object
+	}
+
+	return retDecoded
+}
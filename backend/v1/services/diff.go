@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+)
+
+// the table of the longest common subsequence is limited, larger values are replaced as a whole
+const diffMaxCells = 4 << 20
+
+// diffLines returns the lines prefixed by "-", "+" or " ".
+func diffLines(before, after string) string {
+	var (
+		a   = splitLines(before)
+		b   = splitLines(after)
+		buf bytes.Buffer
+	)
+
+	line := func(prefix byte, text string) {
+		buf.WriteByte(prefix)
+		buf.WriteString(text)
+		if !strings.HasSuffix(text, "\n") {
+			buf.WriteByte('\n')
+		}
+	}
+
+	// the common head and tail are cheap
+	head := 0
+	for head < len(a) && head < len(b) && a[head] == b[head] {
+		head++
+	}
+	tail := 0
+	for tail < len(a)-head && tail < len(b)-head && a[len(a)-1-tail] == b[len(b)-1-tail] {
+		tail++
+	}
+
+	for _, text := range a[:head] {
+		line(' ', text)
+	}
+
+	midA, midB := a[head:len(a)-tail], b[head:len(b)-tail]
+	if (len(midA)+1)*(len(midB)+1) > diffMaxCells {
+		for _, text := range midA {
+			line('-', text)
+		}
+		for _, text := range midB {
+			line('+', text)
+		}
+	} else {
+		// lcs[i][j] is the length of the longest common subsequence of midA[i:] and midB[j:]
+		lcs := make([][]int, len(midA)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(midB)+1)
+		}
+		for i := len(midA) - 1; i >= 0; i-- {
+			for j := len(midB) - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else if lcs[i+1][j] >= lcs[i][j+1] {
+					lcs[i][j] = lcs[i+1][j]
+				} else {
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+
+		i, j := 0, 0
+		for i < len(midA) && j < len(midB) {
+			switch {
+			case midA[i] == midB[j]:
+				line(' ', midA[i])
+				i++
+				j++
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				line('-', midA[i])
+				i++
+			default:
+				line('+', midB[j])
+				j++
+			}
+		}
+		for ; i < len(midA); i++ {
+			line('-', midA[i])
+		}
+		for ; j < len(midB); j++ {
+			line('+', midB[j])
+		}
+	}
+
+	for _, text := range a[len(a)-tail:] {
+		line(' ', text)
+	}
+
+	return buf.String()
+}
+
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) != 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+)
+
+// encodings of keys and values in requests and responses
+const (
+	encodingUTF8   = "utf8"
+	encodingBase64 = "base64"
+	encodingHex    = "hex"
+)
+
+func parseEncoding(encoding string) (string, error) {
+	switch strings.ToLower(encoding) {
+	case "", encodingUTF8, "utf-8":
+		return encodingUTF8, nil
+	case encodingBase64:
+		return encodingBase64, nil
+	case encodingHex:
+		return encodingHex, nil
+	}
+
+	return "", backend.NewBadRequestError(fmt.Sprintf(`unknown "encoding" %s, choose one of utf8, base64 and hex`, encoding))
+}
+
+func encodeBytes(data []byte, encoding string) string {
+	switch encoding {
+	case encodingBase64:
+		return base64.StdEncoding.EncodeToString(data)
+	case encodingHex:
+		return hex.EncodeToString(data)
+	}
+
+	return string(data)
+}
+
+func decodeString(data string, encoding string) (string, error) {
+	switch encoding {
+	case encodingBase64:
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		return string(decoded), err
+	case encodingHex:
+		decoded, err := hex.DecodeString(data)
+		return string(decoded), err
+	}
+
+	return data, nil
+}
+
+// newKeyValue converts the etcd key value, utf8 falls back to base64 if the key or the value is binary.
+func newKeyValue(kv *mvccpb.KeyValue, encoding string, withValue bool) datamodels.KeyValue {
+	binary := !utf8.Valid(kv.Key) || (withValue && !utf8.Valid(kv.Value))
+	if binary && encoding == encodingUTF8 {
+		encoding = encodingBase64
+	}
+
+	keyValue := datamodels.KeyValue{
+		Key:            encodeBytes(kv.Key, encoding),
+		CreateRevision: kv.CreateRevision,
+		ModRevision:    kv.ModRevision,
+		Version:        kv.Version,
+		Lease:          fmt.Sprintf("%x", kv.Lease),
+		Binary:         binary,
+	}
+	if withValue {
+		keyValue.Value = encodeBytes(kv.Value, encoding)
+	}
+	if encoding != encodingUTF8 {
+		keyValue.Encoding = encoding
+	}
+
+	return keyValue
+}
@@ -0,0 +1,443 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	v3 "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+	"gopkg.in/yaml.v2"
+)
+
+// formats of the exports
+const (
+	// one json object per line
+	exportJSONLines = "jsonl"
+	// nested document split by the delimiter
+	exportJSON = "json"
+	exportYAML = "yaml"
+	// key and value lines, like "etcdctl get"
+	exportEtcdctl = "etcdctl"
+	// like "etcdctl get -w json"
+	exportEtcdctlJSON = "etcdctl-json"
+)
+
+// members of the nested documents, the rest members are the children split by the delimiter,
+// a plain string is the short form of {"$value": "..."}
+const (
+	documentValue          = "$value"
+	documentEncoding       = "$encoding"
+	documentCreateRevision = "$createRevision"
+	documentModRevision    = "$modRevision"
+	documentVersion        = "$version"
+	documentLease          = "$lease"
+)
+
+func parseExportFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case "", exportJSONLines, "ndjson":
+		return exportJSONLines, nil
+	case exportJSON:
+		return exportJSON, nil
+	case exportYAML, "yml":
+		return exportYAML, nil
+	case exportEtcdctl:
+		return exportEtcdctl, nil
+	case exportEtcdctlJSON:
+		return exportEtcdctlJSON, nil
+	}
+
+	return "", backend.NewBadRequestError(fmt.Sprintf(`unknown "format" %s, choose one of jsonl, json, yaml, etcdctl and etcdctl-json`, format))
+}
+
+// ExportWriter receives an export, the transports send the content type and the file name ahead of the content.
+type ExportWriter interface {
+	io.Writer
+	// Begin is called once ahead of the content, the file name carries the revision of the export.
+	Begin(contentType string, fileName string, rev int64)
+	// Flush sends the content written so far, it's called after every page of the streamed formats.
+	Flush()
+}
+
+type exporter interface {
+	begin(header *etcdserverpb.ResponseHeader) error
+	write(kv *mvccpb.KeyValue) error
+	end() error
+}
+
+// Export writes the keys to the w page by page, the failures after the first page cut the export off,
+// they're logged instead of returned as the transports cannot answer them anymore.
+func (c *clientService) Export(ctx context.Context, req ExportRequest, w ExportWriter) error {
+	etcdClient := c.deps.Client
+
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, req.Timeout, 60*time.Second)
+	defer timeoutCancelFn()
+
+	version, err := etcdClient.Version()
+	if err != nil {
+		return err
+	}
+	if version.Major() == 2 {
+		return backend.NewUnsupportedError("cannot support v2 now")
+	} else {
+		prefix := req.Prefix
+		fromKey := req.FromKey
+
+		if prefix && fromKey {
+			return backend.NewBadRequestError(`"prefix" and "fromKey" cannot be set at the same time, choose one`)
+		}
+
+		encoding, err := parseEncoding(req.Encoding)
+		if err != nil {
+			return err
+		}
+
+		key, err := decodeString(req.Key, encoding)
+		if err != nil {
+			return backend.NewBadRequestError(fmt.Sprintf(`bad "key", expecting %s`, encoding))
+		}
+
+		format, err := parseExportFormat(req.Format)
+		if err != nil {
+			return err
+		}
+
+		metadata := req.Metadata
+
+		delimiter := req.Delimiter
+		if len(delimiter) == 0 {
+			delimiter = "/"
+		}
+
+		rev := req.Rev
+
+		pageSize := req.PageSize
+		if pageSize <= 0 {
+			pageSize = 1000
+		}
+
+		// "\x00" as the range end means all keys from the start, an empty one means the single key
+		var start, end, documentPrefix = key, "", ""
+		switch {
+		case len(req.Range) != 0:
+			if end, err = decodeString(req.Range, encoding); err != nil {
+				return backend.NewBadRequestError(fmt.Sprintf(`bad "range", expecting %s`, encoding))
+			}
+		case prefix:
+			end = v3.GetPrefixRangeEnd(key)
+			documentPrefix = key
+		case fromKey:
+			end = "\x00"
+		}
+		if len(start) == 0 {
+			start = "\x00"
+			if len(end) == 0 {
+				end = "\x00"
+			}
+		}
+
+		client, err := etcdClient.V3()
+		if err != nil {
+			return err
+		}
+
+		var (
+			buffered = bufio.NewWriter(w)
+			exp      exporter
+			ext      = format
+		)
+		switch format {
+		case exportJSONLines:
+			exp = &jsonLinesExporter{w: buffered, encoding: encoding, metadata: metadata}
+		case exportJSON, exportYAML:
+			exp = &documentExporter{w: buffered, format: format, prefix: documentPrefix, delimiter: delimiter, encoding: encoding, metadata: metadata}
+		case exportEtcdctl:
+			exp = &etcdctlExporter{w: buffered}
+			ext = "txt"
+		case exportEtcdctlJSON:
+			exp = &etcdctlJSONExporter{w: buffered}
+			ext = "json"
+		}
+
+		// documents are written at the end, the errors before can still be responded
+		_, document := exp.(*documentExporter)
+		began := false
+		cutOff := func(err error) error {
+			if !began || document {
+				return err
+			}
+
+			// too late to change the status, the download is cut off
+			c.deps.Logger.Errorf("export is cut off, %v", err)
+			return nil
+		}
+
+		for {
+			opts := []v3.OpOption{
+				v3.WithLimit(pageSize),
+				v3.WithSort(v3.SortByKey, v3.SortAscend),
+			}
+			if len(end) != 0 {
+				opts = append(opts, v3.WithRange(end))
+			}
+			// pin all pages to the revision of the first one
+			if rev > 0 {
+				opts = append(opts, v3.WithRev(rev))
+			}
+
+			getResp, err := client.Get(timeoutCtx, start, opts...)
+			if err != nil {
+				return cutOff(err)
+			}
+			if rev <= 0 {
+				rev = getResp.Header.Revision
+			}
+
+			if !began {
+				w.Begin(exportContentType(format), fmt.Sprintf("etcd-export-%d.%s", rev, ext), rev)
+
+				header := *getResp.Header
+				header.Revision = rev
+				if err := exp.begin(&header); err != nil {
+					return err
+				}
+				began = true
+			}
+
+			for _, kv := range getResp.Kvs {
+				if err := exp.write(kv); err != nil {
+					return cutOff(err)
+				}
+			}
+			if !document {
+				buffered.Flush()
+				w.Flush()
+			}
+
+			if !getResp.More || len(getResp.Kvs) == 0 || len(end) == 0 {
+				break
+			}
+			start = string(getResp.Kvs[len(getResp.Kvs)-1].Key) + "\x00"
+		}
+
+		if err := exp.end(); err != nil {
+			return cutOff(err)
+		}
+		buffered.Flush()
+	}
+
+	return nil
+}
+
+func exportContentType(format string) string {
+	switch format {
+	case exportJSONLines:
+		return "application/x-ndjson"
+	case exportYAML:
+		return "application/x-yaml"
+	case exportEtcdctl:
+		return "text/plain; charset=utf-8"
+	}
+
+	return "application/json"
+}
+
+func newExportKeyValue(kv *mvccpb.KeyValue, encoding string, metadata bool) datamodels.ExportKeyValue {
+	keyValue := newKeyValue(kv, encoding, true)
+
+	exportKeyValue := datamodels.ExportKeyValue{
+		Key:      keyValue.Key,
+		Value:    keyValue.Value,
+		Encoding: keyValue.Encoding,
+	}
+	if metadata {
+		exportKeyValue.CreateRevision = keyValue.CreateRevision
+		exportKeyValue.ModRevision = keyValue.ModRevision
+		exportKeyValue.Version = keyValue.Version
+		if kv.Lease != 0 {
+			exportKeyValue.Lease = keyValue.Lease
+		}
+	}
+
+	return exportKeyValue
+}
+
+type jsonLinesExporter struct {
+	w        io.Writer
+	encoding string
+	metadata bool
+}
+
+func (e *jsonLinesExporter) begin(header *etcdserverpb.ResponseHeader) error {
+	return nil
+}
+
+func (e *jsonLinesExporter) write(kv *mvccpb.KeyValue) error {
+	data, err := json.Marshal(newExportKeyValue(kv, e.encoding, e.metadata))
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(append(data, '\n'))
+	return err
+}
+
+func (e *jsonLinesExporter) end() error {
+	return nil
+}
+
+// documentExporter builds the whole document in memory, the keys are relative to the prefix.
+type documentExporter struct {
+	w         io.Writer
+	format    string
+	prefix    string
+	delimiter string
+	encoding  string
+	metadata  bool
+
+	root map[string]interface{}
+}
+
+func (e *documentExporter) begin(header *etcdserverpb.ResponseHeader) error {
+	e.root = make(map[string]interface{})
+	return nil
+}
+
+func (e *documentExporter) write(kv *mvccpb.KeyValue) error {
+	if !utf8.Valid(kv.Key) {
+		return backend.NewBadRequestError(fmt.Sprintf("binary key %q cannot be exported as a document, choose jsonl or etcdctl-json", kv.Key))
+	}
+
+	// the nodes are folded into plain strings at the end if possible
+	node := e.root
+	if key := strings.TrimPrefix(string(kv.Key), e.prefix); len(key) != 0 {
+		for _, segment := range strings.Split(key, e.delimiter) {
+			child, ok := node[segment].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[segment] = child
+			}
+			node = child
+		}
+	}
+
+	exportKeyValue := newExportKeyValue(kv, e.encoding, e.metadata)
+	node[documentValue] = exportKeyValue.Value
+	if len(exportKeyValue.Encoding) != 0 {
+		node[documentEncoding] = exportKeyValue.Encoding
+	}
+	if e.metadata {
+		node[documentCreateRevision] = exportKeyValue.CreateRevision
+		node[documentModRevision] = exportKeyValue.ModRevision
+		node[documentVersion] = exportKeyValue.Version
+		if len(exportKeyValue.Lease) != 0 {
+			node[documentLease] = exportKeyValue.Lease
+		}
+	}
+
+	return nil
+}
+
+func (e *documentExporter) end() error {
+	document := foldDocument(e.root)
+
+	var (
+		data []byte
+		err  error
+	)
+	if e.format == exportYAML {
+		data, err = yaml.Marshal(document)
+	} else {
+		data, err = json.MarshalIndent(document, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+// foldDocument turns the nodes with the value only into plain strings.
+func foldDocument(node map[string]interface{}) interface{} {
+	if value, ok := node[documentValue]; ok && len(node) == 1 {
+		return value
+	}
+
+	for segment, child := range node {
+		if childNode, ok := child.(map[string]interface{}); ok {
+			node[segment] = foldDocument(childNode)
+		}
+	}
+
+	return node
+}
+
+// etcdctlExporter prints the keys and the values line by line, like "etcdctl get --prefix".
+type etcdctlExporter struct {
+	w io.Writer
+}
+
+func (e *etcdctlExporter) begin(header *etcdserverpb.ResponseHeader) error {
+	return nil
+}
+
+func (e *etcdctlExporter) write(kv *mvccpb.KeyValue) error {
+	if _, err := fmt.Fprintf(e.w, "%s\n%s\n", kv.Key, kv.Value); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (e *etcdctlExporter) end() error {
+	return nil
+}
+
+// etcdctlJSONExporter streams the range response, like "etcdctl get --prefix -w json".
+type etcdctlJSONExporter struct {
+	w     io.Writer
+	count int64
+}
+
+func (e *etcdctlJSONExporter) begin(header *etcdserverpb.ResponseHeader) error {
+	data, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(e.w, `{"header":%s,"kvs":[`, data)
+	return err
+}
+
+func (e *etcdctlJSONExporter) write(kv *mvccpb.KeyValue) error {
+	data, err := json.Marshal(kv)
+	if err != nil {
+		return err
+	}
+
+	if e.count > 0 {
+		if _, err := e.w.Write([]byte{','}); err != nil {
+			return err
+		}
+	}
+	e.count++
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *etcdctlJSONExporter) end() error {
+	_, err := fmt.Fprintf(e.w, `],"count":%d}`+"\n", e.count)
+	return err
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+	"gopkg.in/yaml.v2"
+)
+
+// exportBuffer keeps what the transports would send.
+type exportBuffer struct {
+	bytes.Buffer
+	contentType string
+	fileName    string
+	rev         int64
+	flushes     int
+}
+
+func (b *exportBuffer) Begin(contentType string, fileName string, rev int64) {
+	b.contentType, b.fileName, b.rev = contentType, fileName, rev
+}
+
+func (b *exportBuffer) Flush() {
+	b.flushes++
+}
+
+func TestClientExport(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	rev := e.Put(t,
+		"/a/1", "v1",
+		"/a/2/x", "v2",
+		"/b", "v3",
+	)
+	service := NewClientService(deps)
+	ctx := context.Background()
+
+	// a page per key
+	var lines exportBuffer
+	if err := service.Export(ctx, ExportRequest{KeyRange: KeyRange{Key: "/a/", Prefix: true}, PageSize: 1}, &lines); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if want := "{\"key\":\"/a/1\",\"value\":\"v1\"}\n{\"key\":\"/a/2/x\",\"value\":\"v2\"}\n"; lines.String() != want {
+		t.Errorf("Export() = %q, want %q", lines.String(), want)
+	}
+	if lines.contentType != "application/x-ndjson" || lines.fileName != fmt.Sprintf("etcd-export-%d.jsonl", rev) || lines.rev != rev {
+		t.Errorf("Begin() = %q, %q, %d", lines.contentType, lines.fileName, lines.rev)
+	}
+	if lines.flushes < 2 {
+		t.Errorf("Flush() = %d times, want once a page", lines.flushes)
+	}
+
+	// the document is relative to the prefix
+	var document exportBuffer
+	if err := service.Export(ctx, ExportRequest{KeyRange: KeyRange{Key: "/a/", Prefix: true}, Format: "json"}, &document); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(document.Bytes(), &got); err != nil {
+		t.Fatalf("Export() = %s, %v", document.String(), err)
+	}
+	if fmt.Sprint(got) != "map[1:v1 2:map[x:v2]]" {
+		t.Errorf("Export() = %v", got)
+	}
+
+	// the earlier revision
+	var etcdctl exportBuffer
+	if err := service.Export(ctx, ExportRequest{KeyRange: KeyRange{Key: "/a/1"}, Format: "etcdctl", Rev: rev - 2}, &etcdctl); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if etcdctl.String() != "/a/1\nv1\n" || etcdctl.fileName != fmt.Sprintf("etcd-export-%d.txt", rev-2) {
+		t.Errorf("Export() = %q as %s", etcdctl.String(), etcdctl.fileName)
+	}
+
+	tests := []struct {
+		name string
+		req  ExportRequest
+	}{
+		{"unknown format", ExportRequest{Format: "xml"}},
+		{"prefix and from key", ExportRequest{KeyRange: KeyRange{Key: "/a/", Prefix: true, FromKey: true}}},
+		{"bad encoding", ExportRequest{KeyRange: KeyRange{Key: "!", Encoding: "base64"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf exportBuffer
+			if err := service.Export(ctx, tt.req, &buf); errorCode(err) != backend.ErrCodeBadRequest {
+				t.Errorf("Export() error = %v, want bad request", err)
+			}
+			if buf.Len() != 0 || len(buf.fileName) != 0 {
+				t.Errorf("Export() began the download of an error")
+			}
+		})
+	}
+}
+
+func TestClientExportFormats(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	rev := e.Put(t,
+		"/m/k", "v",
+		"x:a:1", "v1",
+		"x:b", "v2",
+	)
+	service := NewClientService(deps)
+	ctx := context.Background()
+
+	// the metadata go along with the values in the document
+	var document exportBuffer
+	if err := service.Export(ctx, ExportRequest{KeyRange: KeyRange{Key: "/m/", Prefix: true}, Format: "yml", Metadata: true}, &document); err != nil {
+		t.Fatalf("Export() of yaml error = %v", err)
+	}
+	var got map[string]map[string]interface{}
+	if err := yaml.Unmarshal(document.Bytes(), &got); err != nil {
+		t.Fatalf("Export() of yaml = %s, %v", document.String(), err)
+	}
+	if k := got["k"]; k["$value"] != "v" || k["$modRevision"] != int(rev-2) || k["$version"] != 1 {
+		t.Errorf("Export() of yaml = %v, want the value v with the metadata", got)
+	}
+	if document.contentType != "application/x-yaml" || document.fileName != fmt.Sprintf("etcd-export-%d.yaml", rev) {
+		t.Errorf("Begin() of yaml = %q, %q", document.contentType, document.fileName)
+	}
+
+	var delimited exportBuffer
+	if err := service.Export(ctx, ExportRequest{KeyRange: KeyRange{Key: "x:", Prefix: true}, Format: "json", Delimiter: ":"}, &delimited); err != nil {
+		t.Fatalf("Export() of the delimiter error = %v", err)
+	}
+	var tree map[string]interface{}
+	if err := json.Unmarshal(delimited.Bytes(), &tree); err != nil || fmt.Sprint(tree) != "map[a:map[1:v1] b:v2]" {
+		t.Errorf("Export() of the delimiter : = %s, %v, want the nested document", delimited.String(), err)
+	}
+
+	// the binary values fall back to base64, the binary keys cannot be documents
+	if _, err := e.Client.Put(ctx, "/bin/\xff", "\x00"); err != nil {
+		t.Fatal(err)
+	}
+	var lines exportBuffer
+	if err := service.Export(ctx, ExportRequest{KeyRange: KeyRange{Key: "/bin/", Prefix: true}}, &lines); err != nil {
+		t.Fatalf("Export() of the binary key error = %v", err)
+	}
+	var line datamodels.ExportKeyValue
+	if err := json.Unmarshal(lines.Bytes(), &line); err != nil || line.Encoding != "base64" || line.Value != "AA==" {
+		t.Errorf("Export() of the binary key = %s, %v, want it in base64", lines.String(), err)
+	}
+	var binaryDocument exportBuffer
+	if err := service.Export(ctx, ExportRequest{KeyRange: KeyRange{Key: "/bin/", Prefix: true}, Format: "json"}, &binaryDocument); errorCode(err) != backend.ErrCodeBadRequest {
+		t.Errorf("Export() of the binary key as a document error = %v, want bad request", err)
+	}
+
+	// like "etcdctl get -w json"
+	var etcdctl exportBuffer
+	if err := service.Export(ctx, ExportRequest{KeyRange: KeyRange{Key: "x:", Prefix: true}, Format: "etcdctl-json", PageSize: 1}, &etcdctl); err != nil {
+		t.Fatalf("Export() of etcdctl-json error = %v", err)
+	}
+	var response struct {
+		Header struct {
+			Revision int64 `json:"revision"`
+		} `json:"header"`
+		Kvs []struct {
+			Key   []byte `json:"key"`
+			Value []byte `json:"value"`
+		} `json:"kvs"`
+		Count int64 `json:"count"`
+	}
+	if err := json.Unmarshal(etcdctl.Bytes(), &response); err != nil {
+		t.Fatalf("Export() of etcdctl-json = %s, %v", etcdctl.String(), err)
+	}
+	if response.Count != 2 || len(response.Kvs) != 2 || string(response.Kvs[1].Key) != "x:b" || string(response.Kvs[1].Value) != "v2" {
+		t.Errorf("Export() of etcdctl-json = %s, want the 2 keys of x:", etcdctl.String())
+	}
+	if etcdctl.fileName != fmt.Sprintf("etcd-export-%d.json", response.Header.Revision) {
+		t.Errorf("Begin() of etcdctl-json = %q, want the name of the revision %d", etcdctl.fileName, response.Header.Revision)
+	}
+}
@@ -0,0 +1,547 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	v3 "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+)
+
+// changes of the diffs
+const (
+	changeAdded     = "added"
+	changeRemoved   = "removed"
+	changeModified  = "modified"
+	changeRewritten = "rewritten"
+)
+
+// History walks back through the mod revisions of a key, reading at the earlier revisions until the compaction.
+// The history of a key deleted at "rev" starts at its last deletion.
+func (c *clientService) History(ctx context.Context, req HistoryRequest) (datamodels.KeyHistory, error) {
+	etcdClient := c.deps.Client
+
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, req.Timeout, 15*time.Second)
+	defer timeoutCancelFn()
+
+	var retHistory datamodels.KeyHistory
+
+	version, err := etcdClient.Version()
+	if err != nil {
+		return retHistory, err
+	}
+	if version.Major() == 2 {
+		return retHistory, backend.NewUnsupportedError("cannot support v2 now")
+	} else {
+		encoding, err := parseEncoding(req.Encoding)
+		if err != nil {
+			return retHistory, err
+		}
+
+		key, err := decodeString(req.Key, encoding)
+		if err != nil {
+			return retHistory, backend.NewBadRequestError(fmt.Sprintf(`bad "key", expecting %s`, encoding))
+		}
+		if len(key) == 0 {
+			return retHistory, backend.NewBadRequestError(`"key" is required`)
+		}
+
+		rev := req.Rev
+
+		limit := req.Limit
+		if limit <= 0 {
+			limit = 100
+		}
+
+		client, err := etcdClient.V3()
+		if err != nil {
+			return retHistory, err
+		}
+
+		keyValue := newKeyValue(&mvccpb.KeyValue{Key: []byte(key)}, encoding, false)
+		retHistory.Key = keyValue.Key
+		retHistory.Encoding = keyValue.Encoding
+
+		getAt := func(rev int64) (*mvccpb.KeyValue, int64, error) {
+			var opts []v3.OpOption
+			if rev > 0 {
+				opts = append(opts, v3.WithRev(rev))
+			}
+
+			getResp, err := client.Get(timeoutCtx, key, opts...)
+			if err != nil {
+				return nil, 0, err
+			}
+			if len(getResp.Kvs) == 0 {
+				return nil, getResp.Header.Revision, nil
+			}
+			return getResp.Kvs[0], getResp.Header.Revision, nil
+		}
+
+		kv, headerRev, err := getAt(rev)
+		if err != nil {
+			return retHistory, err
+		}
+		if rev <= 0 {
+			rev = headerRev
+		}
+		retHistory.Revision = rev
+
+		if kv == nil {
+			deletion, compacted, err := findLastDeletion(timeoutCtx, client, key, rev)
+			if err != nil {
+				return retHistory, err
+			}
+			if deletion == 0 {
+				// never written, unless it's compacted
+				retHistory.Compacted = compacted
+			} else {
+				retHistory.Versions = append(retHistory.Versions, datamodels.KeyVersion{
+					KeyValue: datamodels.KeyValue{
+						Key:         retHistory.Key,
+						Encoding:    retHistory.Encoding,
+						ModRevision: deletion,
+					},
+					Deleted: true,
+				})
+
+				kv, _, err = getAt(deletion - 1)
+				if err == rpctypes.ErrCompacted {
+					retHistory.Compacted = true
+				} else if err != nil {
+					return retHistory, err
+				}
+			}
+		}
+
+		// the revisions of the deletions, read once the walk reaches the start of a life
+		var (
+			deletions          []int64
+			deletionsRead      bool
+			deletionsCompacted bool
+		)
+		for kv != nil {
+			if int64(len(retHistory.Versions)) >= limit {
+				retHistory.More = true
+				break
+			}
+			retHistory.Versions = append(retHistory.Versions, datamodels.KeyVersion{
+				KeyValue: newKeyValue(kv, encoding, true),
+			})
+
+			// the previous version of the same life
+			prevRev := kv.ModRevision - 1
+			if kv.Version == 1 {
+				// the previous life ends at the last deletion before this one is created
+				if !deletionsRead {
+					deletions, deletionsCompacted, err = findDeletions(timeoutCtx, client, key, kv.CreateRevision)
+					if err != nil {
+						return retHistory, err
+					}
+					deletionsRead = true
+				}
+
+				var deletion int64
+				for len(deletions) != 0 && deletion == 0 {
+					if last := deletions[len(deletions)-1]; last < kv.CreateRevision {
+						deletion = last
+					}
+					deletions = deletions[:len(deletions)-1]
+				}
+				if deletion == 0 {
+					// the first life, unless the earlier ones are compacted
+					retHistory.Compacted = deletionsCompacted
+					break
+				}
+
+				if int64(len(retHistory.Versions)) >= limit {
+					retHistory.More = true
+					break
+				}
+				retHistory.Versions = append(retHistory.Versions, datamodels.KeyVersion{
+					KeyValue: datamodels.KeyValue{
+						Key:         retHistory.Key,
+						Encoding:    retHistory.Encoding,
+						ModRevision: deletion,
+					},
+					Deleted: true,
+				})
+				prevRev = deletion - 1
+			}
+			if prevRev <= 0 {
+				break
+			}
+
+			prevKV, _, err := getAt(prevRev)
+			if err == rpctypes.ErrCompacted {
+				retHistory.Compacted = true
+				break
+			}
+			if err != nil {
+				return retHistory, err
+			}
+			kv = prevKV
+		}
+
+		// the audit records keep the previous values instead of the written revisions, so only the journal can tell
+		times := c.undoJournal.times([]byte(key))
+		for idx := range retHistory.Versions {
+			if t, ok := times[retHistory.Versions[idx].ModRevision]; ok {
+				jsonTime := backend.JSONTime(t)
+				retHistory.Versions[idx].Time = &jsonTime
+			}
+		}
+	}
+
+	return retHistory, nil
+}
+
+// findDeletions returns the revisions of the deletions of the key before the revision from the oldest,
+// and whether the earlier ones are compacted. The watch ends at the revision, so the key must be put at it.
+func findDeletions(ctx context.Context, client *v3.Client, key string, before int64) ([]int64, bool, error) {
+	var (
+		deletions []int64
+		compacted bool
+		from      int64 = 1
+	)
+
+	for {
+		watchCtx, watchCancelFn := context.WithCancel(ctx)
+
+		// the historical events are sent at once
+		var compactRev int64
+	events:
+		for watchResp := range client.Watch(watchCtx, key, v3.WithRev(from)) {
+			if watchResp.CompactRevision != 0 {
+				compactRev = watchResp.CompactRevision
+				break
+			}
+			if err := watchResp.Err(); err != nil {
+				watchCancelFn()
+				return nil, compacted, err
+			}
+
+			for _, event := range watchResp.Events {
+				if event.Kv.ModRevision >= before {
+					break events
+				}
+				if event.Type == mvccpb.DELETE {
+					deletions = append(deletions, event.Kv.ModRevision)
+				}
+			}
+		}
+		watchCancelFn()
+
+		switch {
+		case compactRev >= before:
+			return deletions, true, nil
+		case compactRev != 0:
+			// again from the compaction
+			compacted, from = true, compactRev
+		case ctx.Err() != nil:
+			return nil, compacted, ctx.Err()
+		default:
+			return deletions, compacted, nil
+		}
+	}
+}
+
+// lastDeletionWindow is how many revisions are watched at once by findLastDeletion.
+const lastDeletionWindow = 1000
+
+// findLastDeletion returns the revision of the last deletion of the key up to the revision, which the key doesn't exist at,
+// and whether the earlier revisions are compacted. The watch of the key alone cannot tell when its historical events end,
+// so the whole keyspace is watched back window by window, as every revision but the first one has events to end at.
+func findLastDeletion(ctx context.Context, client *v3.Client, key string, rev int64) (int64, bool, error) {
+	var compactRev int64
+
+	for to := rev; to > 1; {
+		from := to - lastDeletionWindow + 1
+		if from < 2 {
+			from = 2
+		}
+		if from < compactRev {
+			from = compactRev
+		}
+
+		var (
+			deletion int64
+			ended    bool
+		)
+		watchCtx, watchCancelFn := context.WithCancel(ctx)
+		for watchResp := range client.Watch(watchCtx, "\x00", v3.WithFromKey(), v3.WithRev(from)) {
+			if watchResp.CompactRevision != 0 {
+				compactRev = watchResp.CompactRevision
+				break
+			}
+			if err := watchResp.Err(); err != nil {
+				watchCancelFn()
+				return 0, false, err
+			}
+
+			// the events of a revision are sent together
+			for _, event := range watchResp.Events {
+				if event.Kv.ModRevision > to {
+					break
+				}
+				if string(event.Kv.Key) == key && event.Type == mvccpb.DELETE {
+					deletion = event.Kv.ModRevision
+				}
+			}
+			if events := watchResp.Events; len(events) != 0 && events[len(events)-1].Kv.ModRevision >= to {
+				ended = true
+				break
+			}
+		}
+		watchCancelFn()
+
+		switch {
+		case deletion != 0:
+			return deletion, false, nil
+		case compactRev > to:
+			return 0, true, nil
+		case !ended && compactRev > from:
+			// again from the compaction
+			continue
+		case !ended && ctx.Err() != nil:
+			return 0, false, ctx.Err()
+		case !ended:
+			return 0, false, errors.New("watch ended before the revisions are read")
+		case from == compactRev:
+			return 0, true, nil
+		}
+
+		to = from - 1
+	}
+
+	return 0, false, nil
+}
+
+// Diff compares a key, or the keys under a prefix, between two revisions.
+// The prefix is compared page by page, each reads up to "limit" keys of both revisions,
+// the cursor continues after the last compared key, at the same revisions.
+func (c *clientService) Diff(ctx context.Context, req DiffRequest) (datamodels.KeyDiff, error) {
+	etcdClient := c.deps.Client
+
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, req.Timeout, 15*time.Second)
+	defer timeoutCancelFn()
+
+	var retDiff datamodels.KeyDiff
+
+	version, err := etcdClient.Version()
+	if err != nil {
+		return retDiff, err
+	}
+	if version.Major() == 2 {
+		return retDiff, backend.NewUnsupportedError("cannot support v2 now")
+	} else {
+		encoding, err := parseEncoding(req.Encoding)
+		if err != nil {
+			return retDiff, err
+		}
+
+		key, err := decodeString(req.Key, encoding)
+		if err != nil {
+			return retDiff, backend.NewBadRequestError(fmt.Sprintf(`bad "key", expecting %s`, encoding))
+		}
+
+		prefix := req.Prefix
+		if len(key) == 0 && !prefix {
+			return retDiff, backend.NewBadRequestError(`"key" is required`)
+		}
+
+		from := req.From
+		if from <= 0 {
+			return retDiff, backend.NewBadRequestError(`"from" revision is required`)
+		}
+
+		// the latest by default
+		to := req.To
+
+		// the values before and after are returned besides the patches
+		values := req.Values
+
+		limit := req.Limit
+		if limit <= 0 {
+			limit = 1000
+		}
+
+		start, end := key, ""
+		if prefix {
+			end = v3.GetPrefixRangeEnd(key)
+			if len(start) == 0 {
+				start = "\x00"
+			}
+		}
+
+		// continue after the last compared key, at the revision of the first page
+		if len(req.Cursor) != 0 {
+			cursor, err := decodeReadCursor(req.Cursor)
+			if err != nil {
+				return retDiff, err
+			}
+			if !prefix {
+				return retDiff, backend.NewBadRequestError(`"cursor" can only be used with "prefix"`)
+			}
+			start, to = string(cursor.Key)+"\x00", cursor.Rev
+		}
+
+		client, err := etcdClient.V3()
+		if err != nil {
+			return retDiff, err
+		}
+
+		afterKVs, afterMore, to, err := rangeAt(timeoutCtx, client, start, end, to, limit)
+		if err != nil {
+			return retDiff, err
+		}
+		beforeKVs, beforeMore, _, err := rangeAt(timeoutCtx, client, start, end, from, limit)
+		if err != nil {
+			return retDiff, err
+		}
+		retDiff.From, retDiff.To = from, to
+
+		// a side with more keys bounds the page, the keys of the other side after its last one are left to the next page
+		var last []byte
+		for _, side := range []struct {
+			kvs  []*mvccpb.KeyValue
+			more bool
+		}{{afterKVs, afterMore}, {beforeKVs, beforeMore}} {
+			if !side.more || len(side.kvs) == 0 {
+				continue
+			}
+			if sideLast := side.kvs[len(side.kvs)-1].Key; last == nil || bytes.Compare(sideLast, last) < 0 {
+				last = sideLast
+			}
+		}
+		if last != nil {
+			afterKVs, beforeKVs = truncateKVs(afterKVs, last), truncateKVs(beforeKVs, last)
+
+			retDiff.More = true
+			retDiff.Cursor = encodeReadCursor(readCursor{
+				Key: last,
+				Rev: to,
+			})
+		}
+
+		newChange := func(kv *mvccpb.KeyValue, change string) datamodels.KeyChange {
+			keyValue := newKeyValue(&mvccpb.KeyValue{Key: kv.Key}, encoding, false)
+			return datamodels.KeyChange{
+				Key:      keyValue.Key,
+				Encoding: keyValue.Encoding,
+				Change:   change,
+			}
+		}
+		withValues := func(change *datamodels.KeyChange, before, after *mvccpb.KeyValue) {
+			if !values {
+				return
+			}
+			if before != nil {
+				keyValue := newKeyValue(before, encoding, true)
+				change.Before = &keyValue
+			}
+			if after != nil {
+				keyValue := newKeyValue(after, encoding, true)
+				change.After = &keyValue
+			}
+		}
+
+		// both are sorted by key
+		for i, j := 0, 0; i < len(beforeKVs) || j < len(afterKVs); {
+			var cmp int
+			switch {
+			case i == len(beforeKVs):
+				cmp = 1
+			case j == len(afterKVs):
+				cmp = -1
+			default:
+				cmp = bytes.Compare(beforeKVs[i].Key, afterKVs[j].Key)
+			}
+
+			switch {
+			case cmp < 0:
+				change := newChange(beforeKVs[i], changeRemoved)
+				withValues(&change, beforeKVs[i], nil)
+				retDiff.Changes = append(retDiff.Changes, change)
+				retDiff.Removed++
+				i++
+			case cmp > 0:
+				change := newChange(afterKVs[j], changeAdded)
+				withValues(&change, nil, afterKVs[j])
+				retDiff.Changes = append(retDiff.Changes, change)
+				retDiff.Added++
+				j++
+			default:
+				before, after := beforeKVs[i], afterKVs[j]
+				i++
+				j++
+
+				if before.ModRevision == after.ModRevision {
+					continue
+				}
+				if bytes.Equal(before.Value, after.Value) {
+					change := newChange(after, changeRewritten)
+					withValues(&change, before, after)
+					retDiff.Changes = append(retDiff.Changes, change)
+					retDiff.Rewritten++
+					continue
+				}
+
+				change := newChange(after, changeModified)
+				withValues(&change, before, after)
+				if utf8.Valid(before.Value) && utf8.Valid(after.Value) {
+					change.Patch = diffLines(string(before.Value), string(after.Value))
+				}
+				retDiff.Changes = append(retDiff.Changes, change)
+				retDiff.Modified++
+			}
+		}
+	}
+
+	return retDiff, nil
+}
+
+// rangeAt reads up to the limit of keys of the range at the revision, the latest one if zero,
+// an empty end means the single key.
+func rangeAt(ctx context.Context, client *v3.Client, start, end string, rev, limit int64) ([]*mvccpb.KeyValue, bool, int64, error) {
+	opts := []v3.OpOption{
+		v3.WithLimit(limit),
+		v3.WithSort(v3.SortByKey, v3.SortAscend),
+	}
+	if len(end) != 0 {
+		opts = append(opts, v3.WithRange(end))
+	}
+	if rev > 0 {
+		opts = append(opts, v3.WithRev(rev))
+	}
+
+	getResp, err := client.Get(ctx, start, opts...)
+	if err != nil {
+		if err == rpctypes.ErrCompacted {
+			return nil, false, 0, backend.NewBadRequestError(fmt.Sprintf("revision %d is compacted", rev))
+		}
+		return nil, false, 0, err
+	}
+	if rev <= 0 {
+		rev = getResp.Header.Revision
+	}
+
+	return getResp.Kvs, getResp.More, rev, nil
+}
+
+// truncateKVs drops the key values after the last key, they are sorted by key.
+func truncateKVs(kvs []*mvccpb.KeyValue, last []byte) []*mvccpb.KeyValue {
+	for idx := len(kvs); idx > 0; idx-- {
+		if bytes.Compare(kvs[idx-1].Key, last) <= 0 {
+			return kvs[:idx]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+)
+
+func TestClientHistory(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	first := e.Put(t, "/a", "v1")
+	second := e.Put(t, "/a", "v2")
+	deleteResp, err := e.Client.Delete(context.Background(), "/a")
+	if err != nil {
+		t.Fatalf("cannot delete /a, %v", err)
+	}
+	deleted := deleteResp.Header.Revision
+	third := e.Put(t, "/a", "v3")
+
+	service := NewClientService(deps)
+	ctx := context.Background()
+
+	history, err := service.History(ctx, HistoryRequest{Key: "/a"})
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	type version struct {
+		value       string
+		modRevision int64
+		deleted     bool
+	}
+	var got []version
+	for _, v := range history.Versions {
+		got = append(got, version{v.Value, v.ModRevision, v.Deleted})
+	}
+	want := []version{
+		{"v3", third, false},
+		{"", deleted, true},
+		{"v2", second, false},
+		{"v1", first, false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("History() = %+v, want %+v", got, want)
+	}
+	if history.Revision != third || history.More || history.Compacted {
+		t.Errorf("History() = revision %d, more %v, compacted %v, want %d", history.Revision, history.More, history.Compacted, third)
+	}
+
+	// from a revision of the previous life, and limited
+	history, err = service.History(ctx, HistoryRequest{Key: "/a", Rev: second, Limit: 1})
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(history.Versions) != 1 || history.Versions[0].Value != "v2" || !history.More {
+		t.Errorf("History() = %+v, want v2 with more", history)
+	}
+
+	if _, err := service.History(ctx, HistoryRequest{}); errorCode(err) != backend.ErrCodeBadRequest {
+		t.Errorf("History() without the key error = %v, want bad request", err)
+	}
+
+	// the deletion and the last version of the previous life are kept by the compaction
+	if _, err := e.Client.Compact(ctx, second); err != nil {
+		t.Fatalf("cannot compact, %v", err)
+	}
+	history, err = service.History(ctx, HistoryRequest{Key: "/a"})
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(history.Versions) != 3 || history.Versions[1].ModRevision != deleted || history.Versions[2].Value != "v2" || !history.Compacted {
+		t.Errorf("History() = %+v, want v3, the deletion and v2, compacted", history)
+	}
+}
+
+func TestClientHistoryDeleted(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	first := e.Put(t, "/a", "v1")
+	deleteResp, err := e.Client.Delete(context.Background(), "/a")
+	if err != nil {
+		t.Fatalf("cannot delete /a, %v", err)
+	}
+	deleted := deleteResp.Header.Revision
+	e.Put(t, "/b", "v1")
+
+	service := NewClientService(deps)
+	ctx := context.Background()
+
+	// the history of a deleted key starts at its last deletion
+	for _, rev := range []int64{0, deleted} {
+		history, err := service.History(ctx, HistoryRequest{Key: "/a", Rev: rev})
+		if err != nil {
+			t.Fatalf("History() error = %v", err)
+		}
+		if len(history.Versions) != 2 || !history.Versions[0].Deleted || history.Versions[0].ModRevision != deleted ||
+			history.Versions[1].Value != "v1" || history.Versions[1].ModRevision != first || history.Compacted {
+			t.Errorf("History() at %d = %+v, want the deletion and v1", rev, history)
+		}
+	}
+
+	// a key never written has no history
+	history, err := service.History(ctx, HistoryRequest{Key: "/c"})
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(history.Versions) != 0 || history.Compacted {
+		t.Errorf("History() = %+v, want none", history)
+	}
+
+	// unless the earlier revisions are compacted
+	if _, err := e.Client.Compact(ctx, deleted); err != nil {
+		t.Fatalf("cannot compact, %v", err)
+	}
+	history, err = service.History(ctx, HistoryRequest{Key: "/c"})
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(history.Versions) != 0 || !history.Compacted {
+		t.Errorf("History() = %+v, want none but compacted", history)
+	}
+}
+
+func TestClientDiff(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	from := e.Put(t,
+		"/a/1", "a\nb\n",
+		"/a/2", "v2",
+		"/a/3", "v3",
+		"/a/4", "v4",
+	)
+	e.Put(t,
+		"/a/1", "a\nc\n",
+		"/a/2", "v2",
+		"/a/5", "v5",
+	)
+	if _, err := e.Client.Delete(context.Background(), "/a/3"); err != nil {
+		t.Fatalf("cannot delete /a/3, %v", err)
+	}
+
+	service := NewClientService(deps)
+	ctx := context.Background()
+
+	diff, err := service.Diff(ctx, DiffRequest{Key: "/a/", Prefix: true, From: from, Values: true})
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if diff.Added != 1 || diff.Removed != 1 || diff.Modified != 1 || diff.Rewritten != 1 {
+		t.Errorf("Diff() = %+v, want one change of each", diff)
+	}
+
+	changes := make(map[string]string, len(diff.Changes))
+	for _, change := range diff.Changes {
+		changes[change.Key] = change.Change
+	}
+	wantChanges := map[string]string{
+		"/a/1": changeModified,
+		"/a/2": changeRewritten,
+		"/a/3": changeRemoved,
+		"/a/5": changeAdded,
+	}
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Errorf("Diff() changes = %v, want %v", changes, wantChanges)
+	}
+
+	modified := diff.Changes[0]
+	if modified.Patch != " a\n-b\n+c\n" {
+		t.Errorf("Diff() patch = %q", modified.Patch)
+	}
+	if modified.Before == nil || modified.After == nil || modified.Before.Value != "a\nb\n" || modified.After.Value != "a\nc\n" {
+		t.Errorf("Diff() values = %+v, %+v", modified.Before, modified.After)
+	}
+
+	// a single key, without the values
+	diff, err = service.Diff(ctx, DiffRequest{Key: "/a/1", From: from})
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if len(diff.Changes) != 1 || diff.Changes[0].Before != nil {
+		t.Errorf("Diff() = %+v, want the modification only", diff)
+	}
+
+	if _, err := service.Diff(ctx, DiffRequest{Key: "/a/1"}); errorCode(err) != backend.ErrCodeBadRequest {
+		t.Errorf("Diff() without the revision error = %v, want bad request", err)
+	}
+
+	// page by page, at the revision of the first page
+	pagedChanges := map[string]string{}
+	req := DiffRequest{Key: "/a/", Prefix: true, From: from, Limit: 1}
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatalf("Diff() pages more than the keys")
+		}
+		diff, err := service.Diff(ctx, req)
+		if err != nil {
+			t.Fatalf("Diff() error = %v", err)
+		}
+		for _, change := range diff.Changes {
+			pagedChanges[change.Key] = change.Change
+		}
+		if !diff.More {
+			break
+		}
+		if pages == 0 {
+			e.Put(t, "/a/6", "v6")
+		}
+		req.Cursor = diff.Cursor
+	}
+	if !reflect.DeepEqual(pagedChanges, wantChanges) {
+		t.Errorf("Diff() paged changes = %v, want %v", pagedChanges, wantChanges)
+	}
+
+	if _, err := service.Diff(ctx, DiffRequest{Key: "/a/1", From: from, Cursor: req.Cursor}); errorCode(err) != backend.ErrCodeBadRequest {
+		t.Errorf("Diff() of a single key with a cursor error = %v, want bad request", err)
+	}
+}
+
+func TestClientHistoryTime(t *testing.T) {
+	e, deps := startDependencies(t, func(configuration *backend.Configuration) {
+		configuration.JournalSize = 100
+	})
+	defer closeDependencies(e, deps)
+
+	// the seeded version is not written by the console
+	e.Put(t, "/a", "v1")
+
+	service := NewClientService(deps)
+	ctx := context.Background()
+
+	if _, _, err := service.Set(ctx, SetRequest{ClientSetRequest: viewmodels.ClientSetRequest{Key: "/a", Value: "v2"}}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, _, err := service.Del(ctx, DelRequest{KeyRange: KeyRange{Key: "/a"}}); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	if _, _, err := service.Set(ctx, SetRequest{ClientSetRequest: viewmodels.ClientSetRequest{Key: "/a", Value: "v3"}}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	history, err := service.History(ctx, HistoryRequest{Key: "/a"})
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	var timed []bool
+	for _, version := range history.Versions {
+		timed = append(timed, version.Time != nil)
+	}
+	if want := []bool{true, true, true, false}; !reflect.DeepEqual(timed, want) {
+		t.Errorf("History() versions with the time = %v, want %v", timed, want)
+	}
+}
@@ -0,0 +1,533 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	v3 "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/schema"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+	"gopkg.in/yaml.v2"
+)
+
+// actions of the import plans
+const (
+	importCreate    = "create"
+	importUpdate    = "update"
+	importUnchanged = "unchanged"
+	importDelete    = "delete"
+	importInvalid   = "invalid"
+)
+
+// results of the applied actions
+const (
+	importApplied  = "applied"
+	importConflict = "conflict"
+	importFailed   = "failed"
+)
+
+// ImportMaxSize is the limit of the imported files, they're read in memory.
+const ImportMaxSize = 64 << 20
+
+type importEntry struct {
+	key   string
+	value string
+	// by the plan
+	modRevision int64
+}
+
+func (c *clientService) Import(ctx context.Context, req ImportRequest) (datamodels.ImportPlan, error) {
+	etcdClient := c.deps.Client
+
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, req.Timeout, 60*time.Second)
+	defer timeoutCancelFn()
+
+	var retPlan datamodels.ImportPlan
+
+	version, err := etcdClient.Version()
+	if err != nil {
+		return retPlan, err
+	}
+	if version.Major() == 2 {
+		return retPlan, backend.NewUnsupportedError("cannot support v2 now")
+	} else {
+		key := req.Key
+
+		delimiter := req.Delimiter
+		if len(delimiter) == 0 {
+			delimiter = "/"
+		}
+
+		dryRun := req.DryRun
+
+		// keys absent in the file are deleted under the "key" prefix
+		prune := req.Prune
+		if prune && len(key) == 0 {
+			return retPlan, backend.NewBadRequestError(`"prune" needs the "key" prefix`)
+		}
+
+		// the default "--max-txn-ops" of etcd
+		maxTxnOps := req.MaxTxnOps
+		if maxTxnOps <= 0 {
+			maxTxnOps = 128
+		}
+
+		data, name := req.Data, req.Name
+		if len(data) > ImportMaxSize {
+			return retPlan, backend.NewBadRequestError(fmt.Sprintf("the file is larger than %d bytes", ImportMaxSize))
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			return retPlan, backend.NewBadRequestError("the file is empty")
+		}
+
+		format, err := detectImportFormat(req.Format, name, data)
+		if err != nil {
+			return retPlan, err
+		}
+
+		entries, err := parseImport(data, format, key, delimiter)
+		if err != nil {
+			return retPlan, backend.NewBadRequestError(fmt.Sprintf("bad %s file, %v", format, err))
+		}
+
+		client, err := etcdClient.V3()
+		if err != nil {
+			return retPlan, err
+		}
+
+		configuration := c.deps.Configuration
+		if !dryRun {
+			if err := configuration.CheckWritable(); err != nil {
+				return retPlan, err
+			}
+		}
+
+		retPlan, entries, err = planImport(timeoutCtx, client, entries, key, prune, maxTxnOps, c.deps.Schemas, configuration)
+		if err != nil {
+			return retPlan, err
+		}
+
+		retPlan.DryRun = dryRun
+		if !dryRun {
+			journalKeys := applyImport(timeoutCtx, client, &retPlan, entries, maxTxnOps, c.journaling())
+			retPlan.Journal = c.journal(journalImport, journalKeys)
+		}
+	}
+
+	return retPlan, nil
+}
+
+// detectImportFormat goes by the "format", the file extension, then the content.
+func detectImportFormat(format string, name string, data []byte) (string, error) {
+	if len(format) != 0 {
+		format, err := parseExportFormat(format)
+		if err != nil {
+			return "", err
+		}
+		if format == exportEtcdctl {
+			return "", backend.NewBadRequestError(`the lines of "etcdctl" are ambiguous for multi-line values, import "etcdctl-json" instead`)
+		}
+		return format, nil
+	}
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jsonl", ".ndjson":
+		return exportJSONLines, nil
+	case ".yaml", ".yml":
+		return exportYAML, nil
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if trimmed[0] != '{' {
+		return exportYAML, nil
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &object); err != nil {
+		// more than one object
+		return exportJSONLines, nil
+	}
+	if _, ok := object["header"]; ok {
+		if _, ok := object["kvs"]; ok {
+			return exportEtcdctlJSON, nil
+		}
+	}
+	// a single line
+	if _, ok := object["key"]; ok {
+		if _, ok := object["value"]; ok {
+			return exportJSONLines, nil
+		}
+	}
+
+	return exportJSON, nil
+}
+
+func parseImport(data []byte, format string, prefix string, delimiter string) ([]importEntry, error) {
+	var entries []importEntry
+
+	switch format {
+	case exportJSONLines:
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 64*1024), ImportMaxSize)
+
+		for line := 1; scanner.Scan(); line++ {
+			text := bytes.TrimSpace(scanner.Bytes())
+			if len(text) == 0 {
+				continue
+			}
+
+			var exportKeyValue datamodels.ExportKeyValue
+			if err := json.Unmarshal(text, &exportKeyValue); err != nil {
+				return nil, errors.New(fmt.Sprintf("line %d, %v", line, err))
+			}
+			entry, err := newImportEntry(exportKeyValue.Key, exportKeyValue.Value, exportKeyValue.Encoding)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("line %d, %v", line, err))
+			}
+			entries = append(entries, entry)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	case exportEtcdctlJSON:
+		var rangeResp struct {
+			Kvs []*mvccpb.KeyValue `json:"kvs"`
+		}
+		if err := json.Unmarshal(data, &rangeResp); err != nil {
+			return nil, err
+		}
+		for _, kv := range rangeResp.Kvs {
+			entries = append(entries, importEntry{key: string(kv.Key), value: string(kv.Value)})
+		}
+	case exportJSON, exportYAML:
+		var document interface{}
+		if format == exportYAML {
+			if err := yaml.Unmarshal(data, &document); err != nil {
+				return nil, err
+			}
+		} else {
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.UseNumber()
+			if err := decoder.Decode(&document); err != nil {
+				return nil, err
+			}
+		}
+
+		var err error
+		if entries, err = walkDocument(entries, prefix, delimiter, document, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+func newImportEntry(key string, value string, encoding string) (importEntry, error) {
+	encoding, err := parseEncoding(encoding)
+	if err != nil {
+		return importEntry{}, err
+	}
+
+	if key, err = decodeString(key, encoding); err != nil {
+		return importEntry{}, backend.NewBadRequestError(fmt.Sprintf(`bad "key", expecting %s`, encoding))
+	}
+	if value, err = decodeString(value, encoding); err != nil {
+		return importEntry{}, backend.NewBadRequestError(fmt.Sprintf(`bad "value" of %s, expecting %s`, key, encoding))
+	}
+
+	return importEntry{key: key, value: value}, nil
+}
+
+// walkDocument is the reverse of the documentExporter, the document is relative to the prefix.
+func walkDocument(entries []importEntry, key string, delimiter string, node interface{}, root bool) ([]importEntry, error) {
+	var members map[string]interface{}
+
+	switch typed := node.(type) {
+	case map[string]interface{}:
+		members = typed
+	case map[interface{}]interface{}:
+		members = make(map[string]interface{}, len(typed))
+		for member, value := range typed {
+			members[fmt.Sprint(member)] = value
+		}
+	case []interface{}:
+		return nil, errors.New(fmt.Sprintf("unexpected list under %s", key))
+	case nil:
+		return entries, nil
+	default:
+		if root {
+			return nil, errors.New("unexpected value at the root")
+		}
+		// plain strings, and the numbers or booleans of the hand-written documents
+		return append(entries, importEntry{key: key, value: fmt.Sprint(typed)}), nil
+	}
+
+	if value, ok := members[documentValue]; ok {
+		// only the value is encoded in documents
+		valueEncoding, _ := members[documentEncoding].(string)
+		encoding, err := parseEncoding(valueEncoding)
+		if err != nil {
+			return nil, err
+		}
+		decoded, err := decodeString(fmt.Sprint(value), encoding)
+		if err != nil {
+			return nil, backend.NewBadRequestError(fmt.Sprintf(`bad "value" of %s, expecting %s`, key, encoding))
+		}
+		entries = append(entries, importEntry{key: key, value: decoded})
+	}
+
+	var err error
+	for member, child := range members {
+		if strings.HasPrefix(member, "$") {
+			continue
+		}
+
+		childKey := key + delimiter + member
+		if root {
+			childKey = key + member
+		}
+		if entries, err = walkDocument(entries, childKey, delimiter, child, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// planImport reads the current values at one revision, and sorts the entries by key.
+func planImport(ctx context.Context, client *v3.Client, entries []importEntry, prefix string, prune bool, maxTxnOps int64, schemas *schema.Mapping, configuration backend.Configuration) (datamodels.ImportPlan, []importEntry, error) {
+	var retPlan datamodels.ImportPlan
+
+	// the last one wins
+	byKey := make(map[string]importEntry, len(entries))
+	for _, entry := range entries {
+		byKey[entry.key] = entry
+	}
+	entries = entries[:0]
+	for _, entry := range byKey {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	var (
+		rev     int64
+		current = make(map[string]*mvccpb.KeyValue, len(entries))
+	)
+	for start := 0; start < len(entries); start += int(maxTxnOps) {
+		stop := start + int(maxTxnOps)
+		if stop > len(entries) {
+			stop = len(entries)
+		}
+
+		ops := make([]v3.Op, 0, stop-start)
+		for _, entry := range entries[start:stop] {
+			var opts []v3.OpOption
+			// pin all reads to the revision of the first one
+			if rev > 0 {
+				opts = append(opts, v3.WithRev(rev))
+			}
+			ops = append(ops, v3.OpGet(entry.key, opts...))
+		}
+
+		txnResp, err := client.Txn(ctx).Then(ops...).Commit()
+		if err != nil {
+			return retPlan, nil, err
+		}
+		if rev <= 0 {
+			rev = txnResp.Header.Revision
+		}
+		for _, resp := range txnResp.Responses {
+			for _, kv := range resp.GetResponseRange().Kvs {
+				current[string(kv.Key)] = kv
+			}
+		}
+	}
+
+	for idx := range entries {
+		entry := &entries[idx]
+		item := datamodels.ImportItem{
+			Key: entry.key,
+		}
+
+		kv, exists := current[entry.key]
+		switch {
+		case exists && string(kv.Value) == entry.value:
+			item.Action = importUnchanged
+			retPlan.Unchanged++
+		case exists:
+			item.Action = importUpdate
+			entry.modRevision = kv.ModRevision
+			retPlan.Updates++
+		default:
+			item.Action = importCreate
+			retPlan.Creates++
+		}
+
+		if item.Action != importUnchanged {
+			err := configuration.CheckProtected(entry.key, "")
+			if err == nil {
+				err = schemas.Validate([]byte(entry.key), []byte(entry.value))
+			}
+			if err != nil {
+				if item.Action == importUpdate {
+					retPlan.Updates--
+				} else {
+					retPlan.Creates--
+				}
+				item.Action = importInvalid
+				item.Err = err.Error()
+				retPlan.Invalid++
+			}
+		}
+
+		retPlan.Items = append(retPlan.Items, item)
+	}
+
+	if prune {
+		if rev <= 0 {
+			getResp, err := client.Get(ctx, prefix, v3.WithPrefix(), v3.WithCountOnly())
+			if err != nil {
+				return retPlan, nil, err
+			}
+			rev = getResp.Header.Revision
+		}
+
+		start, end := prefix, v3.GetPrefixRangeEnd(prefix)
+		for {
+			getResp, err := client.Get(ctx, start,
+				v3.WithRange(end),
+				v3.WithKeysOnly(),
+				v3.WithRev(rev),
+				v3.WithLimit(1000),
+				v3.WithSort(v3.SortByKey, v3.SortAscend),
+			)
+			if err != nil {
+				return retPlan, nil, err
+			}
+
+			for _, kv := range getResp.Kvs {
+				if _, ok := byKey[string(kv.Key)]; ok {
+					continue
+				}
+
+				entries = append(entries, importEntry{key: string(kv.Key), modRevision: kv.ModRevision})
+				if err := configuration.CheckProtected(string(kv.Key), ""); err != nil {
+					retPlan.Items = append(retPlan.Items, datamodels.ImportItem{
+						Key:    string(kv.Key),
+						Action: importInvalid,
+						Err:    err.Error(),
+					})
+					retPlan.Invalid++
+					continue
+				}
+				retPlan.Items = append(retPlan.Items, datamodels.ImportItem{
+					Key:    string(kv.Key),
+					Action: importDelete,
+				})
+				retPlan.Deletes++
+			}
+
+			if !getResp.More || len(getResp.Kvs) == 0 {
+				break
+			}
+			start = string(getResp.Kvs[len(getResp.Kvs)-1].Key) + "\x00"
+		}
+	}
+
+	// binary keys are rendered in base64
+	for idx := range retPlan.Items {
+		item := &retPlan.Items[idx]
+		if keyValue := newKeyValue(&mvccpb.KeyValue{Key: []byte(item.Key)}, encodingUTF8, false); keyValue.Binary {
+			item.Key, item.Encoding = keyValue.Key, keyValue.Encoding
+		}
+	}
+
+	retPlan.Revision = rev
+	return retPlan, entries, nil
+}
+
+// applyImport writes the planned changes in batches, every key is guarded by its revision in the plan,
+// so a batch is not applied if any of its keys is changed after planning.
+// The previous values of the applied keys are returned for the journal if journaling.
+func applyImport(ctx context.Context, client *v3.Client, plan *datamodels.ImportPlan, entries []importEntry, maxTxnOps int64, journaling bool) []journalKey {
+	var (
+		cmps        []v3.Cmp
+		ops         []v3.Op
+		batched     []int
+		journalKeys []journalKey
+		opts        []v3.OpOption
+	)
+	if journaling {
+		opts = append(opts, v3.WithPrevKV())
+	}
+
+	commit := func() {
+		if len(ops) == 0 {
+			return
+		}
+
+		result, errMsg := importApplied, ""
+		txnResp, err := client.Txn(ctx).If(cmps...).Then(ops...).Commit()
+		if err != nil {
+			result, errMsg = importFailed, err.Error()
+		} else if !txnResp.Succeeded {
+			result, errMsg = importConflict, "changed after planning, the batch is not applied"
+		} else if journaling {
+			journalKeys = appendJournalKeys(journalKeys, ops, txnResp)
+		}
+
+		for _, idx := range batched {
+			plan.Items[idx].Result = result
+			plan.Items[idx].Err = errMsg
+			if result == importApplied {
+				plan.Applied++
+			} else {
+				plan.Failed++
+			}
+		}
+
+		cmps, ops, batched = cmps[:0], ops[:0], batched[:0]
+	}
+
+	// the items are in the same order as the entries
+	for idx := range plan.Items {
+		var (
+			item  = plan.Items[idx]
+			entry = entries[idx]
+		)
+
+		switch item.Action {
+		case importCreate:
+			cmps = append(cmps, v3.Compare(v3.CreateRevision(entry.key), "=", 0))
+			ops = append(ops, v3.OpPut(entry.key, entry.value, opts...))
+		case importUpdate:
+			cmps = append(cmps, v3.Compare(v3.ModRevision(entry.key), "=", entry.modRevision))
+			ops = append(ops, v3.OpPut(entry.key, entry.value, opts...))
+		case importDelete:
+			cmps = append(cmps, v3.Compare(v3.ModRevision(entry.key), "=", entry.modRevision))
+			ops = append(ops, v3.OpDelete(entry.key, opts...))
+		default:
+			continue
+		}
+		batched = append(batched, idx)
+
+		if int64(len(ops)) >= maxTxnOps {
+			commit()
+		}
+	}
+	commit()
+
+	return journalKeys
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/schema"
+)
+
+func TestClientImport(t *testing.T) {
+	e, deps := startDependencies(t, func(configuration *backend.Configuration) {
+		configuration.JournalSize = 100
+	})
+	defer closeDependencies(e, deps)
+
+	s, err := schema.Compile("port", []byte(`{"type": "integer", "minimum": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deps.Schemas = schema.NewMapping()
+	deps.Schemas.Add("/a/port", s)
+
+	e.Put(t,
+		"/a/1", "v1",
+		"/a/2", "v2",
+		"/a/3", "v3",
+	)
+	service := NewClientService(deps)
+	ctx := context.Background()
+
+	data := []byte(`{"key":"/a/1","value":"v1"}
+{"key":"/a/2","value":"changed"}
+{"key":"/a/4","value":"new"}
+{"key":"/a/port","value":"0"}
+`)
+
+	plan, err := service.Import(ctx, ImportRequest{Key: "/a/", Prune: true, DryRun: true, Data: data})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if plan.Unchanged != 1 || plan.Updates != 1 || plan.Creates != 1 || plan.Invalid != 1 || plan.Deletes != 1 || plan.Applied != 0 {
+		t.Errorf("Import() = %+v, want one of each action", plan)
+	}
+	if value, _ := e.Get(t, "/a/2"); value != "v2" {
+		t.Errorf("the dry run changed /a/2 to %q", value)
+	}
+
+	plan, err = service.Import(ctx, ImportRequest{Key: "/a/", Prune: true, Data: data})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if plan.Applied != 3 || plan.Failed != 0 || plan.Journal == 0 {
+		t.Errorf("Import() = %+v, want 3 applied and journaled", plan)
+	}
+	for key, want := range map[string]string{"/a/1": "v1", "/a/2": "changed", "/a/4": "new"} {
+		if value, _ := e.Get(t, key); value != want {
+			t.Errorf("%s = %q, want %q", key, value, want)
+		}
+	}
+	for _, key := range []string{"/a/3", "/a/port"} {
+		if _, ok := e.Get(t, key); ok {
+			t.Errorf("%s exists after the import", key)
+		}
+	}
+
+	tests := []struct {
+		name string
+		req  ImportRequest
+	}{
+		{"empty", ImportRequest{Data: []byte(" \n")}},
+		{"prune without the key", ImportRequest{Prune: true, Data: data}},
+		{"bad line", ImportRequest{Format: "jsonl", Data: []byte("{\"key\":\"/a/1\",\"value\":\"v1\"}\n{\n")}},
+		{"ambiguous format", ImportRequest{Format: "etcdctl", Data: []byte("/a/1\nv1\n")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.Import(ctx, tt.req); errorCode(err) != backend.ErrCodeBadRequest {
+				t.Errorf("Import() error = %v, want bad request", err)
+			}
+		})
+	}
+}
+
+func TestClientExportImport(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/a/1", "v1",
+		"/a/2/x", "v2",
+		"/a/2/y", "\xff\xfe",
+	)
+	service := NewClientService(deps)
+	ctx := context.Background()
+
+	// the formats which can be imported again, under another prefix for the documents
+	for _, format := range []string{"jsonl", "json", "yaml", "etcdctl-json"} {
+		t.Run(format, func(t *testing.T) {
+			var buf exportBuffer
+			if err := service.Export(ctx, ExportRequest{KeyRange: KeyRange{Key: "/a/", Prefix: true}, Format: format}, &buf); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+
+			// the keys of the documents are relative to the prefix
+			prefix, document := "/a/", format == "json" || format == "yaml"
+			if document {
+				prefix = "/" + format + "/"
+			}
+			plan, err := service.Import(ctx, ImportRequest{Key: prefix, Data: buf.Bytes(), Name: buf.fileName})
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+
+			if document {
+				if plan.Creates != 3 || plan.Applied != 3 {
+					t.Errorf("Import() = %+v, want 3 created", plan)
+				}
+				if value, _ := e.Get(t, prefix+"2/y"); value != "\xff\xfe" {
+					t.Errorf("%s2/y = %q after the import", prefix, value)
+				}
+			} else if plan.Unchanged != 3 || plan.Applied != 0 {
+				t.Errorf("Import() = %+v, want all unchanged", plan)
+			}
+		})
+	}
+}
+
+func TestClientImportGuards(t *testing.T) {
+	e, deps := startDependencies(t, func(configuration *backend.Configuration) {
+		configuration.ProtectedPrefixes = []string{"/a/protected/"}
+	})
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/a/1", "v1",
+		"/a/protected/1", "p1",
+	)
+	service := NewClientService(deps)
+	ctx := context.Background()
+
+	// the encoded lines are decoded, the protected keys are neither written nor pruned
+	data := []byte(`{"key":"2f612f32","value":"ff","encoding":"hex"}
+{"key":"/a/protected/2","value":"p2"}
+`)
+	plan, err := service.Import(ctx, ImportRequest{Key: "/a/", Prune: true, Data: data})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if plan.Creates != 1 || plan.Deletes != 1 || plan.Invalid != 2 || plan.Applied != 2 {
+		t.Errorf("Import() = %+v, want /a/2 created, /a/1 pruned and the protected keys invalid", plan)
+	}
+	if value, _ := e.Get(t, "/a/2"); value != "\xff" {
+		t.Errorf("/a/2 = %q, want the decoded value", value)
+	}
+	if value, ok := e.Get(t, "/a/protected/1"); !ok || value != "p1" {
+		t.Errorf("/a/protected/1 = %q, %v, want kept", value, ok)
+	}
+	if _, ok := e.Get(t, "/a/protected/2"); ok {
+		t.Errorf("/a/protected/2 is written")
+	}
+
+	if _, err := service.Import(ctx, ImportRequest{Data: []byte(`{"key":"/a/3","value":"zz","encoding":"hex"}`)}); errorCode(err) != backend.ErrCodeBadRequest {
+		t.Errorf("Import() of a bad hex value error = %v, want bad request", err)
+	}
+
+	// the read-only console plans the import only
+	deps.Configuration.ReadOnly = true
+	service = NewClientService(deps)
+	data = []byte(`{"key":"/a/3","value":"v3"}`)
+	if plan, err := service.Import(ctx, ImportRequest{DryRun: true, Data: data}); err != nil || plan.Creates != 1 {
+		t.Errorf("Import() of the dry run in read-only mode = %+v, %v, want /a/3 planned", plan, err)
+	}
+	if _, err := service.Import(ctx, ImportRequest{Data: data}); !backend.IsForbidden(err) {
+		t.Errorf("Import() in read-only mode error = %v, want forbidden", err)
+	}
+	if _, ok := e.Get(t, "/a/3"); ok {
+		t.Errorf("/a/3 is written in read-only mode")
+	}
+}
+
+func TestDetectImportFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		file   string
+		data   string
+		want   string
+	}{
+		{name: "by the format", format: "JSON", file: "a.yaml", data: "a: 1", want: "json"},
+		{name: "by the extension", file: "a.ndjson", data: `{"a":1}`, want: "jsonl"},
+		{name: "by the yml extension", file: "a.yml", data: `{"a":1}`, want: "yaml"},
+		{name: "not an object", data: "a: 1\n", want: "yaml"},
+		{name: "lines", data: "{\"key\":\"/a\",\"value\":\"1\"}\n{\"key\":\"/b\",\"value\":\"2\"}\n", want: "jsonl"},
+		{name: "a single line", data: `{"key":"/a","value":"1"}`, want: "jsonl"},
+		{name: "etcdctl", data: `{"header":{},"kvs":[]}`, want: "etcdctl-json"},
+		{name: "document", data: `{"key":{"a":"1"}}`, want: "json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := detectImportFormat(tt.format, tt.file, []byte(tt.data))
+			if err != nil || got != tt.want {
+				t.Errorf("detectImportFormat(%q, %q) = %s, %v, want %s", tt.format, tt.file, got, err, tt.want)
+			}
+		})
+	}
+}
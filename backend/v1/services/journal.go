@@ -0,0 +1,406 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v3 "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+)
+
+// ops of the journal entries
+const (
+	journalWrite  = "write"
+	journalRemove = "remove"
+	journalImport = "import"
+	journalCopy   = "copy"
+	journalMove   = "move"
+	journalUndo   = "undo"
+)
+
+// journalKey is a key changed by a mutation at the revision, prev is nil if the key was new.
+type journalKey struct {
+	key      []byte
+	revision int64
+	deleted  bool
+	prev     *mvccpb.KeyValue
+}
+
+type journalEntry struct {
+	id       int64
+	time     time.Time
+	op       string
+	revision int64
+	keys     []journalKey
+	undone   int64
+}
+
+// undoJournal keeps the latest mutations in memory, bounded by the count of their keys.
+type undoJournal struct {
+	mu      sync.Mutex
+	nextID  int64
+	entries []*journalEntry
+	keys    int
+}
+
+// record returns the id of the entry, zero if the journal is disabled or the entry is too large.
+func (j *undoJournal) record(size int, op string, keys []journalKey) int64 {
+	if size <= 0 || len(keys) == 0 || len(keys) > size {
+		return 0
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// drop the oldest
+	for len(j.entries) != 0 && j.keys+len(keys) > size {
+		j.keys -= len(j.entries[0].keys)
+		j.entries[0] = nil
+		j.entries = j.entries[1:]
+	}
+
+	j.nextID++
+	entry := &journalEntry{
+		id:   j.nextID,
+		time: time.Now(),
+		op:   op,
+		keys: keys,
+	}
+	for _, key := range keys {
+		if key.revision > entry.revision {
+			entry.revision = key.revision
+		}
+	}
+	j.entries = append(j.entries, entry)
+	j.keys += len(keys)
+
+	return entry.id
+}
+
+func (j *undoJournal) get(id int64) (*journalEntry, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, entry := range j.entries {
+		if entry.id == id {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+func (j *undoJournal) setUndone(entry *journalEntry, rev int64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry.undone = rev
+}
+
+// list returns the entries from the newest.
+func (j *undoJournal) list(limit int) []journalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var retEntries []journalEntry
+	for idx := len(j.entries) - 1; idx >= 0 && len(retEntries) < limit; idx-- {
+		retEntries = append(retEntries, *j.entries[idx])
+	}
+	return retEntries
+}
+
+// times returns the times of the journaled revisions of the key.
+func (j *undoJournal) times(key []byte) map[int64]time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	times := make(map[int64]time.Time)
+	for _, entry := range j.entries {
+		for _, journaled := range entry.keys {
+			if bytes.Equal(journaled.key, key) {
+				times[journaled.revision] = entry.time
+			}
+		}
+	}
+	return times
+}
+
+// journal records the previous key values returned by a mutation, and returns the id of the entry.
+func (c *clientService) journal(op string, keys []journalKey) int64 {
+	return c.undoJournal.record(c.deps.Configuration.JournalSize, op, keys)
+}
+
+// appendJournalKeys appends the keys changed by the puts and the deletes of a committed transaction,
+// the ops must take the previous key values.
+func appendJournalKeys(journalKeys []journalKey, ops []v3.Op, txnResp *v3.TxnResponse) []journalKey {
+	rev := txnResp.Header.Revision
+	for idx, op := range ops {
+		resp := txnResp.Responses[idx]
+		switch {
+		case op.IsPut():
+			journalKeys = append(journalKeys, journalKey{
+				key:      op.KeyBytes(),
+				revision: rev,
+				prev:     resp.GetResponsePut().PrevKv,
+			})
+		case op.IsDelete():
+			for _, prevKV := range resp.GetResponseDeleteRange().PrevKvs {
+				journalKeys = append(journalKeys, journalKey{
+					key:      prevKV.Key,
+					revision: rev,
+					deleted:  true,
+					prev:     prevKV,
+				})
+			}
+		}
+	}
+
+	return journalKeys
+}
+
+// journaling tells whether the mutations need to return the previous key values for the journal.
+func (c *clientService) journaling() bool {
+	return c.deps.Configuration.JournalSize > 0
+}
+
+func (c *clientService) Journal(ctx context.Context, req JournalRequest) ([]datamodels.JournalEntry, error) {
+	encoding, err := parseEncoding(req.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	entries := c.undoJournal.list(limit)
+	retEntries := make([]datamodels.JournalEntry, 0, len(entries))
+	for _, entry := range entries {
+		retEntry := datamodels.JournalEntry{
+			ID:       entry.id,
+			Time:     backend.JSONTime(entry.time),
+			Op:       entry.op,
+			Revision: entry.revision,
+			Keys:     make([]datamodels.JournalKey, 0, len(entry.keys)),
+			Undone:   entry.undone,
+		}
+		for _, key := range entry.keys {
+			keyValue := newKeyValue(&mvccpb.KeyValue{Key: key.key}, encoding, false)
+			retKey := datamodels.JournalKey{
+				Key:      keyValue.Key,
+				Encoding: keyValue.Encoding,
+				Deleted:  key.deleted,
+			}
+			if key.prev != nil {
+				prev := newKeyValue(key.prev, encoding, true)
+				retKey.Prev = &prev
+			}
+			retEntry.Keys = append(retEntry.Keys, retKey)
+		}
+		retEntries = append(retEntries, retEntry)
+	}
+
+	return retEntries, nil
+}
+
+// Undo re-applies the previous values of a journal entry, every key is guarded by the revision
+// of the mutation, so the keys changed since are left as they are and listed as conflicts.
+// A deleted key can only be guarded by its absence: if it's put and deleted again since,
+// the undo cannot tell, as etcd keeps no revision of an absent key to compare with.
+func (c *clientService) Undo(ctx context.Context, req UndoRequest) (datamodels.UndoResult, error) {
+	etcdClient := c.deps.Client
+
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, req.Timeout, 15*time.Second)
+	defer timeoutCancelFn()
+
+	var retResult datamodels.UndoResult
+
+	version, err := etcdClient.Version()
+	if err != nil {
+		return retResult, err
+	}
+	if version.Major() == 2 {
+		return retResult, backend.NewUnsupportedError("cannot support v2 now")
+	} else {
+		encoding, err := parseEncoding(req.Encoding)
+		if err != nil {
+			return retResult, err
+		}
+
+		id := req.ID
+		if id <= 0 {
+			return retResult, backend.NewBadRequestError(`"id" is required`)
+		}
+		entry, ok := c.undoJournal.get(id)
+		if !ok {
+			return retResult, backend.NewNotFoundError(fmt.Sprintf("cannot find journal entry %d", id))
+		}
+
+		configuration := c.deps.Configuration
+		for _, key := range entry.keys {
+			if err := configuration.CheckKey(string(key.key)); err != nil {
+				return retResult, err
+			}
+		}
+		retResult.ID = id
+		retResult.Conflicts = []datamodels.CopyConflict{}
+
+		client, err := etcdClient.V3()
+		if err != nil {
+			return retResult, err
+		}
+
+		// the expired leases are not attached again
+		leases := make(map[int64]bool)
+		for _, key := range entry.keys {
+			if key.prev == nil || key.prev.Lease == 0 {
+				continue
+			}
+			if _, ok := leases[key.prev.Lease]; ok {
+				continue
+			}
+			ttlResp, err := client.TimeToLive(timeoutCtx, v3.LeaseID(key.prev.Lease))
+			if err != nil && timeoutCtx.Err() != nil {
+				return retResult, err
+			}
+			leases[key.prev.Lease] = err == nil && ttlResp.TTL > 0
+		}
+
+		undoer := &journalUndoer{
+			client:   client,
+			leases:   leases,
+			encoding: encoding,
+			result:   &retResult,
+		}
+
+		const maxTxnOps = 128
+		for start := 0; start < len(entry.keys); start += maxTxnOps {
+			stop := start + maxTxnOps
+			if stop > len(entry.keys) {
+				stop = len(entry.keys)
+			}
+
+			if err := undoer.undo(timeoutCtx, entry.keys[start:stop]); err != nil {
+				return retResult, err
+			}
+		}
+
+		// the undo can be undone as well
+		if len(undoer.undone) != 0 {
+			c.undoJournal.setUndone(entry, undoer.revision)
+			retResult.Journal = c.journal(journalUndo, undoer.undone)
+		}
+	}
+
+	return retResult, nil
+}
+
+type journalUndoer struct {
+	client   *v3.Client
+	leases   map[int64]bool
+	encoding string
+
+	result   *datamodels.UndoResult
+	undone   []journalKey
+	revision int64
+}
+
+// undo commits the batch at once, it falls back to one key per transaction
+// to find out the conflicts if the batch fails.
+func (u *journalUndoer) undo(ctx context.Context, keys []journalKey) error {
+	cmps, ops := u.txn(keys)
+
+	txnResp, err := u.client.Txn(ctx).If(cmps...).Then(ops...).Commit()
+	if err == nil && txnResp.Succeeded {
+		u.commit(keys, txnResp)
+		return nil
+	}
+	if err != nil && ctx.Err() != nil {
+		return err
+	}
+
+	for idx := range keys {
+		cmps, ops := u.txn(keys[idx : idx+1])
+
+		txnResp, err := u.client.Txn(ctx).If(cmps...).Then(ops...).Commit()
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return err
+			}
+			u.conflict(keys[idx].key, err.Error())
+		case !txnResp.Succeeded:
+			u.conflict(keys[idx].key, "the key is changed since")
+		default:
+			u.commit(keys[idx:idx+1], txnResp)
+		}
+	}
+
+	return nil
+}
+
+func (u *journalUndoer) txn(keys []journalKey) ([]v3.Cmp, []v3.Op) {
+	var (
+		cmps = make([]v3.Cmp, 0, len(keys))
+		ops  = make([]v3.Op, 0, len(keys))
+	)
+
+	for _, key := range keys {
+		if key.deleted {
+			// absent now, not necessarily untouched since the delete
+			cmps = append(cmps, v3.Compare(v3.CreateRevision(string(key.key)), "=", 0))
+		} else {
+			cmps = append(cmps, v3.Compare(v3.ModRevision(string(key.key)), "=", key.revision))
+		}
+
+		if key.prev == nil {
+			ops = append(ops, v3.OpDelete(string(key.key), v3.WithPrevKV()))
+			continue
+		}
+		opts := []v3.OpOption{v3.WithPrevKV()}
+		if u.leases[key.prev.Lease] {
+			opts = append(opts, v3.WithLease(v3.LeaseID(key.prev.Lease)))
+		}
+		ops = append(ops, v3.OpPut(string(key.key), string(key.prev.Value), opts...))
+	}
+
+	return cmps, ops
+}
+
+// commit journals the values replaced by the undo.
+func (u *journalUndoer) commit(keys []journalKey, txnResp *v3.TxnResponse) {
+	rev := txnResp.Header.Revision
+	for idx, key := range keys {
+		undone := journalKey{
+			key:      key.key,
+			revision: rev,
+			deleted:  key.prev == nil,
+		}
+		if resp := txnResp.Responses[idx]; undone.deleted {
+			if prevKVs := resp.GetResponseDeleteRange().PrevKvs; len(prevKVs) != 0 {
+				undone.prev = prevKVs[0]
+			}
+		} else {
+			undone.prev = resp.GetResponsePut().PrevKv
+		}
+		u.undone = append(u.undone, undone)
+	}
+
+	u.result.Restored += int64(len(keys))
+	u.revision = rev
+}
+
+func (u *journalUndoer) conflict(key []byte, reason string) {
+	keyValue := newKeyValue(&mvccpb.KeyValue{Key: key}, u.encoding, false)
+
+	u.result.Conflicts = append(u.result.Conflicts, datamodels.CopyConflict{
+		Key:      keyValue.Key,
+		Encoding: keyValue.Encoding,
+		Reason:   reason,
+	})
+}
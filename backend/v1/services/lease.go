@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	v3 "github.com/coreos/etcd/clientv3"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+)
+
+type LeaseService interface {
+	Grant(ctx context.Context, req LeaseGrantRequest) (datamodels.Lease, error)
+	Get(ctx context.Context, req LeaseRequest) (datamodels.Lease, error)
+	Revoke(ctx context.Context, req LeaseRequest) (datamodels.Lease, error)
+}
+
+type leaseService struct {
+	deps Dependencies
+}
+
+func NewLeaseService(deps Dependencies) LeaseService {
+	return &leaseService{
+		deps: deps.withDefaults(),
+	}
+}
+
+func (l *leaseService) Grant(ctx context.Context, req LeaseGrantRequest) (datamodels.Lease, error) {
+	var retLease datamodels.Lease
+
+	client, timeoutCtx, cancelFn, err := l.client(ctx, req.Timeout)
+	if err != nil {
+		return retLease, err
+	}
+	defer cancelFn()
+
+	if err := l.deps.Configuration.CheckWritable(); err != nil {
+		return retLease, err
+	}
+
+	if req.TTL <= 0 {
+		return retLease, backend.NewBadRequestError(`"ttl" must be positive`)
+	}
+
+	grantResp, err := client.Grant(timeoutCtx, req.TTL)
+	if err != nil {
+		return retLease, err
+	}
+
+	retLease = datamodels.Lease{
+		ID:         fmt.Sprintf("%x", grantResp.ID),
+		TTL:        grantResp.TTL,
+		GrantedTTL: grantResp.TTL,
+	}
+	return retLease, nil
+}
+
+func (l *leaseService) Get(ctx context.Context, req LeaseRequest) (datamodels.Lease, error) {
+	client, timeoutCtx, cancelFn, err := l.client(ctx, req.Timeout)
+	if err != nil {
+		return datamodels.Lease{}, err
+	}
+	defer cancelFn()
+
+	keys := req.Keys
+
+	encoding, err := parseEncoding(req.Encoding)
+	if err != nil {
+		return datamodels.Lease{}, err
+	}
+	leaseID, err := parseLeaseID(req.ID)
+	if err != nil {
+		return datamodels.Lease{}, err
+	}
+
+	var opts []v3.LeaseOption
+	if keys {
+		opts = append(opts, v3.WithAttachedKeys())
+	}
+	ttlResp, err := client.TimeToLive(timeoutCtx, leaseID, opts...)
+	if err != nil {
+		return datamodels.Lease{}, err
+	}
+	// an expired or revoked lease has no ttl
+	if ttlResp.TTL == -1 {
+		return datamodels.Lease{}, backend.NewNotFoundError(fmt.Sprintf("cannot find lease %x", leaseID))
+	}
+
+	return newLease(ttlResp, keys, encoding), nil
+}
+
+// Revoke removes the lease along with the attached keys, nothing is revoked if any key is protected.
+func (l *leaseService) Revoke(ctx context.Context, req LeaseRequest) (datamodels.Lease, error) {
+	client, timeoutCtx, cancelFn, err := l.client(ctx, req.Timeout)
+	if err != nil {
+		return datamodels.Lease{}, err
+	}
+	defer cancelFn()
+
+	configuration := l.deps.Configuration
+	if err := configuration.CheckWritable(); err != nil {
+		return datamodels.Lease{}, err
+	}
+
+	encoding, err := parseEncoding(req.Encoding)
+	if err != nil {
+		return datamodels.Lease{}, err
+	}
+	leaseID, err := parseLeaseID(req.ID)
+	if err != nil {
+		return datamodels.Lease{}, err
+	}
+	ttlResp, err := client.TimeToLive(timeoutCtx, leaseID, v3.WithAttachedKeys())
+	if err != nil {
+		return datamodels.Lease{}, err
+	}
+	if ttlResp.TTL == -1 {
+		return datamodels.Lease{}, backend.NewNotFoundError(fmt.Sprintf("cannot find lease %x", leaseID))
+	}
+	for _, key := range ttlResp.Keys {
+		if err := configuration.CheckKey(string(key)); err != nil {
+			return datamodels.Lease{}, err
+		}
+	}
+
+	if _, err := client.Revoke(timeoutCtx, leaseID); err != nil {
+		return datamodels.Lease{}, err
+	}
+
+	return newLease(ttlResp, true, encoding), nil
+}
+
+func (l *leaseService) client(ctx context.Context, timeout time.Duration) (*v3.Client, context.Context, context.CancelFunc, error) {
+	etcdClient := l.deps.Client
+
+	version, err := etcdClient.Version()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if version.Major() == 2 {
+		return nil, nil, nil, backend.NewUnsupportedError("cannot support v2 now")
+	}
+
+	client, err := etcdClient.V3()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	timeoutCtx, timeoutCancelFn := withTimeout(ctx, timeout, 5*time.Second)
+
+	return client, timeoutCtx, timeoutCancelFn, nil
+}
+
+// parseLeaseID takes the ID in hex, the same as the lease of the key values.
+func parseLeaseID(id string) (v3.LeaseID, error) {
+	if len(id) == 0 {
+		return 0, backend.NewBadRequestError(`"id" is required`)
+	}
+
+	leaseID, err := strconv.ParseInt(id, 16, 64)
+	if err != nil || leaseID == 0 {
+		return 0, backend.NewBadRequestError(fmt.Sprintf("bad lease ID (%v), expecting ID in Hex", id))
+	}
+	return v3.LeaseID(leaseID), nil
+}
+
+// newLease converts the lease, utf8 falls back to base64 if any key is binary.
+func newLease(ttlResp *v3.LeaseTimeToLiveResponse, keys bool, encoding string) datamodels.Lease {
+	retLease := datamodels.Lease{
+		ID:         fmt.Sprintf("%x", ttlResp.ID),
+		TTL:        ttlResp.TTL,
+		GrantedTTL: ttlResp.GrantedTTL,
+	}
+	if !keys {
+		return retLease
+	}
+
+	if encoding == encodingUTF8 {
+		for _, key := range ttlResp.Keys {
+			if !utf8.Valid(key) {
+				encoding = encodingBase64
+				break
+			}
+		}
+	}
+	if encoding != encodingUTF8 {
+		retLease.Encoding = encoding
+	}
+
+	retLease.Keys = make([]string, 0, len(ttlResp.Keys))
+	for _, key := range ttlResp.Keys {
+		retLease.Keys = append(retLease.Keys, encodeBytes(key, encoding))
+	}
+	return retLease
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	v3 "github.com/coreos/etcd/clientv3"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+)
+
+func TestLease(t *testing.T) {
+	e, deps := startDependencies(t, func(configuration *backend.Configuration) {
+		configuration.ProtectedPrefixes = []string{"/protected/"}
+	})
+	defer closeDependencies(e, deps)
+
+	service := NewLeaseService(deps)
+
+	lease, err := service.Grant(context.Background(), LeaseGrantRequest{LeaseGrantRequest: viewmodels.LeaseGrantRequest{TTL: 60}})
+	if err != nil {
+		t.Fatalf("Grant() error = %v", err)
+	}
+	if len(lease.ID) == 0 || lease.GrantedTTL != 60 {
+		t.Errorf("Grant() = %+v, want a lease of 60 seconds", lease)
+	}
+	leaseID, err := parseLeaseID(lease.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Client.Put(context.Background(), "/l/1", "1", v3.WithLease(leaseID)); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := service.Get(context.Background(), LeaseRequest{ID: lease.ID, Keys: true})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.ID != lease.ID || got.TTL <= 0 || !reflect.DeepEqual(got.Keys, []string{"/l/1"}) {
+		t.Errorf("Get() = %+v, want the lease with the key /l/1", got)
+	}
+
+	revoked, err := service.Revoke(context.Background(), LeaseRequest{ID: lease.ID})
+	if err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if !reflect.DeepEqual(revoked.Keys, []string{"/l/1"}) {
+		t.Errorf("Revoke() = %+v, want the removed key /l/1", revoked)
+	}
+	if _, ok := e.Get(t, "/l/1"); ok {
+		t.Errorf("the key of the revoked lease is not removed")
+	}
+	if _, err := service.Get(context.Background(), LeaseRequest{ID: lease.ID}); errorCode(err) != backend.ErrCodeNotFound {
+		t.Errorf("Get() of the revoked lease error = %v, want %s", err, backend.ErrCodeNotFound)
+	}
+}
+
+func TestLeaseErrors(t *testing.T) {
+	e, deps := startDependencies(t, func(configuration *backend.Configuration) {
+		configuration.ProtectedPrefixes = []string{"/protected/"}
+	})
+	defer closeDependencies(e, deps)
+
+	service := NewLeaseService(deps)
+
+	if _, err := service.Grant(context.Background(), LeaseGrantRequest{}); errorCode(err) != backend.ErrCodeBadRequest {
+		t.Errorf("Grant() of no ttl error = %v, want %s", err, backend.ErrCodeBadRequest)
+	}
+	for _, id := range []string{"", "0", "xyz"} {
+		if _, err := service.Get(context.Background(), LeaseRequest{ID: id}); errorCode(err) != backend.ErrCodeBadRequest {
+			t.Errorf("Get(%q) error = %v, want %s", id, err, backend.ErrCodeBadRequest)
+		}
+	}
+
+	// the lease of a protected key is kept along with the key
+	grantResp, err := e.Client.Grant(context.Background(), 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Client.Put(context.Background(), "/protected/k", "v", v3.WithLease(grantResp.ID)); err != nil {
+		t.Fatal(err)
+	}
+	lease, err := service.Get(context.Background(), LeaseRequest{ID: fmt.Sprintf("%x", grantResp.ID)})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, err := service.Revoke(context.Background(), LeaseRequest{ID: lease.ID}); !backend.IsForbidden(err) {
+		t.Errorf("Revoke() of a protected key error = %v, want forbidden", err)
+	}
+	if _, ok := e.Get(t, "/protected/k"); !ok {
+		t.Errorf("the protected key is removed")
+	}
+}
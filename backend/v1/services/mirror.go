@@ -0,0 +1,449 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sv2 "github.com/Masterminds/semver"
+	v3 "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/clientv3/mirror"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+)
+
+// states of the mirror jobs
+const (
+	mirrorSyncing  = "syncing"
+	mirrorWatching = "watching"
+	mirrorDone     = "done"
+	mirrorStopped  = "stopped"
+	mirrorFailed   = "failed"
+)
+
+// the default "--max-txn-ops" of etcd
+const mirrorMaxTxnOps = 128
+
+// how many stopped, failed or done jobs are kept to be shown
+const mirrorMaxFinishedJobs = 100
+
+// MirrorService runs the jobs until the ctx of StartJob is done, or they're stopped.
+type MirrorService interface {
+	GetJobs(ctx context.Context, id string) ([]datamodels.MirrorJob, error)
+	StartJob(ctx context.Context, req viewmodels.MirrorStartRequest) (datamodels.MirrorJob, error)
+	StopJob(ctx context.Context, id string) (datamodels.MirrorJob, error)
+}
+
+type mirrorService struct {
+	deps   Dependencies
+	mu     sync.Mutex
+	nextID int64
+	jobs   map[string]*mirrorJob
+}
+
+func NewMirrorService(deps Dependencies) MirrorService {
+	return &mirrorService{
+		deps: deps.withDefaults(),
+		jobs: make(map[string]*mirrorJob),
+	}
+}
+
+type mirrorJob struct {
+	mu     sync.RWMutex
+	status datamodels.MirrorJob
+	cancel context.CancelFunc
+}
+
+func (j *mirrorJob) get() datamodels.MirrorJob {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	return j.status
+}
+
+func (j *mirrorJob) update(fn func(status *datamodels.MirrorJob)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	fn(&j.status)
+}
+
+// GetJobs returns the job of the id, or all jobs if empty.
+func (m *mirrorService) GetJobs(ctx context.Context, id string) ([]datamodels.MirrorJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(id) != 0 {
+		job, ok := m.jobs[id]
+		if !ok {
+			return nil, backend.NewNotFoundError(fmt.Sprintf("cannot find mirror job %s", id))
+		}
+		return []datamodels.MirrorJob{job.get()}, nil
+	}
+
+	retJobs := make([]datamodels.MirrorJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		retJobs = append(retJobs, job.get())
+	}
+	sort.Slice(retJobs, func(i, j int) bool {
+		return retJobs[i].StartTime.Unix() > retJobs[j].StartTime.Unix()
+	})
+
+	return retJobs, nil
+}
+
+func (m *mirrorService) StartJob(ctx context.Context, req viewmodels.MirrorStartRequest) (datamodels.MirrorJob, error) {
+	etcdClient := m.deps.Client
+	configuration := m.deps.Configuration
+
+	var retJob datamodels.MirrorJob
+
+	if err := configuration.CheckWritable(); err != nil {
+		return retJob, err
+	}
+
+	mirrorStartRequest := &req
+
+	if len(mirrorStartRequest.Prefix) == 0 {
+		return retJob, backend.NewBadRequestError(`"prefix" is required`)
+	}
+	destPrefix := mirrorStartRequest.DestPrefix
+	if len(destPrefix) == 0 {
+		destPrefix = mirrorStartRequest.Prefix
+	}
+
+	// the console only dials the clusters of the configuration, unless it's told to dial any
+	if !configuration.MirrorAnyEndpoints && (len(mirrorStartRequest.Endpoints) != 0 || len(mirrorStartRequest.SourceEndpoints) != 0) {
+		return retJob, &backend.ForbiddenError{Reason: `the clusters of the mirror jobs must be named in "Mirrors" of the configuration`}
+	}
+
+	destination := mirrorStartRequest.Endpoints
+	if name := mirrorStartRequest.Destination; len(name) != 0 {
+		endpoints, err := m.mirrorEndpoints(name)
+		if err != nil {
+			return retJob, err
+		}
+		destination = endpoints
+	}
+	if len(destination) == 0 {
+		return retJob, backend.NewBadRequestError(`"destination" or "endpoints" is required`)
+	}
+
+	// the source is the cluster of the console by default
+	var (
+		srcClient *v3.Client
+		source    = mirrorStartRequest.SourceEndpoints
+		err       error
+	)
+	if name := mirrorStartRequest.Source; len(name) != 0 {
+		if source, err = m.mirrorEndpoints(name); err != nil {
+			return retJob, err
+		}
+	}
+	ownSource := len(source) != 0
+	if ownSource {
+		srcClient, err = v3.New(v3.Config{Endpoints: source, DialTimeout: 5 * time.Second})
+	} else {
+		var version *sv2.Version
+		if version, err = etcdClient.Version(); err == nil {
+			if version.Major() == 2 {
+				return retJob, backend.NewUnsupportedError("cannot support v2 now")
+			}
+			source = configuration.Endpoints
+			srcClient, err = etcdClient.V3()
+		}
+	}
+	if err != nil {
+		return retJob, err
+	}
+
+	dstClient, err := v3.New(v3.Config{Endpoints: destination, DialTimeout: 5 * time.Second})
+	if err == nil {
+		err = m.checkClusters(ctx, srcClient, dstClient, mirrorStartRequest.Prefix, destPrefix)
+		if err != nil {
+			dstClient.Close()
+		}
+	}
+	if err != nil {
+		if ownSource {
+			srcClient.Close()
+		}
+		return retJob, err
+	}
+
+	m.mu.Lock()
+	m.nextID++
+	id := fmt.Sprintf("mirror-%d", m.nextID)
+
+	jobCtx, jobCancelFn := context.WithCancel(ctx)
+	job := &mirrorJob{
+		status: datamodels.MirrorJob{
+			ID:          id,
+			Prefix:      mirrorStartRequest.Prefix,
+			DestPrefix:  destPrefix,
+			Source:      source,
+			Destination: destination,
+			Watch:       mirrorStartRequest.Watch,
+			State:       mirrorSyncing,
+			StartTime:   backend.JSONTime(time.Now()),
+		},
+		cancel: jobCancelFn,
+	}
+	m.jobs[id] = job
+	m.evictJobs()
+	m.mu.Unlock()
+
+	logger := m.deps.Logger
+	go func() {
+		defer func() {
+			dstClient.Close()
+			if ownSource {
+				srcClient.Close()
+			}
+		}()
+
+		err := runMirrorJob(jobCtx, job, srcClient, dstClient)
+		job.update(func(status *datamodels.MirrorJob) {
+			status.EndTime = backend.JSONTime(time.Now())
+			switch {
+			case jobCtx.Err() != nil:
+				status.State = mirrorStopped
+			case err != nil:
+				status.State = mirrorFailed
+				status.Err = err.Error()
+			default:
+				status.State = mirrorDone
+			}
+		})
+		if err != nil && jobCtx.Err() == nil {
+			logger.Errorf("mirror job %s failed, %v", id, err)
+		}
+		// releases the syncer if failed halfway
+		jobCancelFn()
+	}()
+
+	return job.get(), nil
+}
+
+// mirrorEndpoints returns the endpoints of the configured mirror.
+func (m *mirrorService) mirrorEndpoints(name string) ([]string, error) {
+	for _, mirrorConfig := range m.deps.Configuration.Mirrors {
+		if mirrorConfig.Name == name && len(mirrorConfig.Endpoints) != 0 {
+			return mirrorConfig.Endpoints, nil
+		}
+	}
+
+	return nil, backend.NewNotFoundError(fmt.Sprintf("cannot find mirror %s in configuration", name))
+}
+
+// checkClusters tells the clusters by their ids: the destination prefix must not be protected if it's the cluster
+// of the console, and the prefixes must not overlap if it's the source, otherwise the job would mirror its own writes.
+func (m *mirrorService) checkClusters(ctx context.Context, srcClient, dstClient *v3.Client, prefix, destPrefix string) error {
+	probeCtx, probeCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer probeCancel()
+
+	srcID, err := clusterID(probeCtx, srcClient)
+	if err != nil {
+		return err
+	}
+	dstID, err := clusterID(probeCtx, dstClient)
+	if err != nil {
+		return err
+	}
+
+	var ownDestination bool
+	if ownClient, err := m.deps.Client.V3(); err == nil {
+		ownID, err := clusterID(probeCtx, ownClient)
+		if err != nil {
+			return err
+		}
+		ownDestination = ownID == dstID
+	} else {
+		// without the v3 api, only the endpoints tell
+		ownDestination = sharesEndpoint(m.deps.Configuration.Endpoints, dstClient.Endpoints())
+	}
+	if ownDestination {
+		if err := m.deps.Configuration.CheckRange(destPrefix, v3.GetPrefixRangeEnd(destPrefix)); err != nil {
+			return err
+		}
+	}
+
+	if srcID == dstID && (strings.HasPrefix(prefix, destPrefix) || strings.HasPrefix(destPrefix, prefix)) {
+		return backend.NewBadRequestError(fmt.Sprintf("prefix %q and destination prefix %q overlap in the same cluster", prefix, destPrefix))
+	}
+
+	return nil
+}
+
+func clusterID(ctx context.Context, client *v3.Client) (uint64, error) {
+	resp, err := client.MemberList(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Header.ClusterId, nil
+}
+
+func sharesEndpoint(endpoints, others []string) bool {
+	for _, endpoint := range endpoints {
+		for _, other := range others {
+			if endpoint == other {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// evictJobs forgets the oldest finished jobs beyond mirrorMaxFinishedJobs, m.mu must be held.
+func (m *mirrorService) evictJobs() {
+	var finished []datamodels.MirrorJob
+	for _, job := range m.jobs {
+		status := job.get()
+		switch status.State {
+		case mirrorSyncing, mirrorWatching:
+		default:
+			finished = append(finished, status)
+		}
+	}
+	if len(finished) <= mirrorMaxFinishedJobs {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return time.Time(finished[i].EndTime).Before(time.Time(finished[j].EndTime))
+	})
+	for _, status := range finished[:len(finished)-mirrorMaxFinishedJobs] {
+		delete(m.jobs, status.ID)
+	}
+}
+
+func (m *mirrorService) StopJob(ctx context.Context, id string) (datamodels.MirrorJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return datamodels.MirrorJob{}, backend.NewNotFoundError(fmt.Sprintf("cannot find mirror job %s", id))
+	}
+
+	// the finished jobs are forgotten once stopped
+	switch job.get().State {
+	case mirrorSyncing, mirrorWatching:
+		job.cancel()
+	default:
+		delete(m.jobs, id)
+	}
+
+	return job.get(), nil
+}
+
+// runMirrorJob syncs the prefix at one revision, then applies the updates since that revision if watching.
+func runMirrorJob(ctx context.Context, job *mirrorJob, srcClient, dstClient *v3.Client) error {
+	var (
+		status = job.get()
+		rename = func(key []byte) string {
+			return status.DestPrefix + strings.TrimPrefix(string(key), status.Prefix)
+		}
+	)
+
+	getResp, err := srcClient.Get(ctx, status.Prefix, v3.WithPrefix(), v3.WithCountOnly())
+	if err != nil {
+		return err
+	}
+	rev := getResp.Header.Revision
+	job.update(func(status *datamodels.MirrorJob) {
+		status.Revision = rev
+		status.LastRevision = rev
+	})
+
+	syncer := mirror.NewSyncer(srcClient, status.Prefix, rev)
+
+	respCh, errCh := syncer.SyncBase(ctx)
+	for resp := range respCh {
+		for start := 0; start < len(resp.Kvs); start += mirrorMaxTxnOps {
+			stop := start + mirrorMaxTxnOps
+			if stop > len(resp.Kvs) {
+				stop = len(resp.Kvs)
+			}
+
+			ops := make([]v3.Op, 0, stop-start)
+			for _, kv := range resp.Kvs[start:stop] {
+				ops = append(ops, v3.OpPut(rename(kv.Key), string(kv.Value)))
+			}
+			if _, err := dstClient.Txn(ctx).Then(ops...).Commit(); err != nil {
+				return err
+			}
+
+			synced := int64(len(ops))
+			job.update(func(status *datamodels.MirrorJob) {
+				status.Synced += synced
+			})
+		}
+	}
+	if err := <-errCh; err != nil {
+		return err
+	}
+
+	if !status.Watch {
+		return nil
+	}
+	job.update(func(status *datamodels.MirrorJob) {
+		status.State = mirrorWatching
+	})
+
+	for watchResp := range syncer.SyncUpdates(ctx) {
+		if err := watchResp.Err(); err != nil {
+			return err
+		}
+
+		// the events of one revision are applied at once, unless there are too many
+		var (
+			ops     []v3.Op
+			lastRev int64
+		)
+		apply := func() error {
+			if len(ops) == 0 {
+				return nil
+			}
+			if _, err := dstClient.Txn(ctx).Then(ops...).Commit(); err != nil {
+				return err
+			}
+
+			updates, appliedRev := int64(len(ops)), lastRev
+			job.update(func(status *datamodels.MirrorJob) {
+				status.Updates += updates
+				status.LastRevision = appliedRev
+			})
+			ops = ops[:0]
+			return nil
+		}
+
+		for _, event := range watchResp.Events {
+			if lastRev != 0 && event.Kv.ModRevision != lastRev || len(ops) >= mirrorMaxTxnOps {
+				if err := apply(); err != nil {
+					return err
+				}
+			}
+			lastRev = event.Kv.ModRevision
+
+			switch event.Type {
+			case mvccpb.PUT:
+				ops = append(ops, v3.OpPut(rename(event.Kv.Key), string(event.Kv.Value)))
+			case mvccpb.DELETE:
+				ops = append(ops, v3.OpDelete(rename(event.Kv.Key)))
+			}
+		}
+		if err := apply(); err != nil {
+			return err
+		}
+	}
+
+	return ctx.Err()
+}
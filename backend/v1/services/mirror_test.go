@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+)
+
+// waitJob waits for the job to leave the state.
+func waitJob(t *testing.T, m MirrorService, id string, state string) datamodels.MirrorJob {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		jobs, err := m.GetJobs(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetJobs() error = %v", err)
+		}
+		if jobs[0].State != state || time.Now().After(deadline) {
+			return jobs[0]
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestMirrorJob(t *testing.T) {
+	e, deps := startDependencies(t, func(configuration *backend.Configuration) {
+		// the console's own cluster, by name
+		configuration.Mirrors = []backend.MirrorConfiguration{{Name: "self", Endpoints: configuration.Endpoints}}
+		configuration.ProtectedPrefixes = []string{"/protected/"}
+	})
+	defer closeDependencies(e, deps)
+
+	e.Put(t,
+		"/a/1", "v1",
+		"/a/2", "v2",
+	)
+
+	m := NewMirrorService(deps)
+	ctx := context.Background()
+
+	job, err := m.StartJob(ctx, viewmodels.MirrorStartRequest{Prefix: "/a/", DestPrefix: "/b/", Destination: "self"})
+	if err != nil {
+		t.Fatalf("StartJob() error = %v", err)
+	}
+	if job = waitJob(t, m, job.ID, mirrorSyncing); job.State != mirrorDone || job.Synced != 2 {
+		t.Fatalf("job = %+v, want done with 2 keys", job)
+	}
+	if value, ok := e.Get(t, "/b/2"); !ok || value != "v2" {
+		t.Errorf("/b/2 = %q, want v2", value)
+	}
+
+	// the job is forgotten once stopped after finishing
+	if _, err := m.StopJob(ctx, job.ID); err != nil {
+		t.Fatalf("StopJob() error = %v", err)
+	}
+	if _, err := m.GetJobs(ctx, job.ID); errorCode(err) != backend.ErrCodeNotFound {
+		t.Errorf("GetJobs() of a stopped job error = %v, want not found", err)
+	}
+}
+
+func TestMirrorJobErrors(t *testing.T) {
+	e, deps := startDependencies(t, func(configuration *backend.Configuration) {
+		configuration.Mirrors = []backend.MirrorConfiguration{{Name: "self", Endpoints: configuration.Endpoints}}
+		configuration.ProtectedPrefixes = []string{"/protected/"}
+	})
+	defer closeDependencies(e, deps)
+
+	m := NewMirrorService(deps)
+
+	tests := []struct {
+		name string
+		req  viewmodels.MirrorStartRequest
+		code string
+	}{
+		{"no prefix", viewmodels.MirrorStartRequest{Destination: "self"}, backend.ErrCodeBadRequest},
+		{"no destination", viewmodels.MirrorStartRequest{Prefix: "/a/"}, backend.ErrCodeBadRequest},
+		{"unknown mirror", viewmodels.MirrorStartRequest{Prefix: "/a/", Destination: "other"}, backend.ErrCodeNotFound},
+		{"endpoints", viewmodels.MirrorStartRequest{Prefix: "/a/", Endpoints: e.Endpoints}, backend.ErrCodeForbidden},
+		{"source endpoints", viewmodels.MirrorStartRequest{Prefix: "/a/", Destination: "self", SourceEndpoints: e.Endpoints}, backend.ErrCodeForbidden},
+		{"protected destination", viewmodels.MirrorStartRequest{Prefix: "/a/", DestPrefix: "/protected/a/", Destination: "self"}, backend.ErrCodeForbidden},
+		{"same prefix", viewmodels.MirrorStartRequest{Prefix: "/a/", Destination: "self"}, backend.ErrCodeBadRequest},
+		{"destination under the prefix", viewmodels.MirrorStartRequest{Prefix: "/a/", DestPrefix: "/a/b/", Destination: "self"}, backend.ErrCodeBadRequest},
+		{"prefix under the destination", viewmodels.MirrorStartRequest{Prefix: "/a/b/", DestPrefix: "/a/", Destination: "self", Source: "self"}, backend.ErrCodeBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := m.StartJob(context.Background(), tt.req); errorCode(err) != tt.code {
+				t.Errorf("StartJob() error = %v, want %s", err, tt.code)
+			}
+		})
+	}
+
+	jobs, err := m.GetJobs(context.Background(), "")
+	if err != nil || len(jobs) != 0 {
+		t.Errorf("GetJobs() = %+v, %v, want no jobs", jobs, err)
+	}
+}
+
+func TestMirrorJobEviction(t *testing.T) {
+	m := NewMirrorService(Dependencies{}).(*mirrorService)
+
+	now := time.Now()
+	for i := 0; i < mirrorMaxFinishedJobs+10; i++ {
+		id := fmt.Sprintf("mirror-%d", i)
+		m.jobs[id] = &mirrorJob{status: datamodels.MirrorJob{
+			ID:      id,
+			State:   mirrorDone,
+			EndTime: backend.JSONTime(now.Add(time.Duration(i) * time.Second)),
+		}}
+	}
+	m.jobs["running"] = &mirrorJob{status: datamodels.MirrorJob{ID: "running", State: mirrorWatching}}
+
+	m.evictJobs()
+
+	if len(m.jobs) != mirrorMaxFinishedJobs+1 {
+		t.Errorf("jobs = %d, want %d", len(m.jobs), mirrorMaxFinishedJobs+1)
+	}
+	if _, ok := m.jobs["running"]; !ok {
+		t.Errorf("the running job is evicted")
+	}
+	if _, ok := m.jobs["mirror-0"]; ok {
+		t.Errorf("the oldest finished job is kept")
+	}
+	if _, ok := m.jobs[fmt.Sprintf("mirror-%d", mirrorMaxFinishedJobs+9)]; !ok {
+		t.Errorf("the newest finished job is evicted")
+	}
+}
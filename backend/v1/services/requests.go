@@ -0,0 +1,170 @@
+package services
+
+import (
+	"time"
+
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+)
+
+// The requests of the services, the keys are in the encoding of the request, one of utf8(default), base64 and hex.
+// A zero timeout means the default of the op.
+
+// KeyRange is a key, or a range from the key by one of prefix, fromKey and range.
+type KeyRange struct {
+	Key     string
+	Prefix  bool
+	FromKey bool
+	// The end of the range, exclusive
+	Range    string
+	Encoding string
+}
+
+type GetRequest struct {
+	KeyRange
+	// "l"(default) for linearizable, "s" for serializable
+	Consistency string
+	Limit       int64
+	// Read at the revision, zero means the latest
+	Rev      int64
+	KeysOnly bool
+	// ASCEND or DESCEND
+	SortOrder string
+	// CREATE, KEY, MODIFY, VALUE or VERSION
+	SortTarget string
+	// The cursor of the previous page
+	Cursor string
+	// auto(default), none, or the name of a codec
+	Decode string
+	// How the decoded values are rendered
+	Format  string
+	Timeout time.Duration
+}
+
+type SetRequest struct {
+	viewmodels.ClientSetRequest
+	Timeout time.Duration
+}
+
+type DelRequest struct {
+	KeyRange
+	// Return the removed key values
+	PrevKV bool
+	// The token confirming a remove over the delete limit
+	Confirm string
+	// Dry run only, the number of keys to return, at most 1000
+	Sample  int64
+	Timeout time.Duration
+}
+
+type TreeRequest struct {
+	// The prefix of the level, in utf8
+	Key string
+	// Defaults to "/"
+	Delimiter string
+	Rev       int64
+	Limit     int64
+	// Defaults to 1000
+	PageSize int64
+	Timeout  time.Duration
+}
+
+type ExportRequest struct {
+	KeyRange
+	// jsonl(default), json, yaml, etcdctl or etcdctl-json
+	Format   string
+	Metadata bool
+	// Defaults to "/"
+	Delimiter string
+	Rev       int64
+	// Defaults to 1000
+	PageSize int64
+	Timeout  time.Duration
+}
+
+type ImportRequest struct {
+	// The prefix of the keys, in utf8
+	Key string
+	// Detected by the file name or the content if empty
+	Format string
+	// Defaults to "/"
+	Delimiter string
+	DryRun    bool
+	// Delete the keys absent in the file under the prefix
+	Prune bool
+	// Defaults to 128
+	MaxTxnOps int64
+	// The file and its name, the name may be empty
+	Data    []byte
+	Name    string
+	Timeout time.Duration
+}
+
+type CopyRequest struct {
+	viewmodels.ClientCopyRequest
+	Timeout time.Duration
+}
+
+type HistoryRequest struct {
+	Key      string
+	Encoding string
+	// The history starts at the revision, zero means the latest
+	Rev int64
+	// Defaults to 100
+	Limit   int64
+	Timeout time.Duration
+}
+
+type DiffRequest struct {
+	Key      string
+	Encoding string
+	Prefix   bool
+	// Required
+	From int64
+	// Zero means the latest
+	To int64
+	// Return the values before and after besides the patches
+	Values bool
+	// Defaults to 1000, the keys read from each revision per page
+	Limit int64
+	// The cursor of the previous page
+	Cursor  string
+	Timeout time.Duration
+}
+
+type JournalRequest struct {
+	// Defaults to 100
+	Limit    int
+	Encoding string
+}
+
+type UndoRequest struct {
+	ID       int64
+	Encoding string
+	Timeout  time.Duration
+}
+
+type LeaseGrantRequest struct {
+	viewmodels.LeaseGrantRequest
+	Timeout time.Duration
+}
+
+type LeaseRequest struct {
+	// In hex
+	ID string
+	// Return the attached keys
+	Keys     bool
+	Encoding string
+	Timeout  time.Duration
+}
+
+type AuditRequest struct {
+	Op         string
+	User       string
+	RemoteAddr string
+	Key        string
+	// RFC 3339 or unix seconds
+	Since string
+	Until string
+	// Defaults to 100
+	Limit int
+}
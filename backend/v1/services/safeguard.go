@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	v3 "github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/thxcode/etcd-console/backend"
+)
+
+// the confirmation tokens of the large removes expire after
+const confirmationTTL = 5 * time.Minute
+
+// DeleteConfirmationError is returned when a range remove has more keys than the delete limit,
+// the keys counted go on being removed if the token is sent back as "confirm" within the TTL.
+type DeleteConfirmationError struct {
+	Count int64
+	Limit int64
+	Token string
+}
+
+func (e *DeleteConfirmationError) Error() string {
+	return fmt.Sprintf("removing %d keys exceeds the limit of %d, confirm with the token", e.Count, e.Limit)
+}
+
+type deleteConfirmation struct {
+	start string
+	end   string
+	// the revision of the count
+	revision int64
+	expire   time.Time
+}
+
+// deleteConfirmations holds the issued tokens, every token confirms one remove of the same range,
+// which removes the keys at the revision of the count.
+type deleteConfirmations struct {
+	mu     sync.Mutex
+	tokens map[string]deleteConfirmation
+}
+
+func (d *deleteConfirmations) issue(start, end string, revision int64) (string, error) {
+	data := make([]byte, 16)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(data)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for token, confirmation := range d.tokens {
+		if now.After(confirmation.expire) {
+			delete(d.tokens, token)
+		}
+	}
+	d.tokens[token] = deleteConfirmation{
+		start:    start,
+		end:      end,
+		revision: revision,
+		expire:   now.Add(confirmationTTL),
+	}
+
+	return token, nil
+}
+
+// confirm uses up the token, and returns the revision of the count which the token is issued at.
+func (d *deleteConfirmations) confirm(token string, start, end string) (int64, bool) {
+	if len(token) == 0 {
+		return 0, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	confirmation, ok := d.tokens[token]
+	if !ok {
+		return 0, false
+	}
+	delete(d.tokens, token)
+
+	if confirmation.start != start || confirmation.end != end || time.Now().After(confirmation.expire) {
+		return 0, false
+	}
+	return confirmation.revision, true
+}
+
+// deleteCounted removes the keys of the range at the revision of their count, in transactions of up to 128 keys.
+// etcd v3.2 cannot compare a range, so every key is guarded by its own revision at the count instead:
+// the keys created since are left, and the keys changed since stop the remove with a conflict.
+// The keys removed before the conflict are returned all the same.
+func deleteCounted(ctx context.Context, client *v3.Client, start, end string, rev int64, withPrevKV bool) ([]*mvccpb.KeyValue, []journalKey, error) {
+	const maxTxnOps = 128
+
+	var (
+		prevKVs     []*mvccpb.KeyValue
+		journalKeys []journalKey
+		removed     int
+		opts        []v3.OpOption
+	)
+	if withPrevKV {
+		opts = append(opts, v3.WithPrevKV())
+	}
+
+	for {
+		getResp, err := client.Get(ctx, start,
+			v3.WithRange(end),
+			v3.WithRev(rev),
+			v3.WithKeysOnly(),
+			v3.WithLimit(maxTxnOps),
+			v3.WithSort(v3.SortByKey, v3.SortAscend),
+		)
+		if err == rpctypes.ErrCompacted {
+			return prevKVs, journalKeys, backend.NewConflictError(fmt.Sprintf("revision %d of the count is compacted, count the keys again", rev))
+		}
+		if err != nil {
+			return prevKVs, journalKeys, err
+		}
+		if len(getResp.Kvs) == 0 {
+			break
+		}
+
+		cmps := make([]v3.Cmp, 0, len(getResp.Kvs))
+		ops := make([]v3.Op, 0, len(getResp.Kvs))
+		for _, kv := range getResp.Kvs {
+			cmps = append(cmps, v3.Compare(v3.ModRevision(string(kv.Key)), "=", kv.ModRevision))
+			ops = append(ops, v3.OpDelete(string(kv.Key), opts...))
+		}
+
+		txnResp, err := client.Txn(ctx).If(cmps...).Then(ops...).Commit()
+		if err != nil {
+			return prevKVs, journalKeys, err
+		}
+		if !txnResp.Succeeded {
+			return prevKVs, journalKeys, backend.NewConflictError(fmt.Sprintf("%d keys are removed, the others are changed since the count at revision %d, count them again", removed, rev))
+		}
+		removed += len(ops)
+
+		if withPrevKV {
+			journalKeys = appendJournalKeys(journalKeys, ops, txnResp)
+			for _, resp := range txnResp.Responses {
+				prevKVs = append(prevKVs, resp.GetResponseDeleteRange().PrevKvs...)
+			}
+		}
+
+		if !getResp.More {
+			break
+		}
+		start = string(getResp.Kvs[len(getResp.Kvs)-1].Key) + "\x00"
+	}
+
+	return prevKVs, journalKeys, nil
+}
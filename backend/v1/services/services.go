@@ -0,0 +1,48 @@
+// Package services holds the operations of the console, they take typed requests and the injected dependencies,
+// so the same services back the routes of every API version, the gRPC server of backend/v1/rpc, and the tools
+// embedding them. The routes and the gRPC server share the same instances, the client service keeps the undo
+// journal in memory.
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/kataras/golog"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/audit"
+	"github.com/thxcode/etcd-console/backend/codec"
+	"github.com/thxcode/etcd-console/backend/embedded"
+	"github.com/thxcode/etcd-console/backend/schema"
+)
+
+// Dependencies are injected into the services once, at the creation.
+type Dependencies struct {
+	Client        *backend.EtcdClient
+	Configuration backend.Configuration
+	// The codecs mapped by the key prefix, nil means none
+	Codecs *codec.Mapping
+	// The schemas mapped by the key prefix, nil means none
+	Schemas *schema.Mapping
+	// The audit log, nil means disabled
+	Audit *audit.Log
+	// The embedding etcd of the test mode, nil means disabled
+	TestCluster *embedded.Cluster
+	// Defaults to golog.Default
+	Logger *golog.Logger
+}
+
+func (d Dependencies) withDefaults() Dependencies {
+	if d.Logger == nil {
+		d.Logger = golog.Default
+	}
+	return d
+}
+
+// withTimeout bounds the ctx by the timeout of the request, or by the default of the op if zero.
+func withTimeout(ctx context.Context, timeout time.Duration, defaultTimeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
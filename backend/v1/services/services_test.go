@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kataras/golog"
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+)
+
+func TestWithTimeout(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{timeout: 0, want: 5 * time.Second},
+		{timeout: -time.Second, want: 5 * time.Second},
+		{timeout: time.Second, want: time.Second},
+	}
+	for _, tt := range tests {
+		ctx, cancel := withTimeout(context.Background(), tt.timeout, 5*time.Second)
+		deadline, ok := ctx.Deadline()
+		cancel()
+		if remaining := time.Until(deadline); !ok || remaining > tt.want || remaining < tt.want-time.Second {
+			t.Errorf("withTimeout(%v) deadline in %v, want %v", tt.timeout, remaining, tt.want)
+		}
+	}
+}
+
+func TestDependenciesDefaults(t *testing.T) {
+	if deps := (Dependencies{}).withDefaults(); deps.Logger != golog.Default {
+		t.Errorf("withDefaults() logger = %v, want golog.Default", deps.Logger)
+	}
+
+	logger := golog.New()
+	if deps := (Dependencies{Logger: logger}).withDefaults(); deps.Logger != logger {
+		t.Errorf("withDefaults() replaced the injected logger")
+	}
+}
+
+// the services are called with the ctx of the caller, the HTTP, the gRPC or any other one
+func TestServicesContext(t *testing.T) {
+	e, deps := startDependencies(t, nil)
+	defer closeDependencies(e, deps)
+
+	e.Put(t, "/k", "v")
+	service := NewClientService(deps)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := service.Get(ctx, GetRequest{KeyRange: KeyRange{Key: "/k"}}); err == nil {
+		t.Errorf("Get() of the cancelled ctx error = nil, want an error")
+	}
+	if _, _, err := service.Set(ctx, SetRequest{ClientSetRequest: viewmodels.ClientSetRequest{Key: "/k", Value: "changed"}}); err == nil {
+		t.Errorf("Set() of the cancelled ctx error = nil, want an error")
+	}
+	if value, _ := e.Get(t, "/k"); value != "v" {
+		t.Errorf("Set() of the cancelled ctx wrote %q", value)
+	}
+
+	// the timeout of the request bounds the op
+	if _, err := service.Get(context.Background(), GetRequest{KeyRange: KeyRange{Key: "/k"}, Timeout: time.Nanosecond}); err == nil {
+		t.Errorf("Get() of the expired timeout error = nil, want an error")
+	}
+
+	page, err := service.Get(context.Background(), GetRequest{KeyRange: KeyRange{Key: "/k"}, Timeout: 5 * time.Second})
+	if err != nil || len(page.KVS) != 1 {
+		t.Errorf("Get() of the timeout = %+v, %v, want the key", page.KVS, err)
+	}
+}
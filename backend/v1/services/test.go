@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/embedded"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+)
+
+// TestService stops and starts the members of the embedding etcd, for demoing the failures of the cluster.
+type TestService interface {
+	GetMembers(ctx context.Context) ([]datamodels.TestMember, error)
+	StartMember(ctx context.Context, name string) (datamodels.TestMember, error)
+	StopMember(ctx context.Context, name string) (datamodels.TestMember, error)
+}
+
+type testService struct {
+	deps Dependencies
+}
+
+func NewTestService(deps Dependencies) TestService {
+	return &testService{
+		deps: deps.withDefaults(),
+	}
+}
+
+func (t *testService) GetMembers(ctx context.Context) ([]datamodels.TestMember, error) {
+	cluster, err := t.cluster()
+	if err != nil {
+		return nil, err
+	}
+
+	members := cluster.Members()
+	retMembers := make([]datamodels.TestMember, 0, len(members))
+	for _, member := range members {
+		retMembers = append(retMembers, toTestMember(member))
+	}
+	return retMembers, nil
+}
+
+func (t *testService) StartMember(ctx context.Context, name string) (datamodels.TestMember, error) {
+	cluster, err := t.cluster()
+	if err != nil {
+		return datamodels.TestMember{}, err
+	}
+	if err := t.deps.Configuration.CheckWritable(); err != nil {
+		return datamodels.TestMember{}, err
+	}
+
+	member, err := cluster.StartMember(name)
+	if err != nil {
+		return datamodels.TestMember{}, testMemberError(name, err)
+	}
+	t.deps.Logger.Infof("embedding etcd member %s is started", name)
+
+	return toTestMember(member), nil
+}
+
+func (t *testService) StopMember(ctx context.Context, name string) (datamodels.TestMember, error) {
+	cluster, err := t.cluster()
+	if err != nil {
+		return datamodels.TestMember{}, err
+	}
+	if err := t.deps.Configuration.CheckWritable(); err != nil {
+		return datamodels.TestMember{}, err
+	}
+
+	member, err := cluster.StopMember(name)
+	if err != nil {
+		return datamodels.TestMember{}, testMemberError(name, err)
+	}
+	t.deps.Logger.Infof("embedding etcd member %s is stopped", name)
+
+	return toTestMember(member), nil
+}
+
+func (t *testService) cluster() (*embedded.Cluster, error) {
+	if t.deps.TestCluster == nil {
+		return nil, backend.NewNotFoundError("test mode is disabled")
+	}
+	return t.deps.TestCluster, nil
+}
+
+func testMemberError(name string, err error) error {
+	switch err {
+	case embedded.ErrMemberNotFound:
+		return backend.NewNotFoundError(fmt.Sprintf("cannot find embedding etcd member %s", name))
+	case embedded.ErrMemberRunning:
+		return backend.NewConflictError(fmt.Sprintf("embedding etcd member %s is running already", name))
+	case embedded.ErrMemberStopped:
+		return backend.NewConflictError(fmt.Sprintf("embedding etcd member %s is stopped already", name))
+	}
+	return err
+}
+
+func toTestMember(member embedded.Member) datamodels.TestMember {
+	return datamodels.TestMember{
+		Name:      member.Name,
+		ClientURL: member.ClientURL,
+		PeerURL:   member.PeerURL,
+		DataDir:   member.DataDir,
+		Running:   member.Running,
+	}
+}
@@ -0,0 +1,4 @@
+// Package openapi holds the OpenAPI document of the API, edit openapi.json and regenerate.
+package openapi
+
+//go:generate go run ../../../../cmd/openapi-gen/main.go -mode spec -in openapi.json -out spec.go -package openapi
@@ -0,0 +1,2789 @@
+// Code generated by openapi-gen. DO NOT EDIT.
+
+package openapi
+
+// Spec is the OpenAPI document of the API, served at /api/v1/openapi.json.
+const Spec = `{
+  "openapi": "3.0.0",
+  "info": {
+    "title": "etcd console",
+    "description": "The API behind the etcd console.",
+    "version": "v1"
+  },
+  "servers": [
+    {
+      "url": "/api/v1"
+    }
+  ],
+  "paths": {
+    "/client/read": {
+      "get": {
+        "operationId": "readKeys",
+        "summary": "Read a key, a prefix or a range, one page at a time",
+        "tags": [
+          "client"
+        ],
+        "parameters": [
+          {
+            "name": "key",
+            "in": "query",
+            "description": "Encoded by the encoding",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "prefix",
+            "in": "query",
+            "description": "Take the key as a prefix",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "fromKey",
+            "in": "query",
+            "description": "Range from the key to the end",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "range",
+            "in": "query",
+            "description": "The end of the range, exclusive",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "consistency",
+            "in": "query",
+            "description": "Linearizable or serializable",
+            "schema": {
+              "type": "string",
+              "default": "l",
+              "enum": [
+                "l",
+                "s"
+              ]
+            }
+          },
+          {
+            "name": "limit",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          },
+          {
+            "name": "rev",
+            "in": "query",
+            "description": "Read at the revision, zero means the latest",
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          },
+          {
+            "name": "keysOnly",
+            "in": "query",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "sortOrder",
+            "in": "query",
+            "schema": {
+              "type": "string",
+              "enum": [
+                "ASCEND",
+                "DESCEND"
+              ]
+            }
+          },
+          {
+            "name": "sortTarget",
+            "in": "query",
+            "schema": {
+              "type": "string",
+              "enum": [
+                "CREATE",
+                "KEY",
+                "MODIFY",
+                "VALUE",
+                "VERSION"
+              ]
+            }
+          },
+          {
+            "name": "cursor",
+            "in": "query",
+            "description": "The cursor of the previous page",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "encoding",
+            "in": "query",
+            "description": "How the keys and the values are encoded",
+            "schema": {
+              "type": "string",
+              "default": "utf8",
+              "enum": [
+                "utf8",
+                "base64",
+                "hex"
+              ]
+            }
+          },
+          {
+            "name": "decode",
+            "in": "query",
+            "description": "auto(default), none, or the name of a codec",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "format",
+            "in": "query",
+            "description": "How the decoded values are rendered",
+            "schema": {
+              "type": "string",
+              "enum": [
+                "json",
+                "yaml"
+              ]
+            }
+          },
+          {
+            "name": "timeout",
+            "in": "query",
+            "description": "In seconds",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 5
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClientResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/client/write": {
+      "post": {
+        "operationId": "writeKey",
+        "summary": "Write a key",
+        "tags": [
+          "client"
+        ],
+        "parameters": [
+          {
+            "name": "timeout",
+            "in": "query",
+            "description": "In seconds",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 5
+            }
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ClientSetRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClientResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/client/remove": {
+      "delete": {
+        "operationId": "removeKeys",
+        "summary": "Remove a key, a prefix or a range",
+        "tags": [
+          "client"
+        ],
+        "parameters": [
+          {
+            "name": "key",
+            "in": "query",
+            "description": "Encoded by the encoding",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "prefix",
+            "in": "query",
+            "description": "Take the key as a prefix",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "fromKey",
+            "in": "query",
+            "description": "Range from the key to the end",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "range",
+            "in": "query",
+            "description": "The end of the range, exclusive",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "encoding",
+            "in": "query",
+            "description": "How the keys and the values are encoded",
+            "schema": {
+              "type": "string",
+              "default": "utf8",
+              "enum": [
+                "utf8",
+                "base64",
+                "hex"
+              ]
+            }
+          },
+          {
+            "name": "prevKV",
+            "in": "query",
+            "description": "Return the removed key values",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "confirm",
+            "in": "query",
+            "description": "The token confirming a remove over the delete limit",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "dryRun",
+            "in": "query",
+            "description": "Count the keys without removing them",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "sample",
+            "in": "query",
+            "description": "Dry run only, the number of keys to return, at most 1000",
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          },
+          {
+            "name": "timeout",
+            "in": "query",
+            "description": "In seconds",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 5
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClientRemoveResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/client/tree": {
+      "get": {
+        "operationId": "readTree",
+        "summary": "Read one level of the key space",
+        "tags": [
+          "client"
+        ],
+        "parameters": [
+          {
+            "name": "key",
+            "in": "query",
+            "description": "The prefix of the level",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "delimiter",
+            "in": "query",
+            "schema": {
+              "type": "string",
+              "default": "/"
+            }
+          },
+          {
+            "name": "rev",
+            "in": "query",
+            "description": "Read at the revision, zero means the latest",
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          },
+          {
+            "name": "limit",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          },
+          {
+            "name": "pageSize",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 1000
+            }
+          },
+          {
+            "name": "timeout",
+            "in": "query",
+            "description": "In seconds",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 15
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClientTreeResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/client/export": {
+      "get": {
+        "operationId": "exportKeys",
+        "summary": "Export a key, a prefix or a range as a file",
+        "tags": [
+          "client"
+        ],
+        "parameters": [
+          {
+            "name": "key",
+            "in": "query",
+            "description": "Encoded by the encoding",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "prefix",
+            "in": "query",
+            "description": "Take the key as a prefix",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "fromKey",
+            "in": "query",
+            "description": "Range from the key to the end",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "range",
+            "in": "query",
+            "description": "The end of the range, exclusive",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "rev",
+            "in": "query",
+            "description": "Read at the revision, zero means the latest",
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          },
+          {
+            "name": "format",
+            "in": "query",
+            "schema": {
+              "type": "string",
+              "default": "jsonl",
+              "enum": [
+                "jsonl",
+                "json",
+                "yaml",
+                "etcdctl",
+                "etcdctl-json"
+              ]
+            }
+          },
+          {
+            "name": "metadata",
+            "in": "query",
+            "description": "Include the revisions, the versions and the leases",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "encoding",
+            "in": "query",
+            "description": "How the keys and the values are encoded",
+            "schema": {
+              "type": "string",
+              "default": "utf8",
+              "enum": [
+                "utf8",
+                "base64",
+                "hex"
+              ]
+            }
+          },
+          {
+            "name": "delimiter",
+            "in": "query",
+            "description": "Nests the keys of the json and yaml documents",
+            "schema": {
+              "type": "string",
+              "default": "/"
+            }
+          },
+          {
+            "name": "pageSize",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 1000
+            }
+          },
+          {
+            "name": "timeout",
+            "in": "query",
+            "description": "In seconds",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 60
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The exported file",
+            "content": {
+              "application/octet-stream": {
+                "schema": {
+                  "type": "string",
+                  "format": "binary"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/client/import": {
+      "post": {
+        "operationId": "importKeys",
+        "summary": "Import a file exported before",
+        "tags": [
+          "client"
+        ],
+        "parameters": [
+          {
+            "name": "key",
+            "in": "query",
+            "description": "The prefix the keys are imported under",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "format",
+            "in": "query",
+            "description": "Detected by the file if empty",
+            "schema": {
+              "type": "string",
+              "enum": [
+                "jsonl",
+                "json",
+                "yaml",
+                "etcdctl-json"
+              ]
+            }
+          },
+          {
+            "name": "delimiter",
+            "in": "query",
+            "schema": {
+              "type": "string",
+              "default": "/"
+            }
+          },
+          {
+            "name": "dryRun",
+            "in": "query",
+            "description": "Plan the import without writing",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "prune",
+            "in": "query",
+            "description": "Remove the keys under the prefix which are absent in the file",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "maxTxnOps",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 128
+            }
+          },
+          {
+            "name": "timeout",
+            "in": "query",
+            "description": "In seconds",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 60
+            }
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/octet-stream": {
+              "schema": {
+                "type": "string",
+                "format": "binary"
+              }
+            },
+            "multipart/form-data": {
+              "schema": {
+                "type": "object",
+                "properties": {
+                  "file": {
+                    "type": "string",
+                    "format": "binary"
+                  }
+                }
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClientImportResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/client/copy": {
+      "post": {
+        "operationId": "copyKeys",
+        "summary": "Copy a prefix or a key to another one",
+        "tags": [
+          "client"
+        ],
+        "parameters": [
+          {
+            "name": "timeout",
+            "in": "query",
+            "description": "In seconds",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 60
+            }
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ClientCopyRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClientCopyResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/client/move": {
+      "post": {
+        "operationId": "moveKeys",
+        "summary": "Move a prefix or a key to another one",
+        "tags": [
+          "client"
+        ],
+        "parameters": [
+          {
+            "name": "timeout",
+            "in": "query",
+            "description": "In seconds",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 60
+            }
+          }
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ClientCopyRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClientCopyResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/client/history": {
+      "get": {
+        "operationId": "getKeyHistory",
+        "summary": "Read the versions of a key",
+        "description": "The versions are read from the newest, a key deleted at the revision starts at its last deletion. etcd keeps no time of the revisions, so only the versions written by the console and still in the undo journal have their time",
+        "tags": [
+          "client"
+        ],
+        "parameters": [
+          {
+            "name": "key",
+            "in": "query",
+            "description": "Encoded by the encoding",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "rev",
+            "in": "query",
+            "description": "Walk back from the revision, zero means the latest",
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          },
+          {
+            "name": "limit",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 100
+            }
+          },
+          {
+            "name": "encoding",
+            "in": "query",
+            "description": "How the keys and the values are encoded",
+            "schema": {
+              "type": "string",
+              "default": "utf8",
+              "enum": [
+                "utf8",
+                "base64",
+                "hex"
+              ]
+            }
+          },
+          {
+            "name": "timeout",
+            "in": "query",
+            "description": "In seconds",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 15
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClientHistoryResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/client/diff": {
+      "get": {
+        "operationId": "diffKeys",
+        "summary": "Compare a key or a prefix between two revisions",
+        "description": "The prefix is compared page by page, the counts are of the page",
+        "tags": [
+          "client"
+        ],
+        "parameters": [
+          {
+            "name": "key",
+            "in": "query",
+            "description": "Encoded by the encoding",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "prefix",
+            "in": "query",
+            "description": "Take the key as a prefix",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "from",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          },
+          {
+            "name": "to",
+            "in": "query",
+            "description": "Zero means the latest",
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          },
+          {
+            "name": "values",
+            "in": "query",
+            "description": "Include the values and the patches",
+            "schema": {
+              "type": "boolean"
+            }
+          },
+          {
+            "name": "limit",
+            "in": "query",
+            "description": "How many keys of each revision a page of the prefix reads at most",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 1000
+            }
+          },
+          {
+            "name": "cursor",
+            "in": "query",
+            "description": "The cursor of the previous page",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "encoding",
+            "in": "query",
+            "description": "How the keys and the values are encoded",
+            "schema": {
+              "type": "string",
+              "default": "utf8",
+              "enum": [
+                "utf8",
+                "base64",
+                "hex"
+              ]
+            }
+          },
+          {
+            "name": "timeout",
+            "in": "query",
+            "description": "In seconds",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 15
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClientDiffResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/client/journal": {
+      "get": {
+        "operationId": "getJournal",
+        "summary": "List the journaled mutations from the newest",
+        "tags": [
+          "client"
+        ],
+        "parameters": [
+          {
+            "name": "limit",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int32",
+              "default": 100
+            }
+          },
+          {
+            "name": "encoding",
+            "in": "query",
+            "description": "How the keys and the values are encoded",
+            "schema": {
+              "type": "string",
+              "default": "utf8",
+              "enum": [
+                "utf8",
+                "base64",
+                "hex"
+              ]
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClientJournalResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/client/undo": {
+      "post": {
+        "operationId": "undoJournalEntry",
+        "summary": "Restore the previous values of a journaled mutation",
+        "tags": [
+          "client"
+        ],
+        "parameters": [
+          {
+            "name": "id",
+            "in": "query",
+            "description": "The journal entry, the X-Journal-Entry header of the mutation",
+            "required": true,
+            "schema": {
+              "type": "integer",
+              "format": "int64"
+            }
+          },
+          {
+            "name": "encoding",
+            "in": "query",
+            "description": "How the keys and the values are encoded",
+            "schema": {
+              "type": "string",
+              "default": "utf8",
+              "enum": [
+                "utf8",
+                "base64",
+                "hex"
+              ]
+            }
+          },
+          {
+            "name": "timeout",
+            "in": "query",
+            "description": "In seconds",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 15
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClientUndoResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/cluster/version": {
+      "get": {
+        "operationId": "getClusterVersion",
+        "summary": "Get the version and the features of the cluster",
+        "tags": [
+          "cluster"
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClusterVersionResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/cluster/status": {
+      "get": {
+        "operationId": "getMemberStatuses",
+        "summary": "Get the statuses of the members",
+        "tags": [
+          "cluster"
+        ],
+        "parameters": [
+          {
+            "name": "timeout",
+            "in": "query",
+            "description": "In seconds",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 5
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClusterMemberStatusResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/cluster/backup": {
+      "get": {
+        "operationId": "getBackups",
+        "summary": "List the backups, or download one by the name",
+        "tags": [
+          "cluster"
+        ],
+        "parameters": [
+          {
+            "name": "name",
+            "in": "query",
+            "description": "Downloads the backup",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "timeout",
+            "in": "query",
+            "description": "In seconds",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 15
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The backups, or the file of the named one",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClusterBackupResponse"
+                }
+              },
+              "application/octet-stream": {
+                "schema": {
+                  "type": "string",
+                  "format": "binary"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        },
+        "x-go-download": "DownloadBackup"
+      },
+      "post": {
+        "operationId": "createBackup",
+        "summary": "Take a snapshot of the cluster",
+        "tags": [
+          "cluster"
+        ],
+        "parameters": [
+          {
+            "name": "timeout",
+            "in": "query",
+            "description": "In seconds",
+            "schema": {
+              "type": "integer",
+              "format": "int64",
+              "default": 30
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ClusterBackupResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      },
+      "delete": {
+        "operationId": "removeBackup",
+        "summary": "Remove a backup",
+        "tags": [
+          "cluster"
+        ],
+        "parameters": [
+          {
+            "name": "name",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK"
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/mirror/job": {
+      "get": {
+        "operationId": "getMirrorJobs",
+        "summary": "List the mirror jobs, or get one by the id",
+        "tags": [
+          "mirror"
+        ],
+        "parameters": [
+          {
+            "name": "id",
+            "in": "query",
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/MirrorResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      },
+      "post": {
+        "operationId": "startMirrorJob",
+        "summary": "Start a mirror job",
+        "tags": [
+          "mirror"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/MirrorStartRequest"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/MirrorResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      },
+      "delete": {
+        "operationId": "stopMirrorJob",
+        "summary": "Stop a mirror job",
+        "tags": [
+          "mirror"
+        ],
+        "parameters": [
+          {
+            "name": "id",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/MirrorResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/audit": {
+      "get": {
+        "operationId": "queryAudit",
+        "summary": "Query the audit log from the newest",
+        "tags": [
+          "audit"
+        ],
+        "parameters": [
+          {
+            "name": "op",
+            "in": "query",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "user",
+            "in": "query",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "remoteAddr",
+            "in": "query",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "key",
+            "in": "query",
+            "description": "A part of the key in the params or the body",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "since",
+            "in": "query",
+            "description": "RFC 3339 or unix seconds",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "until",
+            "in": "query",
+            "description": "RFC 3339 or unix seconds",
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "limit",
+            "in": "query",
+            "schema": {
+              "type": "integer",
+              "format": "int32",
+              "default": 100
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/AuditResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/test/member": {
+      "get": {
+        "operationId": "getTestMembers",
+        "summary": "List the members of the embedding etcd in test mode",
+        "tags": [
+          "test"
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/TestMemberResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      },
+      "post": {
+        "operationId": "startTestMember",
+        "summary": "Start a stopped member of the embedding etcd",
+        "tags": [
+          "test"
+        ],
+        "parameters": [
+          {
+            "name": "name",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/TestMemberResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      },
+      "delete": {
+        "operationId": "stopTestMember",
+        "summary": "Stop a member of the embedding etcd",
+        "tags": [
+          "test"
+        ],
+        "parameters": [
+          {
+            "name": "name",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/TestMemberResponse"
+                }
+              }
+            }
+          },
+          "default": {
+            "description": "Failure",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ErrorResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "KeyValue": {
+        "type": "object",
+        "description": "KeyValue Pair",
+        "required": [
+          "key",
+          "value"
+        ],
+        "properties": {
+          "key": {
+            "type": "string"
+          },
+          "value": {
+            "type": "string"
+          },
+          "createRevision": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "modRevision": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "version": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "lease": {
+            "type": "string",
+            "description": "ID of the lease in hex, empty if none"
+          },
+          "encoding": {
+            "type": "string",
+            "description": "How the key and the value are encoded, empty means utf8"
+          },
+          "binary": {
+            "type": "boolean",
+            "description": "The key or the value isn't valid utf8"
+          },
+          "decoded": {
+            "$ref": "#/components/schemas/DecodedValue"
+          }
+        }
+      },
+      "DecodedValue": {
+        "type": "object",
+        "description": "Decoded Value, the object is rendered in json or yaml",
+        "required": [
+          "decoder"
+        ],
+        "properties": {
+          "decoder": {
+            "type": "string"
+          },
+          "apiVersion": {
+            "type": "string"
+          },
+          "kind": {
+            "type": "string"
+          },
+          "object": {
+            "description": "The decoded object, rendered in json"
+          },
+          "yaml": {
+            "type": "string",
+            "description": "The decoded object, rendered in yaml"
+          },
+          "note": {
+            "type": "string",
+            "description": "What's not decoded, e.g. the fields of the kubernetes protobuf objects whose kinds have no descriptors"
+          },
+          "error": {
+            "type": "string"
+          }
+        }
+      },
+      "KeyDir": {
+        "type": "object",
+        "description": "Directory of the key space, keys under it are counted",
+        "properties": {
+          "key": {
+            "type": "string"
+          },
+          "count": {
+            "type": "integer",
+            "format": "int64"
+          }
+        }
+      },
+      "KeyTree": {
+        "type": "object",
+        "description": "One level of the key space, split by the delimiter",
+        "properties": {
+          "prefix": {
+            "type": "string"
+          },
+          "delimiter": {
+            "type": "string"
+          },
+          "revision": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "dirs": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/KeyDir"
+            }
+          },
+          "keys": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/KeyValue"
+            }
+          },
+          "more": {
+            "type": "boolean"
+          }
+        }
+      },
+      "ImportItem": {
+        "type": "object",
+        "description": "Planned action of one imported key, the result is set once applied",
+        "properties": {
+          "key": {
+            "type": "string"
+          },
+          "encoding": {
+            "type": "string"
+          },
+          "action": {
+            "type": "string",
+            "description": "One of create, update, unchanged, delete and invalid"
+          },
+          "result": {
+            "type": "string"
+          },
+          "error": {
+            "type": "string"
+          }
+        }
+      },
+      "ImportPlan": {
+        "type": "object",
+        "description": "Plan of an import, against the revision the current values are read at",
+        "properties": {
+          "revision": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "dryRun": {
+            "type": "boolean"
+          },
+          "creates": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "updates": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "unchanged": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "deletes": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "invalid": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "applied": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "failed": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "items": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/ImportItem"
+            }
+          },
+          "journal": {
+            "type": "integer",
+            "format": "int64",
+            "description": "The entry of the undo journal, zero if not journaled"
+          }
+        }
+      },
+      "CopyConflict": {
+        "type": "object",
+        "description": "Key which cannot be copied or moved",
+        "properties": {
+          "key": {
+            "type": "string"
+          },
+          "encoding": {
+            "type": "string"
+          },
+          "reason": {
+            "type": "string"
+          }
+        }
+      },
+      "CopyResult": {
+        "type": "object",
+        "description": "Result of a copy or a move, the source is read at the revision",
+        "properties": {
+          "revision": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "copied": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "conflicts": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/CopyConflict"
+            }
+          },
+          "journal": {
+            "type": "integer",
+            "format": "int64",
+            "description": "The entry of the undo journal, zero if not journaled"
+          }
+        }
+      },
+      "KeyVersion": {
+        "description": "One version of a key, a deleted one only has the revision of the deletion, zero if unknown",
+        "allOf": [
+          {
+            "$ref": "#/components/schemas/KeyValue"
+          },
+          {
+            "type": "object",
+            "properties": {
+              "deleted": {
+                "type": "boolean"
+              },
+              "time": {
+                "type": "string",
+                "description": "When the console wrote the version, formatted as \"2006-01-02 15:04:05\", only known while the write is in the undo journal"
+              }
+            }
+          }
+        ]
+      },
+      "KeyHistory": {
+        "type": "object",
+        "description": "Versions of a key from the newest, walking back until the compacted revision",
+        "properties": {
+          "key": {
+            "type": "string"
+          },
+          "encoding": {
+            "type": "string"
+          },
+          "revision": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "versions": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/KeyVersion"
+            }
+          },
+          "compacted": {
+            "type": "boolean"
+          },
+          "more": {
+            "type": "boolean"
+          }
+        }
+      },
+      "KeyChange": {
+        "type": "object",
+        "description": "Change of a key between two revisions",
+        "properties": {
+          "key": {
+            "type": "string"
+          },
+          "encoding": {
+            "type": "string"
+          },
+          "change": {
+            "type": "string",
+            "description": "One of added, removed, modified and rewritten"
+          },
+          "before": {
+            "$ref": "#/components/schemas/KeyValue"
+          },
+          "after": {
+            "$ref": "#/components/schemas/KeyValue"
+          },
+          "patch": {
+            "type": "string",
+            "description": "Line diff of the values"
+          }
+        }
+      },
+      "KeyDiff": {
+        "type": "object",
+        "description": "Changes of a key or a prefix between two revisions",
+        "properties": {
+          "from": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "to": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "added": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "removed": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "modified": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "rewritten": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "changes": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/KeyChange"
+            }
+          },
+          "more": {
+            "type": "boolean",
+            "description": "The prefix has more keys to compare"
+          },
+          "cursor": {
+            "type": "string",
+            "description": "Continues to the next page"
+          }
+        }
+      },
+      "JournalKey": {
+        "type": "object",
+        "description": "A key changed by a mutation, the previous one is absent if the key was new",
+        "properties": {
+          "key": {
+            "type": "string"
+          },
+          "encoding": {
+            "type": "string"
+          },
+          "deleted": {
+            "type": "boolean"
+          },
+          "prev": {
+            "$ref": "#/components/schemas/KeyValue"
+          }
+        }
+      },
+      "JournalEntry": {
+        "type": "object",
+        "description": "A console-initiated mutation, kept with the previous values to be undone",
+        "properties": {
+          "id": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "time": {
+            "type": "string",
+            "description": "formatted as \"2006-01-02 15:04:05\""
+          },
+          "op": {
+            "type": "string",
+            "description": "One of write, remove, import, copy, move and undo"
+          },
+          "revision": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "keys": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/JournalKey"
+            }
+          },
+          "undone": {
+            "type": "integer",
+            "format": "int64",
+            "description": "The revision of the last undo, zero if never undone"
+          }
+        }
+      },
+      "UndoResult": {
+        "type": "object",
+        "description": "Result of an undo, which is journaled as well to be redone",
+        "properties": {
+          "id": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "journal": {
+            "type": "integer",
+            "format": "int64",
+            "description": "The journal entry of the undo itself"
+          },
+          "restored": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "conflicts": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/CopyConflict"
+            }
+          }
+        }
+      },
+      "EtcdFeatures": {
+        "type": "object",
+        "description": "Features of the etcd cluster, by its version",
+        "properties": {
+          "v3api": {
+            "type": "boolean",
+            "description": "Since 3.0"
+          },
+          "moveLeader": {
+            "type": "boolean",
+            "description": "Since 3.3"
+          },
+          "learner": {
+            "type": "boolean",
+            "description": "Since 3.4"
+          },
+          "downgrade": {
+            "type": "boolean",
+            "description": "Since 3.5"
+          }
+        }
+      },
+      "MemberStatus": {
+        "type": "object",
+        "description": "Member Status",
+        "properties": {
+          "id": {
+            "type": "string"
+          },
+          "name": {
+            "type": "string"
+          },
+          "endpoint": {
+            "type": "string"
+          },
+          "leader": {
+            "type": "boolean"
+          },
+          "health": {
+            "type": "boolean"
+          },
+          "connected": {
+            "type": "boolean"
+          },
+          "dbSize": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "version": {
+            "type": "string"
+          }
+        }
+      },
+      "Backup": {
+        "type": "object",
+        "description": "Backup",
+        "properties": {
+          "name": {
+            "type": "string"
+          },
+          "size": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "createTime": {
+            "type": "string",
+            "description": "formatted as \"2006-01-02 15:04:05\""
+          }
+        }
+      },
+      "MirrorJob": {
+        "type": "object",
+        "description": "Mirror Job, copies a prefix to another cluster, then follows the updates if watching",
+        "properties": {
+          "id": {
+            "type": "string"
+          },
+          "prefix": {
+            "type": "string"
+          },
+          "destPrefix": {
+            "type": "string"
+          },
+          "source": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "destination": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "watch": {
+            "type": "boolean"
+          },
+          "state": {
+            "type": "string",
+            "description": "One of syncing, watching, done, stopped and failed"
+          },
+          "revision": {
+            "type": "integer",
+            "format": "int64",
+            "description": "The initial sync is consistent at the revision of the source"
+          },
+          "synced": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "updates": {
+            "type": "integer",
+            "format": "int64",
+            "description": "The updates applied by watching, until the last revision of the source"
+          },
+          "lastRevision": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "startTime": {
+            "type": "string",
+            "description": "formatted as \"2006-01-02 15:04:05\""
+          },
+          "endTime": {
+            "type": "string",
+            "description": "formatted as \"2006-01-02 15:04:05\""
+          },
+          "error": {
+            "type": "string"
+          }
+        }
+      },
+      "AuditRecord": {
+        "type": "object",
+        "description": "Record of one mutating operation",
+        "properties": {
+          "time": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "op": {
+            "type": "string"
+          },
+          "method": {
+            "type": "string"
+          },
+          "path": {
+            "type": "string"
+          },
+          "remoteAddr": {
+            "type": "string"
+          },
+          "user": {
+            "type": "string"
+          },
+          "params": {
+            "type": "object",
+            "additionalProperties": {
+              "type": "string"
+            }
+          },
+          "body": {
+            "description": "The request body, as it was sent"
+          },
+          "status": {
+            "type": "integer",
+            "format": "int32"
+          },
+          "error": {
+            "type": "string"
+          },
+          "result": {
+            "description": "e.g. the previous key values returned by the op"
+          }
+        }
+      },
+      "TestMember": {
+        "type": "object",
+        "description": "Test Member, a member of the embedding etcd of the test mode",
+        "properties": {
+          "name": {
+            "type": "string"
+          },
+          "clientURL": {
+            "type": "string"
+          },
+          "peerURL": {
+            "type": "string"
+          },
+          "dataDir": {
+            "type": "string"
+          },
+          "running": {
+            "type": "boolean"
+          }
+        }
+      },
+      "Violation": {
+        "type": "object",
+        "description": "Violation of a value schema",
+        "properties": {
+          "path": {
+            "type": "string"
+          },
+          "keyword": {
+            "type": "string"
+          },
+          "message": {
+            "type": "string"
+          }
+        }
+      },
+      "ClientSetRequest": {
+        "type": "object",
+        "required": [
+          "key"
+        ],
+        "properties": {
+          "key": {
+            "type": "string"
+          },
+          "value": {
+            "type": "string"
+          },
+          "encoding": {
+            "type": "string",
+            "description": "How the key and the value are encoded",
+            "enum": [
+              "utf8",
+              "base64",
+              "hex"
+            ]
+          },
+          "raw": {
+            "type": "boolean",
+            "description": "Write the value as it is, skipping the codec mapped by the key prefix"
+          },
+          "ttl": {
+            "type": "integer",
+            "format": "int32",
+            "description": "v2 only"
+          },
+          "swapWithValue": {
+            "type": "string",
+            "description": "v2 only"
+          },
+          "swapWithIndex": {
+            "type": "integer",
+            "format": "int32",
+            "description": "v2 only"
+          },
+          "lease": {
+            "type": "string",
+            "description": "ID of the lease in hex"
+          },
+          "prevKV": {
+            "type": "boolean"
+          },
+          "ignoreValue": {
+            "type": "boolean"
+          },
+          "ignoreLease": {
+            "type": "boolean"
+          }
+        }
+      },
+      "ClientCopyRequest": {
+        "type": "object",
+        "required": [
+          "from",
+          "to"
+        ],
+        "properties": {
+          "from": {
+            "type": "string",
+            "description": "Source prefix, or key if exact"
+          },
+          "to": {
+            "type": "string",
+            "description": "Destination prefix, or key if exact"
+          },
+          "exact": {
+            "type": "boolean",
+            "description": "From and to are single keys, e.g. for renaming a key"
+          },
+          "encoding": {
+            "type": "string",
+            "description": "How the prefixes are encoded",
+            "enum": [
+              "utf8",
+              "base64",
+              "hex"
+            ]
+          },
+          "preserveLease": {
+            "type": "boolean"
+          },
+          "overwrite": {
+            "type": "boolean",
+            "description": "Overwrite the existing keys under the destination"
+          },
+          "maxTxnOps": {
+            "type": "integer",
+            "format": "int64"
+          }
+        }
+      },
+      "MirrorStartRequest": {
+        "type": "object",
+        "required": [
+          "prefix"
+        ],
+        "properties": {
+          "prefix": {
+            "type": "string"
+          },
+          "destPrefix": {
+            "type": "string",
+            "description": "The keys are rewritten under another prefix, defaults to the same one"
+          },
+          "destination": {
+            "type": "string",
+            "description": "One of the configured mirrors by name, or set the endpoints"
+          },
+          "endpoints": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            },
+            "description": "Refused unless MirrorAnyEndpoints is configured"
+          },
+          "source": {
+            "type": "string",
+            "description": "The source, one of the configured mirrors by name, defaults to the cluster of the console"
+          },
+          "sourceEndpoints": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            },
+            "description": "Refused unless MirrorAnyEndpoints is configured"
+          },
+          "watch": {
+            "type": "boolean",
+            "description": "Keep following the updates after the initial sync"
+          }
+        }
+      },
+      "ClientResponse": {
+        "type": "object",
+        "properties": {
+          "result": {
+            "type": "string",
+            "description": "Time the op took"
+          },
+          "kvs": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/KeyValue"
+            }
+          },
+          "more": {
+            "type": "boolean",
+            "description": "Read only"
+          },
+          "count": {
+            "type": "integer",
+            "format": "int64",
+            "description": "Read only"
+          },
+          "cursor": {
+            "type": "string",
+            "description": "Read only, continues to the next page"
+          }
+        }
+      },
+      "ClientRemoveResponse": {
+        "type": "object",
+        "description": "The removed key values, or the plan of a dry run",
+        "properties": {
+          "result": {
+            "type": "string",
+            "description": "Time the op took"
+          },
+          "kvs": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/KeyValue"
+            },
+            "description": "The previous key values, if prevKV"
+          },
+          "revision": {
+            "type": "integer",
+            "format": "int64",
+            "description": "Dry run only"
+          },
+          "count": {
+            "type": "integer",
+            "format": "int64",
+            "description": "Dry run only"
+          },
+          "keys": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/KeyValue"
+            },
+            "description": "Dry run only, a sample of the keys"
+          },
+          "more": {
+            "type": "boolean",
+            "description": "Dry run only"
+          },
+          "limit": {
+            "type": "integer",
+            "format": "int64",
+            "description": "Dry run only"
+          },
+          "token": {
+            "type": "string",
+            "description": "Dry run only, confirms the remove if the count exceeds the limit"
+          }
+        }
+      },
+      "ClientTreeResponse": {
+        "allOf": [
+          {
+            "$ref": "#/components/schemas/KeyTree"
+          },
+          {
+            "type": "object",
+            "properties": {
+              "result": {
+                "type": "string",
+                "description": "Time the op took"
+              }
+            }
+          }
+        ]
+      },
+      "ClientImportResponse": {
+        "allOf": [
+          {
+            "$ref": "#/components/schemas/ImportPlan"
+          },
+          {
+            "type": "object",
+            "properties": {
+              "result": {
+                "type": "string",
+                "description": "Time the op took"
+              }
+            }
+          }
+        ]
+      },
+      "ClientCopyResponse": {
+        "allOf": [
+          {
+            "$ref": "#/components/schemas/CopyResult"
+          },
+          {
+            "type": "object",
+            "properties": {
+              "result": {
+                "type": "string",
+                "description": "Time the op took"
+              }
+            }
+          }
+        ]
+      },
+      "ClientHistoryResponse": {
+        "allOf": [
+          {
+            "$ref": "#/components/schemas/KeyHistory"
+          },
+          {
+            "type": "object",
+            "properties": {
+              "result": {
+                "type": "string",
+                "description": "Time the op took"
+              }
+            }
+          }
+        ]
+      },
+      "ClientDiffResponse": {
+        "allOf": [
+          {
+            "$ref": "#/components/schemas/KeyDiff"
+          },
+          {
+            "type": "object",
+            "properties": {
+              "result": {
+                "type": "string",
+                "description": "Time the op took"
+              }
+            }
+          }
+        ]
+      },
+      "ClientJournalResponse": {
+        "type": "object",
+        "properties": {
+          "result": {
+            "type": "string",
+            "description": "Time the op took"
+          },
+          "entries": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/JournalEntry"
+            }
+          }
+        }
+      },
+      "ClientUndoResponse": {
+        "allOf": [
+          {
+            "$ref": "#/components/schemas/UndoResult"
+          },
+          {
+            "type": "object",
+            "properties": {
+              "result": {
+                "type": "string",
+                "description": "Time the op took"
+              }
+            }
+          }
+        ]
+      },
+      "ClusterVersionResponse": {
+        "type": "object",
+        "properties": {
+          "version": {
+            "type": "string"
+          },
+          "major": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "minor": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "patch": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "features": {
+            "$ref": "#/components/schemas/EtcdFeatures"
+          }
+        }
+      },
+      "ClusterMemberStatusResponse": {
+        "type": "object",
+        "properties": {
+          "members": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/MemberStatus"
+            }
+          }
+        }
+      },
+      "ClusterBackupResponse": {
+        "type": "object",
+        "properties": {
+          "backups": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/Backup"
+            }
+          }
+        }
+      },
+      "MirrorResponse": {
+        "type": "object",
+        "properties": {
+          "jobs": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/MirrorJob"
+            }
+          }
+        }
+      },
+      "AuditResponse": {
+        "type": "object",
+        "properties": {
+          "records": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/AuditRecord"
+            }
+          },
+          "more": {
+            "type": "boolean"
+          }
+        }
+      },
+      "TestMemberResponse": {
+        "type": "object",
+        "properties": {
+          "members": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/TestMember"
+            }
+          }
+        }
+      },
+      "ErrorResponse": {
+        "type": "object",
+        "description": "Envelope of the failed responses",
+        "required": [
+          "code",
+          "status",
+          "message"
+        ],
+        "properties": {
+          "code": {
+            "type": "string",
+            "description": "One of the API error codes",
+            "enum": [
+              "BadRequest",
+              "Forbidden",
+              "NotFound",
+              "MethodNotAllowed",
+              "Timeout",
+              "Conflict",
+              "InvalidValue",
+              "ConfirmationRequired",
+              "Internal",
+              "Unsupported",
+              "Unavailable"
+            ]
+          },
+          "status": {
+            "type": "integer",
+            "format": "int32"
+          },
+          "message": {
+            "type": "string"
+          },
+          "details": {
+            "description": "ValidationDetails for InvalidValue, ConfirmationDetails for ConfirmationRequired"
+          }
+        }
+      },
+      "ValidationDetails": {
+        "type": "object",
+        "properties": {
+          "key": {
+            "type": "string"
+          },
+          "schema": {
+            "type": "string"
+          },
+          "violations": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/Violation"
+            }
+          }
+        }
+      },
+      "ConfirmationDetails": {
+        "type": "object",
+        "properties": {
+          "count": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "limit": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "token": {
+            "type": "string",
+            "description": "Send it back as \"confirm\" to go on"
+          }
+        }
+      }
+    }
+  }
+}`
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"context"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/hero"
+	"github.com/thxcode/etcd-console/backend/v1/services"
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+	"github.com/thxcode/etcd-console/backend/web"
+)
+
+func Audit(irisCtx iris.Context, service services.AuditService) hero.Result {
+	var (
+		response = hero.Response{}
+		rootCtx  = irisCtx.Values().Get("etcd-console.ctx").(context.Context)
+	)
+
+	records, more, err := service.Query(rootCtx, web.ParseAuditRequest(irisCtx))
+	if err != nil {
+		irisCtx.Application().Logger().Error(err)
+
+		web.ErrorResponse(&response, err)
+	} else {
+		response.Object = viewmodels.AuditResponse{
+			Records: records,
+			More:    more,
+		}
+	}
+
+	return response
+}
@@ -6,13 +6,29 @@ import (
 	"github.com/thxcode/etcd-console/backend/v1/services"
 	"github.com/kataras/iris/hero"
 	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+	"github.com/thxcode/etcd-console/backend/web"
 	"github.com/thxcode/etcd-console/backend/v1/datamodels"
-	"errors"
 	"time"
 	"fmt"
 	"github.com/thxcode/etcd-console/backend"
 )
 
+// clientOpMethods is the method of each op.
+var clientOpMethods = map[string]string{
+	"read":    iris.MethodGet,
+	"write":   iris.MethodPost,
+	"remove":  iris.MethodDelete,
+	"tree":    iris.MethodGet,
+	"export":  iris.MethodGet,
+	"import":  iris.MethodPost,
+	"copy":    iris.MethodPost,
+	"move":    iris.MethodPost,
+	"history": iris.MethodGet,
+	"diff":    iris.MethodGet,
+	"journal": iris.MethodGet,
+	"undo":    iris.MethodPost,
+}
+
 func Client(irisCtx iris.Context, service services.ClientService, op string) hero.Result {
 	var (
 		start         = time.Now()
@@ -20,32 +36,166 @@ func Client(irisCtx iris.Context, service services.ClientService, op string) her
 		rootCtx       = irisCtx.Values().Get("etcd-console.ctx").(context.Context)
 		requestMethod = irisCtx.Method()
 		kvs           []datamodels.KeyValue
-		err           = errors.New("method not found")
+		page          datamodels.KeyValuePage
+		tree          *datamodels.KeyTree
+		streamed      bool
+		removal       *datamodels.DeletePlan
+		plan          *datamodels.ImportPlan
+		copied        *datamodels.CopyResult
+		history       *datamodels.KeyHistory
+		diff          *datamodels.KeyDiff
+		entries       []datamodels.JournalEntry
+		undo          *datamodels.UndoResult
+		err           error
 	)
 
+	allowed, ok := clientOpMethods[op]
+	if !ok {
+		web.ErrorResponse(&response, backend.NewNotFoundError("op not found"))
+		return response
+	}
+	if requestMethod != allowed {
+		web.MethodNotAllowed(irisCtx, &response, allowed)
+		return response
+	}
+
 	switch op {
 	case "read":
-		if requestMethod == iris.MethodGet {
-			kvs, err = service.Get(rootCtx, irisCtx)
+		if page, err = service.Get(rootCtx, web.ParseGetRequest(irisCtx)); err == nil {
+			kvs = page.KVS
 		}
 	case "write":
-		if requestMethod == iris.MethodPost {
-			kvs, err = service.Set(rootCtx, irisCtx)
+		var setRequest services.SetRequest
+		if setRequest, err = web.ParseSetRequest(irisCtx); err == nil {
+			var journalID int64
+			kvs, journalID, err = service.Set(rootCtx, setRequest)
+			web.JournalHeader(irisCtx, journalID)
 		}
+		web.AuditOp(irisCtx, "client/write", kvs, err)
 	case "remove":
-		if requestMethod == iris.MethodDelete {
-			kvs, err = service.Del(rootCtx, irisCtx)
+		// nothing is deleted on dry run
+		if dryRun, _ := irisCtx.URLParamBool("dryRun"); dryRun {
+			var deletePlan datamodels.DeletePlan
+			if deletePlan, err = service.DelDryRun(rootCtx, web.ParseDelRequest(irisCtx)); err == nil {
+				removal = &deletePlan
+			}
+			break
+		}
+
+		var journalID int64
+		kvs, journalID, err = service.Del(rootCtx, web.ParseDelRequest(irisCtx))
+		web.JournalHeader(irisCtx, journalID)
+		web.AuditOp(irisCtx, "client/remove", kvs, err)
+	case "tree":
+		var keyTree datamodels.KeyTree
+		if keyTree, err = service.Tree(rootCtx, web.ParseTreeRequest(irisCtx)); err == nil {
+			tree = &keyTree
+		}
+	case "export":
+		// the export is written to the response directly
+		if err = service.Export(rootCtx, web.ParseExportRequest(irisCtx), web.NewExportWriter(irisCtx)); err == nil {
+			streamed = true
+		}
+	case "import":
+		var (
+			importRequest services.ImportRequest
+			importPlan    datamodels.ImportPlan
+		)
+		if importRequest, err = web.ParseImportRequest(irisCtx); err == nil {
+			if importPlan, err = service.Import(rootCtx, importRequest); err == nil {
+				plan = &importPlan
+				web.JournalHeader(irisCtx, importPlan.Journal)
+			}
+		}
+		if err != nil || !importPlan.DryRun {
+			web.AuditOp(irisCtx, "client/import", importPlan, err)
+		}
+	case "copy", "move":
+		var (
+			copyRequest services.CopyRequest
+			copyResult  datamodels.CopyResult
+		)
+		if copyRequest, err = web.ParseCopyRequest(irisCtx); err == nil {
+			if copyResult, err = service.Copy(rootCtx, copyRequest, op == "move"); err == nil {
+				copied = &copyResult
+				web.JournalHeader(irisCtx, copyResult.Journal)
+			}
+		}
+		web.AuditOp(irisCtx, "client/"+op, copyResult, err)
+	case "history":
+		var keyHistory datamodels.KeyHistory
+		if keyHistory, err = service.History(rootCtx, web.ParseHistoryRequest(irisCtx)); err == nil {
+			history = &keyHistory
 		}
+	case "diff":
+		var keyDiff datamodels.KeyDiff
+		if keyDiff, err = service.Diff(rootCtx, web.ParseDiffRequest(irisCtx)); err == nil {
+			diff = &keyDiff
+		}
+	case "journal":
+		if entries, err = service.Journal(rootCtx, web.ParseJournalRequest(irisCtx)); err == nil && entries == nil {
+			entries = []datamodels.JournalEntry{}
+		}
+	case "undo":
+		var undoResult datamodels.UndoResult
+		if undoResult, err = service.Undo(rootCtx, web.ParseUndoRequest(irisCtx)); err == nil {
+			undo = &undoResult
+		}
+		web.AuditOp(irisCtx, "client/undo", undoResult, err)
 	}
 
 	if err != nil {
 		irisCtx.Application().Logger().Error(err)
 
-		response.Code = iris.StatusInternalServerError
-		response.Err = err
+		web.ErrorResponse(&response, err)
+	} else if streamed {
+		return response
+	} else if removal != nil {
+		response.Object = viewmodels.ClientDeletePlanResponse{
+			DeletePlan: *removal,
+			Result:     fmt.Sprintf("took time %v", backend.RoundDownDuration(time.Since(start), time.Millisecond)),
+		}
+	} else if plan != nil {
+		response.Object = viewmodels.ClientImportResponse{
+			ImportPlan: *plan,
+			Result:     fmt.Sprintf("took time %v", backend.RoundDownDuration(time.Since(start), time.Millisecond)),
+		}
+	} else if copied != nil {
+		response.Object = viewmodels.ClientCopyResponse{
+			CopyResult: *copied,
+			Result:     fmt.Sprintf("took time %v", backend.RoundDownDuration(time.Since(start), time.Millisecond)),
+		}
+	} else if history != nil {
+		response.Object = viewmodels.ClientHistoryResponse{
+			KeyHistory: *history,
+			Result:     fmt.Sprintf("took time %v", backend.RoundDownDuration(time.Since(start), time.Millisecond)),
+		}
+	} else if diff != nil {
+		response.Object = viewmodels.ClientDiffResponse{
+			KeyDiff: *diff,
+			Result:  fmt.Sprintf("took time %v", backend.RoundDownDuration(time.Since(start), time.Millisecond)),
+		}
+	} else if entries != nil {
+		response.Object = viewmodels.ClientJournalResponse{
+			Entries: entries,
+			Result:  fmt.Sprintf("took time %v", backend.RoundDownDuration(time.Since(start), time.Millisecond)),
+		}
+	} else if undo != nil {
+		response.Object = viewmodels.ClientUndoResponse{
+			UndoResult: *undo,
+			Result:     fmt.Sprintf("took time %v", backend.RoundDownDuration(time.Since(start), time.Millisecond)),
+		}
+	} else if tree != nil {
+		response.Object = viewmodels.ClientTreeResponse{
+			KeyTree: *tree,
+			Result:  fmt.Sprintf("took time %v", backend.RoundDownDuration(time.Since(start), time.Millisecond)),
+		}
 	} else {
 		response.Object = viewmodels.ClientResponse{
 			KVS:    kvs,
+			More:   page.More,
+			Count:  page.Count,
+			Cursor: page.Cursor,
 			Result: fmt.Sprintf("took time %v", backend.RoundDownDuration(time.Since(start), time.Millisecond)),
 		}
 	}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"github.com/thxcode/etcd-console/backend/v1/services"
 	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+	"github.com/thxcode/etcd-console/backend/web"
 	"github.com/kataras/iris/hero"
 	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+	"github.com/thxcode/etcd-console/backend"
 	"sort"
 	"strings"
 )
@@ -50,20 +52,34 @@ func Cluster(irisCtx iris.Context, service services.ClusterService, op string) h
 
 	switch op {
 	case "version":
-		version := service.GetVersion(rootCtx, irisCtx)
-		response.Object = viewmodels.ClusterVersionResponse{
-			Version: version.String(),
-			Major:   version.Major(),
-			Minor:   version.Minor(),
-			Patch:   version.Patch(),
+		version, err := service.GetVersion(rootCtx)
+		if err != nil {
+			irisCtx.Application().Logger().Error(err)
+
+			web.ErrorResponse(&response, err)
+			break
+		}
+
+		features, err := service.GetFeatures(rootCtx)
+		if err != nil {
+			irisCtx.Application().Logger().Error(err)
+
+			web.ErrorResponse(&response, err)
+		} else {
+			response.Object = viewmodels.ClusterVersionResponse{
+				Version:  version.String(),
+				Major:    version.Major(),
+				Minor:    version.Minor(),
+				Patch:    version.Patch(),
+				Features: features,
+			}
 		}
 	case "status":
-		members, err := service.GetStatuses(rootCtx, irisCtx)
+		members, err := service.GetStatuses(rootCtx, web.ParseTimeout(irisCtx))
 		if err != nil {
 			irisCtx.Application().Logger().Error(err)
 
-			response.Code = iris.StatusInternalServerError
-			response.Err = err
+			web.ErrorResponse(&response, err)
 		} else {
 			memberStatusSlices := memberStatusSlice(members)
 			sort.Sort(memberStatusSlices)
@@ -76,20 +92,18 @@ func Cluster(irisCtx iris.Context, service services.ClusterService, op string) h
 		switch requestMethod {
 		case iris.MethodGet:
 			if irisCtx.URLParamExists("name") {
-				err := service.DownloadBackup(rootCtx, irisCtx)
+				err := service.DownloadBackup(rootCtx, web.ParseBackupName(irisCtx), irisCtx.ResponseWriter())
 				if err != nil {
 					irisCtx.Application().Logger().Error(err)
 
-					response.Code = iris.StatusInternalServerError
-					response.Err = err
+					web.ErrorResponse(&response, err)
 				}
 			} else {
-				backups, err := service.GetBackups(rootCtx, irisCtx)
+				backups, err := service.GetBackups(rootCtx, web.ParseTimeout(irisCtx))
 				if err != nil {
 					irisCtx.Application().Logger().Error(err)
 
-					response.Code = iris.StatusInternalServerError
-					response.Err = err
+					web.ErrorResponse(&response, err)
 				} else {
 					backupSlices := backupSlice(backups)
 					sort.Sort(backupSlices)
@@ -100,26 +114,30 @@ func Cluster(irisCtx iris.Context, service services.ClusterService, op string) h
 				}
 			}
 		case iris.MethodDelete:
-			err := service.DelBackup(rootCtx, irisCtx)
+			err := service.DelBackup(rootCtx, web.ParseBackupName(irisCtx))
+			web.AuditOp(irisCtx, "cluster/backup/remove", nil, err)
 			if err != nil {
 				irisCtx.Application().Logger().Error(err)
 
-				response.Code = iris.StatusInternalServerError
-				response.Err = err
+				web.ErrorResponse(&response, err)
 			}
 		case iris.MethodPost:
-			backup, err := service.NewBackup(rootCtx, irisCtx)
+			backup, err := service.NewBackup(rootCtx, web.ParseTimeout(irisCtx))
+			web.AuditOp(irisCtx, "cluster/backup/create", backup, err)
 			if err != nil {
 				irisCtx.Application().Logger().Error(err)
 
-				response.Code = iris.StatusInternalServerError
-				response.Err = err
+				web.ErrorResponse(&response, err)
 			} else {
 				response.Object = viewmodels.ClusterBackupResponse{
 					Backups: []datamodels.Backup{backup},
 				}
 			}
+		default:
+			web.MethodNotAllowed(irisCtx, &response, iris.MethodGet, iris.MethodPost, iris.MethodDelete)
 		}
+	default:
+		web.ErrorResponse(&response, backend.NewNotFoundError("op not found"))
 	}
 
 	return response
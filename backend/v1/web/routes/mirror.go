@@ -0,0 +1,65 @@
+package routes
+
+import (
+	"context"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/hero"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+	"github.com/thxcode/etcd-console/backend/v1/services"
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+	"github.com/thxcode/etcd-console/backend/web"
+)
+
+func Mirror(irisCtx iris.Context, service services.MirrorService, op string) hero.Result {
+	var (
+		response      = hero.Response{}
+		rootCtx       = irisCtx.Values().Get("etcd-console.ctx").(context.Context)
+		requestMethod = irisCtx.Method()
+	)
+
+	switch op {
+	case "job":
+		var (
+			jobs []datamodels.MirrorJob
+			job  datamodels.MirrorJob
+			err  error
+		)
+
+		switch requestMethod {
+		case iris.MethodGet:
+			jobs, err = service.GetJobs(rootCtx, irisCtx.URLParam("id"))
+		case iris.MethodPost:
+			var mirrorStartRequest viewmodels.MirrorStartRequest
+			if mirrorStartRequest, err = web.ParseMirrorStartRequest(irisCtx); err == nil {
+				if job, err = service.StartJob(rootCtx, mirrorStartRequest); err == nil {
+					jobs = []datamodels.MirrorJob{job}
+				}
+			}
+			web.AuditOp(irisCtx, "mirror/job/start", job, err)
+		case iris.MethodDelete:
+			if job, err = service.StopJob(rootCtx, irisCtx.URLParam("id")); err == nil {
+				jobs = []datamodels.MirrorJob{job}
+			}
+			web.AuditOp(irisCtx, "mirror/job/stop", job, err)
+		default:
+			web.MethodNotAllowed(irisCtx, &response, iris.MethodGet, iris.MethodPost, iris.MethodDelete)
+			return response
+		}
+
+		if err != nil {
+			irisCtx.Application().Logger().Error(err)
+
+			web.ErrorResponse(&response, err)
+		} else {
+			response.Object = viewmodels.MirrorResponse{
+				Jobs: jobs,
+			}
+		}
+	default:
+		web.ErrorResponse(&response, backend.NewNotFoundError("op not found"))
+	}
+
+	return response
+}
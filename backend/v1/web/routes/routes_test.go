@@ -0,0 +1,348 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/core/router"
+	"github.com/kataras/iris/hero"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/etcdtest"
+	"github.com/thxcode/etcd-console/backend/v1/services"
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+	"github.com/thxcode/etcd-console/backend/web"
+)
+
+type testServer struct {
+	*etcdtest.Etcd
+	server     *httptest.Server
+	etcdClient *backend.EtcdClient
+}
+
+// startServer serves the /api/v1 routes as cmd/main.go does, by the services of an etcd for the test.
+func startServer(t *testing.T, fn func(configuration *backend.Configuration)) *testServer {
+	e := etcdtest.Start(t)
+
+	configuration := e.Configuration(t)
+	if fn != nil {
+		fn(&configuration)
+	}
+	etcdClient := e.NewEtcdClient(t, configuration)
+
+	deps := services.Dependencies{
+		Client:        etcdClient,
+		Configuration: configuration,
+		TestCluster:   e.Cluster(),
+	}
+	h := hero.New()
+	h.Register(
+		services.NewClusterService(deps),
+		services.NewClientService(deps),
+		services.NewTestService(deps),
+	)
+
+	app := iris.New()
+	app.PartyFunc("/api/v1", func(apiV1 router.Party) {
+		apiV1.Any("/cluster/{op: string}", h.Handler(Cluster))
+		apiV1.Any("/client/{op: string}", h.Handler(Client))
+		apiV1.Any("/test/{op: string}", h.Handler(Test))
+	})
+	app.OnErrorCode(iris.StatusNotFound, web.OnNotFound)
+	app.UseGlobal(func(irisCtx iris.Context) {
+		irisCtx.Values().Set("etcd-console.ctx", context.Background())
+		irisCtx.Next()
+	})
+	if err := app.Build(); err != nil {
+		etcdClient.Close()
+		e.Close()
+		t.Fatalf("cannot build the app, %v", err)
+	}
+
+	return &testServer{
+		Etcd:       e,
+		server:     httptest.NewServer(app),
+		etcdClient: etcdClient,
+	}
+}
+
+func (s *testServer) Close() {
+	s.server.Close()
+	s.etcdClient.Close()
+	s.Etcd.Close()
+}
+
+// do sends the request, and decodes the JSON response into the object if any.
+func (s *testServer) do(t *testing.T, method string, path string, query url.Values, body interface{}, object interface{}) *http.Response {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, s.server.URL+path+"?"+query.Encode(), reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s error = %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if object != nil {
+		if err := json.Unmarshal(data, object); err != nil {
+			t.Fatalf("%s %s answered %q, %v", method, path, data, err)
+		}
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return resp
+}
+
+func TestClientRoutes(t *testing.T) {
+	s := startServer(t, nil)
+	defer s.Close()
+
+	s.Put(t, "/a/1", "v1", "/a/2", "v2")
+
+	var written viewmodels.ClientResponse
+	resp := s.do(t, http.MethodPost, "/api/v1/client/write", nil, viewmodels.ClientSetRequest{Key: "/a/3", Value: "v3"}, &written)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("write status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if len(resp.Header.Get("X-Journal-Entry")) == 0 {
+		t.Errorf("write has no X-Journal-Entry header")
+	}
+
+	var read viewmodels.ClientResponse
+	resp = s.do(t, http.MethodGet, "/api/v1/client/read", url.Values{"key": {"/a/"}, "prefix": {"true"}, "limit": {"2"}}, nil, &read)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("read status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if len(read.KVS) != 2 || read.KVS[0].Key != "/a/1" || read.KVS[0].Value != "v1" {
+		t.Errorf("read = %+v, want /a/1 and /a/2", read.KVS)
+	}
+	if !read.More || read.Count != 3 || len(read.Cursor) == 0 {
+		t.Errorf("read more, count, cursor = %v, %d, %q, want more of 3 keys with a cursor", read.More, read.Count, read.Cursor)
+	}
+
+	var plan viewmodels.ClientDeletePlanResponse
+	resp = s.do(t, http.MethodDelete, "/api/v1/client/remove", url.Values{"key": {"/a/"}, "prefix": {"true"}, "dryRun": {"true"}}, nil, &plan)
+	if resp.StatusCode != http.StatusOK || plan.Count != 3 {
+		t.Errorf("dry run = %d, %+v, want 3 keys", resp.StatusCode, plan.DeletePlan)
+	}
+
+	resp = s.do(t, http.MethodDelete, "/api/v1/client/remove", url.Values{"key": {"/a/1"}}, nil, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("remove status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if _, ok := s.Get(t, "/a/1"); ok {
+		t.Errorf("/a/1 is not removed")
+	}
+}
+
+func TestClientRouteErrors(t *testing.T) {
+	s := startServer(t, func(configuration *backend.Configuration) {
+		configuration.ReadOnly = true
+	})
+	defer s.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		query  url.Values
+		body   interface{}
+		code   string
+		status int
+		// the methods in the Allow header of 405
+		allow string
+	}{
+		{
+			name:   "prefix and from key",
+			method: http.MethodGet,
+			path:   "/api/v1/client/read",
+			query:  url.Values{"key": {"/a"}, "prefix": {"true"}, "fromKey": {"true"}},
+			code:   backend.ErrCodeBadRequest,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "bad body",
+			method: http.MethodPost,
+			path:   "/api/v1/client/write",
+			body:   "not an object",
+			code:   backend.ErrCodeBadRequest,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "read-only",
+			method: http.MethodPost,
+			path:   "/api/v1/client/write",
+			body:   viewmodels.ClientSetRequest{Key: "/a", Value: "v"},
+			code:   backend.ErrCodeForbidden,
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "unknown op",
+			method: http.MethodGet,
+			path:   "/api/v1/client/unknown",
+			code:   backend.ErrCodeNotFound,
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "read by post",
+			method: http.MethodPost,
+			path:   "/api/v1/client/read",
+			code:   backend.ErrCodeMethodNotAllowed,
+			status: http.StatusMethodNotAllowed,
+			allow:  "GET",
+		},
+		{
+			name:   "write by get",
+			method: http.MethodGet,
+			path:   "/api/v1/client/write",
+			code:   backend.ErrCodeMethodNotAllowed,
+			status: http.StatusMethodNotAllowed,
+			allow:  "POST",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errorResponse viewmodels.ErrorResponse
+			resp := s.do(t, tt.method, tt.path, tt.query, tt.body, &errorResponse)
+			if resp.StatusCode != tt.status || errorResponse.Code != tt.code || errorResponse.Status != tt.status {
+				t.Errorf("%s %s = %d, %+v, want %d with %s", tt.method, tt.path, resp.StatusCode, errorResponse, tt.status, tt.code)
+			}
+			if allow := resp.Header.Get("Allow"); allow != tt.allow {
+				t.Errorf("%s %s Allow = %q, want %q", tt.method, tt.path, allow, tt.allow)
+			}
+		})
+	}
+}
+
+func TestClientExportRoute(t *testing.T) {
+	s := startServer(t, nil)
+	defer s.Close()
+
+	s.Put(t, "/e/1", "v1", "/e/2", "v2")
+
+	resp := s.do(t, http.MethodGet, "/api/v1/client/export", url.Values{"key": {"/e/"}, "prefix": {"true"}}, nil, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("export status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if disposition := resp.Header.Get("Content-Disposition"); !strings.HasPrefix(disposition, "attachment;") {
+		t.Errorf("export Content-Disposition = %q, want an attachment", disposition)
+	}
+	if len(resp.Header.Get("X-Etcd-Revision")) == 0 {
+		t.Errorf("export has no X-Etcd-Revision header")
+	}
+	data, _ := ioutil.ReadAll(resp.Body)
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 {
+		t.Errorf("export = %q, want a line per key", data)
+	}
+
+	// the failures ahead of the content are answered as errors
+	var errorResponse viewmodels.ErrorResponse
+	resp = s.do(t, http.MethodGet, "/api/v1/client/export", url.Values{"key": {"/e/"}, "format": {"xml"}}, nil, &errorResponse)
+	if resp.StatusCode != http.StatusBadRequest || len(resp.Header.Get("Content-Disposition")) != 0 {
+		t.Errorf("export of a bad format = %d, %+v, want %d without an attachment", resp.StatusCode, errorResponse, http.StatusBadRequest)
+	}
+}
+
+func TestClusterRoutes(t *testing.T) {
+	s := startServer(t, nil)
+	defer s.Close()
+
+	var statuses viewmodels.ClusterMemberStatusResponse
+	resp := s.do(t, http.MethodGet, "/api/v1/cluster/status", nil, nil, &statuses)
+	if resp.StatusCode != http.StatusOK || len(statuses.Members) != 1 || !statuses.Members[0].IsLeader {
+		t.Errorf("status = %d, %+v, want the leader", resp.StatusCode, statuses.Members)
+	}
+
+	var created viewmodels.ClusterBackupResponse
+	resp = s.do(t, http.MethodPost, "/api/v1/cluster/backup", nil, nil, &created)
+	if resp.StatusCode != http.StatusOK || len(created.Backups) != 1 {
+		t.Fatalf("create backup = %d, %+v, want the backup", resp.StatusCode, created.Backups)
+	}
+	name := created.Backups[0].Name
+
+	var listed viewmodels.ClusterBackupResponse
+	s.do(t, http.MethodGet, "/api/v1/cluster/backup", nil, nil, &listed)
+	if len(listed.Backups) != 1 || listed.Backups[0].Name != name {
+		t.Errorf("list backups = %+v, want %s", listed.Backups, name)
+	}
+
+	resp = s.do(t, http.MethodGet, "/api/v1/cluster/backup", url.Values{"name": {name}}, nil, nil)
+	if data, _ := ioutil.ReadAll(resp.Body); resp.StatusCode != http.StatusOK || len(data) == 0 {
+		t.Errorf("download backup = %d with %d bytes, want the zip", resp.StatusCode, len(data))
+	}
+
+	resp = s.do(t, http.MethodDelete, "/api/v1/cluster/backup", url.Values{"name": {name}}, nil, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("remove backup status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var errorResponse viewmodels.ErrorResponse
+	resp = s.do(t, http.MethodDelete, "/api/v1/cluster/backup", url.Values{"name": {name}}, nil, &errorResponse)
+	if resp.StatusCode != http.StatusNotFound || errorResponse.Code != backend.ErrCodeNotFound {
+		t.Errorf("remove a removed backup = %d, %+v, want %d", resp.StatusCode, errorResponse, http.StatusNotFound)
+	}
+
+	errorResponse = viewmodels.ErrorResponse{}
+	resp = s.do(t, http.MethodPut, "/api/v1/cluster/backup", nil, nil, &errorResponse)
+	if resp.StatusCode != http.StatusMethodNotAllowed || errorResponse.Code != backend.ErrCodeMethodNotAllowed || resp.Header.Get("Allow") != "GET, POST, DELETE" {
+		t.Errorf("PUT backup = %d, %+v, Allow %q, want %d", resp.StatusCode, errorResponse, resp.Header.Get("Allow"), http.StatusMethodNotAllowed)
+	}
+
+	errorResponse = viewmodels.ErrorResponse{}
+	resp = s.do(t, http.MethodGet, "/api/v1/cluster/unknown", nil, nil, &errorResponse)
+	if resp.StatusCode != http.StatusNotFound || errorResponse.Message != "op not found" {
+		t.Errorf("unknown op = %d, %+v, want %d", resp.StatusCode, errorResponse, http.StatusNotFound)
+	}
+}
+
+func TestTestRoutes(t *testing.T) {
+	s := startServer(t, nil)
+	defer s.Close()
+
+	var members viewmodels.TestMemberResponse
+	resp := s.do(t, http.MethodGet, "/api/v1/test/member", nil, nil, &members)
+	if resp.StatusCode != http.StatusOK || len(members.Members) != 1 || !members.Members[0].Running {
+		t.Errorf("members = %d, %+v, want the running member", resp.StatusCode, members.Members)
+	}
+
+	var errorResponse viewmodels.ErrorResponse
+	resp = s.do(t, http.MethodPut, "/api/v1/test/member", nil, nil, &errorResponse)
+	if resp.StatusCode != http.StatusMethodNotAllowed || errorResponse.Code != backend.ErrCodeMethodNotAllowed {
+		t.Errorf("PUT member = %d, %+v, want %d", resp.StatusCode, errorResponse, http.StatusMethodNotAllowed)
+	}
+	if allow := resp.Header.Get("Allow"); allow != "GET, POST, DELETE" {
+		t.Errorf("PUT member Allow = %q", allow)
+	}
+
+	errorResponse = viewmodels.ErrorResponse{}
+	resp = s.do(t, http.MethodGet, "/api/v1/test/unknown", nil, nil, &errorResponse)
+	if resp.StatusCode != http.StatusNotFound || errorResponse.Code != backend.ErrCodeNotFound {
+		t.Errorf("unknown op = %d, %+v, want %d", resp.StatusCode, errorResponse, http.StatusNotFound)
+	}
+}
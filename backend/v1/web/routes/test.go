@@ -0,0 +1,62 @@
+package routes
+
+import (
+	"context"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/hero"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+	"github.com/thxcode/etcd-console/backend/v1/services"
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+	"github.com/thxcode/etcd-console/backend/web"
+)
+
+func Test(irisCtx iris.Context, service services.TestService, op string) hero.Result {
+	var (
+		response      = hero.Response{}
+		rootCtx       = irisCtx.Values().Get("etcd-console.ctx").(context.Context)
+		requestMethod = irisCtx.Method()
+	)
+
+	switch op {
+	case "member":
+		var (
+			members []datamodels.TestMember
+			member  datamodels.TestMember
+			err     error
+		)
+
+		switch requestMethod {
+		case iris.MethodGet:
+			members, err = service.GetMembers(rootCtx)
+		case iris.MethodPost:
+			if member, err = service.StartMember(rootCtx, irisCtx.URLParam("name")); err == nil {
+				members = []datamodels.TestMember{member}
+			}
+			web.AuditOp(irisCtx, "test/member/start", member, err)
+		case iris.MethodDelete:
+			if member, err = service.StopMember(rootCtx, irisCtx.URLParam("name")); err == nil {
+				members = []datamodels.TestMember{member}
+			}
+			web.AuditOp(irisCtx, "test/member/stop", member, err)
+		default:
+			web.MethodNotAllowed(irisCtx, &response, iris.MethodGet, iris.MethodPost, iris.MethodDelete)
+			return response
+		}
+
+		if err != nil {
+			irisCtx.Application().Logger().Error(err)
+
+			web.ErrorResponse(&response, err)
+		} else {
+			response.Object = viewmodels.TestMemberResponse{
+				Members: members,
+			}
+		}
+	default:
+		web.ErrorResponse(&response, backend.NewNotFoundError("op not found"))
+	}
+
+	return response
+}
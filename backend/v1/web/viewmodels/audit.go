@@ -0,0 +1,10 @@
+package viewmodels
+
+import (
+	"github.com/thxcode/etcd-console/backend/audit"
+)
+
+type AuditResponse struct {
+	Records []audit.Record `json:"records"`
+	More    bool           `json:"more"`
+}
@@ -1,10 +1,16 @@
 package viewmodels
 
-import "github.com/thxcode/etcd-console/backend/v1/datamodels"
+import (
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+)
 
 type ClientSetRequest struct {
 	Key   string `json:"key"`
 	Value string `json:"value"`
+	// How the key and the value are encoded, one of utf8(default), base64 and hex
+	Encoding string `json:"encoding"`
+	// Write the value as it is, skipping the codec mapped by the key prefix
+	Raw bool `json:"raw"`
 
 	// v2
 	TTL           int32  `json:"ttl"`
@@ -18,8 +24,72 @@ type ClientSetRequest struct {
 	IgnoreLease bool   `json:"ignoreLease"`
 }
 
+type ClientCopyRequest struct {
+	// Source and destination prefixes, or keys if exact
+	From string `json:"from"`
+	To   string `json:"to"`
+	// From and To are single keys, e.g. for renaming a key
+	Exact bool `json:"exact"`
+	// How the prefixes are encoded, one of utf8(default), base64 and hex
+	Encoding string `json:"encoding"`
+
+	PreserveLease bool `json:"preserveLease"`
+	// Overwrite the existing keys under the destination
+	Overwrite bool  `json:"overwrite"`
+	MaxTxnOps int64 `json:"maxTxnOps"`
+}
 
 type ClientResponse struct {
 	Result  string `json:"result"`
 	KVS []datamodels.KeyValue `json:"kvs"`
+
+	// read only
+	More   bool   `json:"more,omitempty"`
+	Count  int64  `json:"count,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+type ClientTreeResponse struct {
+	Result string `json:"result"`
+	datamodels.KeyTree
+}
+
+type ClientDeletePlanResponse struct {
+	Result string `json:"result"`
+	datamodels.DeletePlan
+}
+
+type ClientImportResponse struct {
+	Result string `json:"result"`
+	datamodels.ImportPlan
+}
+
+type ClientCopyResponse struct {
+	Result string `json:"result"`
+	datamodels.CopyResult
+}
+
+type ClientHistoryResponse struct {
+	Result string `json:"result"`
+	datamodels.KeyHistory
+}
+
+type ClientDiffResponse struct {
+	Result string `json:"result"`
+	datamodels.KeyDiff
+}
+
+type ClientJournalResponse struct {
+	Result  string                    `json:"result"`
+	Entries []datamodels.JournalEntry `json:"entries"`
+}
+
+type ClientUndoResponse struct {
+	Result string `json:"result"`
+	datamodels.UndoResult
+}
+
+type LeaseGrantRequest struct {
+	// In seconds
+	TTL int64 `json:"ttl"`
 }
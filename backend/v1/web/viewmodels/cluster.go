@@ -1,12 +1,16 @@
 package viewmodels
 
-import "github.com/thxcode/etcd-console/backend/v1/datamodels"
+import (
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+)
 
 type ClusterVersionResponse struct {
-	Version string `json:"version"`
-	Major   int64  `json:"major"`
-	Minor   int64  `json:"minor"`
-	Patch   int64  `json:"patch"`
+	Version  string               `json:"version"`
+	Major    int64                `json:"major"`
+	Minor    int64                `json:"minor"`
+	Patch    int64                `json:"patch"`
+	Features backend.EtcdFeatures `json:"features"`
 }
 
 type ClusterMemberStatusResponse struct {
@@ -0,0 +1,27 @@
+package viewmodels
+
+import (
+	"github.com/thxcode/etcd-console/backend/schema"
+)
+
+// Envelope of the failed responses, the code is one of the API error codes
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+	// e.g. the violations of a schema, or the token to confirm a remove
+	Details interface{} `json:"details,omitempty"`
+}
+
+type ValidationDetails struct {
+	Key        string             `json:"key"`
+	Schema     string             `json:"schema"`
+	Violations []schema.Violation `json:"violations"`
+}
+
+type ConfirmationDetails struct {
+	Count int64 `json:"count"`
+	Limit int64 `json:"limit"`
+	// Send it back as "confirm" to go on
+	Token string `json:"token"`
+}
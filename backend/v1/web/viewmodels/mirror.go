@@ -0,0 +1,26 @@
+package viewmodels
+
+import (
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+)
+
+type MirrorStartRequest struct {
+	Prefix string `json:"prefix"`
+	// The keys are rewritten under another prefix, defaults to the same one
+	DestPrefix string `json:"destPrefix"`
+
+	// The destination, one of the configured mirrors by name, or the endpoints if "MirrorAnyEndpoints"
+	Destination string   `json:"destination"`
+	Endpoints   []string `json:"endpoints"`
+	// The source, defaults to the cluster of the console, one of the configured mirrors by name,
+	// or the endpoints if "MirrorAnyEndpoints"
+	Source          string   `json:"source"`
+	SourceEndpoints []string `json:"sourceEndpoints"`
+
+	// Keep following the updates after the initial sync
+	Watch bool `json:"watch"`
+}
+
+type MirrorResponse struct {
+	Jobs []datamodels.MirrorJob `json:"jobs"`
+}
@@ -0,0 +1,9 @@
+package viewmodels
+
+import (
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+)
+
+type TestMemberResponse struct {
+	Members []datamodels.TestMember `json:"members"`
+}
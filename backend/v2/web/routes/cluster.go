@@ -0,0 +1,114 @@
+package routes
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/hero"
+	"github.com/thxcode/etcd-console/backend/v1/services"
+	"github.com/thxcode/etcd-console/backend/v2/web/viewmodels"
+	"github.com/thxcode/etcd-console/backend/web"
+)
+
+func Members(irisCtx iris.Context, service services.ClusterService) hero.Result {
+	var (
+		response = hero.Response{}
+		rootCtx  = irisCtx.Values().Get("etcd-console.ctx").(context.Context)
+	)
+
+	if irisCtx.Method() != iris.MethodGet {
+		web.MethodNotAllowed(irisCtx, &response, iris.MethodGet)
+		return response
+	}
+
+	members, err := service.GetStatuses(rootCtx, web.ParseTimeout(irisCtx))
+	if err != nil {
+		irisCtx.Application().Logger().Error(err)
+
+		web.ErrorResponse(&response, err)
+	} else {
+		sort.Slice(members, func(i, j int) bool {
+			return strings.Compare(members[i].Name, members[j].Name) < 0
+		})
+
+		response.Object = viewmodels.MembersResponse{
+			Members: members,
+		}
+	}
+
+	return response
+}
+
+// Backups lists the backups from the newest, or takes a new one.
+func Backups(irisCtx iris.Context, service services.ClusterService) hero.Result {
+	var (
+		response = hero.Response{}
+		rootCtx  = irisCtx.Values().Get("etcd-console.ctx").(context.Context)
+	)
+
+	switch irisCtx.Method() {
+	case iris.MethodGet:
+		backups, err := service.GetBackups(rootCtx, web.ParseTimeout(irisCtx))
+		if err != nil {
+			irisCtx.Application().Logger().Error(err)
+
+			web.ErrorResponse(&response, err)
+		} else {
+			sort.Slice(backups, func(i, j int) bool {
+				return backups[i].CreateTime.Unix() > backups[j].CreateTime.Unix()
+			})
+
+			response.Object = viewmodels.BackupsResponse{
+				Backups: backups,
+			}
+		}
+	case iris.MethodPost:
+		backup, err := service.NewBackup(rootCtx, web.ParseTimeout(irisCtx))
+		web.AuditOp(irisCtx, "cluster/backup/create", backup, err)
+		if err != nil {
+			irisCtx.Application().Logger().Error(err)
+
+			web.ErrorResponse(&response, err)
+		} else {
+			created(irisCtx, &response, irisCtx.Path()+"/"+backup.Name, backup)
+		}
+	default:
+		web.MethodNotAllowed(irisCtx, &response, iris.MethodGet, iris.MethodPost)
+	}
+
+	return response
+}
+
+// Backup downloads or removes the backup in the path.
+func Backup(irisCtx iris.Context, service services.ClusterService) hero.Result {
+	var (
+		response = hero.Response{}
+		rootCtx  = irisCtx.Values().Get("etcd-console.ctx").(context.Context)
+	)
+
+	switch irisCtx.Method() {
+	case iris.MethodGet:
+		// the backup is written to the response directly
+		if err := service.DownloadBackup(rootCtx, web.ParseBackupName(irisCtx), irisCtx.ResponseWriter()); err != nil {
+			irisCtx.Application().Logger().Error(err)
+
+			web.ErrorResponse(&response, err)
+		}
+	case iris.MethodDelete:
+		err := service.DelBackup(rootCtx, web.ParseBackupName(irisCtx))
+		web.AuditOp(irisCtx, "cluster/backup/remove", nil, err)
+		if err != nil {
+			irisCtx.Application().Logger().Error(err)
+
+			web.ErrorResponse(&response, err)
+		} else {
+			response.Code = iris.StatusNoContent
+		}
+	default:
+		web.MethodNotAllowed(irisCtx, &response, iris.MethodGet, iris.MethodDelete)
+	}
+
+	return response
+}
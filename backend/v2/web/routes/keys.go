@@ -0,0 +1,140 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/hero"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+	"github.com/thxcode/etcd-console/backend/v1/services"
+	"github.com/thxcode/etcd-console/backend/v2/web/viewmodels"
+	"github.com/thxcode/etcd-console/backend/web"
+)
+
+// Keys reads the whole key space, or the range set by the query.
+func Keys(irisCtx iris.Context, service services.ClientService) hero.Result {
+	response := hero.Response{}
+
+	if irisCtx.Method() != iris.MethodGet {
+		web.MethodNotAllowed(irisCtx, &response, iris.MethodGet)
+		return response
+	}
+
+	readKeys(irisCtx, service, &response, true)
+	return response
+}
+
+// Key reads, writes or removes the key in the path, the path is the key from its leading slash in utf8,
+// e.g. "/api/v2/keys/registry/pods" is "/registry/pods", the keys in base64 or hex are taken as they are.
+func Key(irisCtx iris.Context, service services.ClientService, key string) hero.Result {
+	var (
+		response = hero.Response{}
+		rootCtx  = irisCtx.Values().Get("etcd-console.ctx").(context.Context)
+	)
+
+	// the parsers take the key from the path params
+	switch strings.ToLower(irisCtx.URLParam("encoding")) {
+	case "", "utf8", "utf-8":
+		key = "/" + strings.TrimPrefix(key, "/")
+	}
+	irisCtx.Params().Set("key", key)
+
+	switch irisCtx.Method() {
+	case iris.MethodGet:
+		readKeys(irisCtx, service, &response, isRange(irisCtx))
+	case iris.MethodPut:
+		setRequest, err := web.ParseSetRequest(irisCtx)
+		var kvs []datamodels.KeyValue
+		if err == nil {
+			var journalID int64
+			kvs, journalID, err = service.Set(rootCtx, setRequest)
+			web.JournalHeader(irisCtx, journalID)
+		}
+		web.AuditOp(irisCtx, "client/write", kvs, err)
+		if err != nil {
+			irisCtx.Application().Logger().Error(err)
+
+			web.ErrorResponse(&response, err)
+		} else {
+			response.Object = viewmodels.KeysResponse{
+				KVS: nonNilKeyValues(kvs),
+			}
+		}
+	case iris.MethodDelete:
+		// nothing is deleted on dry run
+		if dryRun, _ := irisCtx.URLParamBool("dryRun"); dryRun {
+			deletePlan, err := service.DelDryRun(rootCtx, web.ParseDelRequest(irisCtx))
+			if err != nil {
+				irisCtx.Application().Logger().Error(err)
+
+				web.ErrorResponse(&response, err)
+			} else {
+				response.Object = deletePlan
+			}
+			break
+		}
+
+		kvs, journalID, err := service.Del(rootCtx, web.ParseDelRequest(irisCtx))
+		web.JournalHeader(irisCtx, journalID)
+		web.AuditOp(irisCtx, "client/remove", kvs, err)
+		if err != nil {
+			irisCtx.Application().Logger().Error(err)
+
+			web.ErrorResponse(&response, err)
+		} else {
+			response.Object = viewmodels.KeysResponse{
+				KVS: nonNilKeyValues(kvs),
+			}
+		}
+	default:
+		web.MethodNotAllowed(irisCtx, &response, iris.MethodGet, iris.MethodPut, iris.MethodDelete)
+	}
+
+	return response
+}
+
+// readKeys answers a single key with the key value itself, and a range with a page of them.
+func readKeys(irisCtx iris.Context, service services.ClientService, response *hero.Response, asRange bool) {
+	rootCtx := irisCtx.Values().Get("etcd-console.ctx").(context.Context)
+
+	page, err := service.Get(rootCtx, web.ParseGetRequest(irisCtx))
+	if err != nil {
+		irisCtx.Application().Logger().Error(err)
+
+		web.ErrorResponse(response, err)
+		return
+	}
+
+	if asRange {
+		response.Object = viewmodels.KeysResponse{
+			KVS:    nonNilKeyValues(page.KVS),
+			More:   page.More,
+			Count:  page.Count,
+			Cursor: page.Cursor,
+		}
+		return
+	}
+
+	if len(page.KVS) == 0 {
+		web.ErrorResponse(response, backend.NewNotFoundError(fmt.Sprintf("cannot find key %s", irisCtx.Params().Get("key"))))
+		return
+	}
+	response.Object = page.KVS[0]
+}
+
+func isRange(irisCtx iris.Context) bool {
+	prefix, _ := irisCtx.URLParamBool("prefix")
+	fromKey, _ := irisCtx.URLParamBool("fromKey")
+
+	return prefix || fromKey || irisCtx.URLParamExists("range") || irisCtx.URLParamExists("cursor")
+}
+
+func nonNilKeyValues(kvs []datamodels.KeyValue) []datamodels.KeyValue {
+	if kvs == nil {
+		return []datamodels.KeyValue{}
+	}
+	return kvs
+}
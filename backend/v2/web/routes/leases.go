@@ -0,0 +1,75 @@
+package routes
+
+import (
+	"context"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/hero"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+	"github.com/thxcode/etcd-console/backend/v1/services"
+	"github.com/thxcode/etcd-console/backend/web"
+)
+
+// Leases grants a new lease, the leases cannot be listed by the etcd client yet.
+func Leases(irisCtx iris.Context, service services.LeaseService) hero.Result {
+	var (
+		response = hero.Response{}
+		rootCtx  = irisCtx.Values().Get("etcd-console.ctx").(context.Context)
+	)
+
+	if irisCtx.Method() != iris.MethodPost {
+		web.MethodNotAllowed(irisCtx, &response, iris.MethodPost)
+		return response
+	}
+
+	leaseGrantRequest, err := web.ParseLeaseGrantRequest(irisCtx)
+	var lease datamodels.Lease
+	if err == nil {
+		lease, err = service.Grant(rootCtx, leaseGrantRequest)
+	}
+	web.AuditOp(irisCtx, "lease/grant", lease, err)
+	if err != nil {
+		irisCtx.Application().Logger().Error(err)
+
+		web.ErrorResponse(&response, err)
+	} else {
+		created(irisCtx, &response, irisCtx.Path()+"/"+lease.ID, lease)
+	}
+
+	return response
+}
+
+// Lease gets or revokes the lease in the path, the ID is in hex.
+func Lease(irisCtx iris.Context, service services.LeaseService) hero.Result {
+	var (
+		response = hero.Response{}
+		rootCtx  = irisCtx.Values().Get("etcd-console.ctx").(context.Context)
+	)
+
+	switch irisCtx.Method() {
+	case iris.MethodGet:
+		lease, err := service.Get(rootCtx, web.ParseLeaseRequest(irisCtx))
+		if err != nil {
+			irisCtx.Application().Logger().Error(err)
+
+			web.ErrorResponse(&response, err)
+		} else {
+			response.Object = lease
+		}
+	case iris.MethodDelete:
+		// the revoked lease is answered with the removed keys
+		lease, err := service.Revoke(rootCtx, web.ParseLeaseRequest(irisCtx))
+		web.AuditOp(irisCtx, "lease/revoke", lease, err)
+		if err != nil {
+			irisCtx.Application().Logger().Error(err)
+
+			web.ErrorResponse(&response, err)
+		} else {
+			response.Object = lease
+		}
+	default:
+		web.MethodNotAllowed(irisCtx, &response, iris.MethodGet, iris.MethodDelete)
+	}
+
+	return response
+}
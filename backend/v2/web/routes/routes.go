@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/hero"
+)
+
+// created answers a new resource with its location.
+func created(irisCtx iris.Context, response *hero.Response, location string, object interface{}) {
+	irisCtx.Header("Location", location)
+
+	response.Code = iris.StatusCreated
+	response.Object = object
+}
@@ -0,0 +1,305 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/core/router"
+	"github.com/kataras/iris/hero"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/etcdtest"
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+	"github.com/thxcode/etcd-console/backend/v1/services"
+	v1ViewModels "github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+	"github.com/thxcode/etcd-console/backend/v2/web/viewmodels"
+	"github.com/thxcode/etcd-console/backend/web"
+)
+
+type testServer struct {
+	*etcdtest.Etcd
+	server     *httptest.Server
+	etcdClient *backend.EtcdClient
+}
+
+// startServer serves the /api/v2 routes as cmd/main.go does, by the services of an etcd for the test.
+func startServer(t *testing.T) *testServer {
+	e := etcdtest.Start(t)
+
+	configuration := e.Configuration(t)
+	etcdClient := e.NewEtcdClient(t, configuration)
+
+	deps := services.Dependencies{
+		Client:        etcdClient,
+		Configuration: configuration,
+		TestCluster:   e.Cluster(),
+	}
+	h := hero.New()
+	h.Register(
+		services.NewClusterService(deps),
+		services.NewClientService(deps),
+		services.NewLeaseService(deps),
+	)
+
+	app := iris.New()
+	app.PartyFunc("/api/v2", func(apiV2 router.Party) {
+		apiV2.Any("/keys", h.Handler(Keys))
+		apiV2.Any("/keys/{key:path}", h.Handler(Key))
+		apiV2.Any("/members", h.Handler(Members))
+		apiV2.Any("/backups", h.Handler(Backups))
+		apiV2.Any("/backups/{name:string}", h.Handler(Backup))
+		apiV2.Any("/leases", h.Handler(Leases))
+		apiV2.Any("/leases/{id:string}", h.Handler(Lease))
+	})
+	app.OnErrorCode(iris.StatusNotFound, web.OnNotFound)
+	app.UseGlobal(func(irisCtx iris.Context) {
+		irisCtx.Values().Set("etcd-console.ctx", context.Background())
+		irisCtx.Next()
+	})
+	if err := app.Build(); err != nil {
+		etcdClient.Close()
+		e.Close()
+		t.Fatalf("cannot build the app, %v", err)
+	}
+
+	return &testServer{
+		Etcd:       e,
+		server:     httptest.NewServer(app),
+		etcdClient: etcdClient,
+	}
+}
+
+func (s *testServer) Close() {
+	s.server.Close()
+	s.etcdClient.Close()
+	s.Etcd.Close()
+}
+
+// do sends the request, and decodes the JSON response into the object if any.
+func (s *testServer) do(t *testing.T, method string, path string, query url.Values, body interface{}, object interface{}) *http.Response {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, s.server.URL+path+"?"+query.Encode(), reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s error = %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if object != nil {
+		if err := json.Unmarshal(data, object); err != nil {
+			t.Fatalf("%s %s answered %q, %v", method, path, data, err)
+		}
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return resp
+}
+
+func TestKeyRoutes(t *testing.T) {
+	s := startServer(t)
+	defer s.Close()
+
+	s.Put(t, "/registry/pods/a", "v1", "/registry/pods/b", "v2")
+
+	// the path is the key from its leading slash
+	var written viewmodels.KeysResponse
+	resp := s.do(t, http.MethodPut, "/api/v2/keys/registry/pods/c", nil, v1ViewModels.ClientSetRequest{Value: "v3"}, &written)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("PUT key status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if value, ok := s.Get(t, "/registry/pods/c"); !ok || value != "v3" {
+		t.Errorf("PUT key wrote %q, %v, want v3 to /registry/pods/c", value, ok)
+	}
+
+	var kv datamodels.KeyValue
+	resp = s.do(t, http.MethodGet, "/api/v2/keys/registry/pods/a", nil, nil, &kv)
+	if resp.StatusCode != http.StatusOK || kv.Key != "/registry/pods/a" || kv.Value != "v1" {
+		t.Errorf("GET key = %d, %+v, want /registry/pods/a", resp.StatusCode, kv)
+	}
+
+	var page viewmodels.KeysResponse
+	resp = s.do(t, http.MethodGet, "/api/v2/keys/registry/pods/", url.Values{"prefix": {"true"}, "limit": {"2"}}, nil, &page)
+	if resp.StatusCode != http.StatusOK || len(page.KVS) != 2 || !page.More || page.Count != 3 {
+		t.Errorf("GET prefix = %d, %+v, want a page of 2 of 3 keys", resp.StatusCode, page)
+	}
+
+	page = viewmodels.KeysResponse{}
+	resp = s.do(t, http.MethodGet, "/api/v2/keys", url.Values{"key": {"/registry/"}, "prefix": {"true"}}, nil, &page)
+	if resp.StatusCode != http.StatusOK || len(page.KVS) != 3 {
+		t.Errorf("GET keys = %d, %+v, want 3 keys", resp.StatusCode, page)
+	}
+
+	var removed viewmodels.KeysResponse
+	resp = s.do(t, http.MethodDelete, "/api/v2/keys/registry/pods/a", url.Values{"prevKV": {"true"}}, nil, &removed)
+	if resp.StatusCode != http.StatusOK || len(removed.KVS) != 1 || removed.KVS[0].Key != "/registry/pods/a" {
+		t.Errorf("DELETE key = %d, %+v, want the removed /registry/pods/a", resp.StatusCode, removed.KVS)
+	}
+	if _, ok := s.Get(t, "/registry/pods/a"); ok {
+		t.Errorf("/registry/pods/a is not removed")
+	}
+
+	var errorResponse v1ViewModels.ErrorResponse
+	resp = s.do(t, http.MethodGet, "/api/v2/keys/registry/pods/a", nil, nil, &errorResponse)
+	if resp.StatusCode != http.StatusNotFound || errorResponse.Code != backend.ErrCodeNotFound {
+		t.Errorf("GET a removed key = %d, %+v, want %d", resp.StatusCode, errorResponse, http.StatusNotFound)
+	}
+}
+
+func TestLeaseRoutes(t *testing.T) {
+	s := startServer(t)
+	defer s.Close()
+
+	var lease datamodels.Lease
+	resp := s.do(t, http.MethodPost, "/api/v2/leases", nil, v1ViewModels.LeaseGrantRequest{TTL: 60}, &lease)
+	if resp.StatusCode != http.StatusCreated || len(lease.ID) == 0 {
+		t.Fatalf("POST leases = %d, %+v, want the granted lease", resp.StatusCode, lease)
+	}
+	if location := resp.Header.Get("Location"); location != "/api/v2/leases/"+lease.ID {
+		t.Errorf("POST leases Location = %q, want /api/v2/leases/%s", location, lease.ID)
+	}
+
+	var got datamodels.Lease
+	resp = s.do(t, http.MethodGet, "/api/v2/leases/"+lease.ID, nil, nil, &got)
+	if resp.StatusCode != http.StatusOK || got.ID != lease.ID || got.GrantedTTL != 60 {
+		t.Errorf("GET lease = %d, %+v, want the lease of 60 seconds", resp.StatusCode, got)
+	}
+
+	resp = s.do(t, http.MethodDelete, "/api/v2/leases/"+lease.ID, nil, nil, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("DELETE lease status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestClusterRoutes(t *testing.T) {
+	s := startServer(t)
+	defer s.Close()
+
+	var members viewmodels.MembersResponse
+	resp := s.do(t, http.MethodGet, "/api/v2/members", nil, nil, &members)
+	if resp.StatusCode != http.StatusOK || len(members.Members) != 1 {
+		t.Errorf("GET members = %d, %+v, want the member", resp.StatusCode, members.Members)
+	}
+
+	var backup datamodels.Backup
+	resp = s.do(t, http.MethodPost, "/api/v2/backups", nil, nil, &backup)
+	if resp.StatusCode != http.StatusCreated || len(backup.Name) == 0 {
+		t.Fatalf("POST backups = %d, %+v, want the backup", resp.StatusCode, backup)
+	}
+	if location := resp.Header.Get("Location"); location != "/api/v2/backups/"+backup.Name {
+		t.Errorf("POST backups Location = %q, want /api/v2/backups/%s", location, backup.Name)
+	}
+
+	resp = s.do(t, http.MethodDelete, "/api/v2/backups/"+backup.Name, nil, nil, nil)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE backup status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+}
+
+func TestRouteErrors(t *testing.T) {
+	s := startServer(t)
+	defer s.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		code   string
+		status int
+		// the methods in the Allow header of 405
+		allow string
+	}{
+		{
+			name:   "write keys",
+			method: http.MethodPost,
+			path:   "/api/v2/keys",
+			code:   backend.ErrCodeMethodNotAllowed,
+			status: http.StatusMethodNotAllowed,
+			allow:  "GET",
+		},
+		{
+			name:   "post key",
+			method: http.MethodPost,
+			path:   "/api/v2/keys/registry/pods/a",
+			code:   backend.ErrCodeMethodNotAllowed,
+			status: http.StatusMethodNotAllowed,
+			allow:  "GET, PUT, DELETE",
+		},
+		{
+			name:   "remove members",
+			method: http.MethodDelete,
+			path:   "/api/v2/members",
+			code:   backend.ErrCodeMethodNotAllowed,
+			status: http.StatusMethodNotAllowed,
+			allow:  "GET",
+		},
+		{
+			name:   "put backups",
+			method: http.MethodPut,
+			path:   "/api/v2/backups",
+			code:   backend.ErrCodeMethodNotAllowed,
+			status: http.StatusMethodNotAllowed,
+			allow:  "GET, POST",
+		},
+		{
+			name:   "list leases",
+			method: http.MethodGet,
+			path:   "/api/v2/leases",
+			code:   backend.ErrCodeMethodNotAllowed,
+			status: http.StatusMethodNotAllowed,
+			allow:  "POST",
+		},
+		{
+			name:   "put lease",
+			method: http.MethodPut,
+			path:   "/api/v2/leases/1",
+			code:   backend.ErrCodeMethodNotAllowed,
+			status: http.StatusMethodNotAllowed,
+			allow:  "GET, DELETE",
+		},
+		{
+			name:   "unknown resource",
+			method: http.MethodGet,
+			path:   "/api/v2/unknown",
+			code:   backend.ErrCodeNotFound,
+			status: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errorResponse v1ViewModels.ErrorResponse
+			resp := s.do(t, tt.method, tt.path, nil, nil, &errorResponse)
+			if resp.StatusCode != tt.status || errorResponse.Code != tt.code || errorResponse.Status != tt.status {
+				t.Errorf("%s %s = %d, %+v, want %d with %s", tt.method, tt.path, resp.StatusCode, errorResponse, tt.status, tt.code)
+			}
+			if allow := resp.Header.Get("Allow"); allow != tt.allow {
+				t.Errorf("%s %s Allow = %q, want %q", tt.method, tt.path, allow, tt.allow)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package viewmodels
+
+import (
+	"github.com/thxcode/etcd-console/backend/v1/datamodels"
+)
+
+// Keys of a range read, or the previous ones of a write or a remove
+type KeysResponse struct {
+	KVS []datamodels.KeyValue `json:"kvs"`
+	// range read only
+	More   bool   `json:"more,omitempty"`
+	Count  int64  `json:"count,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+type MembersResponse struct {
+	Members []datamodels.MemberStatus `json:"members"`
+}
+
+type BackupsResponse struct {
+	Backups []datamodels.Backup `json:"backups"`
+}
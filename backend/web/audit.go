@@ -0,0 +1,27 @@
+package web
+
+import (
+	"github.com/kataras/iris"
+	"github.com/thxcode/etcd-console/backend/audit"
+)
+
+// AuditOp records a mutating op with its result, the failures of recording never fail the op.
+func AuditOp(irisCtx iris.Context, op string, result interface{}, err error) {
+	auditLog, _ := irisCtx.Values().Get("etcd-console.audit").(*audit.Log)
+	if auditLog == nil {
+		return
+	}
+
+	record := auditLog.NewRecord(irisCtx, op)
+	record.Status = iris.StatusOK
+	if err != nil {
+		record.Status = ErrorStatusCode(err)
+		record.Err = err.Error()
+	} else {
+		record.Result = result
+	}
+
+	if err := auditLog.Write(record); err != nil {
+		irisCtx.Application().Logger().Errorf("cannot write audit log, %v", err)
+	}
+}
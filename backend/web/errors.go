@@ -0,0 +1,141 @@
+// Package web holds the helpers shared by the routes of all API versions.
+package web
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/coreos/etcd/etcdserver/api/v3rpc/rpctypes"
+	"github.com/kataras/iris"
+	"github.com/kataras/iris/hero"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/schema"
+	"github.com/thxcode/etcd-console/backend/v1/services"
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorStatuses maps the codes of the API errors to the statuses of the responses.
+var errorStatuses = map[string]int{
+	backend.ErrCodeBadRequest:           iris.StatusBadRequest,
+	backend.ErrCodeForbidden:            iris.StatusForbidden,
+	backend.ErrCodeNotFound:             iris.StatusNotFound,
+	backend.ErrCodeMethodNotAllowed:     iris.StatusMethodNotAllowed,
+	backend.ErrCodeTimeout:              iris.StatusRequestTimeout,
+	backend.ErrCodeConflict:             iris.StatusConflict,
+	backend.ErrCodeInvalidValue:         iris.StatusUnprocessableEntity,
+	backend.ErrCodeConfirmationRequired: iris.StatusPreconditionRequired,
+	backend.ErrCodeInternal:             iris.StatusInternalServerError,
+	backend.ErrCodeUnsupported:          iris.StatusNotImplemented,
+	backend.ErrCodeUnavailable:          iris.StatusServiceUnavailable,
+}
+
+// ErrorCode classifies a service error, the errors of etcd are classified by their gRPC codes.
+func ErrorCode(err error) string {
+	switch typedErr := err.(type) {
+	case *backend.APIError:
+		return typedErr.Code
+	case *backend.NotReadyError:
+		return backend.ErrCodeUnavailable
+	case *backend.ForbiddenError:
+		return backend.ErrCodeForbidden
+	case *schema.ValidationError:
+		return backend.ErrCodeInvalidValue
+	case *services.DeleteConfirmationError:
+		return backend.ErrCodeConfirmationRequired
+	case rpctypes.EtcdError:
+		switch typedErr {
+		case rpctypes.ErrTimeout, rpctypes.ErrTimeoutDueToLeaderFail, rpctypes.ErrTimeoutDueToConnectionLost:
+			return backend.ErrCodeTimeout
+		}
+		return grpcErrorCode(typedErr.Code())
+	}
+
+	if err == context.DeadlineExceeded || err == context.Canceled {
+		return backend.ErrCodeTimeout
+	}
+	if grpcStatus, ok := status.FromError(err); ok {
+		return grpcErrorCode(grpcStatus.Code())
+	}
+
+	return backend.ErrCodeInternal
+}
+
+func grpcErrorCode(code codes.Code) string {
+	switch code {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return backend.ErrCodeBadRequest
+	case codes.PermissionDenied, codes.Unauthenticated:
+		return backend.ErrCodeForbidden
+	case codes.NotFound:
+		return backend.ErrCodeNotFound
+	case codes.DeadlineExceeded, codes.Canceled:
+		return backend.ErrCodeTimeout
+	case codes.AlreadyExists, codes.FailedPrecondition, codes.Aborted:
+		return backend.ErrCodeConflict
+	case codes.Unimplemented:
+		return backend.ErrCodeUnsupported
+	case codes.Unavailable, codes.ResourceExhausted:
+		return backend.ErrCodeUnavailable
+	}
+
+	return backend.ErrCodeInternal
+}
+
+// ErrorStatusCode maps a service error to the status code of the response.
+func ErrorStatusCode(err error) int {
+	return errorStatuses[ErrorCode(err)]
+}
+
+// ErrorResponse fills the response with the envelope of the error, along with the details of some errors.
+func ErrorResponse(response *hero.Response, err error) {
+	code := ErrorCode(err)
+
+	errorObject := viewmodels.ErrorResponse{
+		Code:    code,
+		Status:  errorStatuses[code],
+		Message: err.Error(),
+	}
+	switch typedErr := err.(type) {
+	case *schema.ValidationError:
+		errorObject.Details = viewmodels.ValidationDetails{
+			Key:        typedErr.Key,
+			Schema:     typedErr.Schema,
+			Violations: typedErr.Violations,
+		}
+	case *services.DeleteConfirmationError:
+		errorObject.Details = viewmodels.ConfirmationDetails{
+			Count: typedErr.Count,
+			Limit: typedErr.Limit,
+			Token: typedErr.Token,
+		}
+	}
+
+	response.Code = errorObject.Status
+	response.Object = errorObject
+}
+
+// MethodNotAllowed answers the methods a route doesn't support, along with the allowed ones.
+func MethodNotAllowed(irisCtx iris.Context, response *hero.Response, allowed ...string) {
+	irisCtx.Header("Allow", strings.Join(allowed, ", "))
+
+	ErrorResponse(response, backend.NewAPIError(backend.ErrCodeMethodNotAllowed,
+		fmt.Sprintf("method %s is not allowed, choose one of %s", irisCtx.Method(), strings.Join(allowed, ", "))))
+}
+
+// OnNotFound answers the unknown paths of the API with the envelope, and the others with the status text.
+func OnNotFound(irisCtx iris.Context) {
+	if !strings.HasPrefix(irisCtx.Path(), "/api/") {
+		irisCtx.WriteString(http.StatusText(iris.StatusNotFound))
+		return
+	}
+
+	irisCtx.JSON(viewmodels.ErrorResponse{
+		Code:    backend.ErrCodeNotFound,
+		Status:  iris.StatusNotFound,
+		Message: fmt.Sprintf("cannot find %s", irisCtx.Path()),
+	})
+}
@@ -0,0 +1,294 @@
+package web
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/kataras/iris"
+	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/v1/services"
+	"github.com/thxcode/etcd-console/backend/v1/web/viewmodels"
+)
+
+// The parsers build the requests of the services from the query, the body and the path params,
+// the bad values of the optional params fall back to their defaults.
+
+// ParseGetRequest parses the read of a key or a range.
+func ParseGetRequest(irisCtx iris.Context) services.GetRequest {
+	return services.GetRequest{
+		KeyRange:    parseKeyRange(irisCtx),
+		Consistency: irisCtx.URLParam("consistency"),
+		Limit:       int64Param(irisCtx, "limit"),
+		Rev:         int64Param(irisCtx, "rev"),
+		KeysOnly:    boolParam(irisCtx, "keysOnly"),
+		SortOrder:   irisCtx.URLParam("sortOrder"),
+		SortTarget:  irisCtx.URLParam("sortTarget"),
+		Cursor:      irisCtx.URLParam("cursor"),
+		Decode:      irisCtx.URLParam("decode"),
+		Format:      irisCtx.URLParam("format"),
+		Timeout:     ParseTimeout(irisCtx),
+	}
+}
+
+// ParseSetRequest parses the write of a key from the body, the key in the path takes precedence.
+func ParseSetRequest(irisCtx iris.Context) (services.SetRequest, error) {
+	req := services.SetRequest{
+		Timeout: ParseTimeout(irisCtx),
+	}
+	if err := irisCtx.ReadJSON(&req.ClientSetRequest); err != nil {
+		return req, backend.NewBadRequestError(fmt.Sprintf("bad request body, %v", err))
+	}
+	if key := irisCtx.Params().Get("key"); len(key) != 0 {
+		req.Key = key
+	}
+	return req, nil
+}
+
+// ParseDelRequest parses the remove of a key or a range, the dry run included.
+func ParseDelRequest(irisCtx iris.Context) services.DelRequest {
+	return services.DelRequest{
+		KeyRange: parseKeyRange(irisCtx),
+		PrevKV:   boolParam(irisCtx, "prevKV"),
+		Confirm:  irisCtx.URLParam("confirm"),
+		Sample:   int64Param(irisCtx, "sample"),
+		Timeout:  ParseTimeout(irisCtx),
+	}
+}
+
+func ParseTreeRequest(irisCtx iris.Context) services.TreeRequest {
+	return services.TreeRequest{
+		Key:       irisCtx.URLParamEscape("key"),
+		Delimiter: irisCtx.URLParam("delimiter"),
+		Rev:       int64Param(irisCtx, "rev"),
+		Limit:     int64Param(irisCtx, "limit"),
+		PageSize:  int64Param(irisCtx, "pageSize"),
+		Timeout:   ParseTimeout(irisCtx),
+	}
+}
+
+func ParseExportRequest(irisCtx iris.Context) services.ExportRequest {
+	return services.ExportRequest{
+		KeyRange:  parseKeyRange(irisCtx),
+		Format:    irisCtx.URLParam("format"),
+		Metadata:  boolParam(irisCtx, "metadata"),
+		Delimiter: irisCtx.URLParam("delimiter"),
+		Rev:       int64Param(irisCtx, "rev"),
+		PageSize:  int64Param(irisCtx, "pageSize"),
+		Timeout:   ParseTimeout(irisCtx),
+	}
+}
+
+// ParseImportRequest reads the file from the "file" field of a multipart form, or else from the body.
+func ParseImportRequest(irisCtx iris.Context) (services.ImportRequest, error) {
+	req := services.ImportRequest{
+		Key:       irisCtx.URLParamEscape("key"),
+		Format:    irisCtx.URLParam("format"),
+		Delimiter: irisCtx.URLParam("delimiter"),
+		DryRun:    boolParam(irisCtx, "dryRun"),
+		Prune:     boolParam(irisCtx, "prune"),
+		MaxTxnOps: int64Param(irisCtx, "maxTxnOps"),
+		Timeout:   ParseTimeout(irisCtx),
+	}
+
+	var reader io.Reader
+	if file, header, err := irisCtx.FormFile("file"); err == nil {
+		defer file.Close()
+
+		reader = file
+		req.Name = header.Filename
+	} else {
+		reader = irisCtx.Request().Body
+	}
+
+	// the service refuses the files over the max size
+	data, err := ioutil.ReadAll(io.LimitReader(reader, services.ImportMaxSize+1))
+	if err != nil {
+		return req, err
+	}
+	req.Data = data
+
+	return req, nil
+}
+
+func ParseCopyRequest(irisCtx iris.Context) (services.CopyRequest, error) {
+	req := services.CopyRequest{
+		Timeout: ParseTimeout(irisCtx),
+	}
+	if err := irisCtx.ReadJSON(&req.ClientCopyRequest); err != nil {
+		return req, backend.NewBadRequestError(fmt.Sprintf("bad request body, %v", err))
+	}
+	return req, nil
+}
+
+func ParseHistoryRequest(irisCtx iris.Context) services.HistoryRequest {
+	return services.HistoryRequest{
+		Key:      irisCtx.URLParamEscape("key"),
+		Encoding: irisCtx.URLParam("encoding"),
+		Rev:      int64Param(irisCtx, "rev"),
+		Limit:    int64Param(irisCtx, "limit"),
+		Timeout:  ParseTimeout(irisCtx),
+	}
+}
+
+func ParseDiffRequest(irisCtx iris.Context) services.DiffRequest {
+	return services.DiffRequest{
+		Key:      irisCtx.URLParamEscape("key"),
+		Encoding: irisCtx.URLParam("encoding"),
+		Prefix:   boolParam(irisCtx, "prefix"),
+		From:     int64Param(irisCtx, "from"),
+		To:       int64Param(irisCtx, "to"),
+		Values:   boolParam(irisCtx, "values"),
+		Limit:    int64Param(irisCtx, "limit"),
+		Cursor:   irisCtx.URLParam("cursor"),
+		Timeout:  ParseTimeout(irisCtx),
+	}
+}
+
+func ParseJournalRequest(irisCtx iris.Context) services.JournalRequest {
+	return services.JournalRequest{
+		Limit:    int(int64Param(irisCtx, "limit")),
+		Encoding: irisCtx.URLParam("encoding"),
+	}
+}
+
+func ParseUndoRequest(irisCtx iris.Context) services.UndoRequest {
+	return services.UndoRequest{
+		ID:       int64Param(irisCtx, "id"),
+		Encoding: irisCtx.URLParam("encoding"),
+		Timeout:  ParseTimeout(irisCtx),
+	}
+}
+
+func ParseLeaseGrantRequest(irisCtx iris.Context) (services.LeaseGrantRequest, error) {
+	req := services.LeaseGrantRequest{
+		Timeout: ParseTimeout(irisCtx),
+	}
+	if err := irisCtx.ReadJSON(&req.LeaseGrantRequest); err != nil {
+		return req, backend.NewBadRequestError(fmt.Sprintf("bad request body, %v", err))
+	}
+	return req, nil
+}
+
+// ParseLeaseRequest parses the get or the revoke of the lease, the ID in the path takes precedence.
+func ParseLeaseRequest(irisCtx iris.Context) services.LeaseRequest {
+	return services.LeaseRequest{
+		ID:       resourceParam(irisCtx, "id"),
+		Keys:     boolParam(irisCtx, "keys"),
+		Encoding: irisCtx.URLParam("encoding"),
+		Timeout:  ParseTimeout(irisCtx),
+	}
+}
+
+func ParseAuditRequest(irisCtx iris.Context) services.AuditRequest {
+	return services.AuditRequest{
+		Op:         irisCtx.URLParam("op"),
+		User:       irisCtx.URLParam("user"),
+		RemoteAddr: irisCtx.URLParam("remoteAddr"),
+		Key:        irisCtx.URLParam("key"),
+		Since:      irisCtx.URLParam("since"),
+		Until:      irisCtx.URLParam("until"),
+		Limit:      int(int64Param(irisCtx, "limit")),
+	}
+}
+
+func ParseMirrorStartRequest(irisCtx iris.Context) (viewmodels.MirrorStartRequest, error) {
+	req := viewmodels.MirrorStartRequest{}
+	if err := irisCtx.ReadJSON(&req); err != nil {
+		return req, backend.NewBadRequestError(fmt.Sprintf("bad request body, %v", err))
+	}
+	return req, nil
+}
+
+// ParseBackupName takes the name of the backup, the name in the path takes precedence.
+func ParseBackupName(irisCtx iris.Context) string {
+	return resourceParam(irisCtx, "name")
+}
+
+// JournalHeader tells the journal entry of a write or a remove, which can be undone by it.
+func JournalHeader(irisCtx iris.Context, id int64) {
+	if id != 0 {
+		irisCtx.Header("X-Journal-Entry", fmt.Sprintf("%d", id))
+	}
+}
+
+// NewExportWriter streams the export to the response, the headers are sent with the first content,
+// so a failure ahead of it is still answered as an error.
+func NewExportWriter(irisCtx iris.Context) services.ExportWriter {
+	return &exportWriter{
+		irisCtx: irisCtx,
+	}
+}
+
+type exportWriter struct {
+	irisCtx     iris.Context
+	contentType string
+	fileName    string
+	rev         int64
+	began       bool
+}
+
+func (e *exportWriter) Begin(contentType string, fileName string, rev int64) {
+	e.contentType = contentType
+	e.fileName = fileName
+	e.rev = rev
+}
+
+func (e *exportWriter) Write(p []byte) (int, error) {
+	if !e.began {
+		e.began = true
+
+		e.irisCtx.ContentType(e.contentType)
+		e.irisCtx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, e.fileName))
+		e.irisCtx.Header("X-Etcd-Revision", fmt.Sprintf("%d", e.rev))
+	}
+	return e.irisCtx.ResponseWriter().Write(p)
+}
+
+func (e *exportWriter) Flush() {
+	e.irisCtx.ResponseWriter().Flush()
+}
+
+func parseKeyRange(irisCtx iris.Context) services.KeyRange {
+	return services.KeyRange{
+		Key:      resourceParam(irisCtx, "key"),
+		Prefix:   boolParam(irisCtx, "prefix"),
+		FromKey:  boolParam(irisCtx, "fromKey"),
+		Range:    irisCtx.URLParam("range"),
+		Encoding: irisCtx.URLParam("encoding"),
+	}
+}
+
+// resourceParam takes the param from the path of the resource routes, or else from the query.
+func resourceParam(irisCtx iris.Context, name string) string {
+	if value := irisCtx.Params().Get(name); len(value) != 0 {
+		return value
+	}
+	return irisCtx.URLParamEscape(name)
+}
+
+func boolParam(irisCtx iris.Context, name string) bool {
+	value, err := irisCtx.URLParamBool(name)
+	if err != nil {
+		return false
+	}
+	return value
+}
+
+func int64Param(irisCtx iris.Context, name string) int64 {
+	value, err := irisCtx.URLParamInt64Default(name, 0)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// ParseTimeout takes the "timeout" in seconds, zero means the default of the op.
+func ParseTimeout(irisCtx iris.Context) time.Duration {
+	timeout := int64Param(irisCtx, "timeout")
+	if timeout <= 0 {
+		return 0
+	}
+	return time.Duration(timeout) * time.Second
+}
@@ -0,0 +1,190 @@
+// Package cli is the command line client of a running console, it talks to the console over the HTTP API,
+// so etcd is reachable through the console only.
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/thxcode/etcd-console/client"
+)
+
+const defaultConsole = "http://127.0.0.1:8080"
+
+// command is a subcommand of the etcd-console binary.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(ctx context.Context, o *options, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "get", usage: "get [flags] <key>", summary: "Read a key, a prefix or a range.", run: runGet},
+		{name: "put", usage: "put [flags] <key> [value]", summary: "Write a key, the value is read from stdin if absent.", run: runPut},
+		{name: "del", usage: "del [flags] <key>", summary: "Remove a key, a prefix or a range.", run: runDel},
+		{name: "status", usage: "status [flags]", summary: "Show the statuses of the members.", run: runStatus},
+		{name: "backup", usage: "backup create|list|download|delete [flags] [name]", summary: "Manage the backups of the cluster.", run: runBackup},
+		{name: "help", usage: "help", summary: "Show this help.", run: runHelp},
+	}
+}
+
+// IsCommand returns true if the name is a subcommand of the client, otherwise the binary runs the console.
+func IsCommand(name string) bool {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Run runs the subcommand of the args, e.g. ["get", "/registry", "--prefix"], and returns the exit code.
+func Run(args []string) int {
+	if len(args) == 0 || !IsCommand(args[0]) {
+		printUsage(os.Stderr)
+		return 2
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+
+		o := &options{command: cmd, stdout: os.Stdout}
+		err := cmd.run(ctx, o, args[1:])
+		if err == nil || err == flag.ErrHelp {
+			return 0
+		}
+		if _, ok := err.(usageError); ok {
+			fmt.Fprintf(os.Stderr, "Error: %v\nUsage: etcd-console %s\n", err, cmd.usage)
+			return 2
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 2
+}
+
+func runHelp(ctx context.Context, o *options, args []string) error {
+	printUsage(os.Stdout)
+	return nil
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: etcd-console <command> [flags] [args], or etcd-console [flags] to run the console.")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nRun etcd-console <command> -h for the flags of a command.")
+}
+
+// usageError is a bad invocation of a command, it's reported along with the usage.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// options are the flags shared by all commands.
+type options struct {
+	command command
+	// where the responses are written, stdout out of the tests
+	stdout  io.Writer
+	console string
+	output  string
+	timeout int64
+}
+
+// flagSet creates the flags of the command along with the shared ones.
+func (o *options) flagSet() *flag.FlagSet {
+	console := os.Getenv("ETCD_CONSOLE_ADDRESS")
+	if len(console) == 0 {
+		console = defaultConsole
+	}
+
+	fs := flag.NewFlagSet(o.command.name, flag.ContinueOnError)
+	fs.StringVar(&o.console, "console", console, "The address of the console, or $ETCD_CONSOLE_ADDRESS.")
+	fs.StringVar(&o.output, "output", "table", "The output format, table, json or yaml.")
+	fs.StringVar(&o.output, "o", "table", "Shorthand of --output.")
+	fs.Int64Var(&o.timeout, "timeout", 0, "How long the console waits for etcd in seconds, 0 means the default of the console.")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: etcd-console %s\n\n%s\n\nFlags:\n", o.command.usage, o.command.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses the flags, which may come after the args, and returns the args.
+func (o *options) parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var retArgs []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				return nil, err
+			}
+			return nil, usageError(err.Error())
+		}
+
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			retArgs = append(retArgs, rest...)
+			break
+		}
+		if len(rest) == 0 {
+			break
+		}
+		retArgs = append(retArgs, rest[0])
+		args = rest[1:]
+	}
+
+	switch o.output {
+	case "table", "json", "yaml":
+	default:
+		return nil, usageError(fmt.Sprintf("bad output %q, expecting table, json or yaml", o.output))
+	}
+	return retArgs, nil
+}
+
+// client returns the API client of the console.
+func (o *options) client() *client.Client {
+	console := strings.TrimSuffix(o.console, "/")
+	if !strings.Contains(console, "://") {
+		console = "http://" + console
+	}
+	return client.NewClient(console+"/api/v1", nil)
+}
+
+// expectArgs checks the count of the args is between the min and the max.
+func expectArgs(args []string, min, max int) error {
+	if len(args) < min {
+		return usageError("missing arguments")
+	}
+	if len(args) > max {
+		return usageError(fmt.Sprintf("unexpected arguments %s", strings.Join(args[max:], " ")))
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestOptionsParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		prefix  bool
+		output  string
+		wantErr bool
+	}{
+		{
+			name:   "flags before the args",
+			args:   []string{"--prefix", "-o", "json", "/a"},
+			want:   []string{"/a"},
+			prefix: true,
+			output: "json",
+		},
+		{
+			name:   "flags after the args",
+			args:   []string{"/a", "--prefix", "/b", "--output", "yaml"},
+			want:   []string{"/a", "/b"},
+			prefix: true,
+			output: "yaml",
+		},
+		{
+			name:   "args after --",
+			args:   []string{"/a", "--", "--prefix", "-o"},
+			want:   []string{"/a", "--prefix", "-o"},
+			output: "table",
+		},
+		{
+			name:   "-- at the end",
+			args:   []string{"--prefix", "/a", "--"},
+			want:   []string{"/a"},
+			prefix: true,
+			output: "table",
+		},
+		{
+			name:   "no args",
+			args:   nil,
+			output: "table",
+		},
+		{
+			name:    "bad output",
+			args:    []string{"/a", "-o", "xml"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"/a", "--unknown"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &options{command: command{name: "get"}}
+			fs := o.flagSet()
+			var prefix bool
+			fs.BoolVar(&prefix, "prefix", false, "")
+
+			got, err := o.parse(fs, tt.args)
+			if tt.wantErr {
+				if _, ok := err.(usageError); !ok {
+					t.Errorf("parse(%q) error = %v, want a usage error", tt.args, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse(%q) error = %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+			if prefix != tt.prefix || o.output != tt.output {
+				t.Errorf("parse(%q) prefix, output = %v, %q, want %v, %q", tt.args, prefix, o.output, tt.prefix, tt.output)
+			}
+		})
+	}
+
+	o := &options{command: command{name: "get"}}
+	if _, err := o.parse(o.flagSet(), []string{"/a", "-h"}); err != flag.ErrHelp {
+		t.Errorf("parse(-h) error = %v, want %v", err, flag.ErrHelp)
+	}
+}
+
+func TestExpectArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		min, max int
+		wantErr  bool
+	}{
+		{args: nil, min: 0, max: 0},
+		{args: []string{"/a"}, min: 1, max: 1},
+		{args: []string{"/a"}, min: 1, max: 2},
+		{args: []string{"/a", "v"}, min: 1, max: 2},
+		{args: nil, min: 1, max: 1, wantErr: true},
+		{args: []string{"/a", "/b"}, min: 1, max: 1, wantErr: true},
+		{args: []string{"/a"}, min: 0, max: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := expectArgs(tt.args, tt.min, tt.max)
+		if _, ok := err.(usageError); ok != tt.wantErr {
+			t.Errorf("expectArgs(%q, %d, %d) error = %v, want error %v", tt.args, tt.min, tt.max, err, tt.wantErr)
+		}
+	}
+}
+
+func TestIsCommand(t *testing.T) {
+	for _, name := range []string{"get", "put", "del", "status", "backup", "help"} {
+		if !IsCommand(name) {
+			t.Errorf("IsCommand(%s) = false, want true", name)
+		}
+	}
+	if IsCommand("-advertise") {
+		t.Errorf("IsCommand(-advertise) = true, the flags of the console are not commands")
+	}
+}
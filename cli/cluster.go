@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/thxcode/etcd-console/client"
+)
+
+func runStatus(ctx context.Context, o *options, args []string) error {
+	args, err := o.parse(o.flagSet(), args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(args, 0, 0); err != nil {
+		return err
+	}
+
+	resp, err := o.client().GetMemberStatuses(ctx, &client.GetMemberStatusesParams{Timeout: o.timeout})
+	if err != nil {
+		return err
+	}
+	return o.print(resp, func(w io.Writer) {
+		row(w, "ID", "NAME", "ENDPOINT", "LEADER", "HEALTH", "DB_SIZE", "VERSION")
+		for _, member := range resp.Members {
+			row(w, member.ID, member.Name, member.Endpoint, member.Leader, member.Health, humanSize(member.DBSize), member.Version)
+		}
+	})
+}
+
+func runBackup(ctx context.Context, o *options, args []string) error {
+	if len(args) == 0 {
+		return usageError("missing the action, expecting create, list, download or delete")
+	}
+	action, args := args[0], args[1:]
+
+	var file string
+	fs := o.flagSet()
+	if action == "download" {
+		fs.StringVar(&file, "file", "", "Where is writing the backup, - means stdout. Defaults to the name of the backup.")
+	}
+	args, err := o.parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	c := o.client()
+	switch action {
+	case "create":
+		if err := expectArgs(args, 0, 0); err != nil {
+			return err
+		}
+		resp, err := c.CreateBackup(ctx, &client.CreateBackupParams{Timeout: o.timeout})
+		if err != nil {
+			return err
+		}
+		return o.print(resp, func(w io.Writer) {
+			printBackups(w, resp.Backups)
+		})
+	case "list":
+		if err := expectArgs(args, 0, 0); err != nil {
+			return err
+		}
+		resp, err := c.GetBackups(ctx, &client.GetBackupsParams{Timeout: o.timeout})
+		if err != nil {
+			return err
+		}
+		return o.print(resp, func(w io.Writer) {
+			printBackups(w, resp.Backups)
+		})
+	case "download":
+		if err := expectArgs(args, 1, 1); err != nil {
+			return err
+		}
+		return o.downloadBackup(ctx, c, args[0], file)
+	case "delete":
+		if err := expectArgs(args, 1, 1); err != nil {
+			return err
+		}
+		if err := c.RemoveBackup(ctx, &client.RemoveBackupParams{Name: args[0]}); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "OK")
+		return nil
+	default:
+		return usageError(fmt.Sprintf("bad action %q, expecting create, list, download or delete", action))
+	}
+}
+
+func (o *options) downloadBackup(ctx context.Context, c *client.Client, name string, file string) error {
+	content, err := c.DownloadBackup(ctx, &client.GetBackupsParams{Name: name, Timeout: o.timeout})
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	if file == "-" {
+		_, err = io.Copy(o.stdout, content)
+		return err
+	}
+	if len(file) == 0 {
+		file = filepath.Base(name)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, content); err != nil {
+		f.Close()
+		os.Remove(file)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Downloaded to %s\n", file)
+	return nil
+}
+
+func printBackups(w io.Writer, backups []client.Backup) {
+	row(w, "NAME", "SIZE", "CREATE_TIME")
+	for _, backup := range backups {
+		row(w, backup.Name, humanSize(backup.Size), backup.CreateTime)
+	}
+}
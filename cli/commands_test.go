@@ -0,0 +1,335 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thxcode/etcd-console/client"
+	"gopkg.in/yaml.v2"
+)
+
+// fakeConsole answers the API of the console by the canned responses of "METHOD path",
+// and records the queries and the bodies of the requests.
+type fakeConsole struct {
+	*httptest.Server
+	responses map[string]interface{}
+	queries   map[string]url.Values
+	bodies    map[string][]byte
+}
+
+func startFakeConsole(t *testing.T, responses map[string]interface{}) *fakeConsole {
+	c := &fakeConsole{
+		responses: responses,
+		queries:   map[string]url.Values{},
+		bodies:    map[string][]byte{},
+	}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op := r.Method + " " + strings.TrimPrefix(r.URL.Path, "/api/v1")
+		c.queries[op] = r.URL.Query()
+		c.bodies[op], _ = ioutil.ReadAll(r.Body)
+
+		response, ok := c.responses[op]
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(client.ErrorResponse{Code: "NotFound", Status: http.StatusNotFound, Message: "cannot find " + op})
+			return
+		}
+		switch typed := response.(type) {
+		case []byte:
+			w.Write(typed)
+		case client.ErrorResponse:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(int(typed.Status))
+			json.NewEncoder(w).Encode(typed)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(typed)
+		}
+	}))
+	return c
+}
+
+// runCommand runs the command against the console, and returns what it writes to stdout.
+func runCommand(t *testing.T, c *fakeConsole, args ...string) (string, error) {
+	t.Helper()
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+
+		var stdout bytes.Buffer
+		o := &options{command: cmd, stdout: &stdout}
+		err := cmd.run(context.Background(), o, append(args[1:], "--console", c.URL))
+		return stdout.String(), err
+	}
+	t.Fatalf("unknown command %s", args[0])
+	return "", nil
+}
+
+func TestGetCommand(t *testing.T) {
+	c := startFakeConsole(t, map[string]interface{}{
+		"GET /client/read": client.ClientResponse{
+			KVS: []client.KeyValue{
+				{Key: "/a/1", Value: "v1", CreateRevision: 2, ModRevision: 3, Version: 2},
+				{Key: "/a/2", Value: "line\nbreak", CreateRevision: 4, ModRevision: 4, Version: 1},
+			},
+			Count: 2,
+		},
+	})
+	defer c.Close()
+
+	out, err := runCommand(t, c, "get", "/a/", "--prefix", "--limit", "10")
+	if err != nil {
+		t.Fatalf("get error = %v", err)
+	}
+	query := c.queries["GET /client/read"]
+	if query.Get("key") != "/a/" || query.Get("prefix") != "true" || query.Get("limit") != "10" {
+		t.Errorf("get query = %v, want the key /a/ as a prefix of 10 keys", query)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "KEY") || !strings.Contains(lines[2], `line\nbreak`) {
+		t.Errorf("get table = %q, want the header and a row per key, the line breaks escaped", out)
+	}
+	if !strings.Contains(lines[1], "-") {
+		t.Errorf("get table row = %q, want - for the empty lease", lines[1])
+	}
+
+	out, err = runCommand(t, c, "get", "/a/", "--prefix", "-o", "json")
+	if err != nil {
+		t.Fatalf("get -o json error = %v", err)
+	}
+	var jsonResp client.ClientResponse
+	if err := json.Unmarshal([]byte(out), &jsonResp); err != nil || len(jsonResp.KVS) != 2 || jsonResp.Count != 2 {
+		t.Errorf("get -o json = %q, %v, want the response", out, err)
+	}
+
+	out, err = runCommand(t, c, "get", "/a/", "--prefix", "-o", "yaml")
+	if err != nil {
+		t.Fatalf("get -o yaml error = %v", err)
+	}
+	var yamlResp struct {
+		KVS []struct {
+			Key            string `yaml:"key"`
+			CreateRevision int64  `yaml:"createRevision"`
+		} `yaml:"kvs"`
+	}
+	if err := yaml.Unmarshal([]byte(out), &yamlResp); err != nil || len(yamlResp.KVS) != 2 || yamlResp.KVS[0].CreateRevision != 2 {
+		t.Errorf("get -o yaml = %q, %v, want the fields named as the json ones", out, err)
+	}
+
+	if _, err := runCommand(t, c, "get"); err == nil {
+		t.Errorf("get without the key error = nil, want a usage error")
+	}
+	if _, err := runCommand(t, c, "get", "/a", "/b"); err == nil {
+		t.Errorf("get of two keys error = nil, want a usage error")
+	}
+}
+
+func TestPutCommand(t *testing.T) {
+	c := startFakeConsole(t, map[string]interface{}{
+		"POST /client/write": client.ClientResponse{},
+	})
+	defer c.Close()
+
+	out, err := runCommand(t, c, "put", "/a", "v", "--lease", "1f")
+	if err != nil {
+		t.Fatalf("put error = %v", err)
+	}
+	var body client.ClientSetRequest
+	if err := json.Unmarshal(c.bodies["POST /client/write"], &body); err != nil || body.Key != "/a" || body.Value != "v" || body.Lease != "1f" {
+		t.Errorf("put body = %q, %v, want /a=v on the lease 1f", c.bodies["POST /client/write"], err)
+	}
+	if strings.TrimSpace(out) != "OK" {
+		t.Errorf("put table = %q, want OK", out)
+	}
+
+	// the previous key value is answered
+	c.responses["POST /client/write"] = client.ClientResponse{KVS: []client.KeyValue{{Key: "/a", Value: "old"}}}
+	out, err = runCommand(t, c, "put", "/a", "v", "--prev-kv")
+	if err != nil {
+		t.Fatalf("put --prev-kv error = %v", err)
+	}
+	if !strings.Contains(out, "old") {
+		t.Errorf("put --prev-kv table = %q, want the previous value", out)
+	}
+
+	out, err = runCommand(t, c, "put", "/a", "v", "-o", "json")
+	if err != nil {
+		t.Fatalf("put -o json error = %v", err)
+	}
+	var jsonResp client.ClientResponse
+	if err := json.Unmarshal([]byte(out), &jsonResp); err != nil || len(jsonResp.KVS) != 1 {
+		t.Errorf("put -o json = %q, %v, want the response", out, err)
+	}
+
+	if _, err := runCommand(t, c, "put", "/a", "v", "extra"); err == nil {
+		t.Errorf("put of three args error = nil, want a usage error")
+	}
+}
+
+func TestDelCommand(t *testing.T) {
+	c := startFakeConsole(t, map[string]interface{}{
+		"DELETE /client/remove": client.ClientRemoveResponse{KVS: []client.KeyValue{{Key: "/a/1", Value: "v1"}}},
+	})
+	defer c.Close()
+
+	out, err := runCommand(t, c, "del", "/a/1", "--prev-kv")
+	if err != nil {
+		t.Fatalf("del error = %v", err)
+	}
+	if query := c.queries["DELETE /client/remove"]; query.Get("key") != "/a/1" || query.Get("prevKV") != "true" {
+		t.Errorf("del query = %v, want the key /a/1 with the previous key value", query)
+	}
+	if !strings.Contains(out, "/a/1") || !strings.Contains(out, "v1") {
+		t.Errorf("del table = %q, want the removed key value", out)
+	}
+
+	// the dry run shows the count and the token
+	c.responses["DELETE /client/remove"] = client.ClientRemoveResponse{
+		Revision: 5,
+		Count:    3,
+		Limit:    2,
+		Token:    "token",
+		Keys:     []client.KeyValue{{Key: "/a/1"}},
+	}
+	out, err = runCommand(t, c, "del", "/a/", "--prefix", "--dry-run", "--sample", "1")
+	if err != nil {
+		t.Fatalf("del --dry-run error = %v", err)
+	}
+	if query := c.queries["DELETE /client/remove"]; query.Get("dryRun") != "true" || query.Get("sample") != "1" {
+		t.Errorf("del --dry-run query = %v, want the dry run of a sample", query)
+	}
+	if !strings.Contains(out, "COUNT") || !strings.Contains(out, "--confirm token") {
+		t.Errorf("del --dry-run table = %q, want the count and the token", out)
+	}
+
+	out, err = runCommand(t, c, "del", "/a/", "--prefix", "--dry-run", "-o", "yaml")
+	if err != nil {
+		t.Fatalf("del --dry-run -o yaml error = %v", err)
+	}
+	if !strings.Contains(out, "token: token") || !strings.Contains(out, "count: 3") {
+		t.Errorf("del --dry-run -o yaml = %q, want the count and the token", out)
+	}
+
+	// the remove over the delete limit tells how to confirm it
+	c.responses["DELETE /client/remove"] = client.ErrorResponse{
+		Code:    "ConfirmationRequired",
+		Status:  http.StatusPreconditionRequired,
+		Message: "3 keys are over the delete limit 2",
+		Details: client.ConfirmationDetails{Count: 3, Limit: 2, Token: "token"},
+	}
+	if _, err := runCommand(t, c, "del", "/a/", "--prefix"); err == nil || !strings.Contains(err.Error(), "--confirm token") {
+		t.Errorf("del over the limit error = %v, want the hint of --confirm", err)
+	}
+}
+
+func TestStatusCommand(t *testing.T) {
+	c := startFakeConsole(t, map[string]interface{}{
+		"GET /cluster/status": client.ClusterMemberStatusResponse{
+			Members: []client.MemberStatus{
+				{ID: "8e9e05c52164694d", Name: "default", Endpoint: "http://127.0.0.1:2379", Leader: true, Health: true, DBSize: 2048, Version: "3.2.13"},
+			},
+		},
+	})
+	defer c.Close()
+
+	out, err := runCommand(t, c, "status", "--timeout", "3")
+	if err != nil {
+		t.Fatalf("status error = %v", err)
+	}
+	if query := c.queries["GET /cluster/status"]; query.Get("timeout") != "3" {
+		t.Errorf("status query = %v, want the timeout", query)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], "2.0 KiB") || !strings.Contains(lines[1], "true") {
+		t.Errorf("status table = %q, want the leader of 2.0 KiB", out)
+	}
+
+	out, err = runCommand(t, c, "status", "-o", "json")
+	if err != nil {
+		t.Fatalf("status -o json error = %v", err)
+	}
+	var jsonResp client.ClusterMemberStatusResponse
+	if err := json.Unmarshal([]byte(out), &jsonResp); err != nil || len(jsonResp.Members) != 1 || jsonResp.Members[0].DBSize != 2048 {
+		t.Errorf("status -o json = %q, %v, want the response", out, err)
+	}
+
+	if _, err := runCommand(t, c, "status", "extra"); err == nil {
+		t.Errorf("status with an arg error = nil, want a usage error")
+	}
+}
+
+func TestBackupCommand(t *testing.T) {
+	backups := client.ClusterBackupResponse{
+		Backups: []client.Backup{{Name: "etcd-backup-1.zip", Size: 100, CreateTime: "2018-01-01T00:00:00Z"}},
+	}
+	c := startFakeConsole(t, map[string]interface{}{
+		"GET /cluster/backup":    backups,
+		"POST /cluster/backup":   backups,
+		"DELETE /cluster/backup": []byte{},
+	})
+	defer c.Close()
+
+	out, err := runCommand(t, c, "backup", "list")
+	if err != nil {
+		t.Fatalf("backup list error = %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 || !strings.Contains(lines[1], "100 B") {
+		t.Errorf("backup list table = %q, want a row of 100 B", out)
+	}
+
+	out, err = runCommand(t, c, "backup", "create", "-o", "yaml")
+	if err != nil {
+		t.Fatalf("backup create error = %v", err)
+	}
+	if !strings.Contains(out, "name: etcd-backup-1.zip") {
+		t.Errorf("backup create -o yaml = %q, want the backup", out)
+	}
+
+	if _, err := runCommand(t, c, "backup", "delete", "etcd-backup-1.zip"); err != nil {
+		t.Fatalf("backup delete error = %v", err)
+	}
+	if query := c.queries["DELETE /cluster/backup"]; query.Get("name") != "etcd-backup-1.zip" {
+		t.Errorf("backup delete query = %v, want the name", query)
+	}
+
+	// the download is written to stdout by -, or else to the file
+	c.responses["GET /cluster/backup"] = []byte("zip content")
+	out, err = runCommand(t, c, "backup", "download", "etcd-backup-1.zip", "--file", "-")
+	if err != nil {
+		t.Fatalf("backup download error = %v", err)
+	}
+	if out != "zip content" {
+		t.Errorf("backup download to stdout = %q, want the content", out)
+	}
+
+	dir, err := ioutil.TempDir("", "etcd-console-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "backup.zip")
+	if _, err := runCommand(t, c, "backup", "download", "etcd-backup-1.zip", "--file", file); err != nil {
+		t.Fatalf("backup download to the file error = %v", err)
+	}
+	if data, err := ioutil.ReadFile(file); err != nil || string(data) != "zip content" {
+		t.Errorf("backup download to the file = %q, %v, want the content", data, err)
+	}
+
+	for _, args := range [][]string{{"backup"}, {"backup", "unknown"}, {"backup", "delete"}, {"backup", "list", "extra"}} {
+		if _, err := runCommand(t, c, args...); err == nil {
+			t.Errorf("%q error = nil, want a usage error", args)
+		}
+	}
+}
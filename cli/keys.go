@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/thxcode/etcd-console/client"
+)
+
+func runGet(ctx context.Context, o *options, args []string) error {
+	params := &client.ReadKeysParams{}
+	fs := o.flagSet()
+	fs.BoolVar(&params.Prefix, "prefix", false, "Take the key as a prefix.")
+	fs.BoolVar(&params.FromKey, "from-key", false, "Range from the key to the end.")
+	fs.StringVar(&params.Range, "range", "", "The end of the range, exclusive.")
+	fs.Int64Var(&params.Limit, "limit", 0, "The maximum number of keys, 0 means the default of the console.")
+	fs.Int64Var(&params.Rev, "rev", 0, "Read at the revision, 0 means the latest.")
+	fs.BoolVar(&params.KeysOnly, "keys-only", false, "Read the keys without the values.")
+	fs.StringVar(&params.Cursor, "cursor", "", "Continue from the cursor of the previous page.")
+	fs.StringVar(&params.Encoding, "encoding", "", "How the keys and the values are encoded, utf8, base64 or hex.")
+	args, err := o.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(args, 1, 1); err != nil {
+		return err
+	}
+	params.Key = args[0]
+	params.Timeout = o.timeout
+
+	resp, err := o.client().ReadKeys(ctx, params)
+	if err != nil {
+		return err
+	}
+	if resp.More {
+		fmt.Fprintf(os.Stderr, "There are more keys, continue with --cursor %s\n", resp.Cursor)
+	}
+	return o.print(resp, func(w io.Writer) {
+		printKeyValues(w, resp.KVS, !params.KeysOnly)
+	})
+}
+
+func runPut(ctx context.Context, o *options, args []string) error {
+	body := &client.ClientSetRequest{}
+	fs := o.flagSet()
+	fs.StringVar(&body.Encoding, "encoding", "", "How the key and the value are encoded, utf8, base64 or hex.")
+	fs.StringVar(&body.Lease, "lease", "", "Attach the key to the lease, ID in hex.")
+	fs.BoolVar(&body.Raw, "raw", false, "Write the value as it is, skipping the codec mapped by the key prefix.")
+	fs.BoolVar(&body.PrevKV, "prev-kv", false, "Return the previous key value.")
+	fs.BoolVar(&body.IgnoreValue, "ignore-value", false, "Keep the current value, updating the lease only.")
+	fs.BoolVar(&body.IgnoreLease, "ignore-lease", false, "Keep the current lease, updating the value only.")
+	args, err := o.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(args, 1, 2); err != nil {
+		return err
+	}
+	body.Key = args[0]
+	if len(args) == 2 {
+		body.Value = args[1]
+	} else if !body.IgnoreValue {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("cannot read the value from stdin, %v", err)
+		}
+		body.Value = string(data)
+	}
+
+	resp, err := o.client().WriteKey(ctx, body, &client.WriteKeyParams{Timeout: o.timeout})
+	if err != nil {
+		return err
+	}
+	return o.print(resp, func(w io.Writer) {
+		if len(resp.KVS) == 0 {
+			fmt.Fprintln(w, "OK")
+			return
+		}
+		printKeyValues(w, resp.KVS, true)
+	})
+}
+
+func runDel(ctx context.Context, o *options, args []string) error {
+	params := &client.RemoveKeysParams{}
+	fs := o.flagSet()
+	fs.BoolVar(&params.Prefix, "prefix", false, "Take the key as a prefix.")
+	fs.BoolVar(&params.FromKey, "from-key", false, "Range from the key to the end.")
+	fs.StringVar(&params.Range, "range", "", "The end of the range, exclusive.")
+	fs.StringVar(&params.Encoding, "encoding", "", "How the keys and the values are encoded, utf8, base64 or hex.")
+	fs.BoolVar(&params.PrevKV, "prev-kv", false, "Return the removed key values.")
+	fs.StringVar(&params.Confirm, "confirm", "", "The token confirming a remove over the delete limit.")
+	fs.BoolVar(&params.DryRun, "dry-run", false, "Count the keys without removing them.")
+	fs.Int64Var(&params.Sample, "sample", 0, "Dry run only, the number of keys to show.")
+	args, err := o.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := expectArgs(args, 1, 1); err != nil {
+		return err
+	}
+	params.Key = args[0]
+	params.Timeout = o.timeout
+
+	resp, err := o.client().RemoveKeys(ctx, params)
+	if err != nil {
+		if errorResponse, ok := err.(*client.ErrorResponse); ok && errorResponse.Code == "ConfirmationRequired" {
+			if details, ok := errorResponse.Details.(map[string]interface{}); ok {
+				return fmt.Errorf("%v\nRun it again with --confirm %v to go on", err, details["token"])
+			}
+		}
+		return err
+	}
+	return o.print(resp, func(w io.Writer) {
+		if params.DryRun {
+			row(w, "COUNT", "LIMIT", "REVISION")
+			row(w, resp.Count, resp.Limit, resp.Revision)
+			if len(resp.Keys) != 0 {
+				fmt.Fprintln(w)
+				printKeyValues(w, resp.Keys, false)
+			}
+			if len(resp.Token) != 0 {
+				fmt.Fprintf(w, "\nRemove them with --confirm %s\n", resp.Token)
+			}
+			return
+		}
+		if len(resp.KVS) == 0 {
+			fmt.Fprintln(w, "OK")
+			return
+		}
+		printKeyValues(w, resp.KVS, true)
+	})
+}
+
+func printKeyValues(w io.Writer, kvs []client.KeyValue, withValue bool) {
+	if withValue {
+		row(w, "KEY", "VALUE", "CREATE_REVISION", "MOD_REVISION", "VERSION", "LEASE")
+	} else {
+		row(w, "KEY", "CREATE_REVISION", "MOD_REVISION", "VERSION", "LEASE")
+	}
+	for _, kv := range kvs {
+		if withValue {
+			row(w, kv.Key, kv.Value, kv.CreateRevision, kv.ModRevision, kv.Version, kv.Lease)
+		} else {
+			row(w, kv.Key, kv.CreateRevision, kv.ModRevision, kv.Version, kv.Lease)
+		}
+	}
+}
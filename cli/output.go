@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+)
+
+// print writes the response in the output format, the table is written by the fn.
+func (o *options) print(response interface{}, fn func(w io.Writer)) error {
+	switch o.output {
+	case "json":
+		encoder := json.NewEncoder(o.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(response)
+	case "yaml":
+		// round trip through json, so the yaml fields are named as the json ones
+		data, err := json.Marshal(response)
+		if err != nil {
+			return err
+		}
+		var object interface{}
+		if err := json.Unmarshal(data, &object); err != nil {
+			return err
+		}
+		data, err = yaml.Marshal(object)
+		if err != nil {
+			return err
+		}
+		_, err = o.stdout.Write(data)
+		return err
+	default:
+		w := tabwriter.NewWriter(o.stdout, 0, 4, 2, ' ', 0)
+		fn(w)
+		return w.Flush()
+	}
+}
+
+// row writes the cells as a row of the table.
+func row(w io.Writer, cells ...interface{}) {
+	strs := make([]string, len(cells))
+	for idx, c := range cells {
+		switch typedCell := c.(type) {
+		case string:
+			strs[idx] = cell(typedCell)
+		case bool:
+			strs[idx] = strconv.FormatBool(typedCell)
+		default:
+			strs[idx] = fmt.Sprint(typedCell)
+		}
+	}
+	fmt.Fprintln(w, strings.Join(strs, "\t"))
+}
+
+// cell escapes the control characters which break the table, e.g. the line breaks of a value.
+func cell(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	if strings.IndexFunc(s, unicode.IsControl) == -1 {
+		return s
+	}
+	quoted := strconv.Quote(s)
+	return quoted[1 : len(quoted)-1]
+}
+
+// humanSize formats the size in bytes.
+func humanSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
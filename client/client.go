@@ -0,0 +1,123 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client of the API, one is safe to be shared by the goroutines.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient takes the base URL of the API, e.g. "http://127.0.0.1:8080/api/v1",
+// the default HTTP client is used if nil.
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// Error turns the envelope of a failed response into an error.
+func (e *ErrorResponse) Error() string {
+	return fmt.Sprintf("%s(%d): %s", e.Code, e.Status, e.Message)
+}
+
+// ErrorCode returns the API error code of an error returned by the client, empty if it isn't one.
+func ErrorCode(err error) string {
+	if errorResponse, ok := err.(*ErrorResponse); ok {
+		return errorResponse.Code
+	}
+	return ""
+}
+
+// do sends the request and decodes the json response into the out, if any.
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body interface{}, contentType string, out interface{}) error {
+	resp, err := c.send(ctx, method, path, query, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		_, err = io.Copy(ioutil.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("bad response body, %v", err)
+	}
+	return nil
+}
+
+// stream sends the request and hands over the response body.
+func (c *Client) stream(ctx context.Context, method string, path string, query url.Values, body interface{}, contentType string) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, method, path, query, body, contentType)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) send(ctx context.Context, method string, path string, query url.Values, body interface{}, contentType string) (*http.Response, error) {
+	var reader io.Reader
+	switch typedBody := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = typedBody
+	default:
+		data, err := json.Marshal(typedBody)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	requestURL := c.baseURL + path
+	if len(query) != 0 {
+		requestURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, requestURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	if reader != nil && len(contentType) != 0 {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusBadRequest {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	// the failures are answered with the envelope, the others come from the proxies in between
+	errorResponse := &ErrorResponse{}
+	data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err := json.Unmarshal(data, errorResponse); err != nil || len(errorResponse.Code) == 0 {
+		errorResponse = &ErrorResponse{
+			Code:    "Internal",
+			Status:  int32(resp.StatusCode),
+			Message: strings.TrimSpace(string(data)),
+		}
+		if len(errorResponse.Message) == 0 {
+			errorResponse.Message = http.StatusText(resp.StatusCode)
+		}
+	}
+	return nil, errorResponse
+}
@@ -0,0 +1,1322 @@
+// Code generated by openapi-gen. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// KeyValue Pair
+type KeyValue struct {
+	Key            string `json:"key"`
+	Value          string `json:"value"`
+	CreateRevision int64  `json:"createRevision,omitempty"`
+	ModRevision    int64  `json:"modRevision,omitempty"`
+	Version        int64  `json:"version,omitempty"`
+	// ID of the lease in hex, empty if none
+	Lease string `json:"lease,omitempty"`
+	// How the key and the value are encoded, empty means utf8
+	Encoding string `json:"encoding,omitempty"`
+	// The key or the value isn't valid utf8
+	Binary  bool          `json:"binary,omitempty"`
+	Decoded *DecodedValue `json:"decoded,omitempty"`
+}
+
+// Decoded Value, the object is rendered in json or yaml
+type DecodedValue struct {
+	Decoder    string `json:"decoder"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	// The decoded object, rendered in json
+	Object interface{} `json:"object,omitempty"`
+	// The decoded object, rendered in yaml
+	YAML string `json:"yaml,omitempty"`
+	// What's not decoded, e.g. the fields of the kubernetes protobuf objects whose kinds have no descriptors
+	Note  string `json:"note,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Directory of the key space, keys under it are counted
+type KeyDir struct {
+	Key   string `json:"key,omitempty"`
+	Count int64  `json:"count,omitempty"`
+}
+
+// One level of the key space, split by the delimiter
+type KeyTree struct {
+	Prefix    string     `json:"prefix,omitempty"`
+	Delimiter string     `json:"delimiter,omitempty"`
+	Revision  int64      `json:"revision,omitempty"`
+	Dirs      []KeyDir   `json:"dirs,omitempty"`
+	Keys      []KeyValue `json:"keys,omitempty"`
+	More      bool       `json:"more,omitempty"`
+}
+
+// Planned action of one imported key, the result is set once applied
+type ImportItem struct {
+	Key      string `json:"key,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	// One of create, update, unchanged, delete and invalid
+	Action string `json:"action,omitempty"`
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Plan of an import, against the revision the current values are read at
+type ImportPlan struct {
+	Revision  int64        `json:"revision,omitempty"`
+	DryRun    bool         `json:"dryRun,omitempty"`
+	Creates   int64        `json:"creates,omitempty"`
+	Updates   int64        `json:"updates,omitempty"`
+	Unchanged int64        `json:"unchanged,omitempty"`
+	Deletes   int64        `json:"deletes,omitempty"`
+	Invalid   int64        `json:"invalid,omitempty"`
+	Applied   int64        `json:"applied,omitempty"`
+	Failed    int64        `json:"failed,omitempty"`
+	Items     []ImportItem `json:"items,omitempty"`
+	// The entry of the undo journal, zero if not journaled
+	Journal int64 `json:"journal,omitempty"`
+}
+
+// Key which cannot be copied or moved
+type CopyConflict struct {
+	Key      string `json:"key,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Result of a copy or a move, the source is read at the revision
+type CopyResult struct {
+	Revision  int64          `json:"revision,omitempty"`
+	Copied    int64          `json:"copied,omitempty"`
+	Conflicts []CopyConflict `json:"conflicts,omitempty"`
+	// The entry of the undo journal, zero if not journaled
+	Journal int64 `json:"journal,omitempty"`
+}
+
+// One version of a key, a deleted one only has the revision of the deletion, zero if unknown
+type KeyVersion struct {
+	KeyValue
+	Deleted bool `json:"deleted,omitempty"`
+	// When the console wrote the version, formatted as "2006-01-02 15:04:05", only known while the write is in the undo journal
+	Time string `json:"time,omitempty"`
+}
+
+// Versions of a key from the newest, walking back until the compacted revision
+type KeyHistory struct {
+	Key       string       `json:"key,omitempty"`
+	Encoding  string       `json:"encoding,omitempty"`
+	Revision  int64        `json:"revision,omitempty"`
+	Versions  []KeyVersion `json:"versions,omitempty"`
+	Compacted bool         `json:"compacted,omitempty"`
+	More      bool         `json:"more,omitempty"`
+}
+
+// Change of a key between two revisions
+type KeyChange struct {
+	Key      string `json:"key,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	// One of added, removed, modified and rewritten
+	Change string    `json:"change,omitempty"`
+	Before *KeyValue `json:"before,omitempty"`
+	After  *KeyValue `json:"after,omitempty"`
+	// Line diff of the values
+	Patch string `json:"patch,omitempty"`
+}
+
+// Changes of a key or a prefix between two revisions
+type KeyDiff struct {
+	From      int64       `json:"from,omitempty"`
+	To        int64       `json:"to,omitempty"`
+	Added     int64       `json:"added,omitempty"`
+	Removed   int64       `json:"removed,omitempty"`
+	Modified  int64       `json:"modified,omitempty"`
+	Rewritten int64       `json:"rewritten,omitempty"`
+	Changes   []KeyChange `json:"changes,omitempty"`
+	// The prefix has more keys to compare
+	More bool `json:"more,omitempty"`
+	// Continues to the next page
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// A key changed by a mutation, the previous one is absent if the key was new
+type JournalKey struct {
+	Key      string    `json:"key,omitempty"`
+	Encoding string    `json:"encoding,omitempty"`
+	Deleted  bool      `json:"deleted,omitempty"`
+	Prev     *KeyValue `json:"prev,omitempty"`
+}
+
+// A console-initiated mutation, kept with the previous values to be undone
+type JournalEntry struct {
+	ID int64 `json:"id,omitempty"`
+	// formatted as "2006-01-02 15:04:05"
+	Time string `json:"time,omitempty"`
+	// One of write, remove, import, copy, move and undo
+	Op       string       `json:"op,omitempty"`
+	Revision int64        `json:"revision,omitempty"`
+	Keys     []JournalKey `json:"keys,omitempty"`
+	// The revision of the last undo, zero if never undone
+	Undone int64 `json:"undone,omitempty"`
+}
+
+// Result of an undo, which is journaled as well to be redone
+type UndoResult struct {
+	ID int64 `json:"id,omitempty"`
+	// The journal entry of the undo itself
+	Journal   int64          `json:"journal,omitempty"`
+	Restored  int64          `json:"restored,omitempty"`
+	Conflicts []CopyConflict `json:"conflicts,omitempty"`
+}
+
+// Features of the etcd cluster, by its version
+type EtcdFeatures struct {
+	// Since 3.0
+	V3API bool `json:"v3api,omitempty"`
+	// Since 3.3
+	MoveLeader bool `json:"moveLeader,omitempty"`
+	// Since 3.4
+	Learner bool `json:"learner,omitempty"`
+	// Since 3.5
+	Downgrade bool `json:"downgrade,omitempty"`
+}
+
+// Member Status
+type MemberStatus struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Endpoint  string `json:"endpoint,omitempty"`
+	Leader    bool   `json:"leader,omitempty"`
+	Health    bool   `json:"health,omitempty"`
+	Connected bool   `json:"connected,omitempty"`
+	DBSize    int64  `json:"dbSize,omitempty"`
+	Version   string `json:"version,omitempty"`
+}
+
+// Backup
+type Backup struct {
+	Name string `json:"name,omitempty"`
+	Size int64  `json:"size,omitempty"`
+	// formatted as "2006-01-02 15:04:05"
+	CreateTime string `json:"createTime,omitempty"`
+}
+
+// Mirror Job, copies a prefix to another cluster, then follows the updates if watching
+type MirrorJob struct {
+	ID          string   `json:"id,omitempty"`
+	Prefix      string   `json:"prefix,omitempty"`
+	DestPrefix  string   `json:"destPrefix,omitempty"`
+	Source      []string `json:"source,omitempty"`
+	Destination []string `json:"destination,omitempty"`
+	Watch       bool     `json:"watch,omitempty"`
+	// One of syncing, watching, done, stopped and failed
+	State string `json:"state,omitempty"`
+	// The initial sync is consistent at the revision of the source
+	Revision int64 `json:"revision,omitempty"`
+	Synced   int64 `json:"synced,omitempty"`
+	// The updates applied by watching, until the last revision of the source
+	Updates      int64 `json:"updates,omitempty"`
+	LastRevision int64 `json:"lastRevision,omitempty"`
+	// formatted as "2006-01-02 15:04:05"
+	StartTime string `json:"startTime,omitempty"`
+	// formatted as "2006-01-02 15:04:05"
+	EndTime string `json:"endTime,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Record of one mutating operation
+type AuditRecord struct {
+	Time       time.Time         `json:"time,omitempty"`
+	Op         string            `json:"op,omitempty"`
+	Method     string            `json:"method,omitempty"`
+	Path       string            `json:"path,omitempty"`
+	RemoteAddr string            `json:"remoteAddr,omitempty"`
+	User       string            `json:"user,omitempty"`
+	Params     map[string]string `json:"params,omitempty"`
+	// The request body, as it was sent
+	Body   interface{} `json:"body,omitempty"`
+	Status int32       `json:"status,omitempty"`
+	Error  string      `json:"error,omitempty"`
+	// e.g. the previous key values returned by the op
+	Result interface{} `json:"result,omitempty"`
+}
+
+// Test Member, a member of the embedding etcd of the test mode
+type TestMember struct {
+	Name      string `json:"name,omitempty"`
+	ClientURL string `json:"clientURL,omitempty"`
+	PeerURL   string `json:"peerURL,omitempty"`
+	DataDir   string `json:"dataDir,omitempty"`
+	Running   bool   `json:"running,omitempty"`
+}
+
+// Violation of a value schema
+type Violation struct {
+	Path    string `json:"path,omitempty"`
+	Keyword string `json:"keyword,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type ClientSetRequest struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+	// How the key and the value are encoded
+	Encoding string `json:"encoding,omitempty"`
+	// Write the value as it is, skipping the codec mapped by the key prefix
+	Raw bool `json:"raw,omitempty"`
+	// v2 only
+	TTL int32 `json:"ttl,omitempty"`
+	// v2 only
+	SwapWithValue string `json:"swapWithValue,omitempty"`
+	// v2 only
+	SwapWithIndex int32 `json:"swapWithIndex,omitempty"`
+	// ID of the lease in hex
+	Lease       string `json:"lease,omitempty"`
+	PrevKV      bool   `json:"prevKV,omitempty"`
+	IgnoreValue bool   `json:"ignoreValue,omitempty"`
+	IgnoreLease bool   `json:"ignoreLease,omitempty"`
+}
+
+type ClientCopyRequest struct {
+	// Source prefix, or key if exact
+	From string `json:"from"`
+	// Destination prefix, or key if exact
+	To string `json:"to"`
+	// From and to are single keys, e.g. for renaming a key
+	Exact bool `json:"exact,omitempty"`
+	// How the prefixes are encoded
+	Encoding      string `json:"encoding,omitempty"`
+	PreserveLease bool   `json:"preserveLease,omitempty"`
+	// Overwrite the existing keys under the destination
+	Overwrite bool  `json:"overwrite,omitempty"`
+	MaxTxnOps int64 `json:"maxTxnOps,omitempty"`
+}
+
+type MirrorStartRequest struct {
+	Prefix string `json:"prefix"`
+	// The keys are rewritten under another prefix, defaults to the same one
+	DestPrefix string `json:"destPrefix,omitempty"`
+	// One of the configured mirrors by name, or set the endpoints
+	Destination string `json:"destination,omitempty"`
+	// Refused unless MirrorAnyEndpoints is configured
+	Endpoints []string `json:"endpoints,omitempty"`
+	// The source, one of the configured mirrors by name, defaults to the cluster of the console
+	Source string `json:"source,omitempty"`
+	// Refused unless MirrorAnyEndpoints is configured
+	SourceEndpoints []string `json:"sourceEndpoints,omitempty"`
+	// Keep following the updates after the initial sync
+	Watch bool `json:"watch,omitempty"`
+}
+
+type ClientResponse struct {
+	// Time the op took
+	Result string     `json:"result,omitempty"`
+	KVS    []KeyValue `json:"kvs,omitempty"`
+	// Read only
+	More bool `json:"more,omitempty"`
+	// Read only
+	Count int64 `json:"count,omitempty"`
+	// Read only, continues to the next page
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// The removed key values, or the plan of a dry run
+type ClientRemoveResponse struct {
+	// Time the op took
+	Result string `json:"result,omitempty"`
+	// The previous key values, if prevKV
+	KVS []KeyValue `json:"kvs,omitempty"`
+	// Dry run only
+	Revision int64 `json:"revision,omitempty"`
+	// Dry run only
+	Count int64 `json:"count,omitempty"`
+	// Dry run only, a sample of the keys
+	Keys []KeyValue `json:"keys,omitempty"`
+	// Dry run only
+	More bool `json:"more,omitempty"`
+	// Dry run only
+	Limit int64 `json:"limit,omitempty"`
+	// Dry run only, confirms the remove if the count exceeds the limit
+	Token string `json:"token,omitempty"`
+}
+
+type ClientTreeResponse struct {
+	KeyTree
+	// Time the op took
+	Result string `json:"result,omitempty"`
+}
+
+type ClientImportResponse struct {
+	ImportPlan
+	// Time the op took
+	Result string `json:"result,omitempty"`
+}
+
+type ClientCopyResponse struct {
+	CopyResult
+	// Time the op took
+	Result string `json:"result,omitempty"`
+}
+
+type ClientHistoryResponse struct {
+	KeyHistory
+	// Time the op took
+	Result string `json:"result,omitempty"`
+}
+
+type ClientDiffResponse struct {
+	KeyDiff
+	// Time the op took
+	Result string `json:"result,omitempty"`
+}
+
+type ClientJournalResponse struct {
+	// Time the op took
+	Result  string         `json:"result,omitempty"`
+	Entries []JournalEntry `json:"entries,omitempty"`
+}
+
+type ClientUndoResponse struct {
+	UndoResult
+	// Time the op took
+	Result string `json:"result,omitempty"`
+}
+
+type ClusterVersionResponse struct {
+	Version  string        `json:"version,omitempty"`
+	Major    int64         `json:"major,omitempty"`
+	Minor    int64         `json:"minor,omitempty"`
+	Patch    int64         `json:"patch,omitempty"`
+	Features *EtcdFeatures `json:"features,omitempty"`
+}
+
+type ClusterMemberStatusResponse struct {
+	Members []MemberStatus `json:"members,omitempty"`
+}
+
+type ClusterBackupResponse struct {
+	Backups []Backup `json:"backups,omitempty"`
+}
+
+type MirrorResponse struct {
+	Jobs []MirrorJob `json:"jobs,omitempty"`
+}
+
+type AuditResponse struct {
+	Records []AuditRecord `json:"records,omitempty"`
+	More    bool          `json:"more,omitempty"`
+}
+
+type TestMemberResponse struct {
+	Members []TestMember `json:"members,omitempty"`
+}
+
+// Envelope of the failed responses
+type ErrorResponse struct {
+	// One of the API error codes
+	Code    string `json:"code"`
+	Status  int32  `json:"status"`
+	Message string `json:"message"`
+	// ValidationDetails for InvalidValue, ConfirmationDetails for ConfirmationRequired
+	Details interface{} `json:"details,omitempty"`
+}
+
+type ValidationDetails struct {
+	Key        string      `json:"key,omitempty"`
+	Schema     string      `json:"schema,omitempty"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+type ConfirmationDetails struct {
+	Count int64 `json:"count,omitempty"`
+	Limit int64 `json:"limit,omitempty"`
+	// Send it back as "confirm" to go on
+	Token string `json:"token,omitempty"`
+}
+
+// ReadKeysParams are the query parameters of ReadKeys.
+type ReadKeysParams struct {
+	// Encoded by the encoding
+	Key string
+	// Take the key as a prefix
+	Prefix bool
+	// Range from the key to the end
+	FromKey bool
+	// The end of the range, exclusive
+	Range string
+	// Linearizable or serializable
+	Consistency string
+	Limit       int64
+	// Read at the revision, zero means the latest
+	Rev        int64
+	KeysOnly   bool
+	SortOrder  string
+	SortTarget string
+	// The cursor of the previous page
+	Cursor string
+	// How the keys and the values are encoded
+	Encoding string
+	// auto(default), none, or the name of a codec
+	Decode string
+	// How the decoded values are rendered
+	Format string
+	// In seconds
+	Timeout int64
+}
+
+func (p *ReadKeysParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.Key) != 0 {
+		values.Set("key", p.Key)
+	}
+	if p.Prefix {
+		values.Set("prefix", "true")
+	}
+	if p.FromKey {
+		values.Set("fromKey", "true")
+	}
+	if len(p.Range) != 0 {
+		values.Set("range", p.Range)
+	}
+	if len(p.Consistency) != 0 {
+		values.Set("consistency", p.Consistency)
+	}
+	if p.Limit != 0 {
+		values.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.Rev != 0 {
+		values.Set("rev", strconv.FormatInt(p.Rev, 10))
+	}
+	if p.KeysOnly {
+		values.Set("keysOnly", "true")
+	}
+	if len(p.SortOrder) != 0 {
+		values.Set("sortOrder", p.SortOrder)
+	}
+	if len(p.SortTarget) != 0 {
+		values.Set("sortTarget", p.SortTarget)
+	}
+	if len(p.Cursor) != 0 {
+		values.Set("cursor", p.Cursor)
+	}
+	if len(p.Encoding) != 0 {
+		values.Set("encoding", p.Encoding)
+	}
+	if len(p.Decode) != 0 {
+		values.Set("decode", p.Decode)
+	}
+	if len(p.Format) != 0 {
+		values.Set("format", p.Format)
+	}
+	if p.Timeout != 0 {
+		values.Set("timeout", strconv.FormatInt(p.Timeout, 10))
+	}
+	return values
+}
+
+// ReadKeys calls GET /client/read to read a key, a prefix or a range, one page at a time.
+func (c *Client) ReadKeys(ctx context.Context, params *ReadKeysParams) (*ClientResponse, error) {
+	out := &ClientResponse{}
+	if err := c.do(ctx, http.MethodGet, "/client/read", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WriteKeyParams are the query parameters of WriteKey.
+type WriteKeyParams struct {
+	// In seconds
+	Timeout int64
+}
+
+func (p *WriteKeyParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Timeout != 0 {
+		values.Set("timeout", strconv.FormatInt(p.Timeout, 10))
+	}
+	return values
+}
+
+// WriteKey calls POST /client/write to write a key.
+func (c *Client) WriteKey(ctx context.Context, body *ClientSetRequest, params *WriteKeyParams) (*ClientResponse, error) {
+	out := &ClientResponse{}
+	if err := c.do(ctx, http.MethodPost, "/client/write", params.values(), body, "application/json", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RemoveKeysParams are the query parameters of RemoveKeys.
+type RemoveKeysParams struct {
+	// Encoded by the encoding
+	Key string
+	// Take the key as a prefix
+	Prefix bool
+	// Range from the key to the end
+	FromKey bool
+	// The end of the range, exclusive
+	Range string
+	// How the keys and the values are encoded
+	Encoding string
+	// Return the removed key values
+	PrevKV bool
+	// The token confirming a remove over the delete limit
+	Confirm string
+	// Count the keys without removing them
+	DryRun bool
+	// Dry run only, the number of keys to return, at most 1000
+	Sample int64
+	// In seconds
+	Timeout int64
+}
+
+func (p *RemoveKeysParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.Key) != 0 {
+		values.Set("key", p.Key)
+	}
+	if p.Prefix {
+		values.Set("prefix", "true")
+	}
+	if p.FromKey {
+		values.Set("fromKey", "true")
+	}
+	if len(p.Range) != 0 {
+		values.Set("range", p.Range)
+	}
+	if len(p.Encoding) != 0 {
+		values.Set("encoding", p.Encoding)
+	}
+	if p.PrevKV {
+		values.Set("prevKV", "true")
+	}
+	if len(p.Confirm) != 0 {
+		values.Set("confirm", p.Confirm)
+	}
+	if p.DryRun {
+		values.Set("dryRun", "true")
+	}
+	if p.Sample != 0 {
+		values.Set("sample", strconv.FormatInt(p.Sample, 10))
+	}
+	if p.Timeout != 0 {
+		values.Set("timeout", strconv.FormatInt(p.Timeout, 10))
+	}
+	return values
+}
+
+// RemoveKeys calls DELETE /client/remove to remove a key, a prefix or a range.
+func (c *Client) RemoveKeys(ctx context.Context, params *RemoveKeysParams) (*ClientRemoveResponse, error) {
+	out := &ClientRemoveResponse{}
+	if err := c.do(ctx, http.MethodDelete, "/client/remove", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReadTreeParams are the query parameters of ReadTree.
+type ReadTreeParams struct {
+	// The prefix of the level
+	Key       string
+	Delimiter string
+	// Read at the revision, zero means the latest
+	Rev      int64
+	Limit    int64
+	PageSize int64
+	// In seconds
+	Timeout int64
+}
+
+func (p *ReadTreeParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.Key) != 0 {
+		values.Set("key", p.Key)
+	}
+	if len(p.Delimiter) != 0 {
+		values.Set("delimiter", p.Delimiter)
+	}
+	if p.Rev != 0 {
+		values.Set("rev", strconv.FormatInt(p.Rev, 10))
+	}
+	if p.Limit != 0 {
+		values.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if p.PageSize != 0 {
+		values.Set("pageSize", strconv.FormatInt(p.PageSize, 10))
+	}
+	if p.Timeout != 0 {
+		values.Set("timeout", strconv.FormatInt(p.Timeout, 10))
+	}
+	return values
+}
+
+// ReadTree calls GET /client/tree to read one level of the key space.
+func (c *Client) ReadTree(ctx context.Context, params *ReadTreeParams) (*ClientTreeResponse, error) {
+	out := &ClientTreeResponse{}
+	if err := c.do(ctx, http.MethodGet, "/client/tree", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ExportKeysParams are the query parameters of ExportKeys.
+type ExportKeysParams struct {
+	// Encoded by the encoding
+	Key string
+	// Take the key as a prefix
+	Prefix bool
+	// Range from the key to the end
+	FromKey bool
+	// The end of the range, exclusive
+	Range string
+	// Read at the revision, zero means the latest
+	Rev    int64
+	Format string
+	// Include the revisions, the versions and the leases
+	Metadata bool
+	// How the keys and the values are encoded
+	Encoding string
+	// Nests the keys of the json and yaml documents
+	Delimiter string
+	PageSize  int64
+	// In seconds
+	Timeout int64
+}
+
+func (p *ExportKeysParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.Key) != 0 {
+		values.Set("key", p.Key)
+	}
+	if p.Prefix {
+		values.Set("prefix", "true")
+	}
+	if p.FromKey {
+		values.Set("fromKey", "true")
+	}
+	if len(p.Range) != 0 {
+		values.Set("range", p.Range)
+	}
+	if p.Rev != 0 {
+		values.Set("rev", strconv.FormatInt(p.Rev, 10))
+	}
+	if len(p.Format) != 0 {
+		values.Set("format", p.Format)
+	}
+	if p.Metadata {
+		values.Set("metadata", "true")
+	}
+	if len(p.Encoding) != 0 {
+		values.Set("encoding", p.Encoding)
+	}
+	if len(p.Delimiter) != 0 {
+		values.Set("delimiter", p.Delimiter)
+	}
+	if p.PageSize != 0 {
+		values.Set("pageSize", strconv.FormatInt(p.PageSize, 10))
+	}
+	if p.Timeout != 0 {
+		values.Set("timeout", strconv.FormatInt(p.Timeout, 10))
+	}
+	return values
+}
+
+// ExportKeys calls GET /client/export to export a key, a prefix or a range as a file, the caller closes the content.
+func (c *Client) ExportKeys(ctx context.Context, params *ExportKeysParams) (io.ReadCloser, error) {
+	return c.stream(ctx, http.MethodGet, "/client/export", params.values(), nil, "")
+}
+
+// ImportKeysParams are the query parameters of ImportKeys.
+type ImportKeysParams struct {
+	// The prefix the keys are imported under
+	Key string
+	// Detected by the file if empty
+	Format    string
+	Delimiter string
+	// Plan the import without writing
+	DryRun bool
+	// Remove the keys under the prefix which are absent in the file
+	Prune     bool
+	MaxTxnOps int64
+	// In seconds
+	Timeout int64
+}
+
+func (p *ImportKeysParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.Key) != 0 {
+		values.Set("key", p.Key)
+	}
+	if len(p.Format) != 0 {
+		values.Set("format", p.Format)
+	}
+	if len(p.Delimiter) != 0 {
+		values.Set("delimiter", p.Delimiter)
+	}
+	if p.DryRun {
+		values.Set("dryRun", "true")
+	}
+	if p.Prune {
+		values.Set("prune", "true")
+	}
+	if p.MaxTxnOps != 0 {
+		values.Set("maxTxnOps", strconv.FormatInt(p.MaxTxnOps, 10))
+	}
+	if p.Timeout != 0 {
+		values.Set("timeout", strconv.FormatInt(p.Timeout, 10))
+	}
+	return values
+}
+
+// ImportKeys calls POST /client/import to import a file exported before.
+func (c *Client) ImportKeys(ctx context.Context, body io.Reader, params *ImportKeysParams) (*ClientImportResponse, error) {
+	out := &ClientImportResponse{}
+	if err := c.do(ctx, http.MethodPost, "/client/import", params.values(), body, "application/octet-stream", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CopyKeysParams are the query parameters of CopyKeys.
+type CopyKeysParams struct {
+	// In seconds
+	Timeout int64
+}
+
+func (p *CopyKeysParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Timeout != 0 {
+		values.Set("timeout", strconv.FormatInt(p.Timeout, 10))
+	}
+	return values
+}
+
+// CopyKeys calls POST /client/copy to copy a prefix or a key to another one.
+func (c *Client) CopyKeys(ctx context.Context, body *ClientCopyRequest, params *CopyKeysParams) (*ClientCopyResponse, error) {
+	out := &ClientCopyResponse{}
+	if err := c.do(ctx, http.MethodPost, "/client/copy", params.values(), body, "application/json", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MoveKeysParams are the query parameters of MoveKeys.
+type MoveKeysParams struct {
+	// In seconds
+	Timeout int64
+}
+
+func (p *MoveKeysParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Timeout != 0 {
+		values.Set("timeout", strconv.FormatInt(p.Timeout, 10))
+	}
+	return values
+}
+
+// MoveKeys calls POST /client/move to move a prefix or a key to another one.
+func (c *Client) MoveKeys(ctx context.Context, body *ClientCopyRequest, params *MoveKeysParams) (*ClientCopyResponse, error) {
+	out := &ClientCopyResponse{}
+	if err := c.do(ctx, http.MethodPost, "/client/move", params.values(), body, "application/json", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetKeyHistoryParams are the query parameters of GetKeyHistory.
+type GetKeyHistoryParams struct {
+	// Required. Encoded by the encoding
+	Key string
+	// Walk back from the revision, zero means the latest
+	Rev   int64
+	Limit int64
+	// How the keys and the values are encoded
+	Encoding string
+	// In seconds
+	Timeout int64
+}
+
+func (p *GetKeyHistoryParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.Key) != 0 {
+		values.Set("key", p.Key)
+	}
+	if p.Rev != 0 {
+		values.Set("rev", strconv.FormatInt(p.Rev, 10))
+	}
+	if p.Limit != 0 {
+		values.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if len(p.Encoding) != 0 {
+		values.Set("encoding", p.Encoding)
+	}
+	if p.Timeout != 0 {
+		values.Set("timeout", strconv.FormatInt(p.Timeout, 10))
+	}
+	return values
+}
+
+// GetKeyHistory calls GET /client/history to read the versions of a key.
+func (c *Client) GetKeyHistory(ctx context.Context, params *GetKeyHistoryParams) (*ClientHistoryResponse, error) {
+	out := &ClientHistoryResponse{}
+	if err := c.do(ctx, http.MethodGet, "/client/history", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DiffKeysParams are the query parameters of DiffKeys.
+type DiffKeysParams struct {
+	// Encoded by the encoding
+	Key string
+	// Take the key as a prefix
+	Prefix bool
+	// Required.
+	From int64
+	// Zero means the latest
+	To int64
+	// Include the values and the patches
+	Values bool
+	// How many keys of each revision a page of the prefix reads at most
+	Limit int64
+	// The cursor of the previous page
+	Cursor string
+	// How the keys and the values are encoded
+	Encoding string
+	// In seconds
+	Timeout int64
+}
+
+func (p *DiffKeysParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.Key) != 0 {
+		values.Set("key", p.Key)
+	}
+	if p.Prefix {
+		values.Set("prefix", "true")
+	}
+	if p.From != 0 {
+		values.Set("from", strconv.FormatInt(p.From, 10))
+	}
+	if p.To != 0 {
+		values.Set("to", strconv.FormatInt(p.To, 10))
+	}
+	if p.Values {
+		values.Set("values", "true")
+	}
+	if p.Limit != 0 {
+		values.Set("limit", strconv.FormatInt(p.Limit, 10))
+	}
+	if len(p.Cursor) != 0 {
+		values.Set("cursor", p.Cursor)
+	}
+	if len(p.Encoding) != 0 {
+		values.Set("encoding", p.Encoding)
+	}
+	if p.Timeout != 0 {
+		values.Set("timeout", strconv.FormatInt(p.Timeout, 10))
+	}
+	return values
+}
+
+// DiffKeys calls GET /client/diff to compare a key or a prefix between two revisions.
+func (c *Client) DiffKeys(ctx context.Context, params *DiffKeysParams) (*ClientDiffResponse, error) {
+	out := &ClientDiffResponse{}
+	if err := c.do(ctx, http.MethodGet, "/client/diff", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetJournalParams are the query parameters of GetJournal.
+type GetJournalParams struct {
+	Limit int32
+	// How the keys and the values are encoded
+	Encoding string
+}
+
+func (p *GetJournalParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Limit != 0 {
+		values.Set("limit", strconv.FormatInt(int64(p.Limit), 10))
+	}
+	if len(p.Encoding) != 0 {
+		values.Set("encoding", p.Encoding)
+	}
+	return values
+}
+
+// GetJournal calls GET /client/journal to list the journaled mutations from the newest.
+func (c *Client) GetJournal(ctx context.Context, params *GetJournalParams) (*ClientJournalResponse, error) {
+	out := &ClientJournalResponse{}
+	if err := c.do(ctx, http.MethodGet, "/client/journal", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UndoJournalEntryParams are the query parameters of UndoJournalEntry.
+type UndoJournalEntryParams struct {
+	// Required. The journal entry, the X-Journal-Entry header of the mutation
+	ID int64
+	// How the keys and the values are encoded
+	Encoding string
+	// In seconds
+	Timeout int64
+}
+
+func (p *UndoJournalEntryParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.ID != 0 {
+		values.Set("id", strconv.FormatInt(p.ID, 10))
+	}
+	if len(p.Encoding) != 0 {
+		values.Set("encoding", p.Encoding)
+	}
+	if p.Timeout != 0 {
+		values.Set("timeout", strconv.FormatInt(p.Timeout, 10))
+	}
+	return values
+}
+
+// UndoJournalEntry calls POST /client/undo to restore the previous values of a journaled mutation.
+func (c *Client) UndoJournalEntry(ctx context.Context, params *UndoJournalEntryParams) (*ClientUndoResponse, error) {
+	out := &ClientUndoResponse{}
+	if err := c.do(ctx, http.MethodPost, "/client/undo", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetClusterVersion calls GET /cluster/version to get the version and the features of the cluster.
+func (c *Client) GetClusterVersion(ctx context.Context) (*ClusterVersionResponse, error) {
+	out := &ClusterVersionResponse{}
+	if err := c.do(ctx, http.MethodGet, "/cluster/version", nil, nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetMemberStatusesParams are the query parameters of GetMemberStatuses.
+type GetMemberStatusesParams struct {
+	// In seconds
+	Timeout int64
+}
+
+func (p *GetMemberStatusesParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Timeout != 0 {
+		values.Set("timeout", strconv.FormatInt(p.Timeout, 10))
+	}
+	return values
+}
+
+// GetMemberStatuses calls GET /cluster/status to get the statuses of the members.
+func (c *Client) GetMemberStatuses(ctx context.Context, params *GetMemberStatusesParams) (*ClusterMemberStatusResponse, error) {
+	out := &ClusterMemberStatusResponse{}
+	if err := c.do(ctx, http.MethodGet, "/cluster/status", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetBackupsParams are the query parameters of GetBackups.
+type GetBackupsParams struct {
+	// Downloads the backup
+	Name string
+	// In seconds
+	Timeout int64
+}
+
+func (p *GetBackupsParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.Name) != 0 {
+		values.Set("name", p.Name)
+	}
+	if p.Timeout != 0 {
+		values.Set("timeout", strconv.FormatInt(p.Timeout, 10))
+	}
+	return values
+}
+
+// GetBackups calls GET /cluster/backup to list the backups, or download one by the name.
+func (c *Client) GetBackups(ctx context.Context, params *GetBackupsParams) (*ClusterBackupResponse, error) {
+	out := &ClusterBackupResponse{}
+	if err := c.do(ctx, http.MethodGet, "/cluster/backup", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DownloadBackup downloads the binary content of GET /cluster/backup, the caller closes the content.
+func (c *Client) DownloadBackup(ctx context.Context, params *GetBackupsParams) (io.ReadCloser, error) {
+	return c.stream(ctx, http.MethodGet, "/cluster/backup", params.values(), nil, "")
+}
+
+// CreateBackupParams are the query parameters of CreateBackup.
+type CreateBackupParams struct {
+	// In seconds
+	Timeout int64
+}
+
+func (p *CreateBackupParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if p.Timeout != 0 {
+		values.Set("timeout", strconv.FormatInt(p.Timeout, 10))
+	}
+	return values
+}
+
+// CreateBackup calls POST /cluster/backup to take a snapshot of the cluster.
+func (c *Client) CreateBackup(ctx context.Context, params *CreateBackupParams) (*ClusterBackupResponse, error) {
+	out := &ClusterBackupResponse{}
+	if err := c.do(ctx, http.MethodPost, "/cluster/backup", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RemoveBackupParams are the query parameters of RemoveBackup.
+type RemoveBackupParams struct {
+	// Required.
+	Name string
+}
+
+func (p *RemoveBackupParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.Name) != 0 {
+		values.Set("name", p.Name)
+	}
+	return values
+}
+
+// RemoveBackup calls DELETE /cluster/backup to remove a backup.
+func (c *Client) RemoveBackup(ctx context.Context, params *RemoveBackupParams) error {
+	return c.do(ctx, http.MethodDelete, "/cluster/backup", params.values(), nil, "", nil)
+}
+
+// GetMirrorJobsParams are the query parameters of GetMirrorJobs.
+type GetMirrorJobsParams struct {
+	ID string
+}
+
+func (p *GetMirrorJobsParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.ID) != 0 {
+		values.Set("id", p.ID)
+	}
+	return values
+}
+
+// GetMirrorJobs calls GET /mirror/job to list the mirror jobs, or get one by the id.
+func (c *Client) GetMirrorJobs(ctx context.Context, params *GetMirrorJobsParams) (*MirrorResponse, error) {
+	out := &MirrorResponse{}
+	if err := c.do(ctx, http.MethodGet, "/mirror/job", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StartMirrorJob calls POST /mirror/job to start a mirror job.
+func (c *Client) StartMirrorJob(ctx context.Context, body *MirrorStartRequest) (*MirrorResponse, error) {
+	out := &MirrorResponse{}
+	if err := c.do(ctx, http.MethodPost, "/mirror/job", nil, body, "application/json", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StopMirrorJobParams are the query parameters of StopMirrorJob.
+type StopMirrorJobParams struct {
+	// Required.
+	ID string
+}
+
+func (p *StopMirrorJobParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.ID) != 0 {
+		values.Set("id", p.ID)
+	}
+	return values
+}
+
+// StopMirrorJob calls DELETE /mirror/job to stop a mirror job.
+func (c *Client) StopMirrorJob(ctx context.Context, params *StopMirrorJobParams) (*MirrorResponse, error) {
+	out := &MirrorResponse{}
+	if err := c.do(ctx, http.MethodDelete, "/mirror/job", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// QueryAuditParams are the query parameters of QueryAudit.
+type QueryAuditParams struct {
+	Op         string
+	User       string
+	RemoteAddr string
+	// A part of the key in the params or the body
+	Key string
+	// RFC 3339 or unix seconds
+	Since string
+	// RFC 3339 or unix seconds
+	Until string
+	Limit int32
+}
+
+func (p *QueryAuditParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.Op) != 0 {
+		values.Set("op", p.Op)
+	}
+	if len(p.User) != 0 {
+		values.Set("user", p.User)
+	}
+	if len(p.RemoteAddr) != 0 {
+		values.Set("remoteAddr", p.RemoteAddr)
+	}
+	if len(p.Key) != 0 {
+		values.Set("key", p.Key)
+	}
+	if len(p.Since) != 0 {
+		values.Set("since", p.Since)
+	}
+	if len(p.Until) != 0 {
+		values.Set("until", p.Until)
+	}
+	if p.Limit != 0 {
+		values.Set("limit", strconv.FormatInt(int64(p.Limit), 10))
+	}
+	return values
+}
+
+// QueryAudit calls GET /audit to query the audit log from the newest.
+func (c *Client) QueryAudit(ctx context.Context, params *QueryAuditParams) (*AuditResponse, error) {
+	out := &AuditResponse{}
+	if err := c.do(ctx, http.MethodGet, "/audit", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetTestMembers calls GET /test/member to list the members of the embedding etcd in test mode.
+func (c *Client) GetTestMembers(ctx context.Context) (*TestMemberResponse, error) {
+	out := &TestMemberResponse{}
+	if err := c.do(ctx, http.MethodGet, "/test/member", nil, nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StartTestMemberParams are the query parameters of StartTestMember.
+type StartTestMemberParams struct {
+	// Required.
+	Name string
+}
+
+func (p *StartTestMemberParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.Name) != 0 {
+		values.Set("name", p.Name)
+	}
+	return values
+}
+
+// StartTestMember calls POST /test/member to start a stopped member of the embedding etcd.
+func (c *Client) StartTestMember(ctx context.Context, params *StartTestMemberParams) (*TestMemberResponse, error) {
+	out := &TestMemberResponse{}
+	if err := c.do(ctx, http.MethodPost, "/test/member", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StopTestMemberParams are the query parameters of StopTestMember.
+type StopTestMemberParams struct {
+	// Required.
+	Name string
+}
+
+func (p *StopTestMemberParams) values() url.Values {
+	values := url.Values{}
+	if p == nil {
+		return values
+	}
+	if len(p.Name) != 0 {
+		values.Set("name", p.Name)
+	}
+	return values
+}
+
+// StopTestMember calls DELETE /test/member to stop a member of the embedding etcd.
+func (c *Client) StopTestMember(ctx context.Context, params *StopTestMemberParams) (*TestMemberResponse, error) {
+	out := &TestMemberResponse{}
+	if err := c.do(ctx, http.MethodDelete, "/test/member", params.values(), nil, "", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
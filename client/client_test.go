@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientRequests(t *testing.T) {
+	var (
+		gotMethod, gotPath, gotQuery, gotContentType string
+		gotBody                                      []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath, gotQuery, gotContentType = r.Method, r.URL.Path, r.URL.RawQuery, r.Header.Get("Content-Type")
+		gotBody, _ = ioutil.ReadAll(r.Body)
+
+		switch r.URL.Path {
+		case "/api/v1/cluster/backup":
+			w.Write([]byte("zip content"))
+		default:
+			json.NewEncoder(w).Encode(ClientResponse{KVS: []KeyValue{{Key: "/a", Value: "v"}}, Count: 1})
+		}
+	}))
+	defer server.Close()
+
+	// the trailing slash of the base URL is dropped
+	c := NewClient(server.URL+"/api/v1/", nil)
+
+	resp, err := c.ReadKeys(context.Background(), &ReadKeysParams{Key: "/a", Prefix: true, Limit: 10})
+	if err != nil {
+		t.Fatalf("ReadKeys() error = %v", err)
+	}
+	if gotMethod != http.MethodGet || gotPath != "/api/v1/client/read" || gotQuery != "key=%2Fa&limit=10&prefix=true" {
+		t.Errorf("ReadKeys() sent %s %s?%s, want GET /api/v1/client/read of the params", gotMethod, gotPath, gotQuery)
+	}
+	if len(resp.KVS) != 1 || resp.KVS[0].Key != "/a" || resp.Count != 1 {
+		t.Errorf("ReadKeys() = %+v, want the key /a", resp)
+	}
+
+	if _, err := c.WriteKey(context.Background(), &ClientSetRequest{Key: "/a", Value: "v"}, &WriteKeyParams{}); err != nil {
+		t.Fatalf("WriteKey() error = %v", err)
+	}
+	var body ClientSetRequest
+	if err := json.Unmarshal(gotBody, &body); err != nil || body.Key != "/a" || body.Value != "v" {
+		t.Errorf("WriteKey() sent %q, %v, want the request", gotBody, err)
+	}
+	if gotMethod != http.MethodPost || len(gotQuery) != 0 || gotContentType != "application/json" {
+		t.Errorf("WriteKey() sent %s ?%s of %q, want POST of json without a query", gotMethod, gotQuery, gotContentType)
+	}
+
+	content, err := c.DownloadBackup(context.Background(), &GetBackupsParams{Name: "backup.zip"})
+	if err != nil {
+		t.Fatalf("DownloadBackup() error = %v", err)
+	}
+	defer content.Close()
+	if data, _ := ioutil.ReadAll(content); string(data) != "zip content" || gotQuery != "name=backup.zip" {
+		t.Errorf("DownloadBackup() = %q of ?%s, want the content of the name", data, gotQuery)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("key") {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"NotFound","status":404,"message":"cannot find key /missing"}`))
+		case "/proxied":
+			// not the envelope, e.g. from a proxy in between
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("upstream is down\n"))
+		case "/empty":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte("not json"))
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL+"/api/v1", nil)
+
+	tests := []struct {
+		key     string
+		code    string
+		status  int32
+		message string
+	}{
+		{key: "/missing", code: "NotFound", status: http.StatusNotFound, message: "cannot find key /missing"},
+		{key: "/proxied", code: "Internal", status: http.StatusBadGateway, message: "upstream is down"},
+		{key: "/empty", code: "Internal", status: http.StatusServiceUnavailable, message: "Service Unavailable"},
+	}
+	for _, tt := range tests {
+		_, err := c.ReadKeys(context.Background(), &ReadKeysParams{Key: tt.key})
+		errorResponse, ok := err.(*ErrorResponse)
+		if !ok {
+			t.Errorf("ReadKeys(%s) error = %v, want an ErrorResponse", tt.key, err)
+			continue
+		}
+		if errorResponse.Code != tt.code || errorResponse.Status != tt.status || errorResponse.Message != tt.message {
+			t.Errorf("ReadKeys(%s) error = %+v, want %s(%d): %s", tt.key, errorResponse, tt.code, tt.status, tt.message)
+		}
+		if ErrorCode(err) != tt.code {
+			t.Errorf("ErrorCode() of %s = %s, want %s", tt.key, ErrorCode(err), tt.code)
+		}
+	}
+
+	if _, err := c.ReadKeys(context.Background(), &ReadKeysParams{Key: "/a"}); err == nil || ErrorCode(err) != "" {
+		t.Errorf("ReadKeys() of a bad body error = %v, want an error of no code", err)
+	}
+}
@@ -0,0 +1,5 @@
+// Package client calls the API of the etcd console, the types and the methods are generated
+// from the OpenAPI document served at /api/v1/openapi.json.
+package client
+
+//go:generate go run ../cmd/openapi-gen/main.go -mode client -in ../backend/v1/web/openapi/openapi.json -out client_gen.go -package client
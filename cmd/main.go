@@ -3,32 +3,52 @@ package main
 import (
 	"flag"
 	"strings"
-	"time"
 	"context"
-	"errors"
 
 	"github.com/kataras/iris"
 	"github.com/kataras/iris/hero"
 	v1WebRoutes "github.com/thxcode/etcd-console/backend/v1/web/routes"
 	v1Services "github.com/thxcode/etcd-console/backend/v1/services"
+	v2WebRoutes "github.com/thxcode/etcd-console/backend/v2/web/routes"
+	v1RPC "github.com/thxcode/etcd-console/backend/v1/rpc"
+	"google.golang.org/grpc"
+	"net"
 	"github.com/kataras/iris/core/router"
 	"github.com/thxcode/etcd-console/backend"
+	"github.com/thxcode/etcd-console/backend/audit"
+	"github.com/thxcode/etcd-console/backend/codec"
+	"github.com/thxcode/etcd-console/backend/schema"
+	"github.com/thxcode/etcd-console/backend/v1/web/openapi"
+	"github.com/thxcode/etcd-console/backend/web"
 	"github.com/kataras/iris/middleware/pprof"
 	"github.com/kataras/iris/middleware/recover"
-	"github.com/coreos/etcd/embed"
+	"github.com/thxcode/etcd-console/backend/embedded"
 	"os"
 	"path/filepath"
 	"github.com/iris-contrib/middleware/cors"
+	"github.com/thxcode/etcd-console/cli"
 )
 
 func main() {
+	// run as the client of a console, e.g. "etcd-console get /registry --prefix"
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
 	var (
 		rootCtx, rootCancleFn = context.WithCancel(context.Background())
 		advertise             string
 		test                  bool
+		testMembers           int
+		testDataDir           string
 		endpoints             string
 		logLevel              string
 		backupDir             string
+		startupTimeout        int64
+		versionProbeInterval  int64
+		auditFile             string
+		grpcAdvertise         string
+		readOnly              bool
 		config                string
 
 		configuration backend.Configuration
@@ -39,8 +59,15 @@ func main() {
 	flag.StringVar(&advertise, "advertise", "0.0.0.0:8080", "The address is used for communicating etcd-console data.")
 	flag.StringVar(&endpoints, "endpoints", "http://127.0.0.1:2379", "Specify using endpoints of etcd, splitting by comma.")
 	flag.BoolVar(&test, "test", true, "Start with an embedding etcd or not.")
+	flag.IntVar(&testMembers, "test-members", 1, "How many members the embedding etcd starts, on the loopback ports 2379, 12379 and so on.")
+	flag.StringVar(&testDataDir, "test-data-dir", filepath.Join(os.TempDir(), "etcd_console.etcd"), "Where the embedding etcd stores the data, the members of more than one store in the member-N dirs under it.")
 	flag.StringVar(&logLevel, "log-level", "debug", "Log level of etcd-console.")
 	flag.StringVar(&backupDir, "backup-dir", filepath.Join(os.TempDir(), "etcd_console.backup"), "Where is storing the backup zip files.")
+	flag.Int64Var(&startupTimeout, "startup-timeout", 60, "How long to keep retrying the etcd endpoints at startup in seconds, 0 means forever.")
+	flag.Int64Var(&versionProbeInterval, "version-probe-interval", 30, "How often to re-detect the version of etcd in seconds, 0 means never.")
+	flag.StringVar(&auditFile, "audit-file", filepath.Join(os.TempDir(), "etcd_console.audit", "audit.log"), "Where is appending the audit log, empty means disabled.")
+	flag.StringVar(&grpcAdvertise, "grpc-advertise", "", "The address is used for serving the gRPC API, empty means disabled.")
+	flag.BoolVar(&readOnly, "read-only", false, "Refuse all changes of etcd through etcd-console.")
 	flag.StringVar(&config, "config", "", "Specify the configuration yaml of etcd-console.")
 	flag.Parse()
 
@@ -61,8 +88,15 @@ func main() {
 
 		configuration.Advertise = advertise
 		configuration.Test = test
+		configuration.TestMembers = testMembers
+		configuration.TestDataDir = testDataDir
 		configuration.LogLevel = logLevel
 		configuration.BackupDir = backupDir
+		configuration.StartupTimeout = startupTimeout
+		configuration.VersionProbeInterval = versionProbeInterval
+		configuration.AuditFile = auditFile
+		configuration.ReadOnly = readOnly
+		configuration.GRPCAdvertise = grpcAdvertise
 		endpointArr := strings.Split(endpoints, ",")
 		for idx, endpoint := range endpointArr {
 			endpoint = strings.TrimSpace(endpoint)
@@ -72,37 +106,17 @@ func main() {
 	}
 
 	// test or not
+	var testCluster *embedded.Cluster
 	if configuration.Test {
-		embedEtcdSigsChan := make(chan interface{}, 1)
-
-		go func(rootCtx context.Context) {
-			embedCfg := embed.NewConfig()
-			embedCfg.Dir = filepath.Join(os.TempDir(), "etcd_console.etcd")
-			embedCfg.ForceNewCluster = true
-			embedCfg.LogPkgLevels = "etcdserver=WARNING,security=WARNING,raft=WARNING"
-
-			embedEtcd, err := embed.StartEtcd(embedCfg)
-			if err != nil {
-				embedEtcdSigsChan <- err
-			}
-
-			select {
-			case <-embedEtcd.Server.ReadyNotify():
-				embedEtcdSigsChan <- new(interface{})
-			case <-time.After(60 * time.Second):
-				embedEtcd.Server.Stop() // trigger a shutdown
-				embedEtcdSigsChan <- errors.New("embedding etcd took too long to start")
-			}
-		}(rootCtx)
-
-		sig := <-embedEtcdSigsChan
-		switch sig.(type) {
-		case error:
-			logger.Fatal(sig)
-		default:
-			configuration.Endpoints = []string{"http://localhost:2379"}
-			logger.Info("embedding etcd is started")
+		var err error
+		testCluster, err = embedded.Start(configuration.TestMembers, configuration.TestDataDir)
+		if err != nil {
+			logger.Fatal(err)
 		}
+		defer testCluster.Close()
+
+		configuration.Endpoints = testCluster.Endpoints()
+		logger.Infof("embedding etcd is started with %d members", len(configuration.Endpoints))
 	}
 
 	// create backup dir
@@ -118,13 +132,63 @@ func main() {
 		logger.Fatal("backup path is not a directory")
 	}
 
-	// create etcd client
-	etcdClient := backend.NewEtcdClient(app, configuration)
+	// open audit log
+	var auditLog *audit.Log
+	if len(configuration.AuditFile) != 0 {
+		var err error
+		auditLog, err = audit.Open(configuration.AuditFile, configuration.AuditMaxSize<<20, configuration.AuditMaxBackups, configuration.AuditUserHeader, configuration.RemoteAddrHeaders)
+		if err != nil {
+			logger.Fatalf("audit log cannot be opened, %v", err)
+		}
+		defer auditLog.Close()
+	}
+
+	// create etcd client, it keeps connecting in the background
+	etcdClient := backend.NewEtcdClient(logger, configuration)
+	defer etcdClient.Close()
+
+	// map the codecs by key prefix
+	codecs := codec.NewMapping()
+	for _, codecConfig := range configuration.Codecs {
+		decoder, err := codec.New(codecConfig.Codec, codec.Options{
+			DescriptorSet: codecConfig.DescriptorSet,
+			Message:       codecConfig.Message,
+		})
+		if err != nil {
+			logger.Fatalf("cannot create codec for prefix %s, %v", codecConfig.Prefix, err)
+		}
+		codecs.Add(codecConfig.Prefix, decoder)
+	}
+
+	// map the schemas by key prefix
+	schemas := schema.NewMapping()
+	for _, schemaConfig := range configuration.Schemas {
+		valueSchema, err := schema.Load(schemaConfig.Schema)
+		if err != nil {
+			logger.Fatalf("cannot load schema for prefix %s, %v", schemaConfig.Prefix, err)
+		}
+		schemas.Add(schemaConfig.Prefix, valueSchema)
+	}
 
-	// register services
+	// register services, the dependencies are injected once
+	deps := v1Services.Dependencies{
+		Client:        etcdClient,
+		Configuration: configuration,
+		Codecs:        codecs,
+		Schemas:       schemas,
+		Audit:         auditLog,
+		TestCluster:   testCluster,
+		Logger:        logger,
+	}
+	clusterService := v1Services.NewClusterService(deps)
+	clientService := v1Services.NewClientService(deps)
 	hero.Register(
-		v1Services.NewClusterService(),
-		v1Services.NewClientService(),
+		clusterService,
+		clientService,
+		v1Services.NewMirrorService(deps),
+		v1Services.NewAuditService(deps),
+		v1Services.NewLeaseService(deps),
+		v1Services.NewTestService(deps),
 	)
 
 	// config routes
@@ -132,12 +196,35 @@ func main() {
 
 		apiV1.Any("/cluster/{op: string}", hero.Handler(v1WebRoutes.Cluster))
 		apiV1.Any("/client/{op: string}", hero.Handler(v1WebRoutes.Client))
+		apiV1.Any("/mirror/{op: string}", hero.Handler(v1WebRoutes.Mirror))
+		apiV1.Get("/audit", hero.Handler(v1WebRoutes.Audit))
+		apiV1.Any("/test/{op: string}", hero.Handler(v1WebRoutes.Test))
+		apiV1.Get("/openapi.json", hero.Handler(func(irisCtx iris.Context) hero.Result {
+			return hero.Response{
+				ContentType: "application/json",
+				Content:     []byte(openapi.Spec),
+			}
+		}))
 
 	})
 
+	// resource routes, the methods a resource doesn't support are answered with 405
+	app.PartyFunc("/api/v2", func(apiV2 router.Party) {
+
+		apiV2.Any("/keys", hero.Handler(v2WebRoutes.Keys))
+		apiV2.Any("/keys/{key:path}", hero.Handler(v2WebRoutes.Key))
+		apiV2.Any("/members", hero.Handler(v2WebRoutes.Members))
+		apiV2.Any("/backups", hero.Handler(v2WebRoutes.Backups))
+		apiV2.Any("/backups/{name:string}", hero.Handler(v2WebRoutes.Backup))
+		apiV2.Any("/leases", hero.Handler(v2WebRoutes.Leases))
+		apiV2.Any("/leases/{id:string}", hero.Handler(v2WebRoutes.Lease))
+
+	})
+	app.OnErrorCode(iris.StatusNotFound, web.OnNotFound)
+
 	app.Get("/health", hero.Handler(func (irisCtx iris.Context) hero.Result {
 		return hero.Response{
-			Object: iris.Map{"health": true},
+			Object: iris.Map{"health": true, "etcd": etcdClient.Ready()},
 		}
 	}))
 
@@ -148,13 +235,35 @@ func main() {
 	}))
 	app.Any("/debug/pprof/{action:path}", pprof.New())
 	app.UseGlobal( func(irisCtx iris.Context) {
-		irisCtx.Values().Set("etcd-console.client", etcdClient)
-		irisCtx.Values().Set("etcd-console.config", configuration)
 		irisCtx.Values().Set("etcd-console.ctx", rootCtx)
+		if auditLog != nil {
+			irisCtx.Values().Set("etcd-console.audit", auditLog)
+			audit.CaptureBody(irisCtx)
+		}
 
 		irisCtx.Next()
 	})
 
+	// serve the gRPC API by the services of the routes above
+	if len(configuration.GRPCAdvertise) != 0 {
+		listener, err := net.Listen("tcp", configuration.GRPCAdvertise)
+		if err != nil {
+			logger.Fatalf("cannot listen gRPC on %s, %v", configuration.GRPCAdvertise, err)
+		}
+
+		grpcServer := grpc.NewServer()
+		v1RPC.RegisterConsoleServer(grpcServer, v1RPC.NewServer(clientService, clusterService, auditLog, logger))
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				logger.Errorf("gRPC server is stopped, %v", err)
+			}
+		}()
+		go func() {
+			<-rootCtx.Done()
+			grpcServer.GracefulStop()
+		}()
+	}
+
 	// run app
 	app.Run(
 		iris.Addr(configuration.Advertise),
@@ -0,0 +1,464 @@
+// openapi-gen renders the OpenAPI document of the console into Go,
+// either as a string constant to be served, or as the types and the methods of the API client.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const header = "// Code generated by openapi-gen. DO NOT EDIT.\n\n"
+
+// the words are rendered in upper case in the Go names
+var initialisms = map[string]string{
+	"api":   "API",
+	"db":    "DB",
+	"id":    "ID",
+	"ip":    "IP",
+	"json":  "JSON",
+	"kv":    "KV",
+	"kvs":   "KVS",
+	"ttl":   "TTL",
+	"url":   "URL",
+	"v3api": "V3API",
+	"yaml":  "YAML",
+}
+
+// the methods of a path are rendered in this order
+var methods = []string{"get", "post", "put", "patch", "delete"}
+
+type schema struct {
+	Ref                  string     `json:"$ref"`
+	Type                 string     `json:"type"`
+	Format               string     `json:"format"`
+	Description          string     `json:"description"`
+	Required             []string   `json:"required"`
+	Properties           properties `json:"properties"`
+	Items                *schema    `json:"items"`
+	AdditionalProperties *schema    `json:"additionalProperties"`
+	AllOf                []*schema  `json:"allOf"`
+}
+
+// properties keeps the order of the document, so do the fields of the structs.
+type properties struct {
+	names   []string
+	schemas map[string]*schema
+}
+
+func (p *properties) UnmarshalJSON(data []byte) error {
+	p.schemas = make(map[string]*schema)
+	return decodeOrdered(data, func(name string, decoder *json.Decoder) error {
+		s := &schema{}
+		if err := decoder.Decode(s); err != nil {
+			return err
+		}
+		p.names = append(p.names, name)
+		p.schemas[name] = s
+		return nil
+	})
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *schema `json:"schema"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type content map[string]mediaType
+
+type response struct {
+	Description string  `json:"description"`
+	Content     content `json:"content"`
+}
+
+type operation struct {
+	OperationID string      `json:"operationId"`
+	Summary     string      `json:"summary"`
+	Parameters  []parameter `json:"parameters"`
+	RequestBody *struct {
+		Content content `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]response `json:"responses"`
+	// the binary content of the response is downloaded by another method
+	Download string `json:"x-go-download"`
+}
+
+type paths struct {
+	names []string
+	items map[string]map[string]*operation
+}
+
+func (p *paths) UnmarshalJSON(data []byte) error {
+	p.items = make(map[string]map[string]*operation)
+	return decodeOrdered(data, func(name string, decoder *json.Decoder) error {
+		item := make(map[string]*operation)
+		if err := decoder.Decode(&item); err != nil {
+			return err
+		}
+		p.names = append(p.names, name)
+		p.items[name] = item
+		return nil
+	})
+}
+
+type document struct {
+	Paths      paths `json:"paths"`
+	Components struct {
+		Schemas properties `json:"schemas"`
+	} `json:"components"`
+}
+
+// decodeOrdered walks the members of a json object in order.
+func decodeOrdered(data []byte, fn func(name string, decoder *json.Decoder) error) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil {
+		return err
+	} else if token != json.Delim('{') {
+		return fmt.Errorf("expecting an object, got %v", token)
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if err := fn(token.(string), decoder); err != nil {
+			return err
+		}
+	}
+
+	_, err := decoder.Token()
+	return err
+}
+
+// goName turns a json name into an exported Go name, e.g. "dbSize" into "DBSize".
+func goName(name string) string {
+	var (
+		words []string
+		word  []rune
+	)
+	for _, r := range name {
+		if r == '-' || r == '_' || r == '.' {
+			words = append(words, string(word))
+			word = nil
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) != 0 && !unicode.IsUpper(word[len(word)-1]) {
+			words = append(words, string(word))
+			word = nil
+		}
+		word = append(word, r)
+	}
+	words = append(words, string(word))
+
+	var buf bytes.Buffer
+	for _, word := range words {
+		if len(word) == 0 {
+			continue
+		}
+		if initialism, ok := initialisms[strings.ToLower(word)]; ok {
+			buf.WriteString(initialism)
+			continue
+		}
+		buf.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return buf.String()
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+type generator struct {
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) comment(indent string, text string) {
+	if len(text) == 0 {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		g.printf("%s// %s\n", indent, line)
+	}
+}
+
+// goType maps a schema to the Go type, the optional objects are pointers.
+func (g *generator) goType(s *schema, optional bool) string {
+	if s == nil {
+		return "interface{}"
+	}
+	if len(s.Ref) != 0 {
+		if optional {
+			return "*" + refName(s.Ref)
+		}
+		return refName(s.Ref)
+	}
+
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			g.imports["time"] = true
+			return "time.Time"
+		}
+		return "string"
+	case "integer":
+		if s.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(s.Items, false)
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "map[string]" + g.goType(s.AdditionalProperties, false)
+		}
+	}
+	return "interface{}"
+}
+
+func (g *generator) fields(s *schema) {
+	required := make(map[string]bool)
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	for _, name := range s.Properties.names {
+		property := s.Properties.schemas[name]
+
+		tag := name
+		if !required[name] {
+			tag += ",omitempty"
+		}
+		g.comment("\t", property.Description)
+		g.printf("\t%s %s `json:\"%s\"`\n", goName(name), g.goType(property, !required[name]), tag)
+	}
+}
+
+func (g *generator) types(schemas properties) {
+	for _, name := range schemas.names {
+		s := schemas.schemas[name]
+
+		g.comment("", s.Description)
+		if s.Type != "object" && len(s.AllOf) == 0 {
+			g.printf("type %s %s\n\n", name, g.goType(s, false))
+			continue
+		}
+
+		g.printf("type %s struct {\n", name)
+		for _, part := range s.AllOf {
+			if len(part.Ref) != 0 {
+				g.printf("\t%s\n", refName(part.Ref))
+			} else {
+				g.fields(part)
+			}
+		}
+		g.fields(s)
+		g.printf("}\n\n")
+	}
+}
+
+// params renders the query parameters of an operation into a struct, the zero values are left out.
+func (g *generator) params(name string, params []parameter) {
+	g.printf("// %s are the query parameters of %s.\n", name, strings.TrimSuffix(name, "Params"))
+	g.printf("type %s struct {\n", name)
+	for _, param := range params {
+		description := param.Description
+		if param.Required {
+			description = strings.TrimSpace("Required. " + description)
+		}
+		g.comment("\t", description)
+		g.printf("\t%s %s\n", goName(param.Name), g.goType(param.Schema, false))
+	}
+	g.printf("}\n\n")
+
+	g.imports["net/url"] = true
+	g.printf("func (p *%s) values() url.Values {\n", name)
+	g.printf("\tvalues := url.Values{}\n")
+	g.printf("\tif p == nil {\n\t\treturn values\n\t}\n")
+	for _, param := range params {
+		field := "p." + goName(param.Name)
+		switch g.goType(param.Schema, false) {
+		case "string":
+			g.printf("\tif len(%s) != 0 {\n\t\tvalues.Set(%q, %s)\n\t}\n", field, param.Name, field)
+		case "int64":
+			g.imports["strconv"] = true
+			g.printf("\tif %s != 0 {\n\t\tvalues.Set(%q, strconv.FormatInt(%s, 10))\n\t}\n", field, param.Name, field)
+		case "int32":
+			g.imports["strconv"] = true
+			g.printf("\tif %s != 0 {\n\t\tvalues.Set(%q, strconv.FormatInt(int64(%s), 10))\n\t}\n", field, param.Name, field)
+		case "bool":
+			g.printf("\tif %s {\n\t\tvalues.Set(%q, \"true\")\n\t}\n", field, param.Name)
+		default:
+			log.Fatalf("cannot render the query parameter %s", param.Name)
+		}
+	}
+	g.printf("\treturn values\n}\n\n")
+}
+
+func (g *generator) operation(path string, method string, op *operation) {
+	name := goName(op.OperationID)
+
+	var (
+		args        = []string{"ctx context.Context"}
+		bodyArg     = "nil"
+		contentType = ""
+		queryArg    = "nil"
+		paramsName  = name + "Params"
+	)
+	if op.RequestBody != nil {
+		if body, ok := op.RequestBody.Content["application/json"]; ok {
+			args = append(args, "body *"+refName(body.Schema.Ref))
+			bodyArg = "body"
+			contentType = "application/json"
+		} else {
+			g.imports["io"] = true
+			args = append(args, "body io.Reader")
+			bodyArg = "body"
+			contentType = "application/octet-stream"
+		}
+	}
+	if len(op.Parameters) != 0 {
+		g.params(paramsName, op.Parameters)
+		args = append(args, "params *"+paramsName)
+		queryArg = "params.values()"
+	}
+
+	httpMethod := "http.Method" + strings.ToUpper(method[:1]) + method[1:]
+	g.imports["net/http"] = true
+
+	summary := fmt.Sprintf("%s calls %s %s to %s", name, strings.ToUpper(method), path,
+		strings.ToLower(op.Summary[:1])+op.Summary[1:])
+
+	ok := op.Responses["200"]
+	if jsonContent, isJSON := ok.Content["application/json"]; isJSON && len(jsonContent.Schema.Ref) != 0 {
+		out := refName(jsonContent.Schema.Ref)
+		g.printf("// %s.\n", summary)
+		g.printf("func (c *Client) %s(%s) (*%s, error) {\n", name, strings.Join(args, ", "), out)
+		g.printf("\tout := &%s{}\n", out)
+		g.printf("\tif err := c.do(ctx, %s, %q, %s, %s, %q, out); err != nil {\n\t\treturn nil, err\n\t}\n", httpMethod, path, queryArg, bodyArg, contentType)
+		g.printf("\treturn out, nil\n}\n\n")
+
+		if len(op.Download) != 0 {
+			g.imports["io"] = true
+			g.printf("// %s downloads the binary content of %s %s, the caller closes the content.\n", op.Download, strings.ToUpper(method), path)
+			g.printf("func (c *Client) %s(%s) (io.ReadCloser, error) {\n", op.Download, strings.Join(args, ", "))
+			g.printf("\treturn c.stream(ctx, %s, %q, %s, %s, %q)\n}\n\n", httpMethod, path, queryArg, bodyArg, contentType)
+		}
+	} else if _, isBinary := ok.Content["application/octet-stream"]; isBinary {
+		g.imports["io"] = true
+		g.printf("// %s, the caller closes the content.\n", summary)
+		g.printf("func (c *Client) %s(%s) (io.ReadCloser, error) {\n", name, strings.Join(args, ", "))
+		g.printf("\treturn c.stream(ctx, %s, %q, %s, %s, %q)\n}\n\n", httpMethod, path, queryArg, bodyArg, contentType)
+	} else {
+		g.printf("// %s.\n", summary)
+		g.printf("func (c *Client) %s(%s) error {\n", name, strings.Join(args, ", "))
+		g.printf("\treturn c.do(ctx, %s, %q, %s, %s, %q, nil)\n}\n\n", httpMethod, path, queryArg, bodyArg, contentType)
+	}
+}
+
+func renderClient(doc *document, pkg string) []byte {
+	g := &generator{imports: map[string]bool{"context": true}}
+
+	g.types(doc.Components.Schemas)
+	for _, path := range doc.Paths.names {
+		item := doc.Paths.items[path]
+		for _, method := range methods {
+			if op, ok := item[method]; ok {
+				g.operation(path, method, op)
+			}
+		}
+	}
+
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+
+	var out bytes.Buffer
+	out.WriteString(header)
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", pkg)
+	for _, imp := range imports {
+		fmt.Fprintf(&out, "\t%q\n", imp)
+	}
+	out.WriteString(")\n\n")
+	out.Write(g.buf.Bytes())
+	return out.Bytes()
+}
+
+func renderSpec(data []byte, pkg string) []byte {
+	if bytes.IndexByte(data, '`') != -1 {
+		log.Fatal("the document cannot be quoted in a raw string")
+	}
+
+	var out bytes.Buffer
+	out.WriteString(header)
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+	out.WriteString("// Spec is the OpenAPI document of the API, served at /api/v1/openapi.json.\n")
+	fmt.Fprintf(&out, "const Spec = `%s`\n", bytes.TrimSpace(data))
+	return out.Bytes()
+}
+
+func main() {
+	var (
+		mode = flag.String("mode", "client", "what to render, spec or client")
+		in   = flag.String("in", "openapi.json", "the OpenAPI document")
+		out  = flag.String("out", "", "the Go file to write")
+		pkg  = flag.String("package", "", "the package of the Go file")
+	)
+	flag.Parse()
+
+	if len(*out) == 0 || len(*pkg) == 0 {
+		log.Fatal(`"-out" and "-package" are required`)
+	}
+
+	data, err := ioutil.ReadFile(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var source []byte
+	switch *mode {
+	case "spec":
+		source = renderSpec(data, *pkg)
+	case "client":
+		doc := &document{}
+		if err := json.Unmarshal(data, doc); err != nil {
+			log.Fatalf("bad document %s, %v", *in, err)
+		}
+		source = renderClient(doc, *pkg)
+	default:
+		log.Fatalf("unknown mode %s, choose one of spec and client", *mode)
+	}
+
+	formatted, err := format.Source(source)
+	if err != nil {
+		log.Fatalf("cannot format the rendered code, %v", err)
+	}
+	if err := ioutil.WriteFile(*out, formatted, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
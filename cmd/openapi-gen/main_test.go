@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/format"
+	"io/ioutil"
+	"testing"
+)
+
+// the generated files are checked in, so they must follow the document
+func TestGeneratedUpToDate(t *testing.T) {
+	data, err := ioutil.ReadFile("../../backend/v1/web/openapi/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &document{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		t.Fatalf("bad document, %v", err)
+	}
+
+	tests := []struct {
+		file   string
+		source []byte
+	}{
+		{file: "../../backend/v1/web/openapi/spec.go", source: renderSpec(data, "openapi")},
+		{file: "../../client/client_gen.go", source: renderClient(doc, "client")},
+	}
+	for _, tt := range tests {
+		formatted, err := format.Source(tt.source)
+		if err != nil {
+			t.Fatalf("cannot format the rendered %s, %v", tt.file, err)
+		}
+		generated, err := ioutil.ReadFile(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(formatted, generated) {
+			t.Errorf("%s is not generated from openapi.json, run go generate", tt.file)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"dbSize":         "DBSize",
+		"kvs":            "KVS",
+		"createRevision": "CreateRevision",
+		"grantedTTL":     "GrantedTTL",
+		"v3api":          "V3API",
+	}
+	for name, want := range tests {
+		if got := goName(name); got != want {
+			t.Errorf("goName(%s) = %s, want %s", name, got, want)
+		}
+	}
+}
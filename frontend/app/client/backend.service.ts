@@ -278,7 +278,7 @@ export class BackendService {
 
   private processHTTPErrorClient(err: HttpErrorResponse) {
     if (err.error) {
-      return Observable.throw(err.error);
+      return Observable.throw(err.error.message ? err.error.message : err.error);
     } else {
       let errMsg = (err.message) ? err.message :
         err.status ? `${err.status} - ${err.statusText}` : 'Server error';
@@ -113,7 +113,12 @@ export class BackendService {
   }
 
   private processHTTPErrorCluster(error: any) {
-    let errMsg = (error.message) ? error.message :
+    let errJson: any = {};
+    try {
+      errJson = error.json();
+    } catch (err) {}
+
+    let errMsg = errJson.message ? errJson.message : (error.message) ? error.message :
       error.status ? `${error.status} - ${error.statusText}` : 'Server error';
     return Observable.throw(errMsg);
   }